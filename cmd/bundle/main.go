@@ -2,17 +2,18 @@
 // Use of this source code is governed by a BSD-style
 // license that can be found in the LICENSE file.
 
-// Bundle creates a single-source-file version of a source package
-// suitable for inclusion in a particular target package.
+// Bundle creates a single-source-file version of one or more source
+// packages suitable for inclusion in a particular target package.
 //
 // Usage:
 //
-//	bundle [-o file] [-dst path] [-pkg name] [-prefix p] [-import old=new] [-tags build_constraints] <src>
+//	bundle [-o file] [-dst path] [-pkg name] [-prefix p] [-import old=new] [-tags build_constraints] [-deps] <src> ...
 //
-// The src argument specifies the import path of the package to bundle.
+// The src arguments specify the import paths of the packages to bundle.
 // The bundling of a directory of source files into a single source file
 // necessarily imposes a number of constraints.
-// The package being bundled must not use cgo; must not use conditional
+// The package being bundled must not use cgo, which bundle reports as an
+// error; must not use conditional
 // file compilation, whether with build tags or system-specific file names
 // like code_amd64.go; must not depend on any special comments, which
 // may not be preserved; must not use any assembly sources;
@@ -41,7 +42,23 @@
 // every package-level const, func, type, and var identifier in src's code,
 // updating references accordingly. The default prefix is the package name
 // of the source package followed by an underscore. The -prefix option
-// specifies an alternate prefix.
+// specifies an alternate prefix, in which each "&" stands for the name
+// of the package.
+//
+// When several packages are bundled together, each is given its own
+// prefix, and references from one bundled package to another become
+// direct references to the renamed symbols. It is an error if two
+// packages would declare the same renamed identifier, as may happen
+// if the -prefix option does not contain "&".
+// The -deps option additionally bundles, transitively, every dependency
+// of the source packages that belongs to the same module, such as its
+// internal packages, so that the output does not import them.
+//
+// When several packages are bundled together, the distinct comments
+// preceding the package clause of each source file, such as copyright
+// and license notices, are preserved at the start of the output.
+// The src arguments may then also be patterns, such as ./..., that
+// match several packages.
 //
 // Occasionally it is necessary to rewrite imports during the bundling
 // process. The -import option, which may be repeated, specifies that
@@ -72,15 +89,21 @@ package main
 
 import (
 	"bytes"
+	"cmp"
 	"flag"
 	"fmt"
 	"go/ast"
+	"go/build"
+	"go/build/constraint"
 	"go/format"
+	"go/parser"
 	"go/printer"
 	"go/token"
 	"go/types"
 	"log"
 	"os"
+	"path/filepath"
+	"slices"
 	"strconv"
 	"strings"
 	"unicode"
@@ -94,6 +117,7 @@ var (
 	pkgName    = flag.String("pkg", "", "set destination package `name`")
 	prefix     = flag.String("prefix", "&_", "set bundled identifier prefix to `p` (default is \"&_\", where & stands for the original name)")
 	buildTags  = flag.String("tags", "", "the build constraints to be inserted into the generated file")
+	bundleDeps = flag.Bool("deps", false, "also bundle dependencies in the same module as the source packages")
 
 	importMap = map[string]string{}
 )
@@ -115,7 +139,7 @@ func addImportMap(s string) {
 }
 
 func usage() {
-	fmt.Fprintf(os.Stderr, "Usage: bundle [options] <src>\n")
+	fmt.Fprintf(os.Stderr, "Usage: bundle [options] <src> ...\n")
 	flag.PrintDefaults()
 }
 
@@ -126,7 +150,7 @@ func main() {
 	flag.Usage = usage
 	flag.Parse()
 	args := flag.Args()
-	if len(args) != 1 {
+	if len(args) == 0 {
 		usage()
 		os.Exit(2)
 	}
@@ -143,7 +167,7 @@ func main() {
 		*pkgName = pkgs[0].Name
 	}
 
-	code, err := bundle(args[0], pkgs[0].PkgPath, *pkgName, *prefix, *buildTags)
+	code, err := bundle(args, pkgs[0].PkgPath, *pkgName, *prefix, *buildTags, *bundleDeps)
 	if err != nil {
 		log.Fatal(err)
 	}
@@ -172,8 +196,8 @@ func isStandardImportPath(path string) bool {
 
 var testingOnlyPackagesConfig *packages.Config
 
-func bundle(src, dst, dstpkg, prefix, buildTags string) ([]byte, error) {
-	// Load the initial package.
+func bundle(srcs []string, dst, dstpkg, prefix, buildTags string, deps bool) ([]byte, error) {
+	// Load the initial packages.
 	cfg := &packages.Config{}
 	if testingOnlyPackagesConfig != nil {
 		*cfg = *testingOnlyPackagesConfig
@@ -182,25 +206,66 @@ func bundle(src, dst, dstpkg, prefix, buildTags string) ([]byte, error) {
 		// std module vendor folder.
 		cfg.Env = append(os.Environ(), "GOFLAGS=-mod=mod")
 	}
-	cfg.Mode = packages.NeedTypes | packages.NeedSyntax | packages.NeedTypesInfo
-	pkgs, err := packages.Load(cfg, src)
+	cfg.Mode = packages.NeedName | packages.NeedFiles | packages.NeedTypes | packages.NeedSyntax | packages.NeedTypesInfo
+	if len(srcs) > 1 || deps || isPattern(srcs[0]) {
+		// References between bundled packages are resolved by
+		// object identity, so they must be type-checked from
+		// source as a single graph.
+		cfg.Mode |= packages.NeedImports | packages.NeedDeps | packages.NeedModule
+	}
+	roots, err := packages.Load(cfg, srcs...)
 	if err != nil {
 		return nil, err
 	}
-	if packages.PrintErrors(pkgs) > 0 || len(pkgs) != 1 {
-		return nil, fmt.Errorf("failed to load source package")
+	if packages.PrintErrors(roots) > 0 {
+		if len(srcs) == 1 {
+			return nil, fmt.Errorf("failed to load source package")
+		}
+		return nil, fmt.Errorf("failed to load source packages")
+	}
+	if len(roots) == 0 {
+		return nil, fmt.Errorf("no packages match %s", strings.Join(srcs, " "))
 	}
-	pkg := pkgs[0]
 
-	if strings.Contains(prefix, "&") {
-		prefix = strings.Replace(prefix, "&", pkg.Syntax[0].Name.Name, -1)
+	// Print the source packages in command-line order, and those
+	// matched by a pattern, such as ./..., in order of their paths.
+	slices.SortStableFunc(roots, func(x, y *packages.Package) int {
+		return cmp.Or(
+			cmp.Compare(srcIndex(srcs, x), srcIndex(srcs, y)),
+			strings.Compare(x.PkgPath, y.PkgPath))
+	})
+
+	// Determine the packages to bundle: the source packages and,
+	// with -deps, their dependencies within the same module.
+	pkgs := roots
+	bundled := make(map[string]bool) // set of bundled package paths
+	for _, pkg := range roots {
+		bundled[pkg.Types.Path()] = true
+	}
+	if deps {
+		var extra []*packages.Package
+		packages.Visit(roots, nil, func(pkg *packages.Package) {
+			if !bundled[pkg.PkgPath] && pkg.PkgPath != dst && sameModule(pkg, roots) {
+				bundled[pkg.PkgPath] = true
+				extra = append(extra, pkg)
+			}
+		})
+		slices.SortFunc(extra, func(x, y *packages.Package) int { return strings.Compare(x.PkgPath, y.PkgPath) })
+		pkgs = append(pkgs, extra...)
+	}
+
+	for _, pkg := range pkgs {
+		if usesCgo(pkg) {
+			return nil, fmt.Errorf("package %s uses cgo, which cannot be bundled", pkg.PkgPath)
+		}
 	}
 
-	objsToUpdate := make(map[types.Object]bool)
-	var rename func(from types.Object)
-	rename = func(from types.Object) {
-		if !objsToUpdate[from] {
-			objsToUpdate[from] = true
+	// newNames maps each object to be renamed to its new name.
+	newNames := make(map[types.Object]string)
+	var rename func(from types.Object, prefix string)
+	rename = func(from types.Object, prefix string) {
+		if _, ok := newNames[from]; !ok {
+			newNames[from] = prefix + from.Name()
 
 			// Renaming a type that is used as an embedded field
 			// requires renaming the field too. e.g.
@@ -208,10 +273,12 @@ func bundle(src, dst, dstpkg, prefix, buildTags string) ([]byte, error) {
 			// 	var s struct {T}
 			// 	print(s.T) // ...this must change too
 			if _, ok := from.(*types.TypeName); ok {
-				for id, obj := range pkg.TypesInfo.Uses {
-					if obj == from {
-						if field := pkg.TypesInfo.Defs[id]; field != nil {
-							rename(field)
+				for _, pkg := range pkgs {
+					for id, obj := range pkg.TypesInfo.Uses {
+						if obj == from {
+							if field := pkg.TypesInfo.Defs[id]; field != nil {
+								rename(field, prefix)
+							}
 						}
 					}
 				}
@@ -219,10 +286,22 @@ func bundle(src, dst, dstpkg, prefix, buildTags string) ([]byte, error) {
 		}
 	}
 
-	// Rename each package-level object.
-	scope := pkg.Types.Scope()
-	for _, name := range scope.Names() {
-		rename(scope.Lookup(name))
+	// Rename each package-level object, using a prefix specific to
+	// its package, and check that no two new names collide.
+	declared := make(map[string]*types.Package) // new name -> package
+	for _, pkg := range pkgs {
+		prefix := strings.ReplaceAll(prefix, "&", pkg.Types.Name())
+		scope := pkg.Types.Scope()
+		for _, name := range scope.Names() {
+			obj := scope.Lookup(name)
+			rename(obj, prefix)
+			newName := newNames[obj]
+			if other, ok := declared[newName]; ok && other != pkg.Types {
+				return nil, fmt.Errorf("bundled identifier %s of package %s collides with that of package %s (use a -prefix containing \"&\")",
+					newName, pkg.Types.Path(), other.Path())
+			}
+			declared[newName] = pkg.Types
+		}
 	}
 
 	var out bytes.Buffer
@@ -238,11 +317,30 @@ func bundle(src, dst, dstpkg, prefix, buildTags string) ([]byte, error) {
 	}
 	fmt.Fprintf(&out, "\n")
 
-	// Concatenate package comments from all files...
-	for _, f := range pkg.Syntax {
-		if doc := f.Doc.Text(); strings.TrimSpace(doc) != "" {
-			for line := range strings.SplitSeq(doc, "\n") {
-				fmt.Fprintf(&out, "// %s\n", line)
+	// When bundling several packages, preserve their distinct header
+	// comments, such as license notices...
+	if len(pkgs) > 1 {
+		seen := make(map[string]bool)
+		for _, pkg := range pkgs {
+			for _, f := range pkg.Syntax {
+				for _, header := range headerComments(f) {
+					if !seen[header] {
+						seen[header] = true
+						fmt.Fprintf(&out, "%s\n\n", header)
+					}
+				}
+			}
+		}
+	}
+
+	// ...and concatenate package comments from all files of the
+	// source packages...
+	for _, pkg := range roots {
+		for _, f := range pkg.Syntax {
+			if doc := f.Doc.Text(); strings.TrimSpace(doc) != "" {
+				for line := range strings.SplitSeq(doc, "\n") {
+					fmt.Fprintf(&out, "// %s\n", line)
+				}
 			}
 		}
 	}
@@ -268,28 +366,45 @@ func bundle(src, dst, dstpkg, prefix, buildTags string) ([]byte, error) {
 	// to deduplicate instances of the same import name and path.
 	var pkgStd = make(map[string]bool)
 	var pkgExt = make(map[string]bool)
-	for _, f := range pkg.Syntax {
-		for _, imp := range f.Imports {
-			path, err := strconv.Unquote(imp.Path.Value)
-			if err != nil {
-				log.Fatalf("invalid import path string: %v", err) // Shouldn't happen here since packages.Load succeeded.
-			}
-			if path == dst {
-				continue
-			}
-			if newPath, ok := importMap[path]; ok {
-				path = newPath
-			}
+	importedAs := make(map[string]string) // local name -> import path
+	for _, pkg := range pkgs {
+		for _, f := range pkg.Syntax {
+			for _, imp := range f.Imports {
+				path, err := strconv.Unquote(imp.Path.Value)
+				if err != nil {
+					log.Fatalf("invalid import path string: %v", err) // Shouldn't happen here since packages.Load succeeded.
+				}
+				if path == dst || bundled[path] {
+					continue
+				}
+				if newPath, ok := importMap[path]; ok {
+					path = newPath
+				}
 
-			var name string
-			if imp.Name != nil {
-				name = imp.Name.Name
-			}
-			spec := fmt.Sprintf("%s %q", name, path)
-			if isStandardImportPath(path) {
-				pkgStd[spec] = true
-			} else {
-				pkgExt[spec] = true
+				var name string
+				if imp.Name != nil {
+					name = imp.Name.Name
+				}
+
+				// All imports share a single file scope,
+				// so each name must denote a single package.
+				local := name
+				if pkgName := pkg.TypesInfo.PkgNameOf(imp); pkgName != nil && local == "" {
+					local = pkgName.Name()
+				}
+				if local != "" && local != "_" && local != "." {
+					if prev, ok := importedAs[local]; ok && prev != path {
+						return nil, fmt.Errorf("packages %q and %q are both imported as %s", prev, path, local)
+					}
+					importedAs[local] = path
+				}
+
+				spec := fmt.Sprintf("%s %q", name, path)
+				if isStandardImportPath(path) {
+					pkgStd[spec] = true
+				} else {
+					pkgExt[spec] = true
+				}
 			}
 		}
 	}
@@ -308,79 +423,170 @@ func bundle(src, dst, dstpkg, prefix, buildTags string) ([]byte, error) {
 	fmt.Fprint(&out, ")\n\n")
 
 	// Modify and print each file.
-	for _, f := range pkg.Syntax {
+	for _, pkg := range pkgs {
 		// Update renamed identifiers.
 		for id, obj := range pkg.TypesInfo.Defs {
-			if objsToUpdate[obj] {
-				id.Name = prefix + obj.Name()
+			if newName, ok := newNames[obj]; ok {
+				id.Name = newName
 			}
 		}
 		for id, obj := range pkg.TypesInfo.Uses {
-			if objsToUpdate[obj] {
-				id.Name = prefix + obj.Name()
+			if newName, ok := newNames[obj]; ok {
+				id.Name = newName
 			}
 		}
 
-		// For each qualified identifier that refers to the
-		// destination package, remove the qualifier.
-		// The "@@@." strings are removed in postprocessing.
-		ast.Inspect(f, func(n ast.Node) bool {
-			if sel, ok := n.(*ast.SelectorExpr); ok {
-				if id, ok := sel.X.(*ast.Ident); ok {
-					if obj, ok := pkg.TypesInfo.Uses[id].(*types.PkgName); ok {
-						if obj.Imported().Path() == dst {
-							id.Name = "@@@"
-						}
+		for _, f := range pkg.Syntax {
+			printFile(&out, pkg, f, dst, bundled)
+		}
+	}
+
+	// Now format the entire thing.
+	result, err := format.Source(out.Bytes())
+	if err != nil {
+		log.Fatalf("formatting failed: %v", err)
+	}
+
+	return result, nil
+}
+
+// printFile prints the package-level declarations of file f of a
+// bundled package, with qualified references to the destination
+// package and other bundled packages made unqualified.
+func printFile(out *bytes.Buffer, pkg *packages.Package, f *ast.File, dst string, bundled map[string]bool) {
+	// For each qualified identifier that refers to the
+	// destination package or a bundled package, remove the qualifier.
+	// The "@@@." strings are removed in postprocessing.
+	ast.Inspect(f, func(n ast.Node) bool {
+		if sel, ok := n.(*ast.SelectorExpr); ok {
+			if id, ok := sel.X.(*ast.Ident); ok {
+				if obj, ok := pkg.TypesInfo.Uses[id].(*types.PkgName); ok {
+					if path := obj.Imported().Path(); path == dst || bundled[path] {
+						id.Name = "@@@"
 					}
 				}
 			}
-			return true
-		})
-
-		last := f.Package
-		if len(f.Imports) > 0 {
-			imp := f.Imports[len(f.Imports)-1]
-			last = imp.End()
-			if imp.Comment != nil {
-				if e := imp.Comment.End(); e > last {
-					last = e
-				}
+		}
+		return true
+	})
+
+	last := f.Package
+	if len(f.Imports) > 0 {
+		imp := f.Imports[len(f.Imports)-1]
+		last = imp.End()
+		if imp.Comment != nil {
+			if e := imp.Comment.End(); e > last {
+				last = e
 			}
 		}
+	}
 
-		// Pretty-print package-level declarations.
-		// but no package or import declarations.
-		var buf bytes.Buffer
-		for _, decl := range f.Decls {
-			if decl, ok := decl.(*ast.GenDecl); ok && decl.Tok == token.IMPORT {
-				continue
-			}
+	// Pretty-print package-level declarations.
+	// but no package or import declarations.
+	var buf bytes.Buffer
+	for _, decl := range f.Decls {
+		if decl, ok := decl.(*ast.GenDecl); ok && decl.Tok == token.IMPORT {
+			continue
+		}
 
-			beg, end := sourceRange(decl)
+		beg, end := sourceRange(decl)
 
-			printComments(&out, f.Comments, last, beg)
+		printComments(out, f.Comments, last, beg)
 
-			buf.Reset()
-			format.Node(&buf, pkg.Fset, &printer.CommentedNode{Node: decl, Comments: f.Comments})
-			// Remove each "@@@." in the output.
-			// TODO(adonovan): not hygienic.
-			out.Write(bytes.Replace(buf.Bytes(), []byte("@@@."), nil, -1))
+		buf.Reset()
+		format.Node(&buf, pkg.Fset, &printer.CommentedNode{Node: decl, Comments: f.Comments})
+		// Remove each "@@@." in the output.
+		// TODO(adonovan): not hygienic.
+		out.Write(bytes.Replace(buf.Bytes(), []byte("@@@."), nil, -1))
 
-			last = printSameLineComment(&out, f.Comments, pkg.Fset, end)
+		last = printSameLineComment(out, f.Comments, pkg.Fset, end)
 
-			out.WriteString("\n\n")
+		out.WriteString("\n\n")
+	}
+
+	printLastComments(out, f.Comments, last)
+}
+
+// isPattern reports whether the package argument src may match several
+// packages.
+func isPattern(src string) bool {
+	switch src {
+	case "all", "cmd", "std", "tool", "work":
+		return true
+	}
+	return strings.Contains(src, "...")
+}
+
+// srcIndex returns the index of the package argument that names pkg,
+// by import path or directory, or len(srcs) if it was matched by a
+// pattern.
+func srcIndex(srcs []string, pkg *packages.Package) int {
+	for i, src := range srcs {
+		if src == pkg.PkgPath {
+			return i
 		}
+		if build.IsLocalImport(src) || filepath.IsAbs(src) {
+			if dir, err := filepath.Abs(src); err == nil && dir == pkg.Dir {
+				return i
+			}
+		}
+	}
+	return len(srcs)
+}
 
-		printLastComments(&out, f.Comments, last)
+// usesCgo reports whether a Go file of pkg imports "C".
+func usesCgo(pkg *packages.Package) bool {
+	for _, filename := range pkg.GoFiles {
+		f, err := parser.ParseFile(token.NewFileSet(), filename, nil, parser.ImportsOnly)
+		if err != nil {
+			continue // reported by type checking
+		}
+		for _, imp := range f.Imports {
+			if imp.Path.Value == `"C"` {
+				return true
+			}
+		}
 	}
+	return false
+}
 
-	// Now format the entire thing.
-	result, err := format.Source(out.Bytes())
-	if err != nil {
-		log.Fatalf("formatting failed: %v", err)
+// sameModule reports whether pkg belongs to the module of one of the
+// source packages.
+func sameModule(pkg *packages.Package, roots []*packages.Package) bool {
+	if pkg.Module == nil {
+		return false
+	}
+	for _, root := range roots {
+		if root.Module != nil && root.Module.Path == pkg.Module.Path {
+			return true
+		}
 	}
+	return false
+}
 
-	return result, nil
+// headerComments returns the text of each comment group that precedes
+// the package clause of f, such as a copyright notice, other than the
+// package doc comment and build constraints.
+func headerComments(f *ast.File) []string {
+	var headers []string
+	for _, cg := range f.Comments {
+		if cg.Pos() >= f.Package {
+			break
+		}
+		if cg == f.Doc {
+			continue
+		}
+		var lines []string
+		for _, c := range cg.List {
+			if !constraint.IsGoBuild(c.Text) && !constraint.IsPlusBuild(c.Text) {
+				lines = append(lines, c.Text)
+			}
+		}
+		if len(lines) > 0 {
+			headers = append(headers, strings.Join(lines, "\n"))
+		}
+	}
+	return headers
 }
 
 // sourceRange returns the [beg, end) interval of source code
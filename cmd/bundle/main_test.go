@@ -9,9 +9,11 @@ import (
 	"os"
 	"os/exec"
 	"runtime"
+	"strings"
 	"testing"
 
 	"golang.org/x/tools/internal/packagestest"
+	"golang.org/x/tools/internal/testenv"
 )
 
 func TestBundle(t *testing.T) { packagestest.TestAll(t, testBundle) }
@@ -44,7 +46,7 @@ func testBundle(t *testing.T, x packagestest.Exporter) {
 	testingOnlyPackagesConfig = e.Config
 
 	os.Args = os.Args[:1] // avoid e.g. -test=short in the output
-	out, err := bundle("initial", "github.com/dest", "dest", "prefix", "tag")
+	out, err := bundle([]string{"initial"}, "github.com/dest", "dest", "prefix", "tag", false)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -59,6 +61,89 @@ func testBundle(t *testing.T, x packagestest.Exporter) {
 	}
 }
 
+// TestBundleMultiple tests the bundling of several source packages
+// together with their internal dependencies, which requires modules.
+func TestBundleMultiple(t *testing.T) {
+	load := func(name string) string {
+		data, err := os.ReadFile(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(data)
+	}
+
+	e := packagestest.Export(t, packagestest.Modules, []packagestest.Module{
+		{
+			Name: "example.com/multi",
+			Files: map[string]any{
+				"a/a.go":          load("testdata/src/example.com/multi/a/a.go"),
+				"b/b.go":          load("testdata/src/example.com/multi/b/b.go"),
+				"internal/c/c.go": load("testdata/src/example.com/multi/internal/c/c.go"),
+			},
+		},
+	})
+	defer e.Cleanup()
+	testingOnlyPackagesConfig = e.Config
+	defer func() { testingOnlyPackagesConfig = nil }()
+
+	os.Args = os.Args[:1] // avoid e.g. -test=short in the output
+	srcs := []string{"example.com/multi/a", "example.com/multi/b"}
+	out, err := bundle(srcs, "github.com/dest", "dest", "&_", "", true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got, want := string(out), load("testdata/multi.golden"); got != want {
+		t.Errorf("-- got --\n%s\n-- want --\n%s\n-- diff --", got, want)
+
+		if err := os.WriteFile("testdata/multi.got", out, 0644); err != nil {
+			t.Fatal(err)
+		}
+		t.Log(diff("testdata/multi.golden", "testdata/multi.got"))
+	}
+
+	// A pattern bundles the packages it matches in order of their paths.
+	want, err := bundle([]string{"example.com/multi/a", "example.com/multi/b", "example.com/multi/internal/c"}, "github.com/dest", "dest", "&_", "", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := bundle([]string{"example.com/multi/..."}, "github.com/dest", "dest", "&_", "", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != string(want) {
+		t.Errorf("bundle with pattern: got\n%s\nwant\n%s", got, want)
+	}
+
+	// A prefix common to all packages causes identifiers to collide.
+	_, err = bundle(srcs, "github.com/dest", "dest", "x_", "", true)
+	if want := "bundled identifier x_New of package example.com/multi/b collides with that of package example.com/multi/a"; err == nil || !strings.Contains(err.Error(), want) {
+		t.Errorf("bundle with common prefix: got error %v, want %q", err, want)
+	}
+}
+
+// TestBundleCgo tests that bundle refuses packages that use cgo.
+func TestBundleCgo(t *testing.T) {
+	testenv.NeedsTool(t, "cgo")
+
+	e := packagestest.Export(t, packagestest.Modules, []packagestest.Module{
+		{
+			Name: "example.com/cgo",
+			Files: map[string]any{
+				"p/p.go": "package p\n\nimport \"C\"\n\nfunc F() C.int { return 0 }\n",
+			},
+		},
+	})
+	defer e.Cleanup()
+	testingOnlyPackagesConfig = e.Config
+	defer func() { testingOnlyPackagesConfig = nil }()
+
+	_, err := bundle([]string{"example.com/cgo/p"}, "github.com/dest", "dest", "&_", "", false)
+	if want := "package example.com/cgo/p uses cgo, which cannot be bundled"; err == nil || err.Error() != want {
+		t.Errorf("bundle of cgo package: got error %v, want %q", err, want)
+	}
+}
+
 func diff(a, b string) string {
 	var cmd *exec.Cmd
 	switch runtime.GOOS {
@@ -0,0 +1,22 @@
+// Copyright 2020 The Multi Authors.
+// Use of this source code is governed by a license.
+
+// Package a is the first source package.
+package a
+
+import (
+	"fmt"
+
+	"example.com/multi/b"
+	"example.com/multi/internal/c"
+)
+
+// T is a type that embeds a type of package b.
+type T struct {
+	b.T
+}
+
+// New is declared by both packages a and b.
+func New() *T { return &T{b.New()} }
+
+func (t *T) String() string { return fmt.Sprint(t.T.N, c.Double(t.T.N)) }
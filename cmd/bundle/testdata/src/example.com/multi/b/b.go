@@ -0,0 +1,12 @@
+// Copyright 2020 The Multi Authors.
+// Use of this source code is governed by a license.
+
+// Package b is the second source package.
+package b
+
+import cc "example.com/multi/internal/c"
+
+// T is declared by both packages a and b.
+type T struct{ N int }
+
+func New() T { return T{N: cc.Limit} }
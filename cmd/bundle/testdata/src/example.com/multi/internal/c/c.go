@@ -0,0 +1,13 @@
+// Copyright 2021 The Multi Authors.
+// Use of this source code is governed by a license.
+
+//go:build !never
+
+// Package c is an internal dependency.
+package c
+
+import "strconv"
+
+const Limit = 10
+
+func Double(n int) string { return strconv.Itoa(2 * n) }
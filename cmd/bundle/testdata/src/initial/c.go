@@ -1,3 +1,5 @@
+// Copyright notices of a single source package are not preserved.
+
 package initial
 
 import _ "fmt"
@@ -15,7 +15,6 @@ package main // import "golang.org/x/tools/cmd/callgraph"
 //   - unreachable functions (use digraph tool?)
 //   - dynamic (runtime) types
 //   - indexed output (numbered nodes)
-//   - JSON output
 //   - additional template fields:
 //     callee file/line/col
 
@@ -53,6 +52,9 @@ var (
 		"{{.Caller}}\t--{{.Dynamic}}-{{.Line}}:{{.Column}}-->\t{{.Callee}}",
 		"A template expression specifying how to format an edge")
 
+	packagesFlag = flag.Bool("packages", false,
+		"Collapse the json and graphml formats to a graph of packages")
+
 	tagsFlag = flag.String("tags", "", "comma-separated list of extra build tags (see: go help buildconstraint)")
 
 	cpuProfile = flag.String("cpuprofile", "", "write CPU profile to this file")
@@ -63,7 +65,7 @@ const Usage = `callgraph: display the call graph of a Go program.
 
 Usage:
 
-  callgraph [-algo=static|cha|rta|vta] [-test] [-format=...] package...
+  callgraph [-algo=static|cha|rta|vta] [-test] [-format=...] [-packages] package...
 
 Flags:
 
@@ -87,6 +89,11 @@ Flags:
             digraph     output suitable for input to
                         golang.org/x/tools/cmd/digraph.
             graphviz    output in AT&T GraphViz (.dot) format.
+            json        a JSON object of nodes and edges, with
+                        attributes such as package, receiver and
+                        position of functions, and position and
+                        kind (static or dynamic) of calls.
+            graphml     the same attributes, in GraphML format.
             ''          output nothing (useful when profiling)
 
            All other values are interpreted using text/template syntax.
@@ -126,6 +133,10 @@ Flags:
            Consult the documentation for go/token, text/template, and
            golang.org/x/tools/go/ssa for more detail.
 
+-packages  With -format=json or -format=graphml, collapse the call
+           graph to a graph of packages, in which each edge records
+           the number of calls between functions of two packages.
+
 Examples:
 
   Show the call graph of the trivial web server application:
@@ -144,6 +155,10 @@ Examples:
       sed -ne 's/-dynamic-/--/p' |
       sed -ne 's/-->.*fmt_test.*$//p' | sort | uniq
 
+  Export the calls between the packages of the callgraph tool as GraphML:
+
+    callgraph -format=graphml -packages golang.org/x/tools/cmd/callgraph
+
   Show all functions directly called by the callgraph tool's main function:
 
     callgraph -format=digraph golang.org/x/tools/cmd/callgraph |
@@ -153,6 +168,11 @@ Examples:
 func main() {
 	flag.Parse()
 
+	if *packagesFlag && *formatFlag != "json" && *formatFlag != "graphml" {
+		fmt.Fprintln(os.Stderr, "callgraph: -packages requires -format=json or -format=graphml")
+		os.Exit(2)
+	}
+
 	if *cpuProfile != "" {
 		f, err := os.Create(*cpuProfile)
 		if err != nil {
@@ -264,10 +284,17 @@ func doCallgraph(dir, gopath, algo, format string, tests bool, args []string) er
 	var before, after string
 
 	// Pre-canned formats.
+	opts := &callgraph.ExportOptions{Packages: *packagesFlag}
 	switch format {
 	case "":
 		return nil
 
+	case "json":
+		return callgraph.WriteJSON(stdout, cg, opts)
+
+	case "graphml":
+		return callgraph.WriteGraphML(stdout, cg, opts)
+
 	case "digraph":
 		format = `{{printf "%q %q" .Caller .Callee}}`
 
@@ -10,6 +10,7 @@ package main
 
 import (
 	"bytes"
+	"encoding/json"
 	"fmt"
 	"log"
 	"os"
@@ -98,3 +99,39 @@ func TestCallgraph(t *testing.T) {
 		}
 	}
 }
+
+func TestCallgraphJSON(t *testing.T) {
+	testenv.NeedsTool(t, "go")
+
+	gopath, err := filepath.Abs("testdata")
+	if err != nil {
+		t.Fatal(err)
+	}
+	stdout = new(bytes.Buffer)
+	if err := doCallgraph("testdata/src", gopath, "vta", "json", false, []string{"pkg"}); err != nil {
+		t.Fatal(err)
+	}
+	var g struct {
+		Nodes []struct{ ID, Name string }
+		Edges []struct{ Caller, Callee, Kind string }
+	}
+	if err := json.Unmarshal(stdout.(*bytes.Buffer).Bytes(), &g); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	names := make(map[string]string)
+	for _, n := range g.Nodes {
+		names[n.ID] = n.Name
+	}
+	edges := make(map[string]bool)
+	for _, e := range g.Edges {
+		edges[names[e.Caller]+" -"+e.Kind+"-> "+names[e.Callee]] = true
+	}
+	for _, want := range []string{
+		"pkg.main -static-> pkg.main2",
+		"pkg.main -dynamic-> (pkg.C).f",
+	} {
+		if !edges[want] {
+			t.Errorf("missing edge: %s\ngot:\n%s", want, stdout)
+		}
+	}
+}
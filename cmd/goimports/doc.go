@@ -33,6 +33,37 @@ For GoSublime, follow the steps described here:
 
 For other editors, you probably know what to do.
 
+By default, goimports sorts imports into groups of standard library
+packages, third-party packages, and packages whose path begins with
+one of the prefixes given by the -local flag. The -groups flag
+replaces this policy with an ordered, space-separated list of groups,
+each one of:
+
+	std            standard library packages
+	module         packages of the module containing the file
+	default        packages that belong to no other group
+	prefix:P,...   packages whose path begins with one of the prefixes P
+	regexp:RE      packages whose path matches the regular expression RE
+
+An import that matches several groups belongs to the last of them.
+With -groups, goimports moves each import into its group, and
+separates groups, but not the imports within a group, by blank lines.
+For example:
+
+	goimports -w -groups 'std default prefix:example.com/ module regexp:\.pb$' .
+
+The -check-groups flag reports, without changing any file, the imports
+whose grouping violates the policy, and sets a non-zero exit status
+if there are any.
+
+When several packages with the same name could satisfy a missing
+import, goimports prefers the one that is imported most often by
+other files of the module containing the file, and otherwise the
+one closest to the file. The -pin flag, a comma-separated list of
+import paths, names packages to prefer over all others:
+
+	goimports -w -pin github.com/sirupsen/logrus .
+
 To exclude directories in your $GOPATH from being scanned for Go
 files, goimports respects a configuration file at
 $GOPATH/src/.goimportsignore which may contain blank lines, comment
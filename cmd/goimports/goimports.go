@@ -21,6 +21,7 @@ import (
 	"strings"
 	"testing"
 
+	"golang.org/x/mod/modfile"
 	"golang.org/x/telemetry/counter"
 	"golang.org/x/tools/internal/gocommand"
 	"golang.org/x/tools/internal/imports"
@@ -33,6 +34,10 @@ var (
 	doDiff = flag.Bool("d", false, "display diffs instead of rewriting files")
 	srcdir = flag.String("srcdir", "", "choose imports as if source code is from `dir`. When operating on a single file, dir may instead be the complete file name.")
 
+	groups      = flag.String("groups", "", "space-separated list of import `groups`, each one of std, module, default, prefix:P,..., or regexp:RE; replaces the default grouping and -local")
+	checkGroups = flag.Bool("check-groups", false, "report imports whose grouping violates the -groups policy, instead of formatting")
+	pin         = flag.String("pin", "", "comma-separated list of import `paths` to prefer over other packages of the same name when adding imports")
+
 	verbose bool // verbose logging
 
 	cpuProfile     = flag.String("cpuprofile", "", "CPU profile output")
@@ -140,6 +145,26 @@ func processFile(filename string, in io.Reader, out io.Writer, argType argumentT
 		}
 	}
 
+	if opt.Groups != nil {
+		nopt := *opt
+		nopt.ModulePath = modulePath(filepath.Dir(target))
+		opt = &nopt
+	}
+
+	if *checkGroups {
+		errs, err := imports.CheckGroups(filename, src, opt)
+		if err != nil {
+			return err
+		}
+		for _, err := range errs {
+			fmt.Fprintln(out, err)
+		}
+		if len(errs) > 0 && exitCode == 0 {
+			exitCode = 1
+		}
+		return nil
+	}
+
 	res, err := imports.Process(target, src, opt)
 	if err != nil {
 		return err
@@ -185,6 +210,28 @@ func processFile(filename string, in io.Reader, out io.Writer, argType argumentT
 	return err
 }
 
+// modulePaths caches the result of modulePath by directory.
+var modulePaths = make(map[string]string)
+
+// modulePath returns the path of the module whose go.mod file is in
+// dir or its nearest ancestor, or "" if there is none.
+func modulePath(dir string) string {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return ""
+	}
+	path, ok := modulePaths[dir]
+	if !ok {
+		if data, err := os.ReadFile(filepath.Join(dir, "go.mod")); err == nil {
+			path = modfile.ModulePath(data)
+		} else if parent := filepath.Dir(dir); parent != dir {
+			path = modulePath(parent)
+		}
+		modulePaths[dir] = path
+	}
+	return path
+}
+
 func visitFile(path string, f os.FileInfo, err error) error {
 	if err == nil && isGoFile(f) {
 		err = processFile(path, nil, os.Stdout, multipleArg)
@@ -270,6 +317,33 @@ func gofmtMain() {
 		log.SetFlags(log.LstdFlags | log.Lmicroseconds)
 		options.Env.Logf = log.Printf
 	}
+	if *groups != "" {
+		if options.LocalPrefix != "" {
+			fmt.Fprintf(os.Stderr, "-groups and -local are mutually exclusive\n")
+			exitCode = 2
+			return
+		}
+		var err error
+		options.Groups, err = imports.ParseGroups(strings.Fields(*groups))
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			exitCode = 2
+			return
+		}
+	}
+	if *pin != "" {
+		for path := range strings.SplitSeq(*pin, ",") {
+			if path = strings.TrimSpace(path); path != "" {
+				options.Env.PinnedImports = append(options.Env.PinnedImports, path)
+			}
+		}
+	}
+	if *checkGroups && (*write || *list || *doDiff) {
+		fmt.Fprintf(os.Stderr, "-check-groups cannot be used with -w, -l or -d\n")
+		exitCode = 2
+		return
+	}
+
 	if options.TabWidth < 0 {
 		fmt.Fprintf(os.Stderr, "negative tabwidth %d\n", options.TabWidth)
 		exitCode = 2
@@ -11,6 +11,8 @@ import (
 	"fmt"
 	"go/build"
 	"os"
+	"os/exec"
+	"strings"
 
 	"golang.org/x/tools/go/buildutil"
 	"golang.org/x/tools/refactor/rename"
@@ -55,6 +57,15 @@ gomvpkg rejects moves in which a package already exists at the destination impor
 path, or in which a directory already exists at the location the package would be
 moved to.
 
+When run within a module or a go.work workspace, gomvpkg operates on the
+main modules of the workspace instead of GOPATH. The destination may then be
+in a different module of the workspace. Before changing any file, gomvpkg
+reports all conflicts, such as imports of internal packages that would no
+longer be permitted, or imports of modules not required by the destination
+module. A module that comes to import a package of another module of the
+workspace is given a requirement of it, replaced by its directory, in its
+go.mod file.
+
 gomvpkg will not always be able to rename imports when a package's name is changed.
 Import statements may want further cleanup.
 
@@ -87,8 +98,26 @@ func main() {
 		return
 	}
 
-	if err := rename.Move(&build.Default, *fromFlag, *toFlag, *vcsMvCmdFlag); err != nil {
+	var err error
+	if moduleMode() {
+		err = rename.MoveInWorkspace(".", *fromFlag, *toFlag, *vcsMvCmdFlag)
+	} else {
+		err = rename.Move(&build.Default, *fromFlag, *toFlag, *vcsMvCmdFlag)
+	}
+	if err != nil {
 		fmt.Fprintf(os.Stderr, "gomvpkg: %s.\n", err)
 		os.Exit(1)
 	}
 }
+
+// moduleMode reports whether the current directory is within a
+// module or a workspace.
+func moduleMode() bool {
+	out, err := exec.Command("go", "env", "GOMOD", "GOWORK").Output()
+	if err != nil {
+		return false
+	}
+	gomod, gowork, _ := strings.Cut(string(out), "\n")
+	gowork = strings.TrimSpace(gowork)
+	return gomod != "" && gomod != os.DevNull || gowork != "" && gowork != "off"
+}
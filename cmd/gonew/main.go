@@ -6,13 +6,66 @@
 //
 // Usage:
 //
-//	gonew srcmod[@version] [dstmod [dir]]
+//	gonew [-var name=value]... [-hooks] srcmod[@version] [dstmod [dir]]
 //
 // Gonew makes a copy of the srcmod module, changing its module path to dstmod.
 // It writes that new module to a new directory named by dir.
 // If dir already exists, it must be an empty directory.
 // If dir is omitted, gonew uses ./elem where elem is the final path element of dstmod.
 //
+// The srcmod argument may also be a local directory containing a
+// module, such as ./mytemplate, which is copied without any use of
+// the network. A module may likewise be obtained offline from a
+// local module proxy, by setting GOPROXY to a file:// URL (and
+// GOSUMDB=off, or GONOSUMDB, for modules not known to the checksum
+// database).
+//
+// # Templates
+//
+// A template module may contain a manifest file, gonew.json, in its
+// root directory, which is not copied. It is a JSON object with
+// these optional fields:
+//
+//	{
+//		"Vars": [
+//			{"Name": "Project", "Default": "{{.ModuleName}}", "Doc": "project name"},
+//			{"Name": "Port", "Default": "8080"},
+//			{"Name": "Docker", "Default": "false"}
+//		],
+//		"Templates": ["*.go", "README.md"],
+//		"Include": [
+//			{"If": "{{.Docker}}", "Files": ["Dockerfile", "deploy"]}
+//		],
+//		"Hooks": [
+//			["go", "mod", "tidy"]
+//		]
+//	}
+//
+// Vars declares the template's variables and their default values.
+// The -var flag, which may be repeated, sets the value of a variable.
+// Default values may refer to earlier variables and to the predeclared
+// variables ModulePath, the path of the new module, and ModuleName,
+// its final element.
+//
+// The contents of files matching one of the Templates patterns, and
+// the names of all files and directories, are expanded as templates
+// of the text/template package, whose data is the set of variables;
+// so, for example, {{.Port}} is replaced by the value of Port.
+// Go files are expanded before their module paths are changed.
+// Names are expanded only if the template module has a manifest.
+// A pattern containing no slash matches any element of a file's path
+// within the module, as in a .gitignore file; otherwise it matches a
+// prefix of that path.
+//
+// Each condition in Include causes the files and directories matching
+// its patterns to be copied only if its If template expands to a
+// value other than "" or "false".
+//
+// Hooks lists commands to run in the new module directory once it
+// has been created, with each argument expanded as a template. Since
+// they are specified by the template, they are run only if the -hooks
+// flag is given; otherwise, gonew just lists them.
+//
 // This command is highly experimental and subject to change.
 //
 // # Example
@@ -37,6 +90,7 @@ import (
 	"encoding/json"
 	"flag"
 	"fmt"
+	"go/build"
 	"go/parser"
 	"go/token"
 	"io/fs"
@@ -45,6 +99,7 @@ import (
 	"os/exec"
 	"path"
 	"path/filepath"
+	"slices"
 	"strconv"
 	"strings"
 
@@ -53,8 +108,34 @@ import (
 	"golang.org/x/tools/internal/edit"
 )
 
+var (
+	hooksFlag = flag.Bool("hooks", false, "run the hooks of the template")
+	varValues = map[string]string{}
+)
+
+func init() {
+	flag.Var(flagFunc(setVar), "var", "set template variable using `name=value` (can be repeated)")
+}
+
+func setVar(s string) {
+	name, value, ok := strings.Cut(s, "=")
+	if !ok || name == "" {
+		log.Fatal("-var argument must be of the form name=value")
+	}
+	varValues[name] = value
+}
+
+type flagFunc func(string)
+
+func (f flagFunc) Set(s string) error {
+	f(s)
+	return nil
+}
+
+func (f flagFunc) String() string { return "" }
+
 func usage() {
-	fmt.Fprintf(os.Stderr, "usage: gonew srcmod[@version] [dstmod [dir]]\n")
+	fmt.Fprintf(os.Stderr, "usage: gonew [-var name=value]... [-hooks] srcmod[@version] [dstmod [dir]]\n")
 	fmt.Fprintf(os.Stderr, "See https://pkg.go.dev/golang.org/x/tools/cmd/gonew.\n")
 	os.Exit(2)
 }
@@ -70,7 +151,20 @@ func main() {
 		usage()
 	}
 
+	// The source is either a local directory or a module to download.
 	srcMod := args[0]
+	var srcDir string
+	if build.IsLocalImport(srcMod) || filepath.IsAbs(srcMod) {
+		srcDir = srcMod
+		data, err := os.ReadFile(filepath.Join(srcDir, "go.mod"))
+		if err != nil {
+			log.Fatalf("reading source module: %v", err)
+		}
+		srcMod = modfile.ModulePath(data)
+		if srcMod == "" {
+			log.Fatalf("%s: no module statement", filepath.Join(srcDir, "go.mod"))
+		}
+	}
 	srcModVers := srcMod
 	if !strings.Contains(srcModVers, "@") {
 		srcModVers += "@latest"
@@ -102,21 +196,27 @@ func main() {
 	}
 	needMkdir := err != nil
 
-	var stdout, stderr bytes.Buffer
-	cmd := exec.Command("go", "mod", "download", "-json", srcModVers)
-	cmd.Stdout = &stdout
-	cmd.Stderr = &stderr
-	if err := cmd.Run(); err != nil {
-		log.Fatalf("go mod download -json %s: %v\n%s%s", srcModVers, err, stderr.Bytes(), stdout.Bytes())
-	}
-
 	var info struct {
 		Dir string
 	}
-	if err := json.Unmarshal(stdout.Bytes(), &info); err != nil {
-		log.Fatalf("go mod download -json %s: invalid JSON output: %v\n%s%s", srcMod, err, stderr.Bytes(), stdout.Bytes())
+	if srcDir != "" {
+		info.Dir = srcDir
+	} else {
+		var stdout, stderr bytes.Buffer
+		cmd := exec.Command("go", "mod", "download", "-json", srcModVers)
+		cmd.Stdout = &stdout
+		cmd.Stderr = &stderr
+		if err := cmd.Run(); err != nil {
+			log.Fatalf("go mod download -json %s: %v\n%s%s", srcModVers, err, stderr.Bytes(), stdout.Bytes())
+		}
+		if err := json.Unmarshal(stdout.Bytes(), &info); err != nil {
+			log.Fatalf("go mod download -json %s: invalid JSON output: %v\n%s%s", srcMod, err, stderr.Bytes(), stdout.Bytes())
+		}
 	}
 
+	m := readManifest(info.Dir)
+	data := m.data(dstMod, varValues)
+
 	if needMkdir {
 		if err := os.MkdirAll(dir, 0777); err != nil {
 			log.Fatal(err)
@@ -132,6 +232,22 @@ func main() {
 		if err != nil {
 			log.Fatal(err)
 		}
+		if rel == "." {
+			return nil
+		}
+		if d.IsDir() && srcDir != "" && skipLocalDir(src) {
+			return filepath.SkipDir
+		}
+		if !m.included(filepath.ToSlash(rel), data) {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		isTemplate := !d.IsDir() && m.isTemplate(filepath.ToSlash(rel))
+		if m.present {
+			rel = filepath.FromSlash(expand("name of "+filepath.ToSlash(rel), filepath.ToSlash(rel), data))
+		}
 		dst := filepath.Join(dir, rel)
 		if d.IsDir() {
 			if err := os.MkdirAll(dst, 0777); err != nil {
@@ -140,28 +256,45 @@ func main() {
 			return nil
 		}
 
-		data, err := os.ReadFile(src)
+		content, err := os.ReadFile(src)
 		if err != nil {
 			log.Fatal(err)
 		}
 
+		if isTemplate {
+			content = []byte(expand(filepath.ToSlash(rel), string(content), data))
+		}
 		isRoot := !strings.Contains(rel, string(filepath.Separator))
 		if strings.HasSuffix(rel, ".go") {
-			data = fixGo(data, rel, srcMod, dstMod, isRoot)
+			content = fixGo(content, rel, srcMod, dstMod, isRoot)
 		}
 		if rel == "go.mod" {
-			data = fixGoMod(data, dstMod)
+			content = fixGoMod(content, dstMod)
 		}
 
-		if err := os.WriteFile(dst, data, 0666); err != nil {
+		if err := os.WriteFile(dst, content, 0666); err != nil {
 			log.Fatal(err)
 		}
 		return nil
 	})
 
+	m.runHooks(dir, data, *hooksFlag)
+
 	log.Printf("initialized %s in %s", dstMod, dir)
 }
 
+// skipLocalDir reports whether the directory dir of a local source
+// module should not be copied, because it belongs to a version
+// control system or to a nested module, neither of which would be
+// part of the module if it were downloaded.
+func skipLocalDir(dir string) bool {
+	if slices.Contains([]string{".git", ".hg", ".svn", ".bzr"}, filepath.Base(dir)) {
+		return true
+	}
+	_, err := os.Stat(filepath.Join(dir, "go.mod"))
+	return err == nil
+}
+
 // fixGo rewrites the Go source in data to replace srcMod with dstMod.
 // isRoot indicates whether the file is in the root directory of the module,
 // in which case we also update the package name.
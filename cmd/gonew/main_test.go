@@ -39,7 +39,8 @@ func Test(t *testing.T) {
 
 	// Each file in testdata is a txtar file with the command to run,
 	// the contents of modules to initialize in a fake proxy,
-	// the expected stdout and stderr, and the expected file contents.
+	// the contents of a local directory, the expected stdout and
+	// stderr, and the expected file contents.
 	files, err := filepath.Glob("testdata/*.txt")
 	if err != nil {
 		t.Fatal(err)
@@ -80,6 +81,20 @@ func Test(t *testing.T) {
 			}
 			proxyURL := "file://" + extra + filepath.ToSlash(proxyDir)
 
+			// Store files under local/ in the temp directory,
+			// where the command can refer to them as ../local.
+			for _, f := range ar.Files {
+				if strings.HasPrefix(f.Name, "local/") {
+					name := filepath.Join(dir, filepath.FromSlash(f.Name))
+					if err := os.MkdirAll(filepath.Dir(name), 0777); err != nil {
+						t.Fatal(err)
+					}
+					if err := os.WriteFile(name, f.Data, 0666); err != nil {
+						t.Fatal(err)
+					}
+				}
+			}
+
 			// Run gonew in a fresh 'out' directory.
 			out := filepath.Join(dir, "out")
 			if err := os.Mkdir(out, 0777); err != nil {
@@ -0,0 +1,200 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"log"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"strings"
+	"text/template"
+)
+
+// manifestFile is the name of the manifest file in the root
+// directory of a template module. It is not copied.
+const manifestFile = "gonew.json"
+
+// A manifest describes the variables, conditionally included files,
+// and hooks of a template module.
+type manifest struct {
+	// Vars declares the variables of the template, in order.
+	Vars []manifestVar
+
+	// Templates lists patterns of files whose contents are
+	// expanded as templates.
+	Templates []string
+
+	// Include lists files that are copied only under some condition.
+	Include []condition
+
+	// Hooks lists commands to run in the new module directory
+	// after it has been created. Each argument is expanded as a
+	// template.
+	Hooks [][]string
+
+	present bool // the module has a manifest file
+}
+
+// A manifestVar declares a template variable.
+type manifestVar struct {
+	Name    string
+	Default string // expanded as a template, and may refer to earlier variables
+	Doc     string
+}
+
+// A condition causes files to be copied only if it holds.
+type condition struct {
+	If    string   // a template; the condition holds unless it expands to "" or "false"
+	Files []string // patterns of files and directories
+}
+
+// builtinVars are the names of the variables defined for every template.
+var builtinVars = []string{
+	"ModulePath", // the path of the new module
+	"ModuleName", // the final element of ModulePath
+}
+
+// readManifest reads the manifest from the root directory of a
+// template module. A module without a manifest has an empty one.
+func readManifest(dir string) *manifest {
+	data, err := os.ReadFile(filepath.Join(dir, manifestFile))
+	if errors.Is(err, fs.ErrNotExist) {
+		return new(manifest)
+	} else if err != nil {
+		log.Fatal(err)
+	}
+	m := &manifest{present: true}
+	if err := json.Unmarshal(data, m); err != nil {
+		log.Fatalf("parsing %s: %v", manifestFile, err)
+	}
+	for _, cond := range m.Include {
+		if cond.If == "" {
+			log.Fatalf("%s: condition for %s has no If template", manifestFile, strings.Join(cond.Files, ", "))
+		}
+	}
+	return m
+}
+
+// data returns the values of all template variables for the new
+// module dstMod, given the values set on the command line.
+func (m *manifest) data(dstMod string, set map[string]string) map[string]string {
+	data := map[string]string{
+		"ModulePath": dstMod,
+		"ModuleName": path.Base(dstMod),
+	}
+	declared := make(map[string]bool)
+	for _, v := range m.Vars {
+		if _, ok := data[v.Name]; ok {
+			log.Fatalf("%s: variable %s is declared more than once or is predeclared", manifestFile, v.Name)
+		}
+		declared[v.Name] = true
+		if value, ok := set[v.Name]; ok {
+			data[v.Name] = value
+		} else {
+			data[v.Name] = expand("default value of "+v.Name, v.Default, data)
+		}
+	}
+	for name := range set {
+		if !declared[name] {
+			log.Fatalf("-var %s: template has no variable %s", name, name)
+		}
+	}
+	return data
+}
+
+// included reports whether the file or directory at the slash-separated
+// path rel is to be copied.
+func (m *manifest) included(rel string, data map[string]string) bool {
+	if rel == manifestFile {
+		return false
+	}
+	for _, cond := range m.Include {
+		if matchAny(cond.Files, rel) {
+			if v := strings.TrimSpace(expand("condition for "+rel, cond.If, data)); v == "" || v == "false" {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// isTemplate reports whether the contents of the file at the
+// slash-separated path rel are to be expanded as a template.
+func (m *manifest) isTemplate(rel string) bool {
+	return matchAny(m.Templates, rel)
+}
+
+// runHooks runs the hooks of the template in the directory dir,
+// or, if run is false, reports that they were not run.
+func (m *manifest) runHooks(dir string, data map[string]string, run bool) {
+	for i, hook := range m.Hooks {
+		if len(hook) == 0 {
+			log.Fatalf("%s: hook #%d is empty", manifestFile, i+1)
+		}
+		args := make([]string, len(hook))
+		for j, arg := range hook {
+			args[j] = expand("hook argument", arg, data)
+		}
+		if !run {
+			log.Printf("not running hook %q (use -hooks to run it)", strings.Join(args, " "))
+			continue
+		}
+		cmd := exec.Command(args[0], args[1:]...)
+		cmd.Dir = dir
+		cmd.Stdout = os.Stderr
+		cmd.Stderr = os.Stderr
+		if err := cmd.Run(); err != nil {
+			log.Fatalf("hook %q: %v", strings.Join(args, " "), err)
+		}
+	}
+}
+
+// matchAny reports whether the slash-separated path rel matches any
+// of the patterns. As in a .gitignore file, a pattern containing no
+// slash is matched against each element of rel, and otherwise
+// against a prefix of its elements.
+func matchAny(patterns []string, rel string) bool {
+	elems := strings.Split(rel, "/")
+	for _, pattern := range patterns {
+		if !strings.Contains(pattern, "/") {
+			for _, elem := range elems {
+				if ok, _ := path.Match(pattern, elem); ok {
+					return true
+				}
+			}
+			continue
+		}
+		pattern = strings.TrimPrefix(pattern, "/")
+		for i := range elems {
+			if ok, _ := path.Match(pattern, strings.Join(elems[:i+1], "/")); ok {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// expand expands text as a template using data.
+// The description is used in error messages.
+func expand(desc, text string, data map[string]string) string {
+	if !strings.Contains(text, "{{") {
+		return text
+	}
+	tmpl, err := template.New(desc).Option("missingkey=error").Parse(text)
+	if err != nil {
+		log.Fatalf("parsing %s: %v", desc, err)
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		log.Fatalf("expanding %s: %v", desc, err)
+	}
+	return buf.String()
+}
@@ -0,0 +1,282 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+// This file defines the machine-readable and graphical outputs of ssadump.
+
+import (
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"go/token"
+	"go/types"
+	"io"
+	"regexp"
+	"slices"
+	"strings"
+
+	"golang.org/x/tools/go/ssa"
+	"golang.org/x/tools/go/ssa/ssautil"
+)
+
+// functions returns the functions of the specified packages, including
+// anonymous functions and methods, whose names match filter (if
+// non-nil), in order of their position and then of their names.
+func functions(prog *ssa.Program, pkgs []*ssa.Package, filter *regexp.Regexp) []*ssa.Function {
+	var fns []*ssa.Function
+	for fn := range ssautil.AllFunctions(prog) {
+		if fn.Pkg == nil || !slices.Contains(pkgs, fn.Pkg) || fn.Blocks == nil {
+			continue // external, or belongs to another package
+		}
+		if filter != nil && !filter.MatchString(fn.String()) {
+			continue
+		}
+		fns = append(fns, fn)
+	}
+	slices.SortFunc(fns, func(x, y *ssa.Function) int {
+		return cmp.Or(cmp.Compare(x.Pos(), y.Pos()), cmp.Compare(x.String(), y.String()))
+	})
+	return fns
+}
+
+// -- JSON --
+
+type jsonFunction struct {
+	Name      string      `json:"name"`
+	Package   string      `json:"package"`
+	Pos       string      `json:"pos,omitempty"`
+	Synthetic string      `json:"synthetic,omitempty"`
+	Signature string      `json:"signature"`
+	Parent    string      `json:"parent,omitempty"` // enclosing function of an anonymous function
+	Params    []jsonValue `json:"params,omitempty"`
+	FreeVars  []jsonValue `json:"freevars,omitempty"`
+	Blocks    []jsonBlock `json:"blocks"`
+	Recover   *int        `json:"recover,omitempty"` // index of the recover block
+}
+
+type jsonValue struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
+}
+
+type jsonBlock struct {
+	Index   int         `json:"index"`
+	Comment string      `json:"comment,omitempty"`
+	Preds   []int       `json:"preds"`
+	Succs   []int       `json:"succs"`
+	Idom    *int        `json:"idom,omitempty"` // immediate dominator, absent for roots
+	Instrs  []jsonInstr `json:"instrs"`
+}
+
+type jsonInstr struct {
+	Op       string   `json:"op"`             // instruction type, e.g. "BinOp"
+	Name     string   `json:"name,omitempty"` // register name, for values
+	Type     string   `json:"type,omitempty"` // register type, for values
+	Operands []string `json:"operands,omitempty"`
+	Text     string   `json:"text"` // as printed by ssadump -build=F, less the register
+	Pos      string   `json:"pos,omitempty"`
+}
+
+// writeJSON writes the functions fns to w as a JSON array, one element
+// per function, each describing its blocks and their instructions.
+func writeJSON(w io.Writer, fns []*ssa.Function) error {
+	out := make([]jsonFunction, 0, len(fns))
+	for _, fn := range fns {
+		from := fn.Pkg.Pkg
+		rel := func(t types.Type) string { return types.TypeString(t, types.RelativeTo(from)) }
+		jf := jsonFunction{
+			Name:      fn.String(),
+			Package:   fn.Pkg.Pkg.Path(),
+			Pos:       position(fn.Prog.Fset, fn.Pos()),
+			Synthetic: fn.Synthetic,
+			Signature: rel(fn.Signature),
+			Blocks:    []jsonBlock{},
+		}
+		if fn.Parent() != nil {
+			jf.Parent = fn.Parent().String()
+		}
+		for _, p := range fn.Params {
+			jf.Params = append(jf.Params, jsonValue{p.Name(), rel(p.Type())})
+		}
+		for _, fv := range fn.FreeVars {
+			jf.FreeVars = append(jf.FreeVars, jsonValue{fv.Name(), rel(fv.Type())})
+		}
+		if fn.Recover != nil {
+			jf.Recover = &fn.Recover.Index
+		}
+		for _, b := range fn.Blocks {
+			jb := jsonBlock{
+				Index:   b.Index,
+				Comment: b.Comment,
+				Preds:   blockIndices(b.Preds),
+				Succs:   blockIndices(b.Succs),
+				Instrs:  []jsonInstr{},
+			}
+			if idom := b.Idom(); idom != nil {
+				jb.Idom = &idom.Index
+			}
+			for _, instr := range b.Instrs {
+				ji := jsonInstr{
+					Op:   strings.TrimPrefix(fmt.Sprintf("%T", instr), "*ssa."),
+					Text: instr.String(),
+					Pos:  position(fn.Prog.Fset, instr.Pos()),
+				}
+				if v, ok := instr.(ssa.Value); ok {
+					ji.Name = v.Name()
+					ji.Type = rel(v.Type())
+				}
+				for _, op := range instr.Operands(nil) {
+					if *op != nil {
+						ji.Operands = append(ji.Operands, operandName(*op, from))
+					}
+				}
+				jb.Instrs = append(jb.Instrs, ji)
+			}
+			jf.Blocks = append(jf.Blocks, jb)
+		}
+		out = append(out, jf)
+	}
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "\t")
+	return enc.Encode(out)
+}
+
+// operandName returns the name of an operand as printed by the SSA
+// printer: functions, globals and constants of other packages than
+// from are qualified.
+func operandName(v ssa.Value, from *types.Package) string {
+	switch v := v.(type) {
+	case *ssa.Function:
+		return v.RelString(from)
+	case *ssa.Global:
+		return v.RelString(from)
+	case *ssa.Const:
+		return v.RelString(from)
+	}
+	return v.Name()
+}
+
+func blockIndices(blocks []*ssa.BasicBlock) []int {
+	indices := make([]int, len(blocks))
+	for i, b := range blocks {
+		indices[i] = b.Index
+	}
+	return indices
+}
+
+// position returns the position of pos in fset as a string,
+// or "" if pos is not valid.
+func position(fset *token.FileSet, pos token.Pos) string {
+	if !pos.IsValid() {
+		return ""
+	}
+	return fset.Position(pos).String()
+}
+
+// -- control-flow graphs --
+
+// blockLines returns the lines of the label of block b in a
+// control-flow graph: its index and comment, then its instructions.
+func blockLines(b *ssa.BasicBlock) []string {
+	lines := []string{fmt.Sprintf("%d: %s", b.Index, b.Comment)}
+	for _, instr := range b.Instrs {
+		text := instr.String()
+		if v, ok := instr.(ssa.Value); ok && v.Name() != "" {
+			text = v.Name() + " = " + text
+		}
+		lines = append(lines, text)
+	}
+	return lines
+}
+
+// edgeLabel returns the label of the i'th outgoing edge of block b:
+// the outcome of its condition, for the two successors of an If.
+func edgeLabel(b *ssa.BasicBlock, i int) string {
+	if _, ok := b.Instrs[len(b.Instrs)-1].(*ssa.If); ok {
+		return [2]string{"true", "false"}[i]
+	}
+	return ""
+}
+
+// writeDot writes the control-flow graph of each function of fns to w
+// in the AT&T GraphViz (.dot) format, with the edges of the
+// dominator tree overlaid as dashed blue edges.
+func writeDot(w io.Writer, fns []*ssa.Function) error {
+	// quote returns s as a double-quoted dot string, with each
+	// line of a label left-justified.
+	quote := func(s string) string {
+		s = strings.ReplaceAll(s, `\`, `\\`)
+		s = strings.ReplaceAll(s, `"`, `\"`)
+		return `"` + strings.ReplaceAll(s, "\n", `\l`) + `"`
+	}
+	var buf strings.Builder
+	for _, fn := range fns {
+		fmt.Fprintf(&buf, "digraph %s {\n", quote(fn.String()))
+		fmt.Fprintf(&buf, "\tlabel=%s;\n", quote(fn.String()))
+		fmt.Fprintf(&buf, "\tnode [shape=box, fontname=\"monospace\"];\n")
+		for _, b := range fn.Blocks {
+			fmt.Fprintf(&buf, "\tb%d [label=%s];\n", b.Index, quote(strings.Join(blockLines(b), "\n")+"\n"))
+		}
+		for _, b := range fn.Blocks {
+			for i, succ := range b.Succs {
+				if label := edgeLabel(b, i); label != "" {
+					fmt.Fprintf(&buf, "\tb%d -> b%d [label=%s];\n", b.Index, succ.Index, quote(label))
+				} else {
+					fmt.Fprintf(&buf, "\tb%d -> b%d;\n", b.Index, succ.Index)
+				}
+			}
+			// Dominator tree edge.
+			if idom := b.Idom(); idom != nil {
+				fmt.Fprintf(&buf, "\tb%d -> b%d [style=dashed, color=blue, constraint=false];\n", idom.Index, b.Index)
+			}
+		}
+		fmt.Fprintf(&buf, "}\n")
+	}
+	_, err := io.WriteString(w, buf.String())
+	return err
+}
+
+// writeMermaid writes the control-flow graph of each function of fns
+// to w as a Mermaid flowchart, with one subgraph per function, and
+// the edges of the dominator tree overlaid as dotted edges.
+func writeMermaid(w io.Writer, fns []*ssa.Function) error {
+	// escape replaces the characters of s that Mermaid
+	// would interpret by their entity codes.
+	escape := strings.NewReplacer(
+		"#", "#35;",
+		`"`, "#quot;",
+		"<", "#lt;",
+		">", "#gt;",
+	).Replace
+	var buf strings.Builder
+	fmt.Fprintf(&buf, "flowchart TD\n")
+	for i, fn := range fns {
+		fmt.Fprintf(&buf, "\tsubgraph f%d [\"%s\"]\n", i, escape(fn.String()))
+		for _, b := range fn.Blocks {
+			lines := blockLines(b)
+			for j := range lines {
+				lines[j] = escape(lines[j])
+			}
+			fmt.Fprintf(&buf, "\t\tf%db%d[\"%s\"]\n", i, b.Index, strings.Join(lines, "<br/>"))
+		}
+		for _, b := range fn.Blocks {
+			for j, succ := range b.Succs {
+				if label := edgeLabel(b, j); label != "" {
+					fmt.Fprintf(&buf, "\t\tf%db%d -->|%s| f%db%d\n", i, b.Index, label, i, succ.Index)
+				} else {
+					fmt.Fprintf(&buf, "\t\tf%db%d --> f%db%d\n", i, b.Index, i, succ.Index)
+				}
+			}
+			// Dominator tree edge.
+			if idom := b.Idom(); idom != nil {
+				fmt.Fprintf(&buf, "\t\tf%db%d -.->|idom| f%db%d\n", i, idom.Index, i, b.Index)
+			}
+		}
+		fmt.Fprintf(&buf, "\tend\n")
+	}
+	_, err := io.WriteString(w, buf.String())
+	return err
+}
@@ -0,0 +1,128 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"testing"
+
+	"golang.org/x/tools/go/packages"
+	"golang.org/x/tools/go/ssa/ssautil"
+	"golang.org/x/tools/internal/testenv"
+	"golang.org/x/tools/internal/testfiles"
+)
+
+// TestFormats tests the JSON, dot and Mermaid outputs for function f
+// of testdata/dump.txtar.
+func TestFormats(t *testing.T) {
+	testenv.NeedsGoPackages(t)
+
+	dir := testfiles.ExtractTxtarFileToTmp(t, "testdata/dump.txtar")
+	cfg := &packages.Config{Mode: packages.LoadSyntax, Dir: dir}
+	initial, err := packages.Load(cfg, "example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if packages.PrintErrors(initial) > 0 {
+		t.Fatal("there were errors")
+	}
+	prog, pkgs := ssautil.Packages(initial, 0)
+	prog.Build()
+	fns := functions(prog, pkgs, regexp.MustCompile(`\.f$`))
+
+	if len(fns) != 1 {
+		t.Fatalf("got functions %v, want only f", fns)
+	}
+
+	t.Run("json", func(t *testing.T) {
+		var buf bytes.Buffer
+		if err := writeJSON(&buf, fns); err != nil {
+			t.Fatal(err)
+		}
+		var out []jsonFunction
+		if err := json.Unmarshal(buf.Bytes(), &out); err != nil {
+			t.Fatalf("invalid JSON: %v\n%s", err, buf.Bytes())
+		}
+		if len(out) != 1 {
+			t.Fatalf("got %d functions, want 1", len(out))
+		}
+		f := out[0]
+		if f.Name != "example.com.f" || f.Signature != "func(x int) string" || !strings.HasSuffix(f.Pos, "main.go:3:6") {
+			t.Errorf("got function %s %s at %s, want example.com.f func(x int) string at main.go:3:6", f.Name, f.Signature, f.Pos)
+		}
+
+		// Summarize each block, less positions, one line per block.
+		var got []string
+		for _, b := range f.Blocks {
+			idom := "-"
+			if b.Idom != nil {
+				idom = fmt.Sprint(*b.Idom)
+			}
+			line := fmt.Sprintf("%d %s preds=%v succs=%v idom=%s:", b.Index, b.Comment, b.Preds, b.Succs, idom)
+			for _, instr := range b.Instrs {
+				line += fmt.Sprintf(" %s(%s %s %s)", instr.Op, instr.Name, instr.Type, strings.Join(instr.Operands, ", "))
+			}
+			got = append(got, line)
+		}
+		want := []string{
+			`0 entry preds=[] succs=[1 2] idom=-: BinOp(t0 bool x, 0:int) If(  t0)`,
+			`1 if.then preds=[0] succs=[2] idom=0: Jump(  )`,
+			`2 if.done preds=[0 1] succs=[] idom=0: Phi(t1 string "b":string, "a":string) BinOp(t2 string t1, "<#>":string) Return(  t2)`,
+		}
+		if !slices.Equal(got, want) {
+			t.Errorf("got blocks:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+		}
+	})
+
+	t.Run("dot", func(t *testing.T) {
+		var buf bytes.Buffer
+		if err := writeDot(&buf, fns); err != nil {
+			t.Fatal(err)
+		}
+		const want = `digraph "example.com.f" {
+	label="example.com.f";
+	node [shape=box, fontname="monospace"];
+	b0 [label="0: entry\lt0 = x > 0:int\lif t0 goto 1 else 2\l"];
+	b1 [label="1: if.then\ljump 2\l"];
+	b2 [label="2: if.done\lt1 = phi [0: \"b\":string, 1: \"a\":string] #s\lt2 = t1 + \"<#>\":string\lreturn t2\l"];
+	b0 -> b1 [label="true"];
+	b0 -> b2 [label="false"];
+	b1 -> b2;
+	b0 -> b1 [style=dashed, color=blue, constraint=false];
+	b0 -> b2 [style=dashed, color=blue, constraint=false];
+}
+`
+		if got := buf.String(); got != want {
+			t.Errorf("got:\n%s\nwant:\n%s", got, want)
+		}
+	})
+
+	t.Run("mermaid", func(t *testing.T) {
+		var buf bytes.Buffer
+		if err := writeMermaid(&buf, fns); err != nil {
+			t.Fatal(err)
+		}
+		const want = `flowchart TD
+	subgraph f0 ["example.com.f"]
+		f0b0["0: entry<br/>t0 = x #gt; 0:int<br/>if t0 goto 1 else 2"]
+		f0b1["1: if.then<br/>jump 2"]
+		f0b2["2: if.done<br/>t1 = phi [0: #quot;b#quot;:string, 1: #quot;a#quot;:string] #35;s<br/>t2 = t1 + #quot;#lt;#35;#gt;#quot;:string<br/>return t2"]
+		f0b0 -->|true| f0b1
+		f0b0 -->|false| f0b2
+		f0b1 --> f0b2
+		f0b0 -.->|idom| f0b1
+		f0b0 -.->|idom| f0b2
+	end
+`
+		if got := buf.String(); got != want {
+			t.Errorf("got:\n%s\nwant:\n%s", got, want)
+		}
+	})
+}
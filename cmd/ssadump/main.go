@@ -6,11 +6,14 @@
 package main // import "golang.org/x/tools/cmd/ssadump"
 
 import (
+	"bufio"
 	"flag"
 	"fmt"
 	"go/build"
 	"go/types"
+	"io"
 	"os"
+	"regexp"
 	"runtime"
 	"runtime/pprof"
 
@@ -34,11 +37,25 @@ R	disable [R]ecover() from panic; show interpreter crash instead.
 T	[T]race execution of the program.  Best for single-threaded programs!
 `)
 
+	seedFlag = flag.Int64("seed", 0, "if non-zero, schedule the interpreted program's goroutines pseudo-randomly using this seed")
+
+	debugFlag = flag.Bool("debug", false, "interpret the program under an interactive debugger reading commands from stdin")
+
+	traceFlag = flag.String("trace", "", "write a trace of each executed SSA instruction to this file")
+
 	cpuprofile = flag.String("cpuprofile", "", "write cpu profile to file")
 
 	args stringListValue
 
 	tagsFlag = flag.String("tags", "", "comma-separated list of extra build tags (see: go help buildconstraint)")
+
+	formatFlag = flag.String("format", "", `Output format of the SSA form of the functions of the packages:
+json	a JSON array of functions, with their blocks, instructions and operands
+dot	a GraphViz control-flow graph of each function, with its dominator tree
+mermaid	a Mermaid flowchart of the same graphs
+`)
+
+	funcFlag = flag.String("func", "", "with -format, output only the functions whose names match this regular expression")
 )
 
 func init() {
@@ -47,13 +64,19 @@ func init() {
 }
 
 const usage = `SSA builder and interpreter.
-Usage: ssadump [-build=[DBCSNFLG]] [-test] [-run] [-interp=[TR]] [-arg=...] package...
+Usage: ssadump [-build=[DBCSNFLG]] [-test] [-format=json|dot|mermaid] [-func=regexp] [-run] [-interp=[TR]] [-seed=N] [-debug] [-trace=file] [-arg=...] package...
 Use -help flag to display options.
 
 Examples:
 % ssadump -build=F hello.go              # dump SSA form of a single package
 % ssadump -build=F -test fmt             # dump SSA form of a package and its tests
+% ssadump -format=json hello.go          # dump SSA form as JSON
+% ssadump -format=dot -func='^main\.main$' hello.go | dot -Tsvg >cfg.svg
+                                         # draw control-flow graph of main
 % ssadump -run -interp=T hello.go        # interpret a program, with tracing
+% ssadump -run -seed=1 hello.go          # interpret a program, with a random schedule
+% ssadump -run -debug hello.go           # interpret a program under the debugger
+% ssadump -run -trace=out.txt hello.go   # interpret a program, logging each instruction
 
 The -run flag causes ssadump to build the code in a runnable form and run the first
 package named main.
@@ -93,6 +116,33 @@ func doMain() error {
 		WordSize: wordSize,
 	}
 
+	var write func(io.Writer, []*ssa.Function) error
+	switch *formatFlag {
+	case "":
+	case "json":
+		write = writeJSON
+	case "dot":
+		write = writeDot
+	case "mermaid":
+		write = writeMermaid
+	default:
+		return fmt.Errorf("unknown -format: %q (want json, dot, or mermaid)", *formatFlag)
+	}
+	if write != nil && *runFlag {
+		return fmt.Errorf("-format and -run are mutually exclusive")
+	}
+	if write != nil && mode&(ssa.PrintPackages|ssa.PrintFunctions) != 0 {
+		return fmt.Errorf("-format and -build=P or -build=F are mutually exclusive")
+	}
+	var filter *regexp.Regexp
+	if *funcFlag != "" {
+		var err error
+		filter, err = regexp.Compile(*funcFlag)
+		if err != nil {
+			return fmt.Errorf("invalid -func: %v", err)
+		}
+	}
+
 	var interpMode interp.Mode
 	for _, c := range *interpFlag {
 		switch c {
@@ -134,7 +184,7 @@ func doMain() error {
 
 	if !*runFlag {
 		// Create (and display) SSA only for initial packages and wrappers.
-		_, pkgs := ssautil.Packages(initial, mode)
+		prog, pkgs := ssautil.Packages(initial, mode)
 		for i, p := range pkgs {
 			if p == nil {
 				return fmt.Errorf("cannot build SSA for package %s", initial[i])
@@ -142,6 +192,14 @@ func doMain() error {
 			p.Build()
 		}
 
+		if write != nil {
+			out := bufio.NewWriter(os.Stdout)
+			if err := write(out, functions(prog, pkgs, filter)); err != nil {
+				return err
+			}
+			return out.Flush()
+		}
+
 	} else {
 		// Create SSA for initial packages and all dependencies, instantiating generics.
 		mode |= ssa.InstantiateGenerics
@@ -176,10 +234,36 @@ func doMain() error {
 				build.Default.GOARCH, runtime.GOARCH)
 		}
 
+		conf := &interp.Config{Mode: interpMode, Sizes: sizes, Seed: *seedFlag}
+		if *debugFlag {
+			conf.DebugIn, conf.DebugOut = os.Stdin, os.Stderr
+		}
+		var (
+			traceFile *os.File
+			trace     *bufio.Writer
+		)
+		if *traceFlag != "" {
+			f, err := os.Create(*traceFlag)
+			if err != nil {
+				return err
+			}
+			traceFile, trace = f, bufio.NewWriter(f)
+			conf.Trace = trace
+		}
+
 		// Run first main package.
 		for _, main := range ssautil.MainPackages(pkgs) {
 			fmt.Fprintf(os.Stderr, "Running: %s\n", main.Pkg.Path())
-			os.Exit(interp.Interpret(main, interpMode, sizes, main.Pkg.Path(), args))
+			exitCode := conf.Run(main, main.Pkg.Path(), args)
+			if trace != nil {
+				if err := trace.Flush(); err != nil {
+					return err
+				}
+				if err := traceFile.Close(); err != nil {
+					return err
+				}
+			}
+			os.Exit(exitCode)
 		}
 		return fmt.Errorf("no main package")
 	}
@@ -73,8 +73,10 @@ func TestEndToEnd(t *testing.T) {
 			t.Errorf("%s is not a Go file", name)
 			continue
 		}
-		if strings.HasPrefix(name, "tag_") || strings.HasPrefix(name, "vary_") {
-			// This file is used for tag processing in TestTags or TestConstValueChange, below.
+		if strings.HasPrefix(name, "tag_") || strings.HasPrefix(name, "vary_") ||
+			strings.HasPrefix(name, "bitmask_") || strings.HasPrefix(name, "companion_") {
+			// This file is used for tag processing in TestTags, TestConstValueChange,
+			// TestBitmask, or TestCompanions, below.
 			continue
 		}
 		t.Run(name, func(t *testing.T) {
@@ -93,6 +95,43 @@ func typeName(fname string) string {
 	return fmt.Sprintf("%c%s", base[0]+'A'-'a', base[1:len(base)-len(".go")])
 }
 
+// TestBitmask verifies that the -bitmask flag generates String methods
+// that print combinations of bit flags.
+func TestBitmask(t *testing.T) {
+	testenv.NeedsTool(t, "go")
+
+	stringer := stringerPath(t)
+	stringerCompileAndRun(t, t.TempDir(), stringer, "Perm", "bitmask_perm.go", "-bitmask")
+}
+
+// TestCompanions verifies that the -values, -parse, and -text flags
+// generate functions and methods consistent with String.
+func TestCompanions(t *testing.T) {
+	testenv.NeedsTool(t, "go")
+
+	stringer := stringerPath(t)
+	t.Run("pill", func(t *testing.T) {
+		stringerCompileAndRun(t, t.TempDir(), stringer, "Pill", "companion_pill.go",
+			"-values", "-parse", "-text", "-linecomment")
+	})
+	t.Run("flags", func(t *testing.T) {
+		stringerCompileAndRun(t, t.TempDir(), stringer, "Flags", "companion_flags.go",
+			"-bitmask", "-parse", "-text", "-ignorecase")
+	})
+}
+
+// TestIgnoreCaseWithoutParse verifies that -ignorecase is rejected
+// unless -parse or -text is also given.
+func TestIgnoreCaseWithoutParse(t *testing.T) {
+	stringer := stringerPath(t)
+	source := filepath.Join("testdata", "companion_flags.go")
+	output := filepath.Join(t.TempDir(), "flags_string.go")
+	err := run(t, stringer, "-ignorecase", "-type", "Flags", "-output", output, source)
+	if err == nil {
+		t.Fatal("unexpected stringer success")
+	}
+}
+
 // TestTags verifies that the -tags flag works as advertised.
 func TestTags(t *testing.T) {
 	stringer := stringerPath(t)
@@ -327,7 +366,7 @@ func stringerPath(t *testing.T) string {
 
 // stringerCompileAndRun runs stringer for the named file and compiles and
 // runs the target binary in directory dir. That binary will panic if the String method is incorrect.
-func stringerCompileAndRun(t *testing.T, dir, stringer, typeName, fileName string) {
+func stringerCompileAndRun(t *testing.T, dir, stringer, typeName, fileName string, flags ...string) {
 	t.Logf("run: %s %s\n", fileName, typeName)
 	source := filepath.Join(dir, path.Base(fileName))
 	err := copy(source, filepath.Join("testdata", fileName))
@@ -336,7 +375,8 @@ func stringerCompileAndRun(t *testing.T, dir, stringer, typeName, fileName strin
 	}
 	stringSource := filepath.Join(dir, typeName+"_string.go")
 	// Run stringer in temporary directory.
-	err = run(t, stringer, "-type", typeName, "-output", stringSource, source)
+	args := append(flags, "-type", typeName, "-output", stringSource, source)
+	err = run(t, stringer, args...)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -40,6 +40,16 @@ var golden = []Golden{
 	{"overflow8", "", false, overflow8_in, overflow8_out},
 }
 
+// goldenBitmask holds the test cases for the -bitmask flag.
+var goldenBitmask = []Golden{
+	{"flags", "", false, flags_in, flags_out},
+}
+
+// goldenCompanions holds the test cases for the -parse, -text, and -values flags.
+var goldenCompanions = []Golden{
+	{"suit", "Suit", false, suit_in, suit_out},
+}
+
 // Each example starts with "type XXX [u]int", with a single space separating them.
 
 // Simple test: enumeration of type int starting at 0.
@@ -988,39 +998,185 @@ func (i Overflow8) String() string {
 }
 `
 
+// Bit flags with a zero value, a multi-bit alias, and a gap.
+const flags_in = `type Flags uint
+const (
+	FlagA Flags = 1 << iota
+	FlagB
+	_
+	FlagD
+	FlagAB Flags = FlagA | FlagB
+	FlagNone Flags = 0
+)
+`
+
+const flags_out = `func _() {
+	// An "invalid array index" compiler error signifies that the constant values have changed.
+	// Re-run the stringer command to generate them again.
+	var x [1]struct{}
+	_ = x[FlagA-1]
+	_ = x[FlagB-2]
+	_ = x[FlagD-8]
+	_ = x[FlagAB-3]
+	_ = x[FlagNone-0]
+}
+
+const _Flags_name = "FlagNoneFlagAFlagBFlagABFlagD"
+
+var _Flags_map = map[Flags]string{
+	0: _Flags_name[0:8],
+	1: _Flags_name[8:13],
+	2: _Flags_name[13:18],
+	3: _Flags_name[18:24],
+	8: _Flags_name[24:29],
+}
+
+var _Flags_bits = [...]Flags{1, 2, 8}
+
+func (i Flags) String() string {
+	if str, ok := _Flags_map[i]; ok {
+		return str
+	}
+	var b []byte
+	rest := i
+	for _, bit := range _Flags_bits {
+		if i&bit != 0 {
+			if len(b) > 0 {
+				b = append(b, '|')
+			}
+			b = append(b, _Flags_map[bit]...)
+			rest &^= bit
+		}
+	}
+	if len(b) == 0 {
+		return "Flags(" + strconv.FormatInt(int64(i), 10) + ")"
+	}
+	if rest != 0 {
+		b = append(b, "|0x"...)
+		b = strconv.AppendUint(b, uint64(rest), 16)
+	}
+	return string(b)
+}
+`
+
+// Companions of the String method, with a trimmed prefix.
+const suit_in = `type Suit int
+const (
+	SuitClubs Suit = iota
+	SuitDiamonds
+	SuitHearts
+	SuitSpades
+)
+`
+
+const suit_out = `func _() {
+	// An "invalid array index" compiler error signifies that the constant values have changed.
+	// Re-run the stringer command to generate them again.
+	var x [1]struct{}
+	_ = x[SuitClubs-0]
+	_ = x[SuitDiamonds-1]
+	_ = x[SuitHearts-2]
+	_ = x[SuitSpades-3]
+}
+
+const _Suit_name = "ClubsDiamondsHeartsSpades"
+
+var _Suit_index = [...]uint8{0, 5, 13, 19, 25}
+
+func (i Suit) String() string {
+	idx := int(i) - 0
+	if i < 0 || idx >= len(_Suit_index)-1 {
+		return "Suit(" + strconv.FormatInt(int64(i), 10) + ")"
+	}
+	return _Suit_name[_Suit_index[idx]:_Suit_index[idx+1]]
+}
+
+var _Suit_values = [...]Suit{0, 1, 2, 3}
+
+// SuitValues returns the distinct values of the Suit constants, in increasing order.
+func SuitValues() []Suit {
+	return append([]Suit(nil), _Suit_values[:]...)
+}
+
+func _Suit_parse(s string) (Suit, bool) {
+	for _, v := range _Suit_values {
+		if v.String() == s {
+			return v, true
+		}
+	}
+	return 0, false
+}
+
+// ParseSuit returns the Suit value whose String method returns s.
+func ParseSuit(s string) (Suit, error) {
+	if v, ok := _Suit_parse(s); ok {
+		return v, nil
+	}
+	return 0, errors.New(strconv.Quote(s) + " is not a valid Suit")
+}
+
+// MarshalText implements [encoding.TextMarshaler].
+func (i Suit) MarshalText() ([]byte, error) {
+	s := i.String()
+	if v, ok := _Suit_parse(s); !ok || v != i {
+		return nil, errors.New("invalid Suit value " + s)
+	}
+	return []byte(s), nil
+}
+
+// UnmarshalText implements [encoding.TextUnmarshaler].
+func (i *Suit) UnmarshalText(text []byte) error {
+	v, ok := _Suit_parse(string(text))
+	if !ok {
+		return errors.New(strconv.Quote(string(text)) + " is not a valid Suit")
+	}
+	*i = v
+	return nil
+}
+`
+
 func TestGolden(t *testing.T) {
 	testenv.NeedsTool(t, "go")
 
 	dir := t.TempDir()
 	for _, test := range golden {
+		t.Run(test.name, func(t *testing.T) { testGolden(t, dir, test, Generator{}) })
+	}
+	for _, test := range goldenBitmask {
+		t.Run(test.name, func(t *testing.T) { testGolden(t, dir, test, Generator{bitmask: true}) })
+	}
+	for _, test := range goldenCompanions {
 		t.Run(test.name, func(t *testing.T) {
-			input := "package test\n" + test.input
-			file := test.name + ".go"
-			absFile := filepath.Join(dir, file)
-			err := os.WriteFile(absFile, []byte(input), 0644)
-			if err != nil {
-				t.Fatal(err)
-			}
+			testGolden(t, dir, test, Generator{parse: true, text: true, values: true})
+		})
+	}
+}
 
-			pkgs := loadPackages([]string{absFile}, nil, test.trimPrefix, test.lineComment, t.Logf)
-			if len(pkgs) != 1 {
-				t.Fatalf("got %d parsed packages but expected 1", len(pkgs))
-			}
-			// Extract the name and type of the constant from the first line.
-			tokens := strings.SplitN(test.input, " ", 3)
-			if len(tokens) != 3 {
-				t.Fatalf("%s: need type declaration on first line", test.name)
-			}
+// testGolden runs a golden test using a Generator with the options of g.
+func testGolden(t *testing.T, dir string, test Golden, g Generator) {
+	input := "package test\n" + test.input
+	file := test.name + ".go"
+	absFile := filepath.Join(dir, file)
+	err := os.WriteFile(absFile, []byte(input), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
 
-			g := Generator{
-				pkg:  pkgs[0],
-				logf: t.Logf,
-			}
-			g.generate(tokens[1], findValues(tokens[1], pkgs[0]))
-			got := string(g.format())
-			if got != test.output {
-				t.Errorf("%s: got(%d)\n====\n%q====\nexpected(%d)\n====\n%q", test.name, len(got), got, len(test.output), test.output)
-			}
-		})
+	pkgs := loadPackages([]string{absFile}, nil, test.trimPrefix, test.lineComment, t.Logf)
+	if len(pkgs) != 1 {
+		t.Fatalf("got %d parsed packages but expected 1", len(pkgs))
+	}
+	// Extract the name and type of the constant from the first line.
+	tokens := strings.SplitN(test.input, " ", 3)
+	if len(tokens) != 3 {
+		t.Fatalf("%s: need type declaration on first line", test.name)
+	}
+
+	g.pkg = pkgs[0]
+	g.logf = t.Logf
+	g.generate(tokens[1], findValues(tokens[1], pkgs[0]))
+	got := string(g.format())
+	if got != test.output {
+		t.Errorf("%s: got(%d)\n====\n%q====\nexpected(%d)\n====\n%q", test.name, len(got), got, len(test.output), test.output)
 	}
 }
@@ -12,8 +12,8 @@
 // It has helpful defaults designed for use with go generate.
 //
 // Stringer works best with constants that are consecutive values such as created using iota,
-// but creates good code regardless. In the future it might also provide custom support for
-// constant sets that are bit patterns.
+// but creates good code regardless. For constant sets that are bit patterns, see the
+// -bitmask flag below.
 //
 // For example, given this snippet,
 //
@@ -74,6 +74,43 @@
 // The -trimprefix flag specifies a prefix to remove from the constant names
 // when generating the string representations. For instance, -trimprefix=Pill
 // would be an alternative way to ensure that PillAspirin.String() == "Aspirin".
+//
+// The -bitmask flag tells stringer that the constants are bit flags that
+// may be combined, as in
+//
+//	type Perm uint
+//
+//	const (
+//		Read Perm = 1 << iota
+//		Write
+//		Exec
+//		ReadWrite Perm = Read | Write
+//		None Perm = 0
+//	)
+//
+// A value equal to a constant, including zero and multi-bit constants
+// such as ReadWrite, prints as the name of that constant. Any other value
+// prints as the names of its single-bit constants separated by "|",
+// followed by any remaining unknown bits in hexadecimal, so that
+// Read|Exec prints as "Read|Exec" and Read|1<<6 prints as "Read|0x40".
+// A value with none of the named bits prints as "Perm(64)".
+//
+// Three further flags generate companions of the String method that
+// use the same names, so that they cannot drift apart:
+//
+//   - -values generates a function PillValues that returns the distinct
+//     values of the constants, in increasing order.
+//   - -parse generates a function ParsePill that returns the value whose
+//     String method returns the given text, or an error.
+//   - -text generates MarshalText and UnmarshalText methods, which
+//     implement encoding.TextMarshaler and encoding.TextUnmarshaler and
+//     so also serve for JSON. MarshalText reports an error for a value
+//     that would not be parsed back.
+//
+// With -bitmask, parsing accepts every result of String, including
+// combinations such as "Read|Exec", "Read|0x40", and "Perm(64)".
+// The -ignorecase flag, which requires -parse or -text, makes parsing
+// case-insensitive, including the "Perm(" prefix.
 package main // import "golang.org/x/tools/cmd/stringer"
 
 import (
@@ -100,6 +137,11 @@ var (
 	trimprefix  = flag.String("trimprefix", "", "trim the `prefix` from the generated constant names")
 	linecomment = flag.Bool("linecomment", false, "use line comment text as printed text when present")
 	buildTags   = flag.String("tags", "", "comma-separated list of build tags to apply")
+	bitmask     = flag.Bool("bitmask", false, "treat constants as bit flags, printing combinations as A|B")
+	parse       = flag.Bool("parse", false, "generate a ParseT function for each type")
+	text        = flag.Bool("text", false, "generate MarshalText and UnmarshalText methods for each type")
+	values      = flag.Bool("values", false, "generate a TValues function for each type")
+	ignoreCase  = flag.Bool("ignorecase", false, "make generated parsing case-insensitive")
 )
 
 // Usage is a replacement usage function for the flags package.
@@ -122,6 +164,11 @@ func main() {
 		flag.Usage()
 		os.Exit(2)
 	}
+	if *ignoreCase && !*parse && !*text {
+		log.Print("-ignorecase requires -parse or -text")
+		flag.Usage()
+		os.Exit(2)
+	}
 	types := strings.Split(*typeNames, ",")
 	var tags []string
 	if len(*buildTags) > 0 {
@@ -171,7 +218,12 @@ func main() {
 	})
 	for _, pkg := range pkgs {
 		g := Generator{
-			pkg: pkg,
+			pkg:        pkg,
+			bitmask:    *bitmask,
+			parse:      *parse,
+			text:       *text,
+			values:     *values,
+			ignoreCase: *ignoreCase,
 		}
 
 		// Print the header and package clause.
@@ -179,7 +231,15 @@ func main() {
 		g.Printf("\n")
 		g.Printf("package %s", g.pkg.name)
 		g.Printf("\n")
-		g.Printf("import \"strconv\"\n") // Used by all methods.
+		if imports := g.imports(); len(imports) == 1 {
+			g.Printf("import %q\n", imports[0]) // "strconv", used by all methods.
+		} else {
+			g.Printf("import (\n")
+			for _, path := range imports {
+				g.Printf("\t%q\n", path)
+			}
+			g.Printf(")\n")
+		}
 
 		// Run generate for types that can be found. Keep the rest for the remainingTypes iteration.
 		var foundTypes, remainingTypes []string
@@ -245,8 +305,13 @@ func isDirectory(name string) bool {
 // Generator holds the state of the analysis. Primarily used to buffer
 // the output for format.Source.
 type Generator struct {
-	buf bytes.Buffer // Accumulated output.
-	pkg *Package     // Package we are scanning.
+	buf     bytes.Buffer // Accumulated output.
+	pkg     *Package     // Package we are scanning.
+	bitmask bool         // Whether the constants are bit flags.
+
+	// Which companions of the String method to generate.
+	parse, text, values bool
+	ignoreCase          bool // Whether parsing is case-insensitive.
 
 	logf func(format string, args ...any) // test logging hook; nil when not testing
 }
@@ -255,6 +320,19 @@ func (g *Generator) Printf(format string, args ...any) {
 	fmt.Fprintf(&g.buf, format, args...)
 }
 
+// imports returns the packages used by the generated code.
+func (g *Generator) imports() []string {
+	var imports []string
+	if g.parse || g.text {
+		imports = append(imports, "errors")
+	}
+	imports = append(imports, "strconv") // Used by all methods.
+	if (g.parse || g.text) && (g.bitmask || g.ignoreCase) {
+		imports = append(imports, "strings")
+	}
+	return imports
+}
+
 // File holds a single parsed file and associated data.
 type File struct {
 	pkg  *Package  // Package to which this file belongs.
@@ -369,9 +447,10 @@ func (g *Generator) generate(typeName string, values []Value) {
 	// rather than use yet another algorithm such as binary search,
 	// we punt and use a map. In any case, the likelihood of a map
 	// being necessary for any realistic example other than bitmasks
-	// is very low. And bitmasks probably deserve their own analysis,
-	// to be done some other day.
+	// is very low. (Bitmasks are handled separately by -bitmask.)
 	switch {
+	case g.bitmask:
+		g.buildBitmask(runs, typeName)
 	case len(runs) == 1:
 		g.buildOneRun(runs, typeName)
 	case len(runs) <= 10:
@@ -379,6 +458,7 @@ func (g *Generator) generate(typeName string, values []Value) {
 	default:
 		g.buildMap(runs, typeName)
 	}
+	g.buildCompanions(runs, typeName)
 }
 
 // splitIntoRuns breaks the values into runs of contiguous sequences.
@@ -693,6 +773,12 @@ func (g *Generator) buildMultipleRuns(runs [][]Value, typeName string) {
 func (g *Generator) buildMap(runs [][]Value, typeName string) {
 	g.Printf("\n")
 	g.declareNameVars(runs, typeName, "")
+	g.declareMap(runs, typeName)
+	g.Printf(stringMap, typeName)
+}
+
+// declareMap declares the map from each value in the runs to its name.
+func (g *Generator) declareMap(runs [][]Value, typeName string) {
 	g.Printf("\nvar _%s_map = map[%s]string{\n", typeName, typeName)
 	n := 0
 	for _, values := range runs {
@@ -702,7 +788,6 @@ func (g *Generator) buildMap(runs [][]Value, typeName string) {
 		}
 	}
 	g.Printf("}\n\n")
-	g.Printf(stringMap, typeName)
 }
 
 // Argument to format is the type name.
@@ -713,3 +798,193 @@ const stringMap = `func (i %[1]s) String() string {
 	return "%[1]s(" + strconv.FormatInt(int64(i), 10) + ")"
 }
 `
+
+// buildBitmask generates the variables and String method for a set of
+// bit flags. Values equal to a constant are looked up in a map; others
+// are decomposed into their single-bit constants.
+func (g *Generator) buildBitmask(runs [][]Value, typeName string) {
+	g.Printf("\n")
+	g.declareNameVars(runs, typeName, "")
+	g.declareMap(runs, typeName)
+	g.Printf("var _%s_bits = [...]%s{", typeName, typeName)
+	n := 0
+	for _, values := range runs {
+		for _, value := range values {
+			if value.value != 0 && value.value&(value.value-1) == 0 { // a single bit
+				if n > 0 {
+					g.Printf(", ")
+				}
+				g.Printf("%s", &value)
+				n++
+			}
+		}
+	}
+	g.Printf("}\n\n")
+	g.Printf(stringBitmask, typeName)
+}
+
+// Argument to format is the type name.
+const stringBitmask = `func (i %[1]s) String() string {
+	if str, ok := _%[1]s_map[i]; ok {
+		return str
+	}
+	var b []byte
+	rest := i
+	for _, bit := range _%[1]s_bits {
+		if i&bit != 0 {
+			if len(b) > 0 {
+				b = append(b, '|')
+			}
+			b = append(b, _%[1]s_map[bit]...)
+			rest &^= bit
+		}
+	}
+	if len(b) == 0 {
+		return "%[1]s(" + strconv.FormatInt(int64(i), 10) + ")"
+	}
+	if rest != 0 {
+		b = append(b, "|0x"...)
+		b = strconv.AppendUint(b, uint64(rest), 16)
+	}
+	return string(b)
+}
+`
+
+// buildCompanions generates the functions and methods requested by the
+// -values, -parse, and -text flags. They are defined in terms of the
+// String method, so they use the same names.
+func (g *Generator) buildCompanions(runs [][]Value, typeName string) {
+	if !g.values && !g.parse && !g.text {
+		return
+	}
+	g.Printf("\nvar _%s_values = [...]%s{", typeName, typeName)
+	n := 0
+	for _, values := range runs {
+		for _, value := range values {
+			if n > 0 {
+				g.Printf(", ")
+			}
+			g.Printf("%s", &value)
+			n++
+		}
+	}
+	g.Printf("}\n")
+	if g.values {
+		g.Printf(valuesFunc, typeName)
+	}
+	if !g.parse && !g.text {
+		return
+	}
+
+	eq := "v.String() == s"
+	prefix := fmt.Sprintf("strings.HasPrefix(text, %q)", typeName+"(")
+	if g.ignoreCase {
+		eq = "strings.EqualFold(v.String(), s)"
+		prefix = fmt.Sprintf("len(text) >= %[1]d && strings.EqualFold(text[:%[1]d], %[2]q)", len(typeName)+1, typeName+"(")
+	}
+	if g.bitmask {
+		g.Printf(parseBitmaskHelper, typeName, eq, prefix)
+	} else {
+		g.Printf(parseHelper, typeName, eq)
+	}
+	if g.parse {
+		g.Printf(parseFunc, typeName)
+	}
+	if g.text {
+		g.Printf(textMethods, typeName)
+	}
+}
+
+// Arguments to format are:
+//
+//	[1]: type name
+const valuesFunc = `
+// %[1]sValues returns the distinct values of the %[1]s constants, in increasing order.
+func %[1]sValues() []%[1]s {
+	return append([]%[1]s(nil), _%[1]s_values[:]...)
+}
+`
+
+// Arguments to format are:
+//
+//	[1]: type name
+//	[2]: condition that value v is named s
+const parseHelper = `
+func _%[1]s_parse(s string) (%[1]s, bool) {
+	for _, v := range _%[1]s_values {
+		if %[2]s {
+			return v, true
+		}
+	}
+	return 0, false
+}
+`
+
+// Arguments to format are:
+//
+//	[1]: type name
+//	[2]: condition that value v is named s
+//	[3]: condition that text starts with "T("
+const parseBitmaskHelper = `
+func _%[1]s_parse(text string) (%[1]s, bool) {
+	if %[3]s && strings.HasSuffix(text, ")") {
+		i, err := strconv.ParseInt(text[len("%[1]s("):len(text)-1], 10, 64)
+		return %[1]s(i), err == nil && int64(%[1]s(i)) == i
+	}
+	var x %[1]s
+next:
+	for _, s := range strings.Split(text, "|") {
+		for _, v := range _%[1]s_values {
+			if %[2]s {
+				x |= v
+				continue next
+			}
+		}
+		if len(s) > 2 && s[0] == '0' && (s[1] == 'x' || s[1] == 'X') {
+			if u, err := strconv.ParseUint(s[2:], 16, 64); err == nil && u == uint64(%[1]s(u)) {
+				x |= %[1]s(u)
+				continue
+			}
+		}
+		return 0, false
+	}
+	return x, true
+}
+`
+
+// Arguments to format are:
+//
+//	[1]: type name
+const parseFunc = `
+// Parse%[1]s returns the %[1]s value whose String method returns s.
+func Parse%[1]s(s string) (%[1]s, error) {
+	if v, ok := _%[1]s_parse(s); ok {
+		return v, nil
+	}
+	return 0, errors.New(strconv.Quote(s) + " is not a valid %[1]s")
+}
+`
+
+// Arguments to format are:
+//
+//	[1]: type name
+const textMethods = `
+// MarshalText implements [encoding.TextMarshaler].
+func (i %[1]s) MarshalText() ([]byte, error) {
+	s := i.String()
+	if v, ok := _%[1]s_parse(s); !ok || v != i {
+		return nil, errors.New("invalid %[1]s value " + s)
+	}
+	return []byte(s), nil
+}
+
+// UnmarshalText implements [encoding.TextUnmarshaler].
+func (i *%[1]s) UnmarshalText(text []byte) error {
+	v, ok := _%[1]s_parse(string(text))
+	if !ok {
+		return errors.New(strconv.Quote(string(text)) + " is not a valid %[1]s")
+	}
+	*i = v
+	return nil
+}
+`
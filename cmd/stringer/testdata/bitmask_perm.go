@@ -0,0 +1,38 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Bit flags, for use with -bitmask.
+
+package main
+
+import "fmt"
+
+type Perm uint8
+
+const (
+	Read Perm = 1 << iota
+	Write
+	Exec
+	ReadWrite Perm = Read | Write
+	None      Perm = 0
+	Execute   Perm = Exec // an alias of a single bit
+)
+
+func main() {
+	ck(None, "None")
+	ck(Read, "Read")
+	ck(Write, "Write")
+	ck(ReadWrite, "ReadWrite")
+	ck(Read|Exec, "Read|Exec")
+	ck(Read|Write|Exec, "Read|Write|Exec")
+	ck(Read|1<<6, "Read|0x40")
+	ck(Write|Exec|1<<6|1<<7, "Write|Exec|0xc0")
+	ck(1<<6, "Perm(64)")
+}
+
+func ck(perm Perm, str string) {
+	if fmt.Sprint(perm) != str {
+		panic("bitmask_perm.go: " + str)
+	}
+}
@@ -0,0 +1,56 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Parsing of bit flags, for use with -bitmask, -parse, -text, and -ignorecase.
+
+package main
+
+import "fmt"
+
+type Flags uint8
+
+const (
+	Read Flags = 1 << iota
+	Write
+	Exec
+	ReadWrite Flags = Read | Write
+	None      Flags = 0
+)
+
+func main() {
+	for _, test := range []struct {
+		s    string
+		want Flags
+	}{
+		{"None", None},
+		{"ReadWrite", Read | Write},
+		{"Read|Exec", Read | Exec},
+		{"read|EXEC", Read | Exec},
+		{"Read|Write|Exec", Read | Write | Exec},
+		{"Exec|0x40", Exec | 1<<6},
+		{"Flags(64)", 1 << 6},
+		{"flags(64)", 1 << 6},
+		{"FLAGS(3)", Read | Write},
+	} {
+		got, err := ParseFlags(test.s)
+		if err != nil || got != test.want {
+			panic(fmt.Sprintf("ParseFlags(%q) = %v, %v, want %v", test.s, got, err, test.want))
+		}
+	}
+	for _, s := range []string{"", "Read|", "Delete", "0x100", "Flags(256)", "Flags(Read)"} {
+		if got, err := ParseFlags(s); err == nil {
+			panic(fmt.Sprintf("ParseFlags(%q) = %v, want error", s, got))
+		}
+	}
+	for v := range 1 << 8 {
+		text, err := Flags(v).MarshalText()
+		if err != nil {
+			panic(err)
+		}
+		var got Flags
+		if err := got.UnmarshalText(text); err != nil || got != Flags(v) {
+			panic(fmt.Sprintf("UnmarshalText(%q) = %v, %v, want %v", text, got, err, Flags(v)))
+		}
+	}
+}
@@ -0,0 +1,54 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Companions of the String method, for use with -values, -parse, -text,
+// and -linecomment.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+)
+
+type Pill int
+
+const (
+	Placebo       Pill = iota // placebo
+	Aspirin                   // aspirin
+	Ibuprofen                 // ibuprofen
+	Paracetamol               // paracetamol
+	Acetaminophen = Paracetamol
+)
+
+func main() {
+	if got, want := PillValues(), []Pill{Placebo, Aspirin, Ibuprofen, Paracetamol}; !slices.Equal(got, want) {
+		panic(fmt.Sprintf("PillValues() = %v, want %v", got, want))
+	}
+	for _, v := range PillValues() {
+		p, err := ParsePill(v.String())
+		if err != nil || p != v {
+			panic(fmt.Sprintf("ParsePill(%q) = %v, %v", v, p, err))
+		}
+	}
+	if _, err := ParsePill("Aspirin"); err == nil {
+		panic("ParsePill is case-sensitive")
+	}
+
+	data, err := json.Marshal(map[Pill][]Pill{Ibuprofen: {Placebo, Paracetamol}})
+	if err != nil || string(data) != `{"ibuprofen":["placebo","paracetamol"]}` {
+		panic(fmt.Sprintf("json.Marshal = %s, %v", data, err))
+	}
+	var m map[Pill][]Pill
+	if err := json.Unmarshal(data, &m); err != nil || len(m) != 1 || !slices.Equal(m[Ibuprofen], []Pill{Placebo, Paracetamol}) {
+		panic(fmt.Sprintf("json.Unmarshal = %v, %v", m, err))
+	}
+	if err := json.Unmarshal([]byte(`["cyanide"]`), new([]Pill)); err == nil {
+		panic("json.Unmarshal of invalid Pill succeeded")
+	}
+	if _, err := json.Marshal(Pill(42)); err == nil {
+		panic("json.Marshal of invalid Pill succeeded")
+	}
+}
@@ -0,0 +1,308 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package callgraph
+
+// This file provides export of call graphs to standard graph formats.
+
+import (
+	"cmp"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"go/token"
+	"go/types"
+	"io"
+	"slices"
+
+	"golang.org/x/tools/go/ssa"
+)
+
+// ExportOptions controls the output of [WriteJSON] and [WriteGraphML].
+type ExportOptions struct {
+	// Packages causes each node of the exported graph to represent
+	// a package, not a function. There is one edge for each pair
+	// of calling and called packages, whose count is the number of
+	// call graph edges between functions of those packages.
+	//
+	// Functions not associated with any package, such as the
+	// root of the graph, are omitted, as are their edges.
+	Packages bool
+}
+
+// WriteJSON writes the call graph g to w as a JSON object with two
+// arrays, "nodes" and "edges".
+//
+// Each node has the following fields, of which only id and name
+// are present for the root node:
+//
+//	id         string  unique node identifier, e.g. "n1"
+//	name       string  function name, e.g. "(*example.com/pkg.T).M"
+//	package    string  import path of the function's package
+//	receiver   string  receiver type of a method, e.g. "*example.com/pkg.T"
+//	pos        string  declaration position, e.g. "pkg.go:12:6"
+//	synthetic  bool    whether the function is synthetic (e.g. a wrapper)
+//
+// Each edge has the following fields:
+//
+//	caller     string  id of the calling node
+//	callee     string  id of the called node
+//	pos        string  position of the call site
+//	kind       string  "static", "dynamic", or "synthetic" if the call
+//	                   has no call site (see [Edge])
+//	description string description of the call, e.g. "static method call"
+//
+// In [ExportOptions.Packages] mode, node names are package names,
+// and edges have only caller, callee and an integer "count" field.
+//
+// Fields with zero values are omitted. Nodes are ordered by ID, and
+// edges by caller, in the order of [Node.Out]. If opts is nil, the
+// default options are used.
+func WriteJSON(w io.Writer, g *Graph, opts *ExportOptions) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "\t")
+	return enc.Encode(export(g, opts))
+}
+
+// WriteGraphML writes the call graph g to w in GraphML format
+// (http://graphml.graphdrawing.org). Nodes and edges carry the same
+// attributes as in the JSON format described at [WriteJSON], as
+// GraphML data elements whose keys are the JSON field names.
+func WriteGraphML(w io.Writer, g *Graph, opts *ExportOptions) error {
+	eg := export(g, opts)
+
+	type data struct {
+		Key   string `xml:"key,attr"`
+		Value string `xml:",chardata"`
+	}
+	type key struct {
+		ID   string `xml:"id,attr"`
+		For  string `xml:"for,attr"`
+		Name string `xml:"attr.name,attr"`
+		Type string `xml:"attr.type,attr"`
+	}
+	type node struct {
+		ID   string `xml:"id,attr"`
+		Data []data `xml:"data"`
+	}
+	type edge struct {
+		ID     string `xml:"id,attr"`
+		Source string `xml:"source,attr"`
+		Target string `xml:"target,attr"`
+		Data   []data `xml:"data"`
+	}
+	type graph struct {
+		ID          string `xml:"id,attr"`
+		EdgeDefault string `xml:"edgedefault,attr"`
+		Nodes       []node `xml:"node"`
+		Edges       []edge `xml:"edge"`
+	}
+	type graphml struct {
+		XMLName xml.Name `xml:"http://graphml.graphdrawing.org/xmlns graphml"`
+		Keys    []key    `xml:"key"`
+		Graph   graph    `xml:"graph"`
+	}
+
+	doc := graphml{
+		Keys: []key{
+			{"name", "node", "name", "string"},
+			{"package", "node", "package", "string"},
+			{"receiver", "node", "receiver", "string"},
+			{"pos", "all", "pos", "string"},
+			{"synthetic", "node", "synthetic", "boolean"},
+			{"kind", "edge", "kind", "string"},
+			{"description", "edge", "description", "string"},
+			{"count", "edge", "count", "int"},
+		},
+		Graph: graph{ID: "callgraph", EdgeDefault: "directed"},
+	}
+	// add appends a data element for each non-zero value.
+	add := func(ds []data, key string, value any) []data {
+		switch value {
+		case "", 0, false:
+			return ds
+		}
+		return append(ds, data{key, fmt.Sprint(value)})
+	}
+	for _, n := range eg.Nodes {
+		var ds []data
+		ds = add(ds, "name", n.Name)
+		ds = add(ds, "package", n.Package)
+		ds = add(ds, "receiver", n.Receiver)
+		ds = add(ds, "pos", n.Pos)
+		ds = add(ds, "synthetic", n.Synthetic)
+		doc.Graph.Nodes = append(doc.Graph.Nodes, node{n.ID, ds})
+	}
+	for i, e := range eg.Edges {
+		var ds []data
+		ds = add(ds, "pos", e.Pos)
+		ds = add(ds, "kind", e.Kind)
+		ds = add(ds, "description", e.Description)
+		ds = add(ds, "count", e.Count)
+		doc.Graph.Edges = append(doc.Graph.Edges, edge{fmt.Sprintf("e%d", i), e.Caller, e.Callee, ds})
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "\t")
+	if err := enc.Encode(doc); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+// exportGraph is the common representation of an exported call graph.
+type exportGraph struct {
+	Nodes []exportNode `json:"nodes"`
+	Edges []exportEdge `json:"edges"`
+}
+
+type exportNode struct {
+	ID        string `json:"id"`
+	Name      string `json:"name"`
+	Package   string `json:"package,omitempty"`
+	Receiver  string `json:"receiver,omitempty"`
+	Pos       string `json:"pos,omitempty"`
+	Synthetic bool   `json:"synthetic,omitempty"`
+}
+
+type exportEdge struct {
+	Caller      string `json:"caller"`
+	Callee      string `json:"callee"`
+	Pos         string `json:"pos,omitempty"`
+	Kind        string `json:"kind,omitempty"`
+	Description string `json:"description,omitempty"`
+	Count       int    `json:"count,omitempty"`
+}
+
+// export returns the representation of g according to opts.
+func export(g *Graph, opts *ExportOptions) *exportGraph {
+	if opts == nil {
+		opts = new(ExportOptions)
+	}
+	nodes := make([]*Node, 0, len(g.Nodes))
+	for _, n := range g.Nodes {
+		nodes = append(nodes, n)
+	}
+	slices.SortFunc(nodes, func(x, y *Node) int { return cmp.Compare(x.ID, y.ID) })
+
+	if opts.Packages {
+		return exportPackages(nodes)
+	}
+
+	eg := &exportGraph{Nodes: []exportNode{}, Edges: []exportEdge{}}
+	for _, n := range nodes {
+		en := exportNode{ID: nodeID(n)}
+		if fn := n.Func; fn == nil {
+			en.Name = "<root>"
+		} else {
+			en.Name = fn.String()
+			if pkg := funcPackage(fn); pkg != nil {
+				en.Package = pkg.Path()
+			}
+			if recv := fn.Signature.Recv(); recv != nil {
+				en.Receiver = types.TypeString(recv.Type(), nil)
+			}
+			en.Pos = position(fn.Prog.Fset, fn.Pos())
+			en.Synthetic = fn.Synthetic != ""
+		}
+		eg.Nodes = append(eg.Nodes, en)
+	}
+	for _, n := range nodes {
+		for _, e := range n.Out {
+			ee := exportEdge{
+				Caller:      nodeID(e.Caller),
+				Callee:      nodeID(e.Callee),
+				Description: e.Description(),
+			}
+			switch {
+			case e.Site == nil:
+				ee.Kind = "synthetic"
+			case e.Site.Common().StaticCallee() != nil:
+				ee.Kind = "static"
+			default:
+				ee.Kind = "dynamic"
+			}
+			if e.Site != nil {
+				ee.Pos = position(e.Site.Parent().Prog.Fset, e.Pos())
+			}
+			eg.Edges = append(eg.Edges, ee)
+		}
+	}
+	return eg
+}
+
+// exportPackages returns the package graph of the specified
+// function nodes, in the order in which packages first occur.
+func exportPackages(nodes []*Node) *exportGraph {
+	eg := &exportGraph{Nodes: []exportNode{}, Edges: []exportEdge{}}
+	ids := make(map[*types.Package]string)
+	pkgID := func(n *Node) string {
+		if n.Func == nil {
+			return ""
+		}
+		pkg := funcPackage(n.Func)
+		if pkg == nil {
+			return ""
+		}
+		id, ok := ids[pkg]
+		if !ok {
+			id = fmt.Sprintf("p%d", len(ids))
+			ids[pkg] = id
+			eg.Nodes = append(eg.Nodes, exportNode{ID: id, Name: pkg.Name(), Package: pkg.Path()})
+		}
+		return id
+	}
+
+	type pair struct{ caller, callee string }
+	index := make(map[pair]int) // index of edge in eg.Edges
+	for _, n := range nodes {
+		caller := pkgID(n)
+		if caller == "" {
+			continue
+		}
+		for _, e := range n.Out {
+			callee := pkgID(e.Callee)
+			if callee == "" {
+				continue
+			}
+			p := pair{caller, callee}
+			i, ok := index[p]
+			if !ok {
+				i = len(eg.Edges)
+				index[p] = i
+				eg.Edges = append(eg.Edges, exportEdge{Caller: caller, Callee: callee})
+			}
+			eg.Edges[i].Count++
+		}
+	}
+	return eg
+}
+
+// funcPackage returns the package to which fn belongs, or nil. For
+// synthetic functions such as wrappers and instantiations, this is the
+// package of the function or method from which they are derived.
+func funcPackage(fn *ssa.Function) *types.Package {
+	if fn.Pkg != nil {
+		return fn.Pkg.Pkg
+	}
+	if obj := fn.Object(); obj != nil {
+		return obj.Pkg()
+	}
+	return nil
+}
+
+func nodeID(n *Node) string { return fmt.Sprintf("n%d", n.ID) }
+
+// position returns the position of pos in fset as a string,
+// or "" if pos is not valid.
+func position(fset *token.FileSet, pos token.Pos) string {
+	if !pos.IsValid() {
+		return ""
+	}
+	return fset.Position(pos).String()
+}
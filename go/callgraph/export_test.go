@@ -0,0 +1,181 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package callgraph_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"strings"
+	"testing"
+
+	"golang.org/x/tools/go/callgraph"
+	"golang.org/x/tools/go/callgraph/cha"
+	"golang.org/x/tools/go/ssa"
+	"golang.org/x/tools/go/ssa/ssautil"
+	"golang.org/x/tools/internal/testfiles"
+	"golang.org/x/tools/txtar"
+)
+
+const exportEx = `
+-- go.mod --
+module x.io
+
+-- main.go --
+package main
+
+import "x.io/lib"
+
+func main() {
+	var s lib.Shape = lib.Square{}
+	println(s.Area())
+	println(lib.Double(1))
+	println(lib.Double(2))
+}
+
+-- lib/lib.go --
+package lib
+
+type Shape interface{ Area() int }
+
+type Square struct{}
+
+func (Square) Area() int { return Double(1) }
+
+func Double(x int) int { return 2 * x }
+`
+
+// exportGraph returns the CHA call graph of exportEx.
+func exportGraph(t *testing.T) *callgraph.Graph {
+	pkgs := testfiles.LoadPackages(t, txtar.Parse([]byte(exportEx)), "./...")
+	prog, _ := ssautil.Packages(pkgs, ssa.InstantiateGenerics)
+	prog.Build()
+	cg := cha.CallGraph(prog)
+	cg.DeleteSyntheticNodes()
+	return cg
+}
+
+type jsonGraph struct {
+	Nodes []struct {
+		ID, Name, Package, Receiver, Pos string
+		Synthetic                        bool
+	}
+	Edges []struct {
+		Caller, Callee, Pos, Kind, Description string
+		Count                                  int
+	}
+}
+
+func TestWriteJSON(t *testing.T) {
+	cg := exportGraph(t)
+	var buf bytes.Buffer
+	if err := callgraph.WriteJSON(&buf, cg, nil); err != nil {
+		t.Fatal(err)
+	}
+	var g jsonGraph
+	if err := json.Unmarshal(buf.Bytes(), &g); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, &buf)
+	}
+
+	names := make(map[string]string) // node ID -> name
+	for _, n := range g.Nodes {
+		names[n.ID] = n.Name
+		if n.Name == "(x.io/lib.Square).Area" {
+			if n.Package != "x.io/lib" || n.Receiver != "x.io/lib.Square" ||
+				!strings.HasSuffix(n.Pos, "lib.go:7:15") || n.Synthetic {
+				t.Errorf("wrong attributes for node %+v", n)
+			}
+		}
+	}
+	edges := make(map[string]int) // "caller -kind-> callee" -> count
+	for _, e := range g.Edges {
+		// Calls of package initializers have no position.
+		if (e.Pos == "" && !strings.HasSuffix(names[e.Callee], ".init")) || e.Description == "" {
+			t.Errorf("edge %+v lacks position or description", e)
+		}
+		edges[names[e.Caller]+" -"+e.Kind+"-> "+names[e.Callee]]++
+	}
+	for want, count := range map[string]int{
+		"x.io.main -dynamic-> (x.io/lib.Square).Area":      1,
+		"x.io.main -static-> x.io/lib.Double":              2,
+		"(x.io/lib.Square).Area -static-> x.io/lib.Double": 1,
+	} {
+		if got := edges[want]; got != count {
+			t.Errorf("got %d edges %q, want %d", got, want, count)
+		}
+	}
+	if t.Failed() {
+		t.Logf("output:\n%s", &buf)
+	}
+}
+
+func TestWriteJSONPackages(t *testing.T) {
+	cg := exportGraph(t)
+	var buf bytes.Buffer
+	if err := callgraph.WriteJSON(&buf, cg, &callgraph.ExportOptions{Packages: true}); err != nil {
+		t.Fatal(err)
+	}
+	var g jsonGraph
+	if err := json.Unmarshal(buf.Bytes(), &g); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, &buf)
+	}
+
+	paths := make(map[string]string) // node ID -> package path
+	for _, n := range g.Nodes {
+		paths[n.ID] = n.Package
+	}
+	edges := make(map[string]int) // "caller -> callee" -> count
+	for _, e := range g.Edges {
+		edges[paths[e.Caller]+" -> "+paths[e.Callee]] += e.Count
+	}
+	for want, count := range map[string]int{
+		"x.io -> x.io/lib":     4, // including init
+		"x.io/lib -> x.io/lib": 1,
+	} {
+		if got := edges[want]; got != count {
+			t.Errorf("got count %d for %q, want %d\n%s", got, want, count, &buf)
+		}
+	}
+}
+
+func TestWriteGraphML(t *testing.T) {
+	cg := exportGraph(t)
+	var buf bytes.Buffer
+	if err := callgraph.WriteGraphML(&buf, cg, nil); err != nil {
+		t.Fatal(err)
+	}
+	var doc struct {
+		XMLName xml.Name `xml:"http://graphml.graphdrawing.org/xmlns graphml"`
+		Graph   struct {
+			Nodes []struct {
+				ID string `xml:"id,attr"`
+			} `xml:"node"`
+			Edges []struct {
+				Source string `xml:"source,attr"`
+				Data   []struct {
+					Key   string `xml:"key,attr"`
+					Value string `xml:",chardata"`
+				} `xml:"data"`
+			} `xml:"edge"`
+		} `xml:"graph"`
+	}
+	if err := xml.Unmarshal(buf.Bytes(), &doc); err != nil {
+		t.Fatalf("invalid GraphML: %v\n%s", err, &buf)
+	}
+	if got, want := len(doc.Graph.Nodes), len(cg.Nodes); got != want {
+		t.Errorf("got %d nodes, want %d", got, want)
+	}
+	kinds := make(map[string]int)
+	for _, e := range doc.Graph.Edges {
+		for _, d := range e.Data {
+			if d.Key == "kind" {
+				kinds[d.Value]++
+			}
+		}
+	}
+	if kinds["static"] == 0 || kinds["dynamic"] == 0 {
+		t.Errorf("got edge kinds %v, want static and dynamic edges\n%s", kinds, &buf)
+	}
+}
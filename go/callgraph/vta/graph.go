@@ -321,6 +321,7 @@ func typePropGraph(funcs map[*ssa.Function]bool, callees calleesFunc) (*vtaGraph
 type builder struct {
 	graph   vtaGraph
 	callees calleesFunc // initial call graph for creating flows at unresolved call sites.
+	summary *Summary    // if non-nil, call sites are recorded in summary instead
 
 	// Specialized type map for canonicalization of types.Type.
 	// Semantically equivalent types can have different implementations,
@@ -634,6 +635,13 @@ func (b *builder) call(c ssa.CallInstruction) {
 		return
 	}
 
+	if b.summary != nil {
+		// Flows at call sites depend on the initial call graph
+		// and are added when the summary is composed.
+		b.summary.sites = append(b.summary.sites, c)
+		return
+	}
+
 	for f := range siteCallees(c, b.callees) {
 		addArgumentFlows(b, c, f)
 
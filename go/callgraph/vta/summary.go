@@ -0,0 +1,106 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package vta
+
+import (
+	"golang.org/x/tools/go/callgraph"
+	"golang.org/x/tools/go/ssa"
+)
+
+// A Summary is the part of the VTA type propagation graph contributed
+// by a set of functions, typically the functions of a single package.
+//
+// A summary records the type flows induced by the instructions of its
+// functions, except for flows between call sites and their callees:
+// those depend on the initial call graph of the whole program, so the
+// summary records the call sites instead, and their flows are added
+// when summaries are composed by [CallGraphFromSummaries].
+//
+// Consequently, a summary depends only on the SSA code of its own
+// functions. Clients that compute call graphs repeatedly within one
+// process, such as after each edit to a program, can keep the summary
+// of each package and recompute only the summaries of packages whose
+// code changed. All summaries composed together must refer to the same
+// [ssa.Program].
+//
+// A summary refers directly to the SSA values, types, and functions of
+// its program. It exists only in memory: it cannot be serialized, and
+// so cannot be saved and reused across runs.
+//
+// A Summary is immutable once computed, and may be shared by
+// concurrent calls to [CallGraphFromSummaries].
+type Summary struct {
+	funcs map[*ssa.Function]bool
+	graph vtaGraph              // intraprocedural flows
+	sites []ssa.CallInstruction // call sites whose flows are deferred
+}
+
+// Summarize computes the type propagation summary of all functions
+// f:true in funcs. Summarize may be called concurrently for distinct
+// function sets.
+//
+// The supplied SSA functions must have been constructed with the
+// [ssa.InstantiateGenerics] mode flag.
+func Summarize(funcs map[*ssa.Function]bool) *Summary {
+	s := &Summary{funcs: make(map[*ssa.Function]bool)}
+	b := builder{summary: s}
+	for f, in := range funcs {
+		if in {
+			s.funcs[f] = true
+			b.fun(f)
+		}
+	}
+	s.graph = b.graph
+	b.summary = nil
+	return s
+}
+
+// CallGraphFromSummaries uses the VTA algorithm to compute the call
+// graph of the union of the functions summarized in summaries. The
+// result is the same as that of [CallGraph] applied to the union of
+// their function sets and the initial call graph.
+//
+// Composing summaries does not rebuild the type propagation graph
+// from the SSA code of the summarized functions: it costs time
+// proportional to the size of the summaries plus the number of callees
+// of their call sites, followed by type propagation over the whole
+// graph. Constructing the resulting call graph still visits the call
+// sites of every summarized function.
+func CallGraphFromSummaries(summaries []*Summary, initial *callgraph.Graph) *callgraph.Graph {
+	funcs := make(map[*ssa.Function]bool)
+	for _, s := range summaries {
+		for f := range s.funcs {
+			funcs[f] = true
+		}
+	}
+	callees := makeCalleesFunc(funcs, initial)
+
+	b := builder{callees: callees}
+	b.graph.addEdge(panicArg{}, recoverReturn{})
+	for _, s := range summaries {
+		b.compose(s)
+	}
+	b.callees = nil // ensure callees is not pinned by pointers to other fields of b.
+	types := propagate(&b.graph, &b.canon)
+
+	c := &constructor{types: types, callees: callees, cache: make(methodCache)}
+	return c.construct(funcs)
+}
+
+// compose adds the flows of summary s to the graph of b, including
+// the flows at the call sites of s according to b.callees.
+func (b *builder) compose(s *Summary) {
+	// Types of the nodes of s are canonical only with respect to s,
+	// so each node is mapped to its representative in b.
+	for x, succs := range s.graph.m {
+		from := b.representative(s.graph.node[x])
+		for y := range succs {
+			b.graph.addEdge(from, b.representative(s.graph.node[y]))
+		}
+	}
+	for _, c := range s.sites {
+		b.call(c)
+	}
+}
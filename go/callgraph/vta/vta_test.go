@@ -5,6 +5,7 @@
 package vta
 
 import (
+	"slices"
 	"strings"
 	"testing"
 
@@ -186,3 +187,106 @@ func TestVTACallGraphGo117(t *testing.T) {
 		t.Errorf("`%s`: want superset of %v;\n got %v", file, want, got)
 	}
 }
+
+// TestVTASummaries checks that composing summaries yields the same
+// call graph as CallGraph, however the functions are partitioned.
+func TestVTASummaries(t *testing.T) {
+	files := []string{
+		"testdata/src/callgraph_static.go",
+		"testdata/src/callgraph_ho.go",
+		"testdata/src/callgraph_interfaces.go",
+		"testdata/src/callgraph_pointers.go",
+		"testdata/src/callgraph_collections.go",
+		"testdata/src/callgraph_fields.go",
+		"testdata/src/callgraph_recursive_types.go",
+		"testdata/src/callgraph_generics.go",
+		"testdata/src/panic.go",
+	}
+	for _, file := range files {
+		t.Run(file, func(t *testing.T) {
+			prog, _, err := testProg(t, file)
+			if err != nil {
+				t.Fatalf("couldn't load test file '%s': %s", file, err)
+			}
+			allFuncs := ssautil.AllFunctions(prog)
+			want := callGraphStr(CallGraph(allFuncs, nil))
+			slices.Sort(want)
+
+			// Summarize by package, and by individual function.
+			byPkg := make(map[*ssa.Package]map[*ssa.Function]bool)
+			var pkgSums, funcSums []*Summary
+			for f := range allFuncs {
+				if byPkg[f.Pkg] == nil {
+					byPkg[f.Pkg] = make(map[*ssa.Function]bool)
+				}
+				byPkg[f.Pkg][f] = true
+				funcSums = append(funcSums, Summarize(map[*ssa.Function]bool{f: true}))
+			}
+			for _, funcs := range byPkg {
+				pkgSums = append(pkgSums, Summarize(funcs))
+			}
+
+			for _, test := range []struct {
+				name string
+				sums []*Summary
+			}{
+				{"packages", pkgSums},
+				{"functions", funcSums},
+			} {
+				got := callGraphStr(CallGraphFromSummaries(test.sums, nil))
+				slices.Sort(got)
+				if diff := cmp.Diff(want, got); diff != "" {
+					t.Errorf("%s: composed call graph differs from CallGraph (-want +got):\n%s", test.name, diff)
+				}
+			}
+		})
+	}
+}
+
+// TestVTASummariesUpdate checks that replacing or removing one summary
+// is equivalent to recomputing the call graph for the new function set.
+func TestVTASummariesUpdate(t *testing.T) {
+	prog, _, err := testProg(t, "testdata/src/callgraph_nested_ptr.go")
+	if err != nil {
+		t.Fatalf("couldn't load test `testdata/src/callgraph_nested_ptr.go`: %s", err)
+	}
+
+	sums := make(map[*ssa.Function]*Summary)
+	for f := range ssautil.AllFunctions(prog) {
+		sums[f] = Summarize(map[*ssa.Function]bool{f: true})
+	}
+	compose := func() []string {
+		var list []*Summary
+		for _, s := range sums {
+			list = append(list, s)
+		}
+		return callGraphStr(CallGraphFromSummaries(list, cha.CallGraph(prog)))
+	}
+
+	want := []string{"Baz: Do(i) -> Do; invoke t2.Foo() -> A.Foo, B.Foo"}
+	if diff := setdiff(want, compose()); len(diff) > 0 {
+		t.Errorf("composed call graph should contain %v", diff)
+	}
+
+	// Recomputing the summary of Baz does not change the result.
+	for f := range sums {
+		if funcName(f) == "Baz" {
+			sums[f] = Summarize(map[*ssa.Function]bool{f: true})
+		}
+	}
+	if diff := setdiff(want, compose()); len(diff) > 0 {
+		t.Errorf("call graph with recomputed summary should contain %v", diff)
+	}
+
+	// Dropping the summary of Bar removes the flow of B to Baz,
+	// as in TestVTAProgVsFuncSet.
+	for f := range sums {
+		if funcName(f) == "Bar" {
+			delete(sums, f)
+		}
+	}
+	want = []string{"Baz: Do(i) -> Do; invoke t2.Foo() -> A.Foo"}
+	if diff := setdiff(want, compose()); len(diff) > 0 {
+		t.Errorf("call graph without Bar should contain %v", diff)
+	}
+}
@@ -0,0 +1,106 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dataflow
+
+import "math/bits"
+
+// A bitset is an immutable set of small non-negative integers.
+// Operations that change the set return a new one.
+type bitset []uint64
+
+func (s bitset) has(i int) bool {
+	w := i / 64
+	return w < len(s) && s[w]&(1<<(i%64)) != 0
+}
+
+// with returns s ∪ {i}.
+func (s bitset) with(i int) bitset {
+	if s.has(i) {
+		return s
+	}
+	w := i / 64
+	t := make(bitset, max(len(s), w+1))
+	copy(t, s)
+	t[w] |= 1 << (i % 64)
+	return t
+}
+
+// without returns s \ {i}.
+func (s bitset) without(i int) bitset {
+	if !s.has(i) {
+		return s
+	}
+	t := append(bitset(nil), s...)
+	t[i/64] &^= 1 << (i % 64)
+	return t
+}
+
+// minus returns s \ t.
+func (s bitset) minus(t bitset) bitset {
+	var u bitset
+	for w := range s {
+		if w < len(t) && s[w]&t[w] != 0 {
+			if u == nil {
+				u = append(bitset(nil), s...)
+			}
+			u[w] &^= t[w]
+		}
+	}
+	if u == nil {
+		return s
+	}
+	return u
+}
+
+// union returns s ∪ t.
+func (s bitset) union(t bitset) bitset {
+	if len(s) < len(t) {
+		s, t = t, s
+	}
+	for w := range t {
+		if t[w]&^s[w] != 0 {
+			u := append(bitset(nil), s...)
+			for w := range t {
+				u[w] |= t[w]
+			}
+			return u
+		}
+	}
+	return s // t ⊆ s
+}
+
+func (s bitset) equal(t bitset) bool {
+	if len(s) < len(t) {
+		s, t = t, s
+	}
+	for w := range s {
+		var x uint64
+		if w < len(t) {
+			x = t[w]
+		}
+		if s[w] != x {
+			return false
+		}
+	}
+	return true
+}
+
+// elems calls f for each element of s in increasing order.
+func (s bitset) elems(f func(i int)) {
+	for w, x := range s {
+		for x != 0 {
+			i := bits.TrailingZeros64(x)
+			f(w*64 + i)
+			x &^= 1 << i
+		}
+	}
+}
+
+// setLattice is the lattice of bitsets ordered by inclusion.
+type setLattice struct{}
+
+func (setLattice) Bottom() bitset          { return nil }
+func (setLattice) Join(x, y bitset) bitset { return x.union(y) }
+func (setLattice) Equal(x, y bitset) bool  { return x.equal(y) }
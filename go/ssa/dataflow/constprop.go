@@ -0,0 +1,354 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dataflow
+
+import (
+	"go/constant"
+	"go/token"
+	"go/types"
+	"maps"
+
+	"golang.org/x/tools/go/ssa"
+)
+
+// ConstValues records the SSA values of a function that are constant
+// on every execution, and the blocks that are reachable given those
+// constants.
+//
+// The analysis is a form of conditional constant propagation: an
+// [ssa.If] whose condition is constant transfers control only to one
+// of its successors, and a φ-node takes account of only those edges
+// that are reachable. Only arithmetic, logical and comparison
+// operations on values of integer, boolean and string type are
+// evaluated. Operations on integers of type int, uint and uintptr are
+// evaluated only if their results are representable in 32 bits, so
+// the results do not depend on the target platform.
+type ConstValues struct {
+	res *Result[constEnv]
+}
+
+// Constants computes the constant values and reachable blocks of
+// function fn, which must have been built.
+func Constants(fn *ssa.Function) *ConstValues {
+	res := Solve(fn, &Problem[constEnv]{
+		Direction: Forward,
+		Lattice:   constLattice{},
+		Boundary:  constEnv{reachable: true},
+		Transfer: func(instr ssa.Instruction, env constEnv) constEnv {
+			v, ok := instr.(ssa.Value)
+			if !ok || !env.reachable {
+				return env
+			}
+			if _, ok := v.(*ssa.Phi); ok {
+				return env // φ-nodes are evaluated on edges
+			}
+			return env.with(v, eval(v, env))
+		},
+		Edge: func(from, to *ssa.BasicBlock, env constEnv) constEnv {
+			if !env.reachable {
+				return env
+			}
+			if cond, ok := from.Instrs[len(from.Instrs)-1].(*ssa.If); ok {
+				if c := env.lookup(cond.Cond); c.isConst() {
+					taken := from.Succs[1]
+					if constant.BoolVal(c.val) {
+						taken = from.Succs[0]
+					}
+					if taken != to {
+						return constEnv{} // edge not taken
+					}
+				}
+			}
+			for _, instr := range to.Instrs {
+				phi, ok := instr.(*ssa.Phi)
+				if !ok {
+					break
+				}
+				var c constVal
+				for i, pred := range to.Preds {
+					if pred == from {
+						c = c.join(env.lookup(phi.Edges[i]))
+					}
+				}
+				env = env.with(phi, c)
+			}
+			return env
+		},
+	})
+	return &ConstValues{res: res}
+}
+
+// Value returns the constant value of v, or nil if v is not known to
+// be constant. An [ssa.Const] with a nil value is not constant in
+// this sense.
+func (c *ConstValues) Value(v ssa.Value) constant.Value {
+	var env constEnv
+	switch v := v.(type) {
+	case *ssa.Const:
+		return v.Value
+	case *ssa.Phi:
+		env = c.res.In(v.Block())
+	case ssa.Instruction:
+		env = c.res.Out(v.Block())
+	default:
+		return nil
+	}
+	return env.vals[v].val
+}
+
+// Reachable reports whether block b may be reached.
+func (c *ConstValues) Reachable(b *ssa.BasicBlock) bool {
+	return c.res.In(b).reachable
+}
+
+// A constVal is an element of the lattice of constant values:
+// unknown (the zero value), a known constant (val != nil), or
+// overdefined, meaning not constant.
+type constVal struct {
+	val  constant.Value
+	over bool
+}
+
+var overdefined = constVal{over: true}
+
+func (c constVal) isConst() bool { return c.val != nil }
+
+func (c constVal) join(d constVal) constVal {
+	switch {
+	case c.over || d.over:
+		return overdefined
+	case c.val == nil:
+		return d
+	case d.val == nil || constant.Compare(c.val, token.EQL, d.val):
+		return c
+	}
+	return overdefined
+}
+
+func (c constVal) equal(d constVal) bool {
+	if c.val != nil && d.val != nil {
+		return constant.Compare(c.val, token.EQL, d.val)
+	}
+	return c.val == d.val && c.over == d.over
+}
+
+// A constEnv is the fact at a point of the function: whether the
+// point is reachable, and the lattice values of the values defined
+// before it. Values absent from vals are unknown.
+type constEnv struct {
+	reachable bool
+	vals      map[ssa.Value]constVal
+}
+
+// lookup returns the lattice value of v.
+func (env constEnv) lookup(v ssa.Value) constVal {
+	switch c := v.(type) {
+	case *ssa.Const:
+		if c.Value == nil {
+			return overdefined // e.g. nil, or zero value of a struct
+		}
+		return constVal{val: c.Value}
+	case ssa.Instruction:
+		return env.vals[v]
+	}
+	return overdefined // parameter, global, function, etc
+}
+
+// with returns env with the lattice value of v set to c.
+func (env constEnv) with(v ssa.Value, c constVal) constEnv {
+	if old, ok := env.vals[v]; ok && old.equal(c) {
+		return env
+	}
+	vals := maps.Clone(env.vals)
+	if vals == nil {
+		vals = make(map[ssa.Value]constVal)
+	}
+	vals[v] = c
+	return constEnv{reachable: env.reachable, vals: vals}
+}
+
+// constLattice is the lattice of constEnvs.
+type constLattice struct{}
+
+func (constLattice) Bottom() constEnv { return constEnv{} }
+
+func (constLattice) Join(x, y constEnv) constEnv {
+	switch {
+	case !x.reachable:
+		return y
+	case !y.reachable:
+		return x
+	}
+	for v, c := range y.vals {
+		x = x.with(v, x.vals[v].join(c))
+	}
+	return x
+}
+
+func (constLattice) Equal(x, y constEnv) bool {
+	if x.reachable != y.reachable || len(x.vals) != len(y.vals) {
+		return false
+	}
+	for v, c := range x.vals {
+		if d, ok := y.vals[v]; !ok || !c.equal(d) {
+			return false
+		}
+	}
+	return true
+}
+
+// eval returns the lattice value of the result of v in env.
+func eval(v ssa.Value, env constEnv) constVal {
+	switch v := v.(type) {
+	case *ssa.BinOp:
+		x, y := env.lookup(v.X), env.lookup(v.Y)
+		if x.over || y.over {
+			return overdefined
+		}
+		if !x.isConst() || !y.isConst() {
+			return constVal{} // unknown
+		}
+		return constValOf(binary(v.Op, x.val, y.val, v.X.Type()), v.Type())
+
+	case *ssa.UnOp:
+		if v.Op == token.MUL || v.Op == token.ARROW {
+			return overdefined // load or receive
+		}
+		x := env.lookup(v.X)
+		if !x.isConst() {
+			return x
+		}
+		return constValOf(unary(v.Op, x.val, v.X.Type()), v.Type())
+
+	case *ssa.ChangeType:
+		x := env.lookup(v.X)
+		if x.isConst() && !foldable(v.Type()) {
+			return overdefined
+		}
+		return x
+	}
+	return overdefined
+}
+
+// constValOf returns the lattice value for a constant result val of
+// type t, or overdefined if it is nil or not representable in t.
+func constValOf(val constant.Value, t types.Type) constVal {
+	if val == nil || !foldable(t) {
+		return overdefined
+	}
+	if val.Kind() == constant.Int {
+		basic := t.Underlying().(*types.Basic)
+		lo, hi := intRange(basic)
+		if constant.Compare(val, token.LSS, lo) || constant.Compare(val, token.GTR, hi) {
+			return overdefined // would wrap around
+		}
+	}
+	return constVal{val: val}
+}
+
+// foldable reports whether operations on values of type t are
+// evaluated.
+func foldable(t types.Type) bool {
+	basic, ok := t.Underlying().(*types.Basic)
+	return ok && basic.Info()&(types.IsInteger|types.IsBoolean|types.IsString) != 0
+}
+
+// intRange returns the least and greatest values of integer type t.
+func intRange(t *types.Basic) (lo, hi constant.Value) {
+	var size uint
+	switch t.Kind() {
+	case types.Int8, types.Uint8:
+		size = 8
+	case types.Int16, types.Uint16:
+		size = 16
+	case types.Int64, types.Uint64:
+		size = 64
+	default: // int32, uint32, and the platform-dependent int, uint, uintptr
+		size = 32
+	}
+	one := constant.MakeInt64(1)
+	if t.Info()&types.IsUnsigned != 0 {
+		lo = constant.MakeInt64(0)
+		hi = constant.BinaryOp(constant.Shift(one, token.SHL, size), token.SUB, one)
+	} else {
+		lo = constant.UnaryOp(token.SUB, constant.Shift(one, token.SHL, size-1), 0)
+		hi = constant.BinaryOp(constant.Shift(one, token.SHL, size-1), token.SUB, one)
+	}
+	return lo, hi
+}
+
+// binary returns the result of x op y, where x has type t,
+// or nil if it cannot be computed.
+func binary(op token.Token, x, y constant.Value, t types.Type) constant.Value {
+	if !foldable(t) {
+		return nil
+	}
+	switch op {
+	case token.EQL, token.NEQ, token.LSS, token.LEQ, token.GTR, token.GEQ:
+		if x.Kind() == constant.Bool && op != token.EQL && op != token.NEQ {
+			return nil
+		}
+		return constant.MakeBool(constant.Compare(x, op, y))
+
+	case token.SHL, token.SHR:
+		s, ok := constant.Uint64Val(y)
+		if !ok || s >= 64 || x.Kind() != constant.Int {
+			return nil
+		}
+		return constant.Shift(x, op, uint(s))
+
+	case token.QUO, token.REM:
+		if y.Kind() != constant.Int || constant.Sign(y) == 0 {
+			return nil // division by zero panics
+		}
+		if op == token.QUO {
+			op = token.QUO_ASSIGN // integer division
+		}
+	}
+	if x.Kind() != y.Kind() || x.Kind() == constant.Bool {
+		return nil
+	}
+	if x.Kind() == constant.String && op != token.ADD {
+		return nil
+	}
+	return constant.BinaryOp(x, op, y)
+}
+
+// unary returns the result of op x, where x has type t,
+// or nil if it cannot be computed.
+func unary(op token.Token, x constant.Value, t types.Type) constant.Value {
+	if !foldable(t) {
+		return nil
+	}
+	switch op {
+	case token.NOT:
+		if x.Kind() == constant.Bool {
+			return constant.UnaryOp(op, x, 0)
+		}
+	case token.SUB:
+		if x.Kind() == constant.Int {
+			return constant.UnaryOp(op, x, 0)
+		}
+	case token.XOR:
+		if x.Kind() == constant.Int {
+			basic := t.Underlying().(*types.Basic)
+			if basic.Info()&types.IsUnsigned == 0 {
+				return constant.UnaryOp(op, x, 0)
+			}
+			// The complement of an unsigned value depends on its size.
+			switch basic.Kind() {
+			case types.Uint8:
+				return constant.UnaryOp(op, x, 8)
+			case types.Uint16:
+				return constant.UnaryOp(op, x, 16)
+			case types.Uint32:
+				return constant.UnaryOp(op, x, 32)
+			case types.Uint64:
+				return constant.UnaryOp(op, x, 64)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,270 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package dataflow provides a solver for dataflow problems over the
+// control-flow graph of an SSA function.
+//
+// A client describes a [Problem]: the [Lattice] of facts, the
+// direction of the analysis, and the transfer function applied to each
+// instruction. [Solve] iterates over the blocks of the function until
+// the facts at the boundaries of every block reach a fixed point.
+// Facts may optionally be refined along each control-flow edge, for
+// example to take account of the outcome of an [ssa.If].
+//
+// The package also provides several classic analyses built on the
+// solver: [ReachingDefinitions], [Liveness], and [Constants].
+//
+// # Termination
+//
+// The solver terminates only if the lattice has finite height and the
+// transfer and edge functions are monotone: that is, if they never
+// map a larger fact to a smaller one.
+package dataflow // import "golang.org/x/tools/go/ssa/dataflow"
+
+import (
+	"golang.org/x/tools/go/ssa"
+)
+
+// A Lattice describes the domain of facts of a dataflow problem.
+//
+// Facts are values: the solver retains the facts passed to and
+// returned by the methods of the lattice and by the functions of a
+// [Problem], so none of them may modify a fact after it has been
+// created.
+type Lattice[F any] interface {
+	// Bottom returns the least fact, the initial fact of every block.
+	Bottom() F
+
+	// Join returns the least upper bound of x and y.
+	Join(x, y F) F
+
+	// Equal reports whether x and y are the same fact.
+	Equal(x, y F) bool
+}
+
+// A Direction is the direction in which facts flow.
+type Direction int
+
+const (
+	// Forward analyses compute the fact after each instruction from
+	// the fact before it, starting at the entry of the function.
+	Forward Direction = iota
+
+	// Backward analyses compute the fact before each instruction from
+	// the fact after it, starting at the exits of the function.
+	Backward
+)
+
+// A Problem describes a dataflow problem over facts of type F.
+type Problem[F any] struct {
+	Direction Direction
+	Lattice   Lattice[F]
+
+	// Boundary is the fact at the boundary of the function: at the
+	// start of the entry and recover blocks in a forward analysis,
+	// or at the end of every block without successors in a backward
+	// one.
+	Boundary F
+
+	// Transfer returns the fact that holds after instr, given the
+	// fact before it (Forward), or the fact that holds before instr,
+	// given the fact after it (Backward).
+	Transfer func(instr ssa.Instruction, fact F) F
+
+	// Edge, if non-nil, returns the fact that flows along the
+	// control-flow edge from block from to block to, given the fact
+	// at the end of from (Forward) or at the start of to (Backward).
+	// It is called for each edge, so an [ssa.If] whose condition is
+	// known may, for example, yield Bottom for the edge not taken,
+	// and the operands of the φ-nodes of to may be associated with
+	// the edge on which they are used.
+	//
+	// If a block has the same predecessor more than once, Edge is
+	// called once for each occurrence.
+	Edge func(from, to *ssa.BasicBlock, fact F) F
+}
+
+// A Result holds the solution of a dataflow problem for a function.
+//
+// Regardless of the direction of the problem, In and Before report
+// facts at points that precede, in execution order, those reported by
+// Out and After.
+type Result[F any] struct {
+	problem *Problem[F]
+	in, out []F // indexed by BasicBlock.Index
+}
+
+// In returns the fact that holds at the start of block b.
+func (r *Result[F]) In(b *ssa.BasicBlock) F { return r.in[b.Index] }
+
+// Out returns the fact that holds at the end of block b.
+func (r *Result[F]) Out(b *ssa.BasicBlock) F { return r.out[b.Index] }
+
+// Before returns the fact that holds immediately before instr.
+func (r *Result[F]) Before(instr ssa.Instruction) F {
+	before, _ := r.at(instr)
+	return before
+}
+
+// After returns the fact that holds immediately after instr.
+func (r *Result[F]) After(instr ssa.Instruction) F {
+	_, after := r.at(instr)
+	return after
+}
+
+// at returns the facts before and after instr, by reapplying the
+// transfer function to the instructions of its block.
+func (r *Result[F]) at(instr ssa.Instruction) (before, after F) {
+	b := instr.Block()
+	transfer := r.problem.Transfer
+	if r.problem.Direction == Forward {
+		fact := r.in[b.Index]
+		for _, in := range b.Instrs {
+			next := transfer(in, fact)
+			if in == instr {
+				return fact, next
+			}
+			fact = next
+		}
+	} else {
+		fact := r.out[b.Index]
+		for i := len(b.Instrs) - 1; i >= 0; i-- {
+			in := b.Instrs[i]
+			prev := transfer(in, fact)
+			if in == instr {
+				return prev, fact
+			}
+			fact = prev
+		}
+	}
+	panic("instruction not found in its block")
+}
+
+// Solve computes the solution of problem p for function fn, which
+// must have been built.
+//
+// Every block starts with the fact Bottom. Blocks are then visited
+// in an order that follows the direction of the problem: a block's
+// incoming fact is the join of the facts flowing along its incoming
+// edges (and the boundary fact, for boundary blocks), and its
+// outgoing fact is computed by applying the transfer function to each
+// of its instructions. Whenever the outgoing fact of a block changes,
+// the blocks that it flows into are visited again.
+func Solve[F any](fn *ssa.Function, p *Problem[F]) *Result[F] {
+	n := len(fn.Blocks)
+	r := &Result[F]{
+		problem: p,
+		in:      make([]F, n),
+		out:     make([]F, n),
+	}
+	lat := p.Lattice
+	for i := range n {
+		r.in[i] = lat.Bottom()
+		r.out[i] = lat.Bottom()
+	}
+	if n == 0 {
+		return r // external function
+	}
+
+	// Visit each block once, in reverse postorder for a forward
+	// problem or postorder for a backward one, so that most blocks
+	// are visited after the blocks that flow into them.
+	order := postorder(fn)
+	if p.Direction == Forward {
+		for i, j := 0, len(order)-1; i < j; i, j = i+1, j-1 {
+			order[i], order[j] = order[j], order[i]
+		}
+	}
+	queued := make([]bool, n)
+	queue := make([]*ssa.BasicBlock, 0, n)
+	for _, b := range order {
+		queued[b.Index] = true
+		queue = append(queue, b)
+	}
+
+	enqueue := func(blocks []*ssa.BasicBlock) {
+		for _, b := range blocks {
+			if !queued[b.Index] {
+				queued[b.Index] = true
+				queue = append(queue, b)
+			}
+		}
+	}
+
+	for len(queue) > 0 {
+		b := queue[0]
+		queue = queue[1:]
+		queued[b.Index] = false
+
+		fact := lat.Bottom()
+		if p.Direction == Forward {
+			if b.Index == 0 || b == fn.Recover {
+				fact = lat.Join(fact, p.Boundary)
+			}
+			for _, pred := range b.Preds {
+				edge := r.out[pred.Index]
+				if p.Edge != nil {
+					edge = p.Edge(pred, b, edge)
+				}
+				fact = lat.Join(fact, edge)
+			}
+			r.in[b.Index] = fact
+			for _, instr := range b.Instrs {
+				fact = p.Transfer(instr, fact)
+			}
+			if !lat.Equal(fact, r.out[b.Index]) {
+				r.out[b.Index] = fact
+				enqueue(b.Succs)
+			}
+		} else {
+			if len(b.Succs) == 0 {
+				fact = lat.Join(fact, p.Boundary)
+			}
+			for _, succ := range b.Succs {
+				edge := r.in[succ.Index]
+				if p.Edge != nil {
+					edge = p.Edge(b, succ, edge)
+				}
+				fact = lat.Join(fact, edge)
+			}
+			r.out[b.Index] = fact
+			for i := len(b.Instrs) - 1; i >= 0; i-- {
+				fact = p.Transfer(b.Instrs[i], fact)
+			}
+			if !lat.Equal(fact, r.in[b.Index]) {
+				r.in[b.Index] = fact
+				enqueue(b.Preds)
+			}
+		}
+	}
+	return r
+}
+
+// postorder returns the blocks of fn in postorder of a depth-first
+// traversal from the entry and recover blocks, followed by any blocks
+// not reachable from them.
+func postorder(fn *ssa.Function) []*ssa.BasicBlock {
+	seen := make([]bool, len(fn.Blocks))
+	order := make([]*ssa.BasicBlock, 0, len(fn.Blocks))
+	var visit func(b *ssa.BasicBlock)
+	visit = func(b *ssa.BasicBlock) {
+		seen[b.Index] = true
+		for _, succ := range b.Succs {
+			if !seen[succ.Index] {
+				visit(succ)
+			}
+		}
+		order = append(order, b)
+	}
+	visit(fn.Blocks[0])
+	if fn.Recover != nil && !seen[fn.Recover.Index] {
+		visit(fn.Recover)
+	}
+	for _, b := range fn.Blocks {
+		if !seen[b.Index] {
+			visit(b)
+		}
+	}
+	return order
+}
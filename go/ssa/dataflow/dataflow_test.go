@@ -0,0 +1,251 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dataflow_test
+
+import (
+	"fmt"
+	"go/ast"
+	"go/constant"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"slices"
+	"testing"
+
+	"golang.org/x/tools/go/ssa"
+	"golang.org/x/tools/go/ssa/dataflow"
+	"golang.org/x/tools/go/ssa/ssautil"
+)
+
+const src = `package p
+
+func constants(p int) int {
+	x := 2
+	y := x * 3
+	if y > 5 {
+		print("then")
+	} else {
+		print("else")
+	}
+	i := 0
+	for i < p {
+		i += 5
+	}
+	z := 1
+	for j := 0; j < 3; j++ {
+		z = z * 7 / 7
+	}
+	return y + i + z
+}
+
+func live(a, b int) int {
+	c := a + b
+	if c > 0 {
+		return a
+	}
+	return b
+}
+
+func loop(n int) int {
+	s := 0
+	for i := 0; i < n; i++ {
+		s += i
+	}
+	return s
+}
+
+func reaching(cond bool) int {
+	x := 1
+	p := &x
+	if cond {
+		x = 2
+	}
+	return *p
+}
+`
+
+// build returns the SSA package for src.
+func build(t *testing.T) *ssa.Package {
+	t.Helper()
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, "p.go", src, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pkg := types.NewPackage("p", "")
+	ssapkg, _, err := ssautil.BuildPackage(&types.Config{}, fset, pkg, []*ast.File{f}, ssa.SanityCheckFunctions)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return ssapkg
+}
+
+// find returns the first instruction of fn of type T satisfying pred.
+func find[T ssa.Instruction](t *testing.T, fn *ssa.Function, pred func(T) bool) T {
+	t.Helper()
+	for _, b := range fn.Blocks {
+		for _, instr := range b.Instrs {
+			if instr, ok := instr.(T); ok && pred(instr) {
+				return instr
+			}
+		}
+	}
+	var zero T
+	t.Fatalf("no %T found in %s", zero, fn)
+	return zero
+}
+
+// constOperand reports whether v is the integer constant k.
+func constOperand(v ssa.Value, k int64) bool {
+	c, ok := v.(*ssa.Const)
+	return ok && c.Value != nil && c.Value.Kind() == constant.Int && c.Int64() == k
+}
+
+func TestConstants(t *testing.T) {
+	fn := build(t).Func("constants")
+	consts := dataflow.Constants(fn)
+
+	// y := x * 3 is 6.
+	mul := find(t, fn, func(b *ssa.BinOp) bool { return b.Op == token.MUL && constOperand(b.Y, 3) })
+	if got := consts.Value(mul); got == nil || got.ExactString() != "6" {
+		t.Errorf("Value(%s) = %v, want 6", mul, got)
+	}
+
+	// The else branch is unreachable.
+	for _, s := range []string{"then", "else"} {
+		call := find(t, fn, func(c *ssa.Call) bool {
+			c0, ok := c.Call.Args[0].(*ssa.Const)
+			return ok && constant.StringVal(c0.Value) == s
+		})
+		if got, want := consts.Reachable(call.Block()), s == "then"; got != want {
+			t.Errorf("Reachable(%s block) = %t, want %t", s, got, want)
+		}
+	}
+
+	// i, which depends on p, is not constant; z is.
+	for _, test := range []struct {
+		op            token.Token
+		k             int64
+		want, wantPhi string
+	}{
+		{token.ADD, 5, "<nil>", "<nil>"}, // i + 5
+		{token.MUL, 7, "7", "1"},         // z * 7
+	} {
+		bin := find(t, fn, func(b *ssa.BinOp) bool { return b.Op == test.op && constOperand(b.Y, test.k) })
+		if got := fmt.Sprint(consts.Value(bin)); got != test.want {
+			t.Errorf("Value(%s) = %s, want %s", bin, got, test.want)
+		}
+		if phi, ok := bin.X.(*ssa.Phi); !ok {
+			t.Errorf("%s: operand is not a φ-node", bin)
+		} else if got := fmt.Sprint(consts.Value(phi)); got != test.wantPhi {
+			t.Errorf("Value(%s) = %s, want %s", phi, got, test.wantPhi)
+		}
+	}
+}
+
+func TestLiveness(t *testing.T) {
+	pkg := build(t)
+
+	fn := pkg.Func("live")
+	a, b := fn.Params[0], fn.Params[1]
+	liveness := dataflow.Liveness(fn)
+	entry := fn.Blocks[0]
+	checkValues(t, "LiveIn(entry)", liveness.LiveIn(entry), a, b)
+	checkValues(t, "LiveOut(entry)", liveness.LiveOut(entry), a, b)
+	for _, succ := range entry.Succs {
+		ret := succ.Instrs[len(succ.Instrs)-1].(*ssa.Return)
+		checkValues(t, fmt.Sprintf("LiveIn(%s)", succ), liveness.LiveIn(succ), ret.Results[0])
+	}
+	add := find(t, fn, func(b *ssa.BinOp) bool { return b.Op == token.ADD })
+	gtr := find(t, fn, func(b *ssa.BinOp) bool { return b.Op == token.GTR })
+	checkValues(t, "LiveAt(c > 0)", liveness.LiveAt(gtr), a, b, add)
+
+	// The operands of a φ-node are live at the end of the
+	// corresponding predecessor only.
+	fn = pkg.Func("loop")
+	liveness = dataflow.Liveness(fn)
+	checkValues(t, "LiveIn(entry)", liveness.LiveIn(fn.Blocks[0]), fn.Params[0])
+	inc := find(t, fn, func(b *ssa.BinOp) bool { return b.Op == token.ADD && constOperand(b.Y, 1) })
+	sum := find(t, fn, func(b *ssa.BinOp) bool { return b.Op == token.ADD && !constOperand(b.Y, 1) })
+	checkValues(t, "LiveOut(body)", liveness.LiveOut(inc.Block()), fn.Params[0], sum, inc)
+	for _, v := range liveness.LiveIn(fn.Blocks[0]) {
+		if _, ok := v.(*ssa.Phi); ok {
+			t.Errorf("φ-node %s is live at entry", v.Name())
+		}
+	}
+}
+
+func checkValues(t *testing.T, what string, got []ssa.Value, want ...ssa.Value) {
+	t.Helper()
+	name := func(v ssa.Value) string { return v.Name() }
+	gotNames := slices.Sorted(slices.Values(mapSlice(got, name)))
+	wantNames := slices.Sorted(slices.Values(mapSlice(want, name)))
+	if !slices.Equal(gotNames, wantNames) {
+		t.Errorf("%s = %v, want %v", what, gotNames, wantNames)
+	}
+}
+
+func mapSlice[T, U any](s []T, f func(T) U) []U {
+	var res []U
+	for _, x := range s {
+		res = append(res, f(x))
+	}
+	return res
+}
+
+func TestReachingDefinitions(t *testing.T) {
+	fn := build(t).Func("reaching")
+	defs := dataflow.ReachingDefinitions(fn)
+
+	load := find(t, fn, func(u *ssa.UnOp) bool { return u.Op == token.MUL })
+	var got []string
+	for _, def := range defs.At(load) {
+		got = append(got, def.String())
+	}
+	slices.Sort(got)
+	want := []string{"*t0 = 1:int", "*t0 = 2:int"}
+	if !slices.Equal(got, want) {
+		t.Errorf("At(%s) = %q, want %q", load, got, want)
+	}
+
+	// At the start of the function, no definitions reach.
+	if got := defs.In(fn.Blocks[0]); len(got) > 0 {
+		t.Errorf("In(entry) = %v, want none", got)
+	}
+}
+
+// A parity records whether a count may be odd, and whether it may be
+// even.
+type parity struct{ odd, even bool }
+
+type parityLattice struct{}
+
+func (parityLattice) Bottom() parity          { return parity{} }
+func (parityLattice) Join(x, y parity) parity { return parity{x.odd || y.odd, x.even || y.even} }
+func (parityLattice) Equal(x, y parity) bool  { return x == y }
+
+// TestSolve checks a client-defined analysis: the possible parities
+// of the number of calls executed so far.
+func TestSolve(t *testing.T) {
+	fn := build(t).Func("constants")
+	res := dataflow.Solve(fn, &dataflow.Problem[parity]{
+		Direction: dataflow.Forward,
+		Lattice:   parityLattice{},
+		Boundary:  parity{even: true},
+		Transfer: func(instr ssa.Instruction, p parity) parity {
+			if _, ok := instr.(*ssa.Call); ok {
+				p.odd, p.even = p.even, p.odd
+			}
+			return p
+		},
+	})
+	ret := find(t, fn, func(*ssa.Return) bool { return true })
+	if got, want := res.Before(ret), (parity{odd: true}); got != want {
+		t.Errorf("Before(return) = %+v, want %+v", got, want)
+	}
+	if got, want := res.In(fn.Blocks[0]), (parity{even: true}); got != want {
+		t.Errorf("In(entry) = %+v, want %+v", got, want)
+	}
+}
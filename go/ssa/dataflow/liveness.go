@@ -0,0 +1,106 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dataflow
+
+import "golang.org/x/tools/go/ssa"
+
+// LiveValues records the SSA values that are live at each point of a
+// function: those whose current value may later be used.
+//
+// The values considered are the function's parameters, free variables,
+// and the values defined by its instructions. An operand of a φ-node
+// is used on the edge from the corresponding predecessor, so it is
+// live at the end of that predecessor but not, on account of the
+// φ-node, at the start of the φ-node's block. Uses by [ssa.DebugRef]
+// instructions are ignored.
+type LiveValues struct {
+	res    *Result[bitset]
+	values []ssa.Value // by number
+}
+
+// Liveness computes the values that are live at each point of
+// function fn, which must have been built.
+func Liveness(fn *ssa.Function) *LiveValues {
+	var (
+		values []ssa.Value
+		index  = make(map[ssa.Value]int)
+	)
+	add := func(v ssa.Value) {
+		index[v] = len(values)
+		values = append(values, v)
+	}
+	for _, p := range fn.Params {
+		add(p)
+	}
+	for _, fv := range fn.FreeVars {
+		add(fv)
+	}
+	for _, b := range fn.Blocks {
+		for _, instr := range b.Instrs {
+			if v, ok := instr.(ssa.Value); ok {
+				add(v)
+			}
+		}
+	}
+
+	res := Solve(fn, &Problem[bitset]{
+		Direction: Backward,
+		Lattice:   setLattice{},
+		Transfer: func(instr ssa.Instruction, live bitset) bitset {
+			if v, ok := instr.(ssa.Value); ok {
+				live = live.without(index[v])
+			}
+			switch instr.(type) {
+			case *ssa.Phi, *ssa.DebugRef:
+				return live // φ-node operands are used on edges
+			}
+			var buf [8]*ssa.Value
+			for _, rand := range instr.Operands(buf[:0]) {
+				if i, ok := index[*rand]; ok {
+					live = live.with(i)
+				}
+			}
+			return live
+		},
+		Edge: func(from, to *ssa.BasicBlock, live bitset) bitset {
+			for _, instr := range to.Instrs {
+				phi, ok := instr.(*ssa.Phi)
+				if !ok {
+					break
+				}
+				for i, pred := range to.Preds {
+					if pred == from {
+						if j, ok := index[phi.Edges[i]]; ok {
+							live = live.with(j)
+						}
+					}
+				}
+			}
+			return live
+		},
+	})
+	return &LiveValues{res: res, values: values}
+}
+
+// LiveIn returns the values live at the start of block b.
+func (l *LiveValues) LiveIn(b *ssa.BasicBlock) []ssa.Value {
+	return l.set(l.res.In(b))
+}
+
+// LiveOut returns the values live at the end of block b.
+func (l *LiveValues) LiveOut(b *ssa.BasicBlock) []ssa.Value {
+	return l.set(l.res.Out(b))
+}
+
+// LiveAt returns the values live immediately before instr.
+func (l *LiveValues) LiveAt(instr ssa.Instruction) []ssa.Value {
+	return l.set(l.res.Before(instr))
+}
+
+func (l *LiveValues) set(s bitset) []ssa.Value {
+	var values []ssa.Value
+	s.elems(func(i int) { values = append(values, l.values[i]) })
+	return values
+}
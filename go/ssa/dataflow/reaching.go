@@ -0,0 +1,86 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dataflow
+
+import "golang.org/x/tools/go/ssa"
+
+// ReachingDefs records the definitions of local variables that may
+// reach each point of a function.
+//
+// The local variables of an SSA function are the memory locations
+// allocated by its [ssa.Alloc] instructions that were not lifted to
+// registers. A definition of such a variable is either the Alloc
+// itself, which zeroes it, or a [ssa.Store] whose address operand is
+// the Alloc. Stores through other pointers to the variable, or to its
+// fields or elements, are not considered definitions, and do not kill
+// other definitions.
+type ReachingDefs struct {
+	res  *Result[bitset]
+	defs []ssa.Instruction // definitions, by number
+}
+
+// ReachingDefinitions computes the definitions that may reach each
+// point of function fn, which must have been built.
+func ReachingDefinitions(fn *ssa.Function) *ReachingDefs {
+	// Number the definitions, and compute for each variable the set
+	// of its definitions, which each definition kills.
+	var (
+		defs  []ssa.Instruction
+		index = make(map[ssa.Instruction]int)
+		kills = make(map[*ssa.Alloc]bitset)
+	)
+	for _, b := range fn.Blocks {
+		for _, instr := range b.Instrs {
+			if alloc := definedVar(instr); alloc != nil {
+				index[instr] = len(defs)
+				kills[alloc] = kills[alloc].with(len(defs))
+				defs = append(defs, instr)
+			}
+		}
+	}
+
+	res := Solve(fn, &Problem[bitset]{
+		Direction: Forward,
+		Lattice:   setLattice{},
+		Transfer: func(instr ssa.Instruction, fact bitset) bitset {
+			if alloc := definedVar(instr); alloc != nil {
+				fact = fact.minus(kills[alloc]).with(index[instr])
+			}
+			return fact
+		},
+	})
+	return &ReachingDefs{res: res, defs: defs}
+}
+
+// definedVar returns the variable defined by instr, or nil if it
+// is not a definition.
+func definedVar(instr ssa.Instruction) *ssa.Alloc {
+	switch instr := instr.(type) {
+	case *ssa.Alloc:
+		return instr
+	case *ssa.Store:
+		if alloc, ok := instr.Addr.(*ssa.Alloc); ok {
+			return alloc
+		}
+	}
+	return nil
+}
+
+// In returns the definitions that may reach the start of block b.
+func (r *ReachingDefs) In(b *ssa.BasicBlock) []ssa.Instruction {
+	return r.instrs(r.res.In(b))
+}
+
+// At returns the definitions that may reach the point immediately
+// before instr.
+func (r *ReachingDefs) At(instr ssa.Instruction) []ssa.Instruction {
+	return r.instrs(r.res.Before(instr))
+}
+
+func (r *ReachingDefs) instrs(s bitset) []ssa.Instruction {
+	var instrs []ssa.Instruction
+	s.elems(func(i int) { instrs = append(instrs, r.defs[i]) })
+	return instrs
+}
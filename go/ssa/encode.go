@@ -0,0 +1,1772 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ssa
+
+// This file defines the serialization of built SSA packages, which
+// allows SSA construction to be performed separately for each package,
+// and its results to be cached, much as export data and analysis facts
+// are.
+//
+// An encoded package holds the export data for its types (including
+// its unexported declarations), followed by a description of the
+// bodies of its functions. References to types, functions, globals
+// and methods are symbolic: package-level objects are identified by
+// package path and name, and are resolved against the
+// program into which the package is read. Synthetic functions that the
+// builder can recreate from types alone (wrappers, thunks, bounds and
+// instantiation wrappers) are encoded as recipes, not bodies.
+//
+// The encoding does not preserve debug information (DebugRef
+// instructions and SelectState.DebugNode), nor function syntax.
+
+import (
+	"bytes"
+	"encoding/gob"
+	"fmt"
+	"go/constant"
+	"go/token"
+	"go/types"
+	"io"
+	"math/big"
+	"os"
+	"slices"
+	"sort"
+	"strings"
+
+	"golang.org/x/tools/go/gcexportdata"
+	"golang.org/x/tools/internal/gcimporter"
+	"golang.org/x/tools/internal/typesinternal"
+)
+
+// encodingVersion is the version of the encoding written by
+// Package.Encode. Increase it with each change to the format.
+const encodingVersion = 1
+
+// Encode writes an encoding of package p, which must have been
+// built, to w. The encoding may be read into another program by
+// [Program.DecodePackage].
+//
+// The encoding is specific to the version of this package that
+// wrote it; clients that save it in the file system must include a
+// digest of the executable in the cache key.
+func (p *Package) Encode(w io.Writer) (err error) {
+	if p.info != nil {
+		return fmt.Errorf("package %s has not been built", p.Pkg.Path())
+	}
+
+	e := &encoder{
+		prog:   p.Prog,
+		pkg:    p,
+		files:  make(map[*token.File]int),
+		types:  make(map[types.Type]int),
+		funcs:  make(map[*Function]int),
+		bodies: make(map[*Function]bool),
+		values: make(map[Value]int),
+		deps:   make(map[*types.Package]bool),
+	}
+	var addDeps func(pkg *types.Package)
+	addDeps = func(pkg *types.Package) {
+		if !e.deps[pkg] {
+			e.deps[pkg] = true
+			for _, imp := range pkg.Imports() {
+				addDeps(imp)
+			}
+		}
+	}
+	addDeps(p.Pkg)
+	defer func() {
+		if x := recover(); x != nil {
+			if ex, ok := x.(encodeError); ok {
+				err = fmt.Errorf("encoding package %s: %v", p.Pkg.Path(), ex.err)
+				return
+			}
+			panic(x)
+		}
+	}()
+
+	var export bytes.Buffer
+	export.WriteByte('i') // indexed format, as expected by gcexportdata.Read
+	if err := gcimporter.IExportAllData(&export, p.Prog.Fset, p.Pkg); err != nil {
+		return err
+	}
+	e.out = encPackage{
+		Version:  encodingVersion,
+		Path:     p.Pkg.Path(),
+		Export:   export.Bytes(),
+		NumInits: int(p.ninit),
+	}
+
+	// Encode the package-level functions and methods, in a
+	// deterministic order, along with any anonymous functions and
+	// instances they refer to.
+	names := make([]string, 0, len(p.Members))
+	for name := range p.Members {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		switch mem := p.Members[name].(type) {
+		case *Function:
+			e.root(mem)
+		case *Type:
+			if named, ok := mem.Type().(*types.Named); ok && mem.object.Pkg() == p.Pkg {
+				for i := range named.NumMethods() {
+					if m := named.Method(i); m.Name() != "_" {
+						if fn, ok := p.objects[m].(*Function); ok {
+							e.root(fn)
+						}
+					}
+				}
+			}
+		}
+	}
+	for i := 0; i < len(e.pending); i++ {
+		fn := e.pending[i]
+		body := e.body(fn)
+		e.out.Funcs[e.funcs[fn]].Body = body
+	}
+
+	return gob.NewEncoder(w).Encode(&e.out)
+}
+
+// DecodePackage reads an encoding of a package written by [Package.Encode]
+// and returns the corresponding built package, which it adds to prog.
+//
+// The package's types are decoded from the export data within the
+// encoding, and are added to the imports map, in the manner of
+// [gcexportdata.Read]. The map must already contain complete type
+// information for all packages referred to by the encoded functions;
+// typically this is achieved by reading the encodings of all the
+// package's dependencies, in dependency order, using the same map.
+// Dependencies that have not been added to prog are created from
+// their types, as if by [Program.CreatePackage].
+//
+// The package must not already exist in prog, and prog must use the
+// same [BuilderMode] as the program from which the package was
+// written. Functions created on demand after reading, such as
+// instances of decoded generic functions in [InstantiateGenerics] mode,
+// have no bodies, as there is no syntax from which to build them.
+// The positions of declared objects, and thus of the functions and
+// globals they declare, are those recorded by the export data, which
+// lack column information.
+//
+// DecodePackage must not be called concurrently with other operations
+// on prog.
+func (prog *Program) DecodePackage(r io.Reader, imports map[string]*types.Package) (_ *Package, err error) {
+	var in encPackage
+	if err := gob.NewDecoder(r).Decode(&in); err != nil {
+		return nil, fmt.Errorf("reading SSA package: %v", err)
+	}
+	if in.Version != encodingVersion {
+		return nil, fmt.Errorf("reading SSA package %s: unsupported encoding version %d", in.Path, in.Version)
+	}
+	if tpkg := imports[in.Path]; tpkg != nil && prog.packages[tpkg] != nil {
+		return nil, fmt.Errorf("reading SSA package %s: package already exists", in.Path)
+	}
+	tpkg, err := gcexportdata.Read(bytes.NewReader(in.Export), prog.Fset, imports, in.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	d := &decoder{
+		prog:    prog,
+		in:      &in,
+		imports: imports,
+		tpkg:    tpkg,
+		types:   make([]types.Type, len(in.Types)),
+		values:  make([]Value, len(in.Values)),
+		funcs:   make([]*Function, len(in.Funcs)),
+		fresh:   make([]bool, len(in.Funcs)),
+	}
+	defer func() {
+		if x := recover(); x != nil {
+			if ex, ok := x.(encodeError); ok {
+				err = fmt.Errorf("reading SSA package %s: %v", in.Path, ex.err)
+				return
+			}
+			panic(x)
+		}
+	}()
+	for _, f := range in.Files {
+		tf := prog.Fset.AddFile(f.Name, -1, f.Size)
+		if !tf.SetLines(f.Lines) {
+			d.errorf("invalid line table for file %s", f.Name)
+		}
+		d.files = append(d.files, tf)
+	}
+
+	p := prog.CreatePackage(tpkg, nil, nil, true)
+	d.pkg = p
+	for range in.NumInits {
+		memberFromObject(p, types.NewFunc(token.NoPos, tpkg, "init", new(types.Signature)), nil, "")
+	}
+
+	// Create (or find) all the functions, then populate the bodies
+	// of those we created.
+	for i := range in.Funcs {
+		d.funcs[i], d.fresh[i] = d.function(&in.Funcs[i])
+	}
+	var bodies []*Function
+	for i := range in.Funcs {
+		if body := in.Funcs[i].Body; body != nil && d.fresh[i] {
+			d.body(d.funcs[i], body)
+			bodies = append(bodies, d.funcs[i])
+		}
+	}
+	for _, fn := range bodies {
+		if fn.Blocks != nil {
+			buildReferrers(fn)
+			buildDomTree(fn)
+			numberRegisters(fn)
+		}
+	}
+	for _, fn := range bodies {
+		fn.build = nil
+		if prog.mode&PrintFunctions != 0 {
+			printMu.Lock()
+			fn.WriteTo(os.Stdout)
+			printMu.Unlock()
+		}
+		if prog.mode&SanityCheckFunctions != 0 {
+			mustSanityCheck(fn, nil)
+		}
+	}
+
+	// Build the synthetic functions created from recipes.
+	d.b.iterate()
+
+	p.created = nil
+	p.initVersion = nil
+	p.buildOnce.Do(func() {}) // already built
+	if prog.mode&SanityCheckFunctions != 0 {
+		sanityCheckPackage(p)
+	}
+	return p, nil
+}
+
+// -- encoding format --
+
+// An encPackage is the encoding of a built package.
+type encPackage struct {
+	Version  int
+	Path     string
+	Export   []byte // export data for the package's types
+	Files    []encFile
+	Types    []encType
+	Methods  []encMethod
+	Funcs    []encFunc
+	Values   []encValue
+	NumInits int // number of declared init functions
+}
+
+// An encFile records a file mentioned by an encoded position.
+type encFile struct {
+	Name  string
+	Size  int
+	Lines []int
+}
+
+// Kinds of encType.
+const (
+	typeBasic      = iota // Basic, Name
+	typePointer           // Elem
+	typeSlice             // Elem
+	typeArray             // Len, Elem
+	typeMap               // Key, Elem
+	typeChan              // Basic (direction), Elem
+	typeSignature         // Vars (params), Results, Variadic
+	typeTuple             // Vars
+	typeStruct            // Vars (fields)
+	typeInterface         // Vars (methods), Types (embeddeds), Implicit
+	typeUnion             // Types, Tildes
+	typeObject            // Pkg, Name: a package-level or universal named type or alias
+	typeInstance          // Elem (origin), Types (type arguments)
+	typeLocal             // Pkg, Name, Pos, Elem (underlying): a local named type
+	typeLocalAlias        // Pkg, Name, Pos, Elem (aliased type): a local alias
+	typeParam             // Pkg, Name, Method, Recv, Index: a type parameter of a package-level declaration
+	typeRangeIter         // the type of Range instructions
+	typeDeferStack        // the type of the ssa:deferstack intrinsic
+)
+
+// An encType is the encoding of a type. References to types are
+// 1-based indices into encPackage.Types; 0 means nil.
+type encType struct {
+	Kind     int
+	Basic    int
+	Len      int64
+	Elem     int
+	Key      int
+	Pkg      string
+	Name     string
+	Method   string
+	Index    int
+	Pos      int64
+	Vars     []encVar
+	Results  []encVar
+	Types    []int
+	Tildes   []bool
+	Variadic bool
+	Implicit bool
+	Recv     bool
+}
+
+// An encVar is the encoding of a parameter, result, struct field or
+// interface method.
+type encVar struct {
+	Name     string
+	Pkg      string
+	Type     int
+	Embedded bool
+	Tag      string
+}
+
+// An encMethod identifies a method, either among the methods of the
+// package-level named type TypePkg.Type, or by looking up its name in
+// the method set of Recv.
+// References to methods are 1-based indices into encPackage.Methods.
+type encMethod struct {
+	Pkg     string // package of the method
+	Name    string
+	TypePkg string
+	Type    string
+	Recv    int
+	RTArgs  []int // receiver type arguments of an instantiated method
+}
+
+// Kinds of encFunc.
+const (
+	funcMember   = iota // Pkg, Name: package-level function or init
+	funcMethod          // Method: declared method
+	funcAnon            // Parent, Index, Type: anonymous function
+	funcInstance        // Origin, RTArgs, TArgs: instance of a generic function
+	funcWrapper         // Method: method wrapper
+	funcThunk           // Method, Type, TArgs: thunk
+	funcBound           // Method, TArgs: bound method wrapper
+)
+
+// An encFunc is the encoding of a function. It holds a recipe for
+// creating or finding the function, and, for functions whose code
+// cannot be recreated without syntax, its body.
+// References to functions are indices into encPackage.Funcs.
+type encFunc struct {
+	Kind   int
+	Pkg    string
+	Name   string
+	Method int
+	Type   int
+	Parent int
+	Index  int
+	Origin int
+	RTArgs []int
+	TArgs  []int
+	Body   *encBody
+}
+
+// An encBody is the encoding of the code of a function.
+//
+// Operands are encoded as integers: 0 is nil; n > 0 refers to the
+// local value n-1, where the locals of a function are its parameters,
+// followed by its free variables, followed by its value-defining
+// instructions in order; and n < 0 refers to encPackage.Values[-n-1].
+type encBody struct {
+	Name      string
+	Synthetic string
+	Pos       int64
+	Params    []encLocal
+	FreeVars  []encLocal
+	Locals    []int // local value indices of non-heap Allocs
+	Blocks    []encBlock
+	Recover   int // 1-based block index; 0 means none
+	AnonFuncs []int
+}
+
+// An encLocal is the encoding of a parameter or free variable.
+type encLocal struct {
+	Name string
+	Type int
+	Pos  int64
+}
+
+// An encBlock is the encoding of a basic block.
+type encBlock struct {
+	Comment      string
+	Preds, Succs []int
+	Instrs       []encInstr
+}
+
+// Opcodes of encInstr.
+const (
+	opAlloc = iota
+	opPhi
+	opCall
+	opBinOp
+	opUnOp
+	opChangeType
+	opConvert
+	opMultiConvert
+	opChangeInterface
+	opSliceToArrayPointer
+	opMakeInterface
+	opMakeClosure
+	opMakeMap
+	opMakeChan
+	opMakeSlice
+	opSlice
+	opFieldAddr
+	opField
+	opIndexAddr
+	opIndex
+	opLookup
+	opSelect
+	opRange
+	opNext
+	opTypeAssert
+	opExtract
+	opJump
+	opIf
+	opReturn
+	opRunDefers
+	opPanic
+	opGo
+	opDefer
+	opSend
+	opStore
+	opMapUpdate
+)
+
+// An encInstr is the encoding of an instruction. Its operands are
+// encoded in the order of [Instruction.Operands].
+type encInstr struct {
+	Op      int
+	Type    int   // type of the value defined by the instruction
+	Pos     int64 // position (the call position, for Call)
+	Rands   []int
+	Int     int  // Op (BinOp, UnOp), Field, Index (Extract)
+	Bool    bool // Heap, CommaOk, Blocking, IsString
+	Comment string
+	Type2   int   // AssertedType, or source type of MultiConvert
+	Type3   int   // destination type of MultiConvert
+	Method  int   // interface method of an invoke-mode call
+	CallPos int64 // call position of Go and Defer
+	States  []encSelectState
+}
+
+// An encSelectState is the encoding of a SelectState, less its operands.
+type encSelectState struct {
+	Dir int
+	Pos int64
+}
+
+// Kinds of encValue.
+const (
+	valueFunc    = iota // Func
+	valueGlobal         // Pkg, Name
+	valueConst          // Type, Const
+	valueBuiltin        // Name, Type
+)
+
+// An encValue is the encoding of a non-local value.
+type encValue struct {
+	Kind  int
+	Func  int
+	Pkg   string
+	Name  string
+	Type  int
+	Const *encConst
+}
+
+// An encConst is the encoding of a constant.Value.
+type encConst struct {
+	Kind       int
+	Bool       bool
+	String     string
+	Int64      int64
+	Int        *big.Int
+	Rat        *big.Rat
+	Float      *big.Float
+	Real, Imag *encConst
+}
+
+// encodeError is the panic value used to abandon encoding or decoding.
+type encodeError struct{ err error }
+
+// -- encoder --
+
+type encoder struct {
+	prog    *Program
+	pkg     *Package
+	out     encPackage
+	tparams map[*types.TypeParam]encType // owners of type parameters, by package
+	tpkgs   map[*types.Package]bool      // packages whose type parameters are indexed
+	deps    map[*types.Package]bool      // the package and its transitive imports
+	files   map[*token.File]int
+	types   map[types.Type]int
+	funcs   map[*Function]int
+	values  map[Value]int
+	bodies  map[*Function]bool // functions whose bodies are encoded
+	pending []*Function        // functions whose bodies are yet to be encoded
+
+	locals map[Value]int // locals of the current function
+}
+
+func (e *encoder) errorf(format string, args ...any) {
+	panic(encodeError{fmt.Errorf(format, args...)})
+}
+
+// root encodes a package-level function or method of the package,
+// and its body.
+func (e *encoder) root(fn *Function) {
+	if _, ok := e.funcs[fn]; !ok {
+		e.function(fn)
+		e.bodies[fn] = true
+		e.pending = append(e.pending, fn)
+	}
+}
+
+// function returns the index of the encoding of a function,
+// encoding it if necessary.
+func (e *encoder) function(fn *Function) int {
+	if i, ok := e.funcs[fn]; ok {
+		return i
+	}
+	var f encFunc
+	body := false
+	switch {
+	case fn.parent != nil:
+		f.Kind = funcAnon
+		f.Parent = e.function(fn.parent)
+		if !e.bodies[fn.parent] {
+			e.errorf("anonymous function %s of a function without an encoded body", fn)
+		}
+		f.Index = int(fn.anonIdx)
+		f.Type = e.typ(fn.Signature)
+		body = true
+
+	case fn.topLevelOrigin != nil:
+		// The type arguments are those of the program's
+		// canonical instance, which may have been created by
+		// another package using its own aliases for them;
+		// see [encoder.typ].
+		f.Kind = funcInstance
+		f.Origin = e.function(fn.topLevelOrigin)
+		f.RTArgs = e.typeList(fn.recvtypeargs)
+		f.TArgs = e.typeList(fn.typeargs)
+		// Instances built from syntax cannot be rebuilt.
+		body = fn.Blocks != nil && strings.HasPrefix(fn.Synthetic, "instance of")
+
+	case fn.method != nil:
+		if len(fn.typeargs) > 0 && fn.method.kind != types.MethodExpr {
+			e.errorf("cannot encode wrapper %s with type arguments", fn)
+		}
+		f.Kind = funcWrapper
+		if fn.method.kind == types.MethodExpr {
+			f.Kind = funcThunk
+			f.Type = e.typ(fn.method.typ)
+			f.TArgs = e.typeList(fn.typeargs)
+		}
+		f.Method = e.selection(fn.method.recv, fn.method.obj)
+
+	case fn.object != nil && strings.HasPrefix(fn.Synthetic, "bound method wrapper"):
+		f.Kind = funcBound
+		f.Method = e.method(fn.object)
+		f.TArgs = e.typeList(fn.typeargs)
+
+	case fn.Pkg != nil && (fn.object == nil || fn.object.Signature().Recv() == nil):
+		if fn.Pkg.Members[fn.name] != fn {
+			e.errorf("cannot encode function %s", fn)
+		}
+		f.Kind = funcMember
+		f.Pkg = fn.Pkg.Pkg.Path()
+		f.Name = fn.name
+
+	case fn.object != nil:
+		f.Kind = funcMethod
+		f.Method = e.method(fn.object)
+
+	default:
+		e.errorf("cannot encode function %s", fn)
+	}
+
+	i := len(e.out.Funcs)
+	e.out.Funcs = append(e.out.Funcs, f)
+	e.funcs[fn] = i
+	if body {
+		e.bodies[fn] = true
+		e.pending = append(e.pending, fn)
+	}
+	return i
+}
+
+// body returns the encoding of the code of fn.
+func (e *encoder) body(fn *Function) *encBody {
+	e.locals = make(map[Value]int)
+	x := &encBody{
+		Name:      fn.name,
+		Synthetic: fn.Synthetic,
+		Pos:       e.pos(fn.pos),
+	}
+	for _, p := range fn.Params {
+		e.locals[p] = len(e.locals)
+		x.Params = append(x.Params, encLocal{Name: p.name, Type: e.typ(p.typ)})
+	}
+	for _, fv := range fn.FreeVars {
+		e.locals[fv] = len(e.locals)
+		x.FreeVars = append(x.FreeVars, encLocal{Name: fv.name, Type: e.typ(fv.typ), Pos: e.pos(fv.pos)})
+	}
+	for _, b := range fn.Blocks {
+		for _, instr := range b.Instrs {
+			if v, ok := instr.(Value); ok {
+				e.locals[v] = len(e.locals)
+			}
+		}
+	}
+	for _, l := range fn.Locals {
+		x.Locals = append(x.Locals, e.locals[l])
+	}
+	if fn.Recover != nil {
+		x.Recover = fn.Recover.Index + 1
+	}
+	for _, b := range fn.Blocks {
+		xb := encBlock{Comment: b.Comment}
+		for _, pred := range b.Preds {
+			xb.Preds = append(xb.Preds, pred.Index)
+		}
+		for _, succ := range b.Succs {
+			xb.Succs = append(xb.Succs, succ.Index)
+		}
+		for _, instr := range b.Instrs {
+			if _, ok := instr.(*DebugRef); ok {
+				continue // debug information is not preserved
+			}
+			xb.Instrs = append(xb.Instrs, e.instr(instr))
+		}
+		x.Blocks = append(x.Blocks, xb)
+	}
+	for _, anon := range fn.AnonFuncs {
+		x.AnonFuncs = append(x.AnonFuncs, e.function(anon))
+	}
+	return x
+}
+
+// instr returns the encoding of an instruction.
+func (e *encoder) instr(instr Instruction) encInstr {
+	var x encInstr
+	if v, ok := instr.(Value); ok {
+		x.Type = e.typ(v.Type())
+	}
+	x.Pos = e.pos(instr.Pos())
+	for _, rand := range instr.Operands(nil) {
+		x.Rands = append(x.Rands, e.value(*rand))
+	}
+	switch instr := instr.(type) {
+	case *Alloc:
+		x.Op = opAlloc
+		x.Bool = instr.Heap
+		x.Comment = instr.Comment
+	case *Phi:
+		x.Op = opPhi
+		x.Comment = instr.Comment
+	case *Call:
+		x.Op = opCall
+		x.Method = e.invoke(&instr.Call)
+	case *BinOp:
+		x.Op = opBinOp
+		x.Int = int(instr.Op)
+	case *UnOp:
+		x.Op = opUnOp
+		x.Int = int(instr.Op)
+		x.Bool = instr.CommaOk
+	case *ChangeType:
+		x.Op = opChangeType
+	case *Convert:
+		x.Op = opConvert
+	case *MultiConvert:
+		x.Op = opMultiConvert
+		x.Type2 = e.typ(instr.from)
+		x.Type3 = e.typ(instr.to)
+	case *ChangeInterface:
+		x.Op = opChangeInterface
+	case *SliceToArrayPointer:
+		x.Op = opSliceToArrayPointer
+	case *MakeInterface:
+		x.Op = opMakeInterface
+	case *MakeClosure:
+		x.Op = opMakeClosure
+	case *MakeMap:
+		x.Op = opMakeMap
+	case *MakeChan:
+		x.Op = opMakeChan
+	case *MakeSlice:
+		x.Op = opMakeSlice
+	case *Slice:
+		x.Op = opSlice
+	case *FieldAddr:
+		x.Op = opFieldAddr
+		x.Int = instr.Field
+	case *Field:
+		x.Op = opField
+		x.Int = instr.Field
+	case *IndexAddr:
+		x.Op = opIndexAddr
+	case *Index:
+		x.Op = opIndex
+	case *Lookup:
+		x.Op = opLookup
+		x.Bool = instr.CommaOk
+	case *Select:
+		x.Op = opSelect
+		x.Bool = instr.Blocking
+		for _, st := range instr.States {
+			x.States = append(x.States, encSelectState{Dir: int(st.Dir), Pos: e.pos(st.Pos)})
+		}
+	case *Range:
+		x.Op = opRange
+	case *Next:
+		x.Op = opNext
+		x.Bool = instr.IsString
+	case *TypeAssert:
+		x.Op = opTypeAssert
+		x.Type2 = e.typ(instr.AssertedType)
+		x.Bool = instr.CommaOk
+	case *Extract:
+		x.Op = opExtract
+		x.Int = instr.Index
+	case *Jump:
+		x.Op = opJump
+	case *If:
+		x.Op = opIf
+	case *Return:
+		x.Op = opReturn
+	case *RunDefers:
+		x.Op = opRunDefers
+	case *Panic:
+		x.Op = opPanic
+	case *Go:
+		x.Op = opGo
+		x.Method = e.invoke(&instr.Call)
+		x.CallPos = e.pos(instr.Call.pos)
+	case *Defer:
+		x.Op = opDefer
+		x.Method = e.invoke(&instr.Call)
+		x.CallPos = e.pos(instr.Call.pos)
+	case *Send:
+		x.Op = opSend
+	case *Store:
+		x.Op = opStore
+	case *MapUpdate:
+		x.Op = opMapUpdate
+	default:
+		e.errorf("unexpected instruction %T", instr)
+	}
+	return x
+}
+
+// invoke returns the method reference of an invoke-mode call, or 0.
+func (e *encoder) invoke(call *CallCommon) int {
+	if call.Method == nil {
+		return 0
+	}
+	return e.method(call.Method)
+}
+
+// value returns the encoding of an operand.
+func (e *encoder) value(v Value) int {
+	if v == nil {
+		return 0
+	}
+	if i, ok := e.locals[v]; ok {
+		return i + 1
+	}
+	if i, ok := e.values[v]; ok {
+		return -i - 1
+	}
+	var x encValue
+	switch v := v.(type) {
+	case *Function:
+		x.Kind = valueFunc
+		x.Func = e.function(v)
+	case *Global:
+		if v.Pkg == nil || v.Pkg.Members[v.name] != v {
+			e.errorf("cannot encode global %s", v)
+		}
+		x.Kind = valueGlobal
+		x.Pkg = v.Pkg.Pkg.Path()
+		x.Name = v.name
+	case *Const:
+		x.Kind = valueConst
+		x.Type = e.typ(v.typ)
+		x.Const = e.constant(v.Value)
+	case *Builtin:
+		x.Kind = valueBuiltin
+		x.Name = v.name
+		x.Type = e.typ(v.sig)
+	default:
+		e.errorf("unexpected operand %s of type %T", v, v)
+	}
+	i := len(e.out.Values)
+	e.out.Values = append(e.out.Values, x)
+	e.values[v] = i
+	return -i - 1
+}
+
+// constant returns the encoding of a constant value.
+func (e *encoder) constant(v constant.Value) *encConst {
+	if v == nil {
+		return nil
+	}
+	x := &encConst{Kind: int(v.Kind())}
+	switch v.Kind() {
+	case constant.Bool:
+		x.Bool = constant.BoolVal(v)
+	case constant.String:
+		x.String = constant.StringVal(v)
+	case constant.Int, constant.Float:
+		switch val := constant.Val(v).(type) {
+		case int64:
+			x.Int64 = val
+		case *big.Int:
+			x.Int = val
+		case *big.Rat:
+			x.Rat = val
+		case *big.Float:
+			x.Float = val
+		}
+	case constant.Complex:
+		x.Real = e.constant(constant.Real(v))
+		x.Imag = e.constant(constant.Imag(v))
+	default:
+		e.errorf("unexpected constant %s", v)
+	}
+	return x
+}
+
+// method returns the reference to an encoding of a method.
+func (e *encoder) method(obj *types.Func) int {
+	var x encMethod
+	if orig := obj.Origin(); orig != obj {
+		x.RTArgs = e.typeList(receiverTypeArgs(obj))
+		obj = orig
+	}
+	x.Pkg = pkgPath(obj.Pkg())
+	x.Name = obj.Name()
+	if _, named := typesinternal.ReceiverNamed(obj.Signature().Recv()); named != nil && isPackageLevel(named.Obj()) {
+		x.TypePkg = pkgPath(named.Obj().Pkg())
+		x.Type = named.Obj().Name()
+	} else {
+		// e.g. method of an unnamed interface
+		x.Recv = e.typ(recvType(obj))
+	}
+	e.out.Methods = append(e.out.Methods, x)
+	return len(e.out.Methods)
+}
+
+// selection returns the reference to an encoding of the method obj
+// selected from type recv.
+func (e *encoder) selection(recv types.Type, obj types.Object) int {
+	e.out.Methods = append(e.out.Methods, encMethod{
+		Recv: e.typ(recv),
+		Pkg:  pkgPath(obj.Pkg()),
+		Name: obj.Name(),
+	})
+	return len(e.out.Methods)
+}
+
+// pos returns the encoding of a position.
+func (e *encoder) pos(pos token.Pos) int64 {
+	if !pos.IsValid() {
+		return 0
+	}
+	tf := e.prog.Fset.File(pos)
+	if tf == nil {
+		return 0
+	}
+	i, ok := e.files[tf]
+	if !ok {
+		i = len(e.out.Files)
+		e.out.Files = append(e.out.Files, encFile{Name: tf.Name(), Size: tf.Size(), Lines: tf.Lines()})
+		e.files[tf] = i
+	}
+	return int64(i+1)<<32 | int64(tf.Offset(pos))
+}
+
+func (e *encoder) typeList(ts []types.Type) []int {
+	var refs []int
+	for _, t := range ts {
+		refs = append(refs, e.typ(t))
+	}
+	return refs
+}
+
+// typ returns the reference to an encoding of a type.
+func (e *encoder) typ(t types.Type) int {
+	if t == nil {
+		return 0
+	}
+	if i, ok := e.types[t]; ok {
+		return i + 1
+	}
+	// Canonical types, such as the type arguments of an instance
+	// shared by several packages, may refer to an alias declared in
+	// a package that is not a dependency of this one, such as
+	// os.DirEntry for io/fs.DirEntry. The decoder cannot resolve
+	// such an alias, so encode the identical type it denotes.
+	if alias, ok := t.(*types.Alias); ok && alias.Obj().Pkg() != nil && !e.deps[alias.Obj().Pkg()] {
+		ref := e.typ(types.Unalias(alias))
+		e.types[t] = ref - 1
+		return ref
+	}
+	// Reserve the index first, as local named types may be recursive.
+	i := len(e.out.Types)
+	e.out.Types = append(e.out.Types, encType{})
+	e.types[t] = i
+
+	var x encType
+	switch t := t.(type) {
+	case *types.Basic:
+		x.Kind = typeBasic
+		x.Basic = int(t.Kind())
+		x.Name = t.Name()
+	case *types.Pointer:
+		if t == tDeferStack {
+			x.Kind = typeDeferStack
+			break
+		}
+		x.Kind = typePointer
+		x.Elem = e.typ(t.Elem())
+	case *types.Slice:
+		x.Kind = typeSlice
+		x.Elem = e.typ(t.Elem())
+	case *types.Array:
+		x.Kind = typeArray
+		x.Len = t.Len()
+		x.Elem = e.typ(t.Elem())
+	case *types.Map:
+		x.Kind = typeMap
+		x.Key = e.typ(t.Key())
+		x.Elem = e.typ(t.Elem())
+	case *types.Chan:
+		x.Kind = typeChan
+		x.Basic = int(t.Dir())
+		x.Elem = e.typ(t.Elem())
+	case *types.Signature:
+		// The receiver is not encoded; it is not part of the
+		// type's identity, and is set by NewInterfaceType for
+		// interface methods.
+		if t.TypeParams().Len() > 0 {
+			e.errorf("cannot encode generic signature %s", t)
+		}
+		x.Kind = typeSignature
+		x.Vars = e.tuple(t.Params())
+		x.Results = e.tuple(t.Results())
+		x.Variadic = t.Variadic()
+	case *types.Tuple:
+		x.Kind = typeTuple
+		x.Vars = e.tuple(t)
+	case *types.Struct:
+		x.Kind = typeStruct
+		for i := range t.NumFields() {
+			v := e.variable(t.Field(i))
+			v.Embedded = t.Field(i).Embedded()
+			v.Tag = t.Tag(i)
+			x.Vars = append(x.Vars, v)
+		}
+	case *types.Interface:
+		x.Kind = typeInterface
+		for i := range t.NumExplicitMethods() {
+			m := t.ExplicitMethod(i)
+			x.Vars = append(x.Vars, encVar{Name: m.Name(), Pkg: pkgPath(m.Pkg()), Type: e.typ(m.Type())})
+		}
+		for i := range t.NumEmbeddeds() {
+			x.Types = append(x.Types, e.typ(t.EmbeddedType(i)))
+		}
+		x.Implicit = t.IsImplicit()
+	case *types.Union:
+		x.Kind = typeUnion
+		for i := range t.Len() {
+			term := t.Term(i)
+			x.Types = append(x.Types, e.typ(term.Type()))
+			x.Tildes = append(x.Tildes, term.Tilde())
+		}
+	case *types.Named:
+		e.named(&x, t.Obj(), t.Origin(), t.TypeArgs())
+		if x.Kind == typeLocal {
+			x.Elem = e.typ(t.Underlying())
+		}
+	case *types.Alias:
+		e.named(&x, t.Obj(), t.Origin(), t.TypeArgs())
+		if x.Kind == typeLocal {
+			x.Kind = typeLocalAlias
+			x.Elem = e.typ(t.Rhs())
+		}
+	case *types.TypeParam:
+		x = e.typeParam(t)
+	case *opaqueType:
+		if t != tRangeIter {
+			e.errorf("unexpected type %s", t)
+		}
+		x.Kind = typeRangeIter
+	default:
+		e.errorf("unexpected type %T", t)
+	}
+	e.out.Types[i] = x
+	return i + 1
+}
+
+// named populates the encoding of a named type or alias.
+func (e *encoder) named(x *encType, obj *types.TypeName, orig types.Type, targs *types.TypeList) {
+	switch {
+	case targs.Len() > 0:
+		x.Kind = typeInstance
+		x.Elem = e.typ(orig)
+		for i := range targs.Len() {
+			x.Types = append(x.Types, e.typ(targs.At(i)))
+		}
+	case obj.Pkg() == nil || isPackageLevel(obj):
+		x.Kind = typeObject
+		x.Pkg = pkgPath(obj.Pkg())
+		x.Name = obj.Name()
+	default:
+		x.Kind = typeLocal
+		x.Pkg = obj.Pkg().Path()
+		x.Name = obj.Name()
+		x.Pos = e.pos(obj.Pos())
+	}
+}
+
+func (e *encoder) tuple(tuple *types.Tuple) []encVar {
+	var vars []encVar
+	for v := range tuple.Variables() {
+		vars = append(vars, e.variable(v))
+	}
+	return vars
+}
+
+func (e *encoder) variable(v *types.Var) encVar {
+	return encVar{Name: v.Name(), Pkg: pkgPath(v.Pkg()), Type: e.typ(v.Type())}
+}
+
+// isPackageLevel reports whether obj is declared at package level.
+func isPackageLevel(obj types.Object) bool {
+	return obj.Pkg() != nil && obj.Pkg().Scope().Lookup(obj.Name()) == obj
+}
+
+// typeParam returns the encoding of a type parameter, which identifies
+// it by its index within the declaration that binds it.
+func (e *encoder) typeParam(tparam *types.TypeParam) encType {
+	pkg := tparam.Obj().Pkg()
+	if !e.tpkgs[pkg] {
+		if e.tpkgs == nil {
+			e.tpkgs = make(map[*types.Package]bool)
+			e.tparams = make(map[*types.TypeParam]encType)
+		}
+		e.tpkgs[pkg] = true
+		add := func(list *types.TypeParamList, x encType) {
+			for i := range list.Len() {
+				x.Index = i
+				e.tparams[list.At(i)] = x
+			}
+		}
+		scope := pkg.Scope()
+		for _, name := range scope.Names() {
+			x := encType{Kind: typeParam, Pkg: pkg.Path(), Name: name}
+			switch obj := scope.Lookup(name).(type) {
+			case *types.Func:
+				add(obj.Signature().TypeParams(), x)
+			case *types.TypeName:
+				switch t := obj.Type().(type) {
+				case *types.Alias:
+					add(t.TypeParams(), x)
+				case *types.Named:
+					add(t.TypeParams(), x)
+					for m := range t.Methods() {
+						x.Method = m.Name()
+						x.Recv = true
+						add(m.Signature().RecvTypeParams(), x)
+						x.Recv = false
+						add(m.Signature().TypeParams(), x)
+					}
+				}
+			}
+		}
+	}
+	x, ok := e.tparams[tparam]
+	if !ok {
+		e.errorf("cannot encode type parameter %s of non-package-level declaration", tparam)
+	}
+	return x
+}
+
+// pkgPath returns the path of pkg, or "" if it is nil.
+func pkgPath(pkg *types.Package) string {
+	if pkg == nil {
+		return ""
+	}
+	return pkg.Path()
+}
+
+// -- decoder --
+
+type decoder struct {
+	prog    *Program
+	pkg     *Package
+	in      *encPackage
+	imports map[string]*types.Package
+	tpkg    *types.Package
+	files   []*token.File
+	types   []types.Type
+	values  []Value
+	funcs   []*Function
+	fresh   []bool // fresh[i] => funcs[i] was created by the decoder
+	b       builder
+}
+
+func (d *decoder) errorf(format string, args ...any) {
+	panic(encodeError{fmt.Errorf(format, args...)})
+}
+
+// function creates or finds the function described by f, and
+// reports whether it is in need of a body.
+func (d *decoder) function(f *encFunc) (*Function, bool) {
+	prog := d.prog
+	switch f.Kind {
+	case funcMember:
+		p := d.ensurePackage(d.lookupPackage(f.Pkg))
+		fn, ok := p.Members[f.Name].(*Function)
+		if !ok {
+			d.errorf("package %s has no function %s", f.Pkg, f.Name)
+		}
+		return fn, p == d.pkg
+
+	case funcMethod:
+		obj := d.method(f.Method)
+		if obj.Pkg() != nil && obj.Pkg() != d.tpkg {
+			d.ensurePackage(obj.Pkg())
+		}
+		fn := prog.objectMethod(obj, nil, &d.b)
+		return fn, fn.Pkg == d.pkg
+
+	case funcAnon:
+		parent := d.funcs[f.Parent]
+		if !d.fresh[f.Parent] {
+			if f.Index >= len(parent.AnonFuncs) {
+				d.errorf("%s has no anonymous function #%d", parent, f.Index)
+			}
+			return parent.AnonFuncs[f.Index], false
+		}
+		sig, ok := d.typ(f.Type).(*types.Signature)
+		if !ok {
+			d.errorf("anonymous function has non-signature type")
+		}
+		return &Function{
+			name:       f.Body.Name,
+			Signature:  sig,
+			parent:     parent,
+			anonIdx:    int32(f.Index),
+			Pkg:        parent.Pkg,
+			Prog:       prog,
+			typeparams: parent.typeparams,
+			typeargs:   parent.typeargs,
+		}, true
+
+	case funcInstance:
+		orig := d.funcs[f.Origin]
+		if orig.generic == nil {
+			d.errorf("instance of non-generic function %s", orig)
+		}
+		rtargs, targs := d.typeList(f.RTArgs), d.typeList(f.TArgs)
+		if f.Body == nil {
+			return orig.instance(rtargs, targs, &d.b), false
+		}
+		return orig.decodedInstance(rtargs, targs)
+
+	case funcWrapper:
+		m := &d.in.Methods[f.Method-1]
+		T := d.typ(m.Recv)
+		sel := prog.MethodSets.MethodSet(T).Lookup(d.lookupPackage(m.Pkg), m.Name)
+		if sel == nil {
+			d.errorf("type %s has no method %s", T, m.Name)
+		}
+		fn := prog.MethodValue(sel)
+		if fn == nil {
+			d.errorf("no wrapper for method %s of type %s", m.Name, T)
+		}
+		return fn, false
+
+	case funcThunk:
+		m := &d.in.Methods[f.Method-1]
+		T := d.typ(m.Recv)
+		obj, index, indirect := types.LookupFieldOrMethod(T, false, d.lookupPackage(m.Pkg), m.Name)
+		if _, ok := obj.(*types.Func); !ok {
+			d.errorf("type %s has no method %s", T, m.Name)
+		}
+		sel := &selection{
+			kind:     types.MethodExpr,
+			recv:     T,
+			typ:      d.typ(f.Type),
+			obj:      obj,
+			index:    index,
+			indirect: indirect,
+		}
+		fn := createThunk(prog, sel, d.typeList(f.TArgs))
+		d.b.enqueue(fn)
+		return fn, false
+
+	case funcBound:
+		fn := createBound(prog, d.method(f.Method), d.typeList(f.TArgs))
+		d.b.enqueue(fn)
+		return fn, false
+	}
+	d.errorf("invalid function kind %d", f.Kind)
+	return nil, false
+}
+
+// decodedInstance returns the instance of generic function fn with
+// the specified type arguments, and reports whether it was created,
+// in which case it is in need of a body.
+//
+// Acquires fn.generic.instancesMu.
+func (fn *Function) decodedInstance(rtargs, targs []types.Type) (*Function, bool) {
+	key := fn.Prog.canon.List(slices.Concat(rtargs, targs))
+
+	gen := fn.generic
+	gen.instancesMu.Lock()
+	defer gen.instancesMu.Unlock()
+	if inst, ok := gen.instances[key]; ok {
+		return inst, false
+	}
+	inst := createInstance(fn, rtargs, targs)
+	if gen.instances == nil {
+		gen.instances = make(map[*typeList]*Function)
+	}
+	gen.instances[key] = inst
+	return inst, true
+}
+
+// body populates the code of fn from its encoding.
+func (d *decoder) body(fn *Function, x *encBody) {
+	fn.Synthetic = x.Synthetic
+	if fn.object == nil {
+		fn.pos = d.pos(x.Pos)
+	}
+	fn.subst = nil
+	fn.decoded = true
+
+	// Parameters are associated with the variables of the signature.
+	var vars []*types.Var
+	if recv := fn.Signature.Recv(); recv != nil {
+		vars = append(vars, recv)
+	}
+	vars = slices.AppendSeq(vars, fn.Signature.Params().Variables())
+	if len(vars) != len(x.Params) {
+		d.errorf("%s has %d parameters, want %d", fn, len(x.Params), len(vars))
+	}
+
+	var locals []Value
+	fn.Params = nil
+	for i, p := range x.Params {
+		param := &Parameter{name: p.Name, object: vars[i], typ: d.typ(p.Type), parent: fn}
+		fn.Params = append(fn.Params, param)
+		locals = append(locals, param)
+	}
+	for _, v := range x.FreeVars {
+		fv := &FreeVar{name: v.Name, typ: d.typ(v.Type), pos: d.pos(v.Pos), parent: fn}
+		fn.FreeVars = append(fn.FreeVars, fv)
+		locals = append(locals, fv)
+	}
+
+	for i, xb := range x.Blocks {
+		b := &BasicBlock{Index: i, Comment: xb.Comment, parent: fn}
+		b.Succs = b.succs2[:0]
+		fn.Blocks = append(fn.Blocks, b)
+	}
+	block := func(i int) *BasicBlock {
+		if i < 0 || i >= len(fn.Blocks) {
+			d.errorf("%s: invalid block index %d", fn, i)
+		}
+		return fn.Blocks[i]
+	}
+	for i, xb := range x.Blocks {
+		b := fn.Blocks[i]
+		for _, j := range xb.Preds {
+			b.Preds = append(b.Preds, block(j))
+		}
+		for _, j := range xb.Succs {
+			b.Succs = append(b.Succs, block(j))
+		}
+		for _, xi := range xb.Instrs {
+			instr := d.instr(&xi)
+			instr.setBlock(b)
+			b.Instrs = append(b.Instrs, instr)
+			if v, ok := instr.(Value); ok {
+				locals = append(locals, v)
+			}
+		}
+	}
+
+	// Now that all local values exist, resolve the operands.
+	for i, xb := range x.Blocks {
+		for j, instr := range fn.Blocks[i].Instrs {
+			rands := instr.Operands(nil)
+			if len(rands) != len(xb.Instrs[j].Rands) {
+				d.errorf("%s: instruction has %d operands, want %d", fn, len(xb.Instrs[j].Rands), len(rands))
+			}
+			for k, ref := range xb.Instrs[j].Rands {
+				switch {
+				case ref > 0:
+					if ref > len(locals) {
+						d.errorf("%s: invalid local value %d", fn, ref-1)
+					}
+					*rands[k] = locals[ref-1]
+				case ref < 0:
+					*rands[k] = d.value(-ref - 1)
+				}
+			}
+			if mi, ok := instr.(*MakeInterface); ok {
+				if t := mi.X.Type(); !d.prog.isParameterized(t) {
+					addMakeInterfaceType(d.prog, t)
+				}
+			}
+		}
+	}
+
+	for _, i := range x.Locals {
+		alloc, ok := locals[i].(*Alloc)
+		if !ok {
+			d.errorf("%s: local %d is not an Alloc", fn, i)
+		}
+		fn.Locals = append(fn.Locals, alloc)
+	}
+	if x.Recover > 0 {
+		fn.Recover = block(x.Recover - 1)
+	}
+	for _, i := range x.AnonFuncs {
+		fn.AnonFuncs = append(fn.AnonFuncs, d.funcs[i])
+	}
+}
+
+// instr returns a new instruction from its encoding, less its operands.
+func (d *decoder) instr(x *encInstr) Instruction {
+	n := len(x.Rands)
+	if n < 0 {
+		n = 0
+	}
+	var instr Instruction
+	switch x.Op {
+	case opAlloc:
+		instr = &Alloc{Heap: x.Bool, Comment: x.Comment}
+	case opPhi:
+		instr = &Phi{Comment: x.Comment, Edges: make([]Value, n)}
+	case opCall:
+		v := &Call{}
+		d.call(&v.Call, x, n-1)
+		v.Call.pos = d.pos(x.Pos)
+		instr = v
+	case opBinOp:
+		instr = &BinOp{Op: token.Token(x.Int)}
+	case opUnOp:
+		instr = &UnOp{Op: token.Token(x.Int), CommaOk: x.Bool}
+	case opChangeType:
+		instr = &ChangeType{}
+	case opConvert:
+		instr = &Convert{}
+	case opMultiConvert:
+		instr = &MultiConvert{from: d.typ(x.Type2), to: d.typ(x.Type3)}
+	case opChangeInterface:
+		instr = &ChangeInterface{}
+	case opSliceToArrayPointer:
+		instr = &SliceToArrayPointer{}
+	case opMakeInterface:
+		instr = &MakeInterface{}
+	case opMakeClosure:
+		instr = &MakeClosure{Bindings: make([]Value, max(n-1, 0))}
+	case opMakeMap:
+		instr = &MakeMap{}
+	case opMakeChan:
+		instr = &MakeChan{}
+	case opMakeSlice:
+		instr = &MakeSlice{}
+	case opSlice:
+		instr = &Slice{}
+	case opFieldAddr:
+		instr = &FieldAddr{Field: x.Int}
+	case opField:
+		instr = &Field{Field: x.Int}
+	case opIndexAddr:
+		instr = &IndexAddr{}
+	case opIndex:
+		instr = &Index{}
+	case opLookup:
+		instr = &Lookup{CommaOk: x.Bool}
+	case opSelect:
+		v := &Select{Blocking: x.Bool}
+		for _, st := range x.States {
+			v.States = append(v.States, &SelectState{Dir: types.ChanDir(st.Dir), Pos: d.pos(st.Pos)})
+		}
+		instr = v
+	case opRange:
+		instr = &Range{}
+	case opNext:
+		instr = &Next{IsString: x.Bool}
+	case opTypeAssert:
+		instr = &TypeAssert{AssertedType: d.typ(x.Type2), CommaOk: x.Bool}
+	case opExtract:
+		instr = &Extract{Index: x.Int}
+	case opJump:
+		instr = &Jump{}
+	case opIf:
+		instr = &If{}
+	case opReturn:
+		instr = &Return{Results: make([]Value, n), pos: d.pos(x.Pos)}
+	case opRunDefers:
+		instr = &RunDefers{}
+	case opPanic:
+		instr = &Panic{pos: d.pos(x.Pos)}
+	case opGo:
+		v := &Go{pos: d.pos(x.Pos)}
+		d.call(&v.Call, x, n-1)
+		v.Call.pos = d.pos(x.CallPos)
+		instr = v
+	case opDefer:
+		v := &Defer{pos: d.pos(x.Pos)}
+		d.call(&v.Call, x, n-2)
+		v.Call.pos = d.pos(x.CallPos)
+		instr = v
+	case opSend:
+		instr = &Send{pos: d.pos(x.Pos)}
+	case opStore:
+		instr = &Store{pos: d.pos(x.Pos)}
+	case opMapUpdate:
+		instr = &MapUpdate{pos: d.pos(x.Pos)}
+	default:
+		d.errorf("invalid opcode %d", x.Op)
+	}
+	if v, ok := instr.(interface {
+		setType(types.Type)
+		setPos(token.Pos)
+	}); ok {
+		v.setType(d.typ(x.Type))
+		v.setPos(d.pos(x.Pos))
+	}
+	return instr
+}
+
+// call populates the non-operand parts of a CallCommon with nargs arguments.
+func (d *decoder) call(call *CallCommon, x *encInstr, nargs int) {
+	call.Args = make([]Value, max(nargs, 0))
+	if x.Method > 0 {
+		call.Method = d.method(x.Method)
+	}
+}
+
+// value returns the non-local value with the specified index.
+func (d *decoder) value(i int) Value {
+	if i >= len(d.values) {
+		d.errorf("invalid value index %d", i)
+	}
+	if v := d.values[i]; v != nil {
+		return v
+	}
+	x := &d.in.Values[i]
+	var v Value
+	switch x.Kind {
+	case valueFunc:
+		v = d.funcs[x.Func]
+	case valueGlobal:
+		p := d.ensurePackage(d.lookupPackage(x.Pkg))
+		g, ok := p.Members[x.Name].(*Global)
+		if !ok {
+			d.errorf("package %s has no variable %s", x.Pkg, x.Name)
+		}
+		v = g
+	case valueConst:
+		v = NewConst(d.constant(x.Const), d.typ(x.Type))
+	case valueBuiltin:
+		if x.Name == vDeferStack.name {
+			v = vDeferStack
+			break
+		}
+		sig, ok := d.typ(x.Type).(*types.Signature)
+		if !ok {
+			d.errorf("builtin %s has non-signature type", x.Name)
+		}
+		v = &Builtin{name: x.Name, sig: sig}
+	default:
+		d.errorf("invalid value kind %d", x.Kind)
+	}
+	d.values[i] = v
+	return v
+}
+
+// constant returns the constant.Value for an encoding.
+func (d *decoder) constant(x *encConst) constant.Value {
+	if x == nil {
+		return nil
+	}
+	switch constant.Kind(x.Kind) {
+	case constant.Bool:
+		return constant.MakeBool(x.Bool)
+	case constant.String:
+		return constant.MakeString(x.String)
+	case constant.Int, constant.Float:
+		var v constant.Value
+		switch {
+		case x.Int != nil:
+			v = constant.Make(x.Int)
+		case x.Rat != nil:
+			v = constant.Make(x.Rat)
+		case x.Float != nil:
+			v = constant.Make(x.Float)
+		default:
+			v = constant.MakeInt64(x.Int64)
+		}
+		if constant.Kind(x.Kind) == constant.Float {
+			v = constant.ToFloat(v)
+		}
+		return v
+	case constant.Complex:
+		return constant.BinaryOp(d.constant(x.Real), token.ADD, constant.MakeImag(d.constant(x.Imag)))
+	}
+	d.errorf("invalid constant kind %d", x.Kind)
+	return nil
+}
+
+// method returns the method with the specified reference.
+func (d *decoder) method(ref int) *types.Func {
+	if ref <= 0 || ref > len(d.in.Methods) {
+		d.errorf("invalid method reference %d", ref)
+	}
+	x := &d.in.Methods[ref-1]
+	var m *types.Func
+	if x.Type != "" {
+		named := d.namedType(x.TypePkg, x.Type)
+		if iface, ok := named.Underlying().(*types.Interface); ok {
+			for im := range iface.Methods() {
+				if im.Name() == x.Name && pkgPath(im.Pkg()) == x.Pkg {
+					m = im
+					break
+				}
+			}
+		} else {
+			m = d.namedMethod(named, x.Name)
+		}
+	} else {
+		obj, _, _ := types.LookupFieldOrMethod(d.typ(x.Recv), true, d.lookupPackage(x.Pkg), x.Name)
+		m, _ = obj.(*types.Func)
+	}
+	if m == nil {
+		d.errorf("cannot find method %s", x.Name)
+	}
+	if len(x.RTArgs) > 0 {
+		m = d.prog.canon.instantiateMethod(m, d.typeList(x.RTArgs), d.prog.ctxt)
+	}
+	return m
+}
+
+// namedType returns the package-level named type pkg.name.
+func (d *decoder) namedType(pkg, name string) *types.Named {
+	obj, _ := d.lookupPackage(pkg).Scope().Lookup(name).(*types.TypeName)
+	if obj != nil {
+		if named, ok := obj.Type().(*types.Named); ok {
+			return named
+		}
+	}
+	d.errorf("%s.%s is not a named type", pkg, name)
+	return nil
+}
+
+// namedMethod returns the declared method of named with the given name.
+func (d *decoder) namedMethod(named *types.Named, name string) *types.Func {
+	for m := range named.Methods() {
+		if m.Name() == name {
+			return m
+		}
+	}
+	d.errorf("cannot find method %s of %s", name, named)
+	return nil
+}
+
+// pos returns the position for an encoding.
+func (d *decoder) pos(x int64) token.Pos {
+	if x == 0 {
+		return token.NoPos
+	}
+	i := int(x>>32) - 1
+	if i < 0 || i >= len(d.files) {
+		d.errorf("invalid file index %d", i)
+	}
+	tf := d.files[i]
+	offset := int(x & (1<<32 - 1))
+	if offset > tf.Size() {
+		d.errorf("invalid offset %d in file %s", offset, tf.Name())
+	}
+	return tf.Pos(offset)
+}
+
+// lookupPackage returns the package with the specified path, which
+// must be among the imports, or nil if path is empty.
+func (d *decoder) lookupPackage(path string) *types.Package {
+	switch path {
+	case "":
+		return nil
+	case "unsafe":
+		return types.Unsafe
+	}
+	pkg := d.imports[path]
+	if pkg == nil {
+		d.errorf("no type information for package %s", path)
+	}
+	return pkg
+}
+
+// ensurePackage returns the SSA package for pkg, creating it from
+// its types if necessary.
+func (d *decoder) ensurePackage(pkg *types.Package) *Package {
+	if p := d.prog.packages[pkg]; p != nil {
+		return p
+	}
+	return d.prog.CreatePackage(pkg, nil, nil, true)
+}
+
+func (d *decoder) typeList(refs []int) []types.Type {
+	var ts []types.Type
+	for _, ref := range refs {
+		ts = append(ts, d.typ(ref))
+	}
+	return ts
+}
+
+// typ returns the type with the specified reference.
+func (d *decoder) typ(ref int) types.Type {
+	if ref == 0 {
+		return nil
+	}
+	if ref < 0 || ref > len(d.types) {
+		d.errorf("invalid type reference %d", ref)
+	}
+	if t := d.types[ref-1]; t != nil {
+		return t
+	}
+	x := &d.in.Types[ref-1]
+	var t types.Type
+	switch x.Kind {
+	case typeBasic:
+		if x.Basic < 0 || x.Basic >= len(types.Typ) {
+			d.errorf("invalid basic type %d", x.Basic)
+		}
+		t = types.Typ[x.Basic]
+		if x.Name != t.(*types.Basic).Name() {
+			t = types.Universe.Lookup(x.Name).Type() // byte or rune
+		}
+	case typePointer:
+		t = types.NewPointer(d.typ(x.Elem))
+	case typeSlice:
+		t = types.NewSlice(d.typ(x.Elem))
+	case typeArray:
+		t = types.NewArray(d.typ(x.Elem), x.Len)
+	case typeMap:
+		t = types.NewMap(d.typ(x.Key), d.typ(x.Elem))
+	case typeChan:
+		t = types.NewChan(types.ChanDir(x.Basic), d.typ(x.Elem))
+	case typeSignature:
+		t = types.NewSignatureType(nil, nil, nil, d.tuple(x.Vars), d.tuple(x.Results), x.Variadic)
+	case typeTuple:
+		t = d.tuple(x.Vars)
+	case typeStruct:
+		var fields []*types.Var
+		var tags []string
+		for _, v := range x.Vars {
+			fields = append(fields, types.NewField(token.NoPos, d.lookupPackage(v.Pkg), v.Name, d.typ(v.Type), v.Embedded))
+			tags = append(tags, v.Tag)
+		}
+		t = types.NewStruct(fields, tags)
+	case typeInterface:
+		var methods []*types.Func
+		for _, v := range x.Vars {
+			sig, ok := d.typ(v.Type).(*types.Signature)
+			if !ok {
+				d.errorf("interface method %s has non-signature type", v.Name)
+			}
+			methods = append(methods, types.NewFunc(token.NoPos, d.lookupPackage(v.Pkg), v.Name, sig))
+		}
+		iface := types.NewInterfaceType(methods, d.typeList(x.Types))
+		if x.Implicit {
+			iface.MarkImplicit()
+		}
+		t = iface.Complete()
+	case typeUnion:
+		var terms []*types.Term
+		for i, ref := range x.Types {
+			terms = append(terms, types.NewTerm(x.Tildes[i], d.typ(ref)))
+		}
+		t = types.NewUnion(terms)
+	case typeObject:
+		scope := types.Universe
+		if x.Pkg != "" {
+			scope = d.lookupPackage(x.Pkg).Scope()
+		}
+		obj, ok := scope.Lookup(x.Name).(*types.TypeName)
+		if !ok {
+			d.errorf("package %s has no type %s", x.Pkg, x.Name)
+		}
+		t = obj.Type()
+	case typeInstance:
+		inst, err := types.Instantiate(d.prog.ctxt, d.typ(x.Elem), d.typeList(x.Types), false)
+		if err != nil {
+			d.errorf("%v", err)
+		}
+		t = inst
+	case typeLocal:
+		obj := types.NewTypeName(d.pos(x.Pos), d.lookupPackage(x.Pkg), x.Name, nil)
+		named := types.NewNamed(obj, nil, nil)
+		d.types[ref-1] = named // before decoding the (possibly recursive) underlying type
+		named.SetUnderlying(d.typ(x.Elem).Underlying())
+		t = named
+	case typeLocalAlias:
+		obj := types.NewTypeName(d.pos(x.Pos), d.lookupPackage(x.Pkg), x.Name, nil)
+		t = types.NewAlias(obj, d.typ(x.Elem))
+	case typeParam:
+		var list *types.TypeParamList
+		if x.Method != "" {
+			sig := d.namedMethod(d.namedType(x.Pkg, x.Name), x.Method).Signature()
+			if x.Recv {
+				list = sig.RecvTypeParams()
+			} else {
+				list = sig.TypeParams()
+			}
+		} else {
+			switch obj := d.lookupPackage(x.Pkg).Scope().Lookup(x.Name).(type) {
+			case *types.Func:
+				list = obj.Signature().TypeParams()
+			case *types.TypeName:
+				if t, ok := obj.Type().(interface{ TypeParams() *types.TypeParamList }); ok {
+					list = t.TypeParams()
+				}
+			}
+		}
+		if x.Index >= list.Len() {
+			d.errorf("cannot find type parameter %d of %s.%s", x.Index, x.Pkg, x.Name)
+		}
+		t = list.At(x.Index)
+	case typeRangeIter:
+		t = tRangeIter
+	case typeDeferStack:
+		t = tDeferStack
+	default:
+		d.errorf("invalid type kind %d", x.Kind)
+	}
+	d.types[ref-1] = t
+	return t
+}
+
+func (d *decoder) tuple(vars []encVar) *types.Tuple {
+	if len(vars) == 0 {
+		return nil
+	}
+	var vs []*types.Var
+	for _, v := range vars {
+		vs = append(vs, types.NewParam(token.NoPos, d.lookupPackage(v.Pkg), v.Name, d.typ(v.Type)))
+	}
+	return types.NewTuple(vs...)
+}
@@ -0,0 +1,339 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ssa_test
+
+import (
+	"bytes"
+	"go/token"
+	"go/types"
+	"slices"
+	"sort"
+	"strings"
+	"testing"
+
+	"golang.org/x/tools/go/packages"
+	"golang.org/x/tools/go/ssa"
+	"golang.org/x/tools/go/ssa/ssautil"
+	"golang.org/x/tools/txtar"
+)
+
+const encodeSrc = `
+-- go.mod --
+module example.com
+
+go 1.23
+-- lib/lib.go --
+package lib
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+type Number interface{ ~int | ~int64 | ~float64 }
+
+func Sum[T Number](xs ...T) (s T) {
+	for _, x := range xs {
+		s += x
+	}
+	return
+}
+
+type List[T any] struct {
+	elems []T
+}
+
+func (l *List[T]) Push(x T) { l.elems = append(l.elems, x) }
+
+func (l *List[T]) All() func(yield func(int, T) bool) {
+	return func(yield func(int, T) bool) {
+		for i, x := range l.elems {
+			if !yield(i, x) {
+				return
+			}
+		}
+	}
+}
+
+type base struct{ name string }
+
+func (b base) Name() string   { return b.name }
+func (b *base) Rename(s string) { b.name = s }
+
+type Thing struct {
+	*base
+	Count int
+}
+
+var ErrEmpty = errors.New("empty")
+
+var registry = map[string]func() fmt.Stringer{}
+
+func init() { registry["x"] = nil }
+
+func init() { helper() }
+
+func helper() {}
+
+func Describe(v any) (string, error) {
+	type local struct {
+		next *local
+		s    string
+	}
+	switch v := v.(type) {
+	case nil:
+		return "", ErrEmpty
+	case fmt.Stringer:
+		return v.String(), nil
+	case int, int64:
+		return fmt.Sprint(v), nil
+	case local:
+		return v.s, nil
+	}
+	l := &local{s: strings.ToUpper(fmt.Sprint(v))}
+	return l.s, nil
+}
+
+const (
+	Big   = 1 << 100
+	Third = 1.0 / 3
+	Imag  = 2 + 3i
+	Name  = "lib"
+)
+
+func Consts() (float64, complex128, string, bool, uint8) {
+	return Third, Imag, Name, Big > 0, 'x'
+}
+-- main/main.go --
+package main
+
+import (
+	"fmt"
+	"os"
+	"sort"
+
+	"example.com/lib"
+)
+
+type byLen []string
+
+func (s byLen) Len() int           { return len(s) }
+func (s byLen) Less(i, j int) bool { return len(s[i]) < len(s[j]) }
+func (s byLen) Swap(i, j int)      { s[i], s[j] = s[j], s[i] }
+
+func main() {
+	defer func() {
+		if r := recover(); r != nil {
+			fmt.Fprintln(os.Stderr, r)
+		}
+	}()
+
+	var l lib.List[string]
+	l.Push("hello")
+	l.Push("hi")
+	for i, s := range l.All() {
+		fmt.Println(i, s)
+	}
+	fmt.Println(lib.Sum(1, 2, 3), lib.Sum(1.5, 2.5))
+
+	words := byLen{"ccc", "a", "bb"}
+	sort.Sort(words)
+	less := words.Less
+	swap := byLen.Swap
+	swap(words, 0, 1)
+	fmt.Println(less(0, 1))
+
+	t := lib.Thing{Count: 1}
+	var named interface{ Name() string } = t
+	fmt.Println(named.Name())
+
+	ch := make(chan int, 1)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		ch <- 1
+	}()
+	select {
+	case x, ok := <-ch:
+		fmt.Println(x, ok)
+	case <-done:
+	}
+	m := map[string]int{"a": 1}
+	for k, v := range m {
+		m[k] = v + 1
+	}
+	arr := [...]int{1, 2, 3}
+	p := (*[2]int)(arr[:2])
+	fmt.Println(p[0], len("héllo"[1:]), lib.Consts)
+	if s, err := lib.Describe(nil); err != nil {
+		panic(s)
+	}
+}
+`
+
+func TestEncode(t *testing.T) {
+	ar := txtar.Parse([]byte(encodeSrc))
+	fs, err := txtar.FS(ar)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pkgs := loadPackages(t, fs, "./main")
+
+	for _, mode := range []ssa.BuilderMode{0, ssa.InstantiateGenerics} {
+		prog, _ := ssautil.AllPackages(pkgs, mode)
+
+		// Build and encode all packages in dependency order.
+		// (Building them concurrently would make the names of
+		// instances shared by several packages, which mention the
+		// aliases of the package that first created them, vary.)
+		var order []*packages.Package
+		packages.Visit(pkgs, nil, func(p *packages.Package) {
+			if p.PkgPath != "unsafe" {
+				order = append(order, p)
+			}
+		})
+		for _, p := range order {
+			prog.Package(p.Types).Build()
+		}
+		encoded := make([][]byte, len(order))
+		for i, p := range order {
+			var buf bytes.Buffer
+			if err := prog.Package(p.Types).Encode(&buf); err != nil {
+				t.Fatal(err)
+			}
+			encoded[i] = buf.Bytes()
+		}
+
+		// Decode them into a new program.
+		prog2 := ssa.NewProgram(token.NewFileSet(), mode|ssa.SanityCheckFunctions)
+		imports := make(map[string]*types.Package)
+		for i, p := range order {
+			p2, err := prog2.DecodePackage(bytes.NewReader(encoded[i]), imports)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got, want := dumpPackage(p2), dumpPackage(prog.Package(p.Types)); got != want {
+				t.Errorf("mode %v: package %s: decoded functions differ:\n--- got ---\n%s\n--- want ---\n%s", mode, p.PkgPath, got, want)
+			}
+		}
+	}
+}
+
+// TestEncodeForeignAlias tests the encoding of a reference to an
+// instance whose canonical type arguments were created by a package
+// that is not a dependency of the encoded one, using an alias.
+func TestEncodeForeignAlias(t *testing.T) {
+	const src = `
+-- go.mod --
+module example.com
+
+go 1.23
+-- g/g.go --
+package g
+
+func G[T any](x T) {}
+-- a/a.go --
+package a
+
+import "example.com/g"
+
+type T struct{}
+
+func A() { g.G([]T(nil)) }
+-- b/b.go --
+package b
+
+import (
+	"example.com/a"
+	"example.com/g"
+)
+
+type U = a.T
+
+func B() { g.G([]U(nil)) }
+`
+	fs, err := txtar.FS(txtar.Parse([]byte(src)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	pkgs := loadPackages(t, fs, "./b")
+
+	for _, mode := range []ssa.BuilderMode{0, ssa.InstantiateGenerics} {
+		prog, _ := ssautil.AllPackages(pkgs, mode)
+
+		// Build b first, so that the canonical instance of G
+		// called by a is g.G[[]b.U].
+		var order []*packages.Package
+		packages.Visit(pkgs, nil, func(p *packages.Package) {
+			order = append(order, p)
+		})
+		for _, p := range slices.Backward(order) {
+			prog.Package(p.Types).Build()
+		}
+
+		prog2 := ssa.NewProgram(token.NewFileSet(), mode|ssa.SanityCheckFunctions)
+		imports := make(map[string]*types.Package)
+		for _, p := range order {
+			var buf bytes.Buffer
+			if err := prog.Package(p.Types).Encode(&buf); err != nil {
+				t.Fatal(err)
+			}
+			p2, err := prog2.DecodePackage(&buf, imports)
+			if err != nil {
+				t.Fatalf("mode %v: %v", mode, err)
+			}
+			if p.PkgPath == "example.com/a" {
+				call := p2.Func("A").Blocks[0].Instrs[0].(*ssa.Call)
+				callee := call.Call.StaticCallee()
+				if got, want := callee.TypeArgs()[0].String(), "[]example.com/a.T"; got != want {
+					t.Errorf("mode %v: type argument of %s is %s, want %s", mode, callee, got, want)
+				}
+			}
+		}
+	}
+}
+
+// dumpPackage returns the printed form of the package-level functions
+// and methods of p, and their anonymous functions.
+func dumpPackage(p *ssa.Package) string {
+	var fns []*ssa.Function
+	for _, mem := range p.Members {
+		switch mem := mem.(type) {
+		case *ssa.Function:
+			fns = append(fns, mem)
+		case *ssa.Type:
+			if named, ok := mem.Type().(*types.Named); ok {
+				for i := range named.NumMethods() {
+					if fn := p.Prog.FuncValue(named.Method(i)); fn != nil {
+						fns = append(fns, fn)
+					}
+				}
+			}
+		}
+	}
+	sort.Slice(fns, func(i, j int) bool { return fns[i].String() < fns[j].String() })
+
+	// Export data does not record the columns of declarations, so
+	// omit the location of each function.
+	var buf bytes.Buffer
+	var visit func(fn *ssa.Function)
+	visit = func(fn *ssa.Function) {
+		var fbuf bytes.Buffer
+		fn.WriteTo(&fbuf)
+		for line := range strings.Lines(fbuf.String()) {
+			if !strings.HasPrefix(line, "# Location:") {
+				buf.WriteString(line)
+			}
+		}
+		for _, anon := range fn.AnonFuncs {
+			visit(anon)
+		}
+	}
+	for _, fn := range fns {
+		visit(fn)
+	}
+	return buf.String()
+}
@@ -0,0 +1,421 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package interp
+
+// This file defines the interactive debugger and the execution trace.
+//
+// Both observe the program one SSA instruction at a time, just before
+// the instruction executes. The debugger stops at breakpoints and
+// after stepping commands, and reads commands until told to resume.
+// Since only one goroutine runs at a time, the debugger needs no
+// synchronization: while it reads commands, the program is stopped.
+
+import (
+	"bufio"
+	"fmt"
+	"go/token"
+	"go/types"
+	"io"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"golang.org/x/tools/go/ssa"
+	"golang.org/x/tools/go/ssa/ssautil"
+)
+
+// traceInstr writes a line describing instr, which is about to be
+// executed in frame fr, to the trace.
+func (i *interpreter) traceInstr(fr *frame, instr ssa.Instruction) {
+	fmt.Fprintf(i.trace, "%s\tg%d\t%s\t%s\n",
+		posString(i.prog.Fset, instr.Pos()), i.sched.cur.id, fr.fn, instrString(instr))
+}
+
+// instrString returns the string form of instr, including the name of
+// the value it defines, if any.
+func instrString(instr ssa.Instruction) string {
+	if v, ok := instr.(ssa.Value); ok {
+		return v.Name() + " = " + instr.String()
+	}
+	return instr.String()
+}
+
+func posString(fset *token.FileSet, pos token.Pos) string {
+	if !pos.IsValid() {
+		return "-"
+	}
+	return fset.Position(pos).String()
+}
+
+// A debugger is the state of the interactive debugger.
+type debugger struct {
+	in     *bufio.Scanner
+	out    io.Writer
+	breaks []*breakpoint
+	nextID int // ID of the next breakpoint
+
+	// The condition under which the program next stops,
+	// in addition to breakpoints.
+	stop  stopMode
+	g     *goroutine // the goroutine that was stepped
+	depth int        // the depth of the frame that was stepped
+}
+
+type stopMode int
+
+const (
+	stopNever  stopMode = iota // only at breakpoints (continue)
+	stopStep                   // at the next instruction of g (step)
+	stopNext                   // at the next instruction of g at or above depth (next)
+	stopFinish                 // at the next instruction of g above depth (finish)
+)
+
+// A breakpoint stops the program on entry to a function, or on
+// arrival at a source line.
+type breakpoint struct {
+	id   int
+	spec string // as specified by the user
+
+	fn   string // the function, or
+	file string // the base name of the file,
+	line int    // and the line
+}
+
+const debugHelp = `Commands:
+  break FUNC | FILE:LINE  set a breakpoint on entry to FUNC, or at FILE:LINE (b)
+  delete N                delete breakpoint N (d)
+  breakpoints             list breakpoints
+  continue                continue until a breakpoint (c)
+  step                    execute one instruction, stepping into calls (s)
+  next                    execute one instruction, stepping over calls (n)
+  finish                  continue until the current function returns
+  print NAME | *NAME      print a register, parameter, local or global variable (p)
+  regs                    print the registers of the current function
+  list                    list the instructions of the current block (l)
+  where                   print the call stack of the current goroutine (bt)
+  goroutines              list goroutines (gs)
+  quit                    end the program (q)
+`
+
+func newDebugger(in io.Reader, out io.Writer) *debugger {
+	// Stop before the first instruction.
+	return &debugger{in: bufio.NewScanner(in), out: out, stop: stopStep}
+}
+
+// depth returns the number of frames of fr's goroutine.
+func depth(fr *frame) int {
+	n := 0
+	for ; fr != nil; fr = fr.caller {
+		n++
+	}
+	return n
+}
+
+// before is called just before instr executes in frame fr,
+// and stops the program if necessary.
+func (d *debugger) before(fr *frame, instr ssa.Instruction) {
+	g := fr.i.sched.cur
+	pos := fr.i.prog.Fset.Position(instr.Pos())
+
+	// A line breakpoint applies only on arrival at a line.
+	arrived := pos.IsValid() && pos.Line != fr.line
+	if pos.IsValid() {
+		fr.line = pos.Line
+	}
+
+	var hit *breakpoint
+	for _, b := range d.breaks {
+		if b.fn != "" {
+			if fr.prevBlock == nil && instr == fr.fn.Blocks[0].Instrs[0] && matchFunc(fr.fn, b.fn) {
+				hit = b
+				break
+			}
+		} else if arrived && pos.Line == b.line && filepath.Base(pos.Filename) == b.file {
+			hit = b
+			break
+		}
+	}
+
+	switch {
+	case hit != nil:
+		fmt.Fprintf(d.out, "Breakpoint %d, ", hit.id)
+	case d.stop == stopNever:
+		return
+	case d.g != nil && g != d.g:
+		return // stepping applies only to the stepped goroutine
+	case d.stop == stopNext && depth(fr) > d.depth:
+		return
+	case d.stop == stopFinish && depth(fr) >= d.depth:
+		return
+	}
+	fmt.Fprintf(d.out, "goroutine %d, %s at %s\n\t%s\n", g.id, fr.fn, posString(fr.i.prog.Fset, instr.Pos()), instrString(instr))
+	d.prompt(fr, instr)
+}
+
+// matchFunc reports whether fn is the function named by spec, which
+// may be qualified by its package path or name, or unqualified.
+func matchFunc(fn *ssa.Function, spec string) bool {
+	if fn.String() == spec {
+		return true
+	}
+	if fn.Pkg == nil {
+		return false
+	}
+	rel := fn.RelString(fn.Pkg.Pkg)
+	return spec == rel || spec == fn.Pkg.Pkg.Name()+"."+rel
+}
+
+// prompt reads and executes commands until one resumes the program.
+func (d *debugger) prompt(fr *frame, instr ssa.Instruction) {
+	g := fr.i.sched.cur
+	for {
+		fmt.Fprint(d.out, "(ssadebug) ")
+		if !d.in.Scan() {
+			// End of input: let the program run to completion.
+			fmt.Fprintln(d.out, "\nend of input; continuing without breakpoints")
+			d.breaks = nil
+			d.stop, d.g = stopNever, nil
+			return
+		}
+		fields := strings.Fields(d.in.Text())
+		if len(fields) == 0 {
+			continue
+		}
+		cmd, args := fields[0], fields[1:]
+		switch cmd {
+		case "c", "continue":
+			d.stop, d.g = stopNever, nil
+			return
+
+		case "s", "step":
+			d.stop, d.g = stopStep, g
+			return
+
+		case "n", "next":
+			d.stop, d.g, d.depth = stopNext, g, depth(fr)
+			return
+
+		case "finish":
+			d.stop, d.g, d.depth = stopFinish, g, depth(fr)
+			return
+
+		case "b", "break":
+			if len(args) != 1 {
+				fmt.Fprintln(d.out, "usage: break FUNC | FILE:LINE")
+				continue
+			}
+			d.addBreak(fr.i.prog, args[0])
+
+		case "d", "delete":
+			d.deleteBreak(args)
+
+		case "breakpoints":
+			if len(d.breaks) == 0 {
+				fmt.Fprintln(d.out, "no breakpoints")
+			}
+			for _, b := range d.breaks {
+				fmt.Fprintf(d.out, "%d\t%s\n", b.id, b.spec)
+			}
+
+		case "p", "print":
+			if len(args) != 1 {
+				fmt.Fprintln(d.out, "usage: print NAME | *NAME")
+				continue
+			}
+			d.print(fr, args[0])
+
+		case "regs":
+			d.regs(fr)
+
+		case "l", "list":
+			for _, in := range fr.block.Instrs {
+				marker := "  "
+				if in == instr {
+					marker = "=>"
+				}
+				fmt.Fprintf(d.out, "%s %s\n", marker, instrString(in))
+			}
+
+		case "bt", "where":
+			pos := instr.Pos()
+			for n, f := 0, fr; f != nil; n, f = n+1, f.caller {
+				fmt.Fprintf(d.out, "#%d %s at %s\n", n, f.fn, posString(f.i.prog.Fset, pos))
+				pos = f.callpos
+			}
+
+		case "gs", "goroutines":
+			d.goroutines(fr)
+
+		case "q", "quit":
+			fr.i.sched.terminate(exitPanic(1))
+
+		case "h", "help":
+			fmt.Fprint(d.out, debugHelp)
+
+		default:
+			fmt.Fprintf(d.out, "unknown command %q; try help\n", cmd)
+		}
+	}
+}
+
+// addBreak adds a breakpoint for spec, a function or FILE:LINE.
+func (d *debugger) addBreak(prog *ssa.Program, spec string) {
+	b := &breakpoint{spec: spec}
+	if file, line, ok := strings.Cut(spec, ":"); ok {
+		n, err := strconv.Atoi(line)
+		if err != nil || n <= 0 {
+			fmt.Fprintf(d.out, "invalid line number in %q\n", spec)
+			return
+		}
+		b.file, b.line = filepath.Base(file), n
+	} else {
+		found := false
+		for fn := range ssautil.AllFunctions(prog) {
+			if matchFunc(fn, spec) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			fmt.Fprintf(d.out, "no function %q\n", spec)
+			return
+		}
+		b.fn = spec
+	}
+	d.nextID++
+	b.id = d.nextID
+	d.breaks = append(d.breaks, b)
+	fmt.Fprintf(d.out, "Breakpoint %d at %s\n", b.id, spec)
+}
+
+func (d *debugger) deleteBreak(args []string) {
+	if len(args) != 1 {
+		fmt.Fprintln(d.out, "usage: delete N")
+		return
+	}
+	id, _ := strconv.Atoi(args[0])
+	for i, b := range d.breaks {
+		if b.id == id {
+			d.breaks = append(d.breaks[:i:i], d.breaks[i+1:]...)
+			return
+		}
+	}
+	fmt.Fprintf(d.out, "no breakpoint %s\n", args[0])
+}
+
+// print prints the value named by expr in frame fr. A leading *
+// prints the variable to which the value points.
+func (d *debugger) print(fr *frame, expr string) {
+	name, deref := strings.CutPrefix(expr, "*")
+	t, v, err := lookupName(fr, name)
+	if err != nil {
+		fmt.Fprintln(d.out, err)
+		return
+	}
+	if deref {
+		ptr, ok := t.Underlying().(*types.Pointer)
+		if !ok {
+			fmt.Fprintf(d.out, "%s is not a pointer\n", name)
+			return
+		}
+		if v.(*value) == nil {
+			fmt.Fprintf(d.out, "%s is nil\n", name)
+			return
+		}
+		t, v = ptr.Elem(), *v.(*value)
+	}
+	fmt.Fprintf(d.out, "%s (%s) = %s\n", expr, t, toString(v))
+}
+
+// lookupName returns the type and value of the register, parameter,
+// free variable, address-taken local variable or global variable
+// called name in frame fr.
+func lookupName(fr *frame, name string) (types.Type, value, error) {
+	for _, p := range fr.fn.Params {
+		if p.Name() == name {
+			return p.Type(), fr.env[p], nil
+		}
+	}
+	for _, fv := range fr.fn.FreeVars {
+		if fv.Name() == name {
+			return fv.Type(), fr.env[fv], nil
+		}
+	}
+	for _, b := range fr.fn.Blocks {
+		for _, instr := range b.Instrs {
+			v, ok := instr.(ssa.Value)
+			if !ok {
+				continue
+			}
+			// A local variable is an Alloc whose comment is its name.
+			alloc, isAlloc := v.(*ssa.Alloc)
+			if v.Name() == name || isAlloc && alloc.Comment == name {
+				x, ok := fr.env[v]
+				if !ok {
+					return nil, nil, fmt.Errorf("%s has not been computed", name)
+				}
+				if v.Name() != name {
+					// Print the variable, not its address.
+					return alloc.Type().Underlying().(*types.Pointer).Elem(), *x.(*value), nil
+				}
+				return v.Type(), x, nil
+			}
+		}
+	}
+	if fr.fn.Pkg != nil {
+		if g, ok := fr.fn.Pkg.Members[name].(*ssa.Global); ok {
+			ptr := g.Type().(*types.Pointer)
+			return ptr.Elem(), *fr.i.globals[g], nil
+		}
+	}
+	return nil, nil, fmt.Errorf("no value %q in %s", name, fr.fn)
+}
+
+// regs prints the parameters, free variables and computed registers
+// of frame fr.
+func (d *debugger) regs(fr *frame) {
+	show := func(v ssa.Value) {
+		if x, ok := fr.env[v]; ok {
+			fmt.Fprintf(d.out, "%s (%s) = %s\n", v.Name(), v.Type(), toString(x))
+		}
+	}
+	for _, p := range fr.fn.Params {
+		show(p)
+	}
+	for _, fv := range fr.fn.FreeVars {
+		show(fv)
+	}
+	for _, b := range fr.fn.Blocks {
+		for _, instr := range b.Instrs {
+			if v, ok := instr.(ssa.Value); ok {
+				show(v)
+			}
+		}
+	}
+}
+
+// goroutines prints the goroutines of the program.
+func (d *debugger) goroutines(fr *frame) {
+	s := fr.i.sched
+	for _, g := range s.all {
+		marker := " "
+		var where string
+		switch {
+		case g == s.cur:
+			marker = "*"
+			where = fmt.Sprintf(" %s", fr.fn)
+		case g.status == grunnable:
+			where = " [runnable]"
+		case g.status == gsleeping:
+			where = fmt.Sprintf(" [sleep] %s at %s", g.frame.fn, posString(fr.i.prog.Fset, g.pos))
+		case g.status == gblocked:
+			where = fmt.Sprintf(" [%s] %s", g.reason, g.frame.fn)
+			if g.pos.IsValid() {
+				where += " at " + posString(fr.i.prog.Fset, g.pos)
+			}
+		}
+		fmt.Fprintf(d.out, "%s goroutine %d%s\n", marker, g.id, where)
+	}
+}
@@ -0,0 +1,108 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package interp_test
+
+import (
+	"strings"
+	"testing"
+
+	"golang.org/x/tools/go/ssa/interp"
+)
+
+const debugSrc = `package main
+
+func add(x, y int) int {
+	return x + y
+}
+
+var total int
+
+func main() {
+	ch := make(chan int)
+	go func() { ch <- add(1, 2) }()
+	total = <-ch
+	println(total)
+}
+`
+
+func TestDebugger(t *testing.T) {
+	const script = `
+help
+break main.add
+break main.go:13
+breakpoints
+continue
+where
+print x
+print nosuch
+finish
+goroutines
+continue
+print total
+next
+regs
+delete 2
+`
+	goroutines := `  goroutine 1 [chan receive] main.main at `
+	var out strings.Builder
+	_, exitCode := runSource(t, makeGoroot(t), debugSrc, &interp.Config{
+		DebugIn:  strings.NewReader(script),
+		DebugOut: &out,
+	})
+	if exitCode != 0 {
+		t.Errorf("exit code = %d, want 0", exitCode)
+	}
+	got := out.String()
+	for _, want := range []string{
+		"stepping into calls",                            // help
+		"Breakpoint 1 at main.add\n",                     // break
+		"Breakpoint 2 at main.go:13\n",                   // break
+		"1\tmain.add\n2\tmain.go:13\n",                   // breakpoints
+		"Breakpoint 1, goroutine 2, main.add at ",        // continue
+		"#0 main.add at ",                                // where
+		"main.go:4:",                                     // where
+		"#1 main.main$1 at ",                             // where
+		"main.go:11:23\n",                                // where
+		"x (int) = 1\n",                                  // print
+		`no value "nosuch" in main.add`,                  // print
+		"goroutine 2, main.main$1 at ",                   // finish
+		goroutines,                                       // goroutines
+		"* goroutine 2 main.main$1\n",                    // goroutines
+		"Breakpoint 2, goroutine 1, main.main at ",       // continue
+		"main.go:13:",                                    // continue
+		"total (int) = 3\n",                              // print
+		"goroutine 1, main.main at ",                     // next
+		"(int) = 3\n",                                    // regs
+		"end of input; continuing without breakpoints\n", // EOF
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("debugger output does not contain %q", want)
+		}
+	}
+	if t.Failed() {
+		t.Logf("debugger output:\n%s", got)
+	}
+}
+
+func TestTrace(t *testing.T) {
+	var trace strings.Builder
+	_, exitCode := runSource(t, makeGoroot(t), debugSrc, &interp.Config{Trace: &trace})
+	if exitCode != 0 {
+		t.Errorf("exit code = %d, want 0", exitCode)
+	}
+	got := trace.String()
+	for _, want := range []string{
+		"main.go:10:12\tg1\tmain.main\tt1 = make chan int 0:int\n",
+		"main.go:4:11\tg2\tmain.add\tt0 = x + y\n",
+		"\tg1\tmain.main\treturn\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("trace does not contain %q", want)
+		}
+	}
+	if t.Failed() {
+		t.Logf("trace:\n%s", got)
+	}
+}
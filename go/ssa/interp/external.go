@@ -9,6 +9,7 @@ package interp
 
 import (
 	"bytes"
+	"go/token"
 	"maps"
 	"math"
 	"os"
@@ -17,7 +18,6 @@ import (
 	"sort"
 	"strconv"
 	"strings"
-	"time"
 	"unicode/utf8"
 )
 
@@ -270,9 +270,7 @@ func ext۰runtime۰GOMAXPROCS(fr *frame, args []value) value {
 }
 
 func ext۰runtime۰Goexit(fr *frame, args []value) value {
-	// TODO(adonovan): don't kill the interpreter's main goroutine.
-	runtime.Goexit()
-	return nil
+	panic(goexitPanic{})
 }
 
 func ext۰runtime۰GOROOT(fr *frame, args []value) value {
@@ -285,7 +283,7 @@ func ext۰runtime۰GC(fr *frame, args []value) value {
 }
 
 func ext۰runtime۰Gosched(fr *frame, args []value) value {
-	runtime.Gosched()
+	fr.i.sched.yield()
 	return nil
 }
 
@@ -294,7 +292,7 @@ func ext۰runtime۰NumCPU(fr *frame, args []value) value {
 }
 
 func ext۰time۰Sleep(fr *frame, args []value) value {
-	time.Sleep(time.Duration(args[0].(int64)))
+	fr.i.sched.sleep(fr, args[0].(int64), token.NoPos)
 	return nil
 }
 
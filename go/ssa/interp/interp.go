@@ -23,10 +23,12 @@
 // * The "testing" package is no longer supported because it
 // depends on low-level details that change too often.
 //
-// * "sync/atomic" operations are not atomic due to the "boxed" value
-// representation: it is not possible to read, modify and write an
-// interface value atomically. As a consequence, Mutexes are currently
-// broken.
+// * Only one goroutine runs at a time. Goroutines are switched at
+// channel and synchronization operations, and periodically, so a
+// program's behavior is deterministic for a given scheduling seed
+// (see [Config]). Time is virtual: time.Sleep returns immediately
+// when every other goroutine is blocked. The sync and sync/atomic
+// packages are emulated by the interpreter.
 //
 // * recover is only partially implemented.  Also, the interpreter
 // makes no attempt to distinguish target panics from interpreter
@@ -48,12 +50,11 @@ import (
 	"fmt"
 	"go/token"
 	"go/types"
+	"io"
 	"log"
 	"os"
-	"reflect"
 	"runtime"
 	"slices"
-	"sync/atomic"
 	_ "unsafe"
 
 	"golang.org/x/tools/go/ssa"
@@ -89,7 +90,9 @@ type interpreter struct {
 	rtypeMethods       methodSet              // the method set of rtype, which implements the reflect.Type interface.
 	runtimeErrorString types.Type             // the runtime.errorString type (iff "runtime" is present)
 	sizes              types.Sizes            // the effective type-sizing function
-	goroutines         int32                  // atomically updated
+	sched              *scheduler             // the scheduler of interpreted goroutines
+	debug              *debugger              // the interactive debugger, if enabled
+	trace              io.Writer              // destination of the execution trace, if enabled
 }
 
 type deferred struct {
@@ -102,6 +105,7 @@ type deferred struct {
 type frame struct {
 	i                *interpreter
 	caller           *frame
+	callpos          token.Pos // position of the call from caller
 	fn               *ssa.Function
 	block, prevBlock *ssa.BasicBlock
 	env              map[ssa.Value]value // dynamic values of SSA variables
@@ -111,6 +115,7 @@ type frame struct {
 	panicking        bool
 	panic            any
 	phitemps         []value // temporaries for parallel phi assignment
+	line             int     // source line of the last instruction executed (for the debugger)
 }
 
 func (fr *frame) get(key ssa.Value) value {
@@ -195,6 +200,14 @@ func visitInstr(fr *frame, instr ssa.Instruction) continuation {
 		// no-op
 
 	case *ssa.UnOp:
+		if instr.Op == token.ARROW {
+			v, ok := fr.i.sched.recv(fr, fr.get(instr.X).(*channel), instr.Pos())
+			if instr.CommaOk {
+				v = tuple{v, ok}
+			}
+			fr.env[instr] = v
+			break
+		}
 		fr.env[instr] = unop(instr, fr.get(instr.X))
 
 	case *ssa.BinOp:
@@ -247,7 +260,7 @@ func visitInstr(fr *frame, instr ssa.Instruction) continuation {
 		panic(targetPanic{fr.get(instr.X)})
 
 	case *ssa.Send:
-		fr.get(instr.Chan).(chan value) <- fr.get(instr.X)
+		fr.i.sched.send(fr, fr.get(instr.Chan).(*channel), fr.get(instr.X), instr.Pos())
 
 	case *ssa.Store:
 		store(typeparams.MustDeref(instr.Addr.Type()), fr.get(instr.Addr).(*value), fr.get(instr.Val))
@@ -279,14 +292,14 @@ func visitInstr(fr *frame, instr ssa.Instruction) continuation {
 
 	case *ssa.Go:
 		fn, args := prepareCall(fr, &instr.Call)
-		atomic.AddInt32(&fr.i.goroutines, 1)
-		go func() {
-			call(fr.i, nil, instr.Pos(), fn, args)
-			atomic.AddInt32(&fr.i.goroutines, -1)
-		}()
+		i := fr.i
+		i.sched.spawn(i, func() {
+			call(i, nil, instr.Pos(), fn, args)
+		})
 
 	case *ssa.MakeChan:
-		fr.env[instr] = make(chan value, asInt64(fr.get(instr.Size)))
+		elem := instr.Type().Underlying().(*types.Chan).Elem()
+		fr.env[instr] = makeChannel(elem, asInt64(fr.get(instr.Size)))
 
 	case *ssa.Alloc:
 		var addr *value
@@ -385,40 +398,22 @@ func visitInstr(fr *frame, instr ssa.Instruction) continuation {
 		log.Fatal("unreachable") // phis are processed at block entry
 
 	case *ssa.Select:
-		var cases []reflect.SelectCase
-		if !instr.Blocking {
-			cases = append(cases, reflect.SelectCase{
-				Dir: reflect.SelectDefault,
-			})
-		}
+		var cases []selectCase
 		for _, state := range instr.States {
-			var dir reflect.SelectDir
-			if state.Dir == types.RecvOnly {
-				dir = reflect.SelectRecv
-			} else {
-				dir = reflect.SelectSend
-			}
-			var send reflect.Value
-			if state.Send != nil {
-				send = reflect.ValueOf(fr.get(state.Send))
-			}
-			cases = append(cases, reflect.SelectCase{
-				Dir:  dir,
-				Chan: reflect.ValueOf(fr.get(state.Chan)),
-				Send: send,
+			cases = append(cases, selectCase{
+				send: state.Dir == types.SendOnly,
+				c:    fr.get(state.Chan).(*channel),
+				val:  fr.get(state.Send),
 			})
 		}
-		chosen, recv, recvOk := reflect.Select(cases)
-		if !instr.Blocking {
-			chosen-- // default case should have index -1.
-		}
+		chosen, recv, recvOk := fr.i.sched.selectOp(fr, cases, instr.Blocking, instr.Pos())
 		r := tuple{chosen, recvOk}
 		for i, st := range instr.States {
 			if st.Dir == types.RecvOnly {
 				var v value
-				if i == chosen && recvOk {
+				if i == chosen {
 					// No need to copy since send makes an unaliased copy.
-					v = recv.Interface().(value)
+					v = recv
 				} else {
 					v = zero(st.Chan.Type().Underlying().(*types.Chan).Elem())
 				}
@@ -506,13 +501,18 @@ func callSSA(i *interpreter, caller *frame, callpos token.Pos, fn *ssa.Function,
 		defer fmt.Fprintf(os.Stderr, "Leaving %s%s.\n", fn, suffix)
 	}
 	fr := &frame{
-		i:      i,
-		caller: caller, // for panic/recover
-		fn:     fn,
+		i:       i,
+		caller:  caller, // for panic/recover
+		callpos: callpos,
+		fn:      fn,
 	}
 	if fn.Parent() == nil {
 		name := fn.String()
-		if ext := externals[name]; ext != nil {
+		ext := externals[name]
+		if ext == nil && fn.Origin() != nil {
+			ext = externals[fn.Origin().String()] // method of generic type
+		}
+		if ext != nil {
 			if i.mode&EnableTracing != 0 {
 				fmt.Fprintln(os.Stderr, "\t(external)")
 			}
@@ -574,6 +574,9 @@ func runFrame(fr *frame) {
 		if fr.i.mode&DisableRecover != 0 {
 			return // let interpreter crash
 		}
+		if fr.i.sched.done.Load() {
+			return // the program is ending; don't run deferred calls
+		}
 		fr.panicking = true
 		fr.panic = recover()
 		if fr.i.mode&EnableTracing != 0 {
@@ -587,6 +590,7 @@ func runFrame(fr *frame) {
 		if fr.i.mode&EnableTracing != 0 {
 			fmt.Fprintf(os.Stderr, ".%s:\n", fr.block)
 		}
+		fr.i.sched.tick()
 
 		nonPhis := executePhis(fr)
 		for _, instr := range nonPhis {
@@ -597,6 +601,12 @@ func runFrame(fr *frame) {
 					fmt.Fprintln(os.Stderr, "\t", instr)
 				}
 			}
+			if fr.i.trace != nil {
+				fr.i.traceInstr(fr, instr)
+			}
+			if fr.i.debug != nil {
+				fr.i.debug.before(fr, instr)
+			}
 			if visitInstr(fr, instr) == kReturn {
 				return
 			}
@@ -648,12 +658,12 @@ func doRecover(caller *frame) value {
 	// "defer f() -> g() -> recover()".
 	if caller.i.mode&DisableRecover == 0 &&
 		caller != nil && !caller.panicking &&
-		caller.caller != nil && caller.caller.panicking {
+		caller.caller != nil && caller.caller.panicking &&
+		caller.caller.panic != (goexitPanic{}) {
 		caller.caller.panicking = false
 		p := caller.caller.panic
 		caller.caller.panic = nil
 
-		// TODO(adonovan): support runtime.Goexit.
 		switch p := p.(type) {
 		case targetPanic:
 			// The target program explicitly called panic().
@@ -684,13 +694,48 @@ func doRecover(caller *frame) value {
 // Type parameterized functions must have been built with
 // InstantiateGenerics in the ssa.BuilderMode to be interpreted.
 func Interpret(mainpkg *ssa.Package, mode Mode, sizes types.Sizes, filename string, args []string) (exitCode int) {
+	conf := &Config{Mode: mode, Sizes: sizes}
+	return conf.Run(mainpkg, filename, args)
+}
+
+// Config specifies the options of an interpreter.
+type Config struct {
+	Mode  Mode        // interpreter options
+	Sizes types.Sizes // the effective type-sizing function
+
+	// Seed, if non-zero, selects a pseudo-random scheduling policy
+	// that switches goroutines at every scheduling point, to explore
+	// interleavings that the default first-in first-out policy does
+	// not. A given seed always produces the same interleaving.
+	Seed int64
+
+	// Trace, if non-nil, receives a line for each SSA instruction
+	// executed, giving its source position, goroutine and function.
+	Trace io.Writer
+
+	// DebugIn, if non-nil, enables the interactive debugger, which
+	// stops the program before its first instruction and reads
+	// commands from DebugIn, writing its responses to DebugOut.
+	// The "help" command lists the commands.
+	DebugIn  io.Reader
+	DebugOut io.Writer
+}
+
+// Run interprets the Go program whose main package is mainpkg, as
+// described at [Interpret].
+func (conf *Config) Run(mainpkg *ssa.Package, filename string, args []string) (exitCode int) {
 	i := &interpreter{
-		prog:       mainpkg.Prog,
-		globals:    make(map[*ssa.Global]*value),
-		mode:       mode,
-		sizes:      sizes,
-		goroutines: 1,
+		prog:    mainpkg.Prog,
+		globals: make(map[*ssa.Global]*value),
+		mode:    conf.Mode,
+		sizes:   conf.Sizes,
+		sched:   newScheduler(conf.Seed),
+		trace:   conf.Trace,
+	}
+	if conf.DebugIn != nil {
+		i.debug = newDebugger(conf.DebugIn, conf.DebugOut)
 	}
+	defer i.sched.shutdown()
 	runtimePkg := i.prog.ImportedPackage("runtime")
 	if runtimePkg != nil {
 		i.runtimeErrorString = runtimePkg.Type("errorString").Object().Type()
@@ -724,6 +769,10 @@ func Interpret(mainpkg *ssa.Package, mode Mode, sizes types.Sizes, filename stri
 		case exitPanic:
 			exitCode = int(p)
 			return
+		case goexitPanic:
+			fmt.Fprintln(os.Stderr, "fatal error: no goroutines (main called runtime.Goexit) - deadlock!")
+		case deadlockError, fatalError:
+			fmt.Fprintln(os.Stderr, "fatal error:", p.(error).Error())
 		case targetPanic:
 			fmt.Fprintln(os.Stderr, "panic:", toString(p.v))
 		case runtime.Error:
@@ -127,6 +127,7 @@ var testdataTests = []string{
 	"issue79414a.go",
 	"issue79414b.go",
 	"forvarlifetime_old.go",
+	"goroutines.go",
 	"ifaceconv.go",
 	"ifaceprom.go",
 	"initorder.go",
@@ -158,6 +159,20 @@ func init() {
 
 // run runs a single test. On success it returns the captured std{out,err}.
 func run(t *testing.T, input string, goroot string) string {
+	capturedOutput, exitCode := runConfig(t, input, goroot, &interp.Config{})
+	if exitCode != 0 {
+		t.Fatalf("interpreting %s: exit code was %d", input, exitCode)
+	}
+	// $GOROOT/test tests use this convention:
+	if strings.Contains(capturedOutput, "BUG") {
+		t.Fatalf("interpreting %s: exited zero but output contained 'BUG'", input)
+	}
+	return capturedOutput
+}
+
+// runConfig interprets a single program using the specified
+// configuration, and returns its captured std{out,err} and exit code.
+func runConfig(t *testing.T, input string, goroot string, iconf *interp.Config) (string, int) {
 	testenv.NeedsExec(t) // really we just need os.Pipe, but os/exec uses pipes
 
 	t.Logf("Input: %s\n", input)
@@ -266,22 +281,15 @@ func run(t *testing.T, input string, goroot string) string {
 		}
 	}
 
-	var imode interp.Mode // default mode
-	// imode |= interp.DisableRecover // enable for debugging
-	// imode |= interp.EnableTracing // enable for debugging
-	exitCode := interp.Interpret(mainPkg, imode, sizes, input, []string{})
+	iconf.Sizes = sizes
+	// iconf.Mode |= interp.DisableRecover // enable for debugging
+	// iconf.Mode |= interp.EnableTracing // enable for debugging
+	exitCode := iconf.Run(mainPkg, input, []string{})
 	capturedOutput := restore()
-	if exitCode != 0 {
-		t.Fatalf("interpreting %s: exit code was %d", input, exitCode)
-	}
-	// $GOROOT/test tests use this convention:
-	if strings.Contains(capturedOutput, "BUG") {
-		t.Fatalf("interpreting %s: exited zero but output contained 'BUG'", input)
-	}
 
 	hint = "" // call off the hounds
 
-	return capturedOutput
+	return capturedOutput, exitCode
 }
 
 // makeGoroot copies testdata/src into the "src" directory of a temporary
@@ -31,6 +31,10 @@ func (p targetPanic) String() string {
 // If the target program calls exit, the interpreter panics with this type.
 type exitPanic int
 
+// If the target program calls runtime.Goexit, the interpreter panics
+// with this type. Deferred calls run, but cannot recover it.
+type goexitPanic struct{}
+
 // constValue returns the value of the constant with the
 // dynamic type tag appropriate for c.Type().
 func constValue(c *ssa.Const) value {
@@ -256,7 +260,7 @@ func zero(t types.Type) value {
 		}
 		return s
 	case *types.Chan:
-		return chan value(nil)
+		return (*channel)(nil)
 	case *types.Map:
 		if usesBuiltinMap(t.Key()) {
 			return map[value]value(nil)
@@ -840,15 +844,6 @@ func eqnil(t types.Type, x, y value) bool {
 
 func unop(instr *ssa.UnOp, x value) value {
 	switch instr.Op {
-	case token.ARROW: // receive
-		v, ok := <-x.(chan value)
-		if !ok {
-			v = zero(instr.X.Type().Underlying().(*types.Chan).Elem())
-		}
-		if instr.CommaOk {
-			v = tuple{v, ok}
-		}
-		return v
 	case token.SUB:
 		switch x := x.(type) {
 		case int:
@@ -980,7 +975,7 @@ func callBuiltin(caller *frame, fn *ssa.Builtin, args []value) value {
 		return copy(args[0].([]value), src.([]value))
 
 	case "close": // close(chan T)
-		close(args[0].(chan value))
+		caller.i.sched.close(args[0].(*channel))
 		return nil
 
 	case "delete": // delete(map[K]value, K)
@@ -1023,8 +1018,8 @@ func callBuiltin(caller *frame, fn *ssa.Builtin, args []value) value {
 			return len(x)
 		case *hashmap:
 			return x.len()
-		case chan value:
-			return len(x)
+		case *channel:
+			return x.len()
 		default:
 			panic(fmt.Sprintf("len: illegal operand: %T", x))
 		}
@@ -1037,8 +1032,8 @@ func callBuiltin(caller *frame, fn *ssa.Builtin, args []value) value {
 			return cap((*x).(array))
 		case []value:
 			return cap(x)
-		case chan value:
-			return cap(x)
+		case *channel:
+			return x.capacity()
 		default:
 			panic(fmt.Sprintf("cap: illegal operand: %T", x))
 		}
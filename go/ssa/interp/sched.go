@@ -0,0 +1,552 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package interp
+
+// This file defines the scheduler of interpreted goroutines, and the
+// channel operations, which are its principal clients.
+//
+// Each interpreted goroutine runs on its own goroutine of the
+// interpreter, but only one of them runs at a time: the others are
+// parked, waiting on their wake channels. The running goroutine
+// passes control to another only at well-defined points (channel and
+// synchronization operations, go statements, calls to runtime.Gosched
+// and time.Sleep, and periodically between blocks), so the
+// interleaving of a program's goroutines is determined entirely by
+// the scheduling policy. The default policy runs goroutines in FIFO
+// order and switches only when the running goroutine blocks or
+// yields; a seeded policy makes random choices at every scheduling
+// point, to explore other interleavings.
+//
+// Time is virtual: sleeping goroutines are woken, in order of their
+// deadlines, only when no other goroutine can run.
+
+import (
+	"cmp"
+	"fmt"
+	"go/token"
+	"go/types"
+	"math/rand"
+	"runtime"
+	"slices"
+	"strings"
+	"sync/atomic"
+)
+
+// timeslice is the number of blocks a goroutine may execute before
+// it yields to others.
+const timeslice = 1 << 12
+
+type gstatus int
+
+const (
+	grunning gstatus = iota
+	grunnable
+	gblocked
+	gsleeping
+)
+
+// A goroutine is an interpreted goroutine.
+type goroutine struct {
+	id     int
+	wake   chan wakeup // signaled to resume the goroutine
+	status gstatus
+	ticks  int // blocks executed since the goroutine last yielded
+
+	// The following fields are set while the goroutine is not running.
+	reason string    // why the goroutine is blocked, e.g. "chan receive"
+	frame  *frame    // innermost frame
+	pos    token.Pos // position of the blocking operation
+	until  int64     // virtual time at which a sleeping goroutine wakes
+}
+
+type wakeup int
+
+const (
+	wakeRun   wakeup = iota // run the goroutine
+	wakeFatal               // terminate the program with sched.fatal (main goroutine only)
+	wakeExit                // the program has ended; exit quietly
+)
+
+// A scheduler chooses which interpreted goroutine runs.
+type scheduler struct {
+	rand     *rand.Rand   // source of scheduling choices; nil => FIFO
+	main     *goroutine   // the main goroutine
+	cur      *goroutine   // the running goroutine
+	all      []*goroutine // live goroutines, in order of creation
+	runq     []*goroutine // runnable goroutines
+	sleeping []*goroutine // sleeping goroutines
+	nextID   int
+	now      int64       // virtual time, in nanoseconds
+	done     atomic.Bool // the program has ended; read by exiting goroutines
+	fatal    any         // the panic value with which the program is ending
+	locks    map[*value]*rwmutex
+	groups   map[*value]*waitGroup
+}
+
+// newScheduler returns a scheduler whose running goroutine is the
+// main goroutine. If seed is non-zero, scheduling choices are made by
+// a pseudo-random generator with that seed.
+func newScheduler(seed int64) *scheduler {
+	s := &scheduler{
+		locks:  make(map[*value]*rwmutex),
+		groups: make(map[*value]*waitGroup),
+	}
+	if seed != 0 {
+		s.rand = rand.New(rand.NewSource(seed))
+	}
+	s.main = s.newGoroutine()
+	s.cur = s.main
+	return s
+}
+
+func (s *scheduler) newGoroutine() *goroutine {
+	s.nextID++
+	g := &goroutine{id: s.nextID, wake: make(chan wakeup, 1)}
+	s.all = append(s.all, g)
+	return g
+}
+
+// spawn creates a runnable goroutine that executes body.
+func (s *scheduler) spawn(i *interpreter, body func()) {
+	g := s.newGoroutine()
+	s.ready(g)
+	go func() {
+		s.park(g)
+		ok := false
+		defer func() {
+			if ok || s.done.Load() {
+				return
+			}
+			if i.mode&DisableRecover != 0 {
+				return // let interpreter crash
+			}
+			if p := recover(); p != (goexitPanic{}) {
+				// An unrecovered panic in any goroutine
+				// terminates the program.
+				s.terminate(p)
+				return
+			}
+			s.exit()
+		}()
+		body()
+		ok = true
+		s.exit()
+	}()
+	s.preempt()
+}
+
+// ready makes g runnable.
+func (s *scheduler) ready(g *goroutine) {
+	g.status = grunnable
+	s.runq = append(s.runq, g)
+}
+
+// block blocks the running goroutine until another goroutine makes it
+// ready. fr is its innermost frame, and pos the position of the
+// blocking operation.
+func (s *scheduler) block(fr *frame, reason string, pos token.Pos) {
+	g := s.cur
+	g.status = gblocked
+	g.reason, g.frame, g.pos = reason, fr, pos
+	s.switchFrom(g)
+}
+
+// sleep blocks the running goroutine for duration d of virtual time.
+func (s *scheduler) sleep(fr *frame, d int64, pos token.Pos) {
+	if d <= 0 {
+		s.yield()
+		return
+	}
+	g := s.cur
+	g.status = gsleeping
+	g.reason, g.frame, g.pos = "sleep", fr, pos
+	g.until = s.now + d
+	s.sleeping = append(s.sleeping, g)
+	s.switchFrom(g)
+}
+
+// yield lets other runnable goroutines run before the running one
+// continues.
+func (s *scheduler) yield() {
+	g := s.cur
+	g.ticks = 0
+	if len(s.runq) > 0 {
+		s.ready(g)
+		s.switchFrom(g)
+	}
+}
+
+// preempt marks a scheduling point: under a seeded policy, it may
+// let another goroutine run.
+func (s *scheduler) preempt() {
+	if s.rand != nil && len(s.runq) > 0 && s.rand.Intn(len(s.runq)+1) > 0 {
+		s.yield()
+	}
+}
+
+// tick is called at the start of each block, and yields if the
+// running goroutine has used up its timeslice.
+func (s *scheduler) tick() {
+	g := s.cur
+	g.ticks++
+	if g.ticks >= timeslice {
+		s.yield()
+	}
+}
+
+// next removes and returns the next goroutine to run, advancing the
+// virtual clock if necessary, or returns nil if no goroutine can run.
+func (s *scheduler) next() *goroutine {
+	if len(s.runq) == 0 && len(s.sleeping) > 0 {
+		// Wake the earliest sleepers.
+		slices.SortStableFunc(s.sleeping, func(x, y *goroutine) int {
+			return cmp.Compare(x.until, y.until)
+		})
+		s.now = s.sleeping[0].until
+		for len(s.sleeping) > 0 && s.sleeping[0].until <= s.now {
+			s.ready(s.sleeping[0])
+			s.sleeping = s.sleeping[1:]
+		}
+	}
+	if len(s.runq) == 0 {
+		return nil
+	}
+	i := 0
+	if s.rand != nil {
+		i = s.rand.Intn(len(s.runq))
+	}
+	g := s.runq[i]
+	s.runq = slices.Delete(s.runq, i, i+1)
+	return g
+}
+
+// switchFrom suspends the running goroutine g, which must no longer be
+// running, until it is next chosen to run.
+func (s *scheduler) switchFrom(g *goroutine) {
+	next := s.next()
+	if next == nil {
+		s.terminate(s.deadlock())
+	}
+	if next != g {
+		s.cur = next
+		next.wake <- wakeRun
+		s.park(g)
+	}
+	g.status = grunning
+	g.frame = nil
+}
+
+// park waits until g is woken.
+func (s *scheduler) park(g *goroutine) {
+	switch <-g.wake {
+	case wakeFatal:
+		panic(s.fatal)
+	case wakeExit:
+		runtime.Goexit()
+	}
+}
+
+// exit ends the running goroutine, which is not the main goroutine.
+func (s *scheduler) exit() {
+	g := s.cur
+	s.all = slices.DeleteFunc(s.all, func(x *goroutine) bool { return x == g })
+	next := s.next()
+	if next == nil {
+		s.terminate(s.deadlock())
+		return
+	}
+	s.cur = next
+	next.wake <- wakeRun
+}
+
+// terminate ends the program with panic value p.
+// When called from the main goroutine, it panics with p; otherwise,
+// it wakes the main goroutine to do so, and exits the calling goroutine.
+func (s *scheduler) terminate(p any) {
+	if s.done.Load() {
+		runtime.Goexit() // already terminating
+	}
+	s.fatal = p
+	s.done.Store(true)
+	if s.cur == s.main {
+		panic(p)
+	}
+	s.main.wake <- wakeFatal
+	runtime.Goexit()
+}
+
+// shutdown releases the interpreter goroutines of all goroutines other
+// than the main one, after the program has ended.
+func (s *scheduler) shutdown() {
+	s.done.Store(true)
+	for _, g := range s.all {
+		if g != s.main && g != s.cur {
+			g.wake <- wakeExit
+		}
+	}
+	s.all = nil
+}
+
+// A deadlockError is the fatal error reported when all goroutines are
+// blocked.
+type deadlockError struct {
+	goroutines string // a description of the blocked goroutines
+}
+
+func (e deadlockError) Error() string {
+	return "all goroutines are asleep - deadlock!\n\n" + e.goroutines
+}
+
+// A fatalError is an unrecoverable runtime error.
+type fatalError string
+
+func (e fatalError) Error() string { return string(e) }
+
+// deadlock returns the error describing the blocked goroutines.
+func (s *scheduler) deadlock() deadlockError {
+	var buf strings.Builder
+	for _, g := range s.all {
+		if g.status != gblocked {
+			continue
+		}
+		if buf.Len() > 0 {
+			buf.WriteByte('\n')
+		}
+		fmt.Fprintf(&buf, "goroutine %d [%s]:\n", g.id, g.reason)
+		pos := g.pos
+		for fr := g.frame; fr != nil; fr = fr.caller {
+			fmt.Fprintf(&buf, "%s(...)\n", fr.fn)
+			if pos.IsValid() {
+				fmt.Fprintf(&buf, "\t%s\n", fr.i.prog.Fset.Position(pos))
+			}
+			pos = fr.callpos
+		}
+	}
+	return deadlockError{buf.String()}
+}
+
+// -- channels --
+
+// A channel is the representation of a Go channel.
+type channel struct {
+	elem   types.Type // element type
+	cap    int
+	buf    []value
+	closed bool
+	recvq  []*sudog // blocked receivers
+	sendq  []*sudog // blocked senders
+}
+
+func makeChannel(elem types.Type, size int64) *channel {
+	if size < 0 || int64(int(size)) != size {
+		panic("makechan: size out of range")
+	}
+	return &channel{elem: elem, cap: int(size)}
+}
+
+func (c *channel) len() int {
+	if c == nil {
+		return 0
+	}
+	return len(c.buf)
+}
+
+func (c *channel) capacity() int {
+	if c == nil {
+		return 0
+	}
+	return c.cap
+}
+
+// A sudog represents a goroutine blocked in a channel operation,
+// which may be one case of a select.
+type sudog struct {
+	g      *goroutine
+	op     *pendingOp
+	index  int   // case index, for a select
+	val    value // the value sent, or received
+	ok     bool  // (receive) the value was sent, not the result of close
+	closed bool  // (send) the channel was closed
+}
+
+// A pendingOp is a blocking channel operation, which completes when
+// the first of its sudogs fires.
+type pendingOp struct {
+	fired *sudog
+}
+
+// dequeue removes and returns the first sudog of q whose operation
+// has not completed, or nil if there is none.
+func dequeue(q *[]*sudog) *sudog {
+	for len(*q) > 0 {
+		sd := (*q)[0]
+		*q = (*q)[1:]
+		if sd.op.fired == nil {
+			return sd
+		}
+	}
+	return nil
+}
+
+// fire completes the operation of sd, and readies its goroutine.
+func (s *scheduler) fire(sd *sudog) {
+	sd.op.fired = sd
+	s.ready(sd.g)
+}
+
+// trySend sends v on c if it can do so without blocking.
+func (s *scheduler) trySend(c *channel, v value) bool {
+	if c.closed {
+		panic("send on closed channel")
+	}
+	if sd := dequeue(&c.recvq); sd != nil {
+		sd.val, sd.ok = v, true
+		s.fire(sd)
+		return true
+	}
+	if len(c.buf) < c.cap {
+		c.buf = append(c.buf, v)
+		return true
+	}
+	return false
+}
+
+// tryRecv receives from c if it can do so without blocking.
+func (s *scheduler) tryRecv(c *channel) (v value, ok, done bool) {
+	if len(c.buf) > 0 {
+		v = c.buf[0]
+		c.buf = c.buf[1:]
+		if sd := dequeue(&c.sendq); sd != nil {
+			c.buf = append(c.buf, sd.val)
+			s.fire(sd)
+		}
+		return v, true, true
+	}
+	if sd := dequeue(&c.sendq); sd != nil {
+		s.fire(sd)
+		return sd.val, true, true
+	}
+	if c.closed {
+		return zero(c.elem), false, true
+	}
+	return nil, false, false
+}
+
+// send implements the send statement c <- v.
+func (s *scheduler) send(fr *frame, c *channel, v value, pos token.Pos) {
+	s.preempt()
+	if c == nil {
+		s.block(fr, "chan send (nil chan)", pos)
+		panic("unreachable") // never woken
+	}
+	if s.trySend(c, v) {
+		return
+	}
+	sd := &sudog{g: s.cur, op: new(pendingOp), val: v}
+	c.sendq = append(c.sendq, sd)
+	s.block(fr, "chan send", pos)
+	if sd.closed {
+		panic("send on closed channel")
+	}
+}
+
+// recv implements the receive operation <-c.
+func (s *scheduler) recv(fr *frame, c *channel, pos token.Pos) (value, bool) {
+	s.preempt()
+	if c == nil {
+		s.block(fr, "chan receive (nil chan)", pos)
+		panic("unreachable") // never woken
+	}
+	if v, ok, done := s.tryRecv(c); done {
+		return v, ok
+	}
+	sd := &sudog{g: s.cur, op: new(pendingOp)}
+	c.recvq = append(c.recvq, sd)
+	s.block(fr, "chan receive", pos)
+	return sd.val, sd.ok
+}
+
+// close implements the close built-in.
+func (s *scheduler) close(c *channel) {
+	if c == nil {
+		panic("close of nil channel")
+	}
+	if c.closed {
+		panic("close of closed channel")
+	}
+	c.closed = true
+	for sd := dequeue(&c.recvq); sd != nil; sd = dequeue(&c.recvq) {
+		sd.val, sd.ok = zero(c.elem), false
+		s.fire(sd)
+	}
+	for sd := dequeue(&c.sendq); sd != nil; sd = dequeue(&c.sendq) {
+		sd.closed = true
+		s.fire(sd)
+	}
+}
+
+// A selectCase is one case of a select statement.
+type selectCase struct {
+	send bool
+	c    *channel
+	val  value // value to send
+}
+
+// selectOp implements the select statement, returning the index of
+// the chosen case (-1 for the default case of a non-blocking select)
+// and, for a receive, the value received and whether it was sent.
+//
+// If several cases are ready, the default policy chooses the first,
+// and the seeded policy chooses one at random.
+func (s *scheduler) selectOp(fr *frame, cases []selectCase, blocking bool, pos token.Pos) (int, value, bool) {
+	s.preempt()
+	order := make([]int, len(cases))
+	for i := range order {
+		order[i] = i
+	}
+	if s.rand != nil {
+		s.rand.Shuffle(len(order), func(i, j int) { order[i], order[j] = order[j], order[i] })
+	}
+	for _, i := range order {
+		cas := cases[i]
+		if cas.c == nil {
+			continue // never ready
+		}
+		if cas.send {
+			if s.trySend(cas.c, cas.val) {
+				return i, nil, false
+			}
+		} else if v, ok, done := s.tryRecv(cas.c); done {
+			return i, v, ok
+		}
+	}
+	if !blocking {
+		return -1, nil, false
+	}
+
+	op := new(pendingOp)
+	for i, cas := range cases {
+		if cas.c == nil {
+			continue
+		}
+		sd := &sudog{g: s.cur, op: op, index: i}
+		if cas.send {
+			sd.val = cas.val
+			cas.c.sendq = append(cas.c.sendq, sd)
+		} else {
+			cas.c.recvq = append(cas.c.recvq, sd)
+		}
+	}
+	reason := "select"
+	if !slices.ContainsFunc(cases, func(cas selectCase) bool { return cas.c != nil }) {
+		reason = "select (no cases)"
+	}
+	s.block(fr, reason, pos)
+	sd := op.fired
+	if sd.closed {
+		panic("send on closed channel")
+	}
+	return sd.index, sd.val, sd.ok
+}
@@ -0,0 +1,182 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package interp_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"golang.org/x/tools/go/ssa/interp"
+)
+
+// runSource interprets the program src using the specified
+// configuration, and returns its output and exit code.
+func runSource(t *testing.T, goroot, src string, conf *interp.Config) (string, int) {
+	t.Helper()
+	input := filepath.Join(t.TempDir(), "main.go")
+	if err := os.WriteFile(input, []byte(src), 0666); err != nil {
+		t.Fatal(err)
+	}
+	return runConfig(t, input, goroot, conf)
+}
+
+func TestFatalErrors(t *testing.T) {
+	goroot := makeGoroot(t)
+	for _, test := range []struct {
+		name, src string
+		want      []string // substrings of the output
+	}{
+		{
+			"deadlock",
+			`package main
+
+import "sync"
+
+var mu sync.Mutex
+
+func main() {
+	ch := make(chan int)
+	mu.Lock()
+	go func() {
+		mu.Lock()
+		ch <- 1
+	}()
+	<-ch
+}
+`,
+			[]string{
+				"fatal error: all goroutines are asleep - deadlock!",
+				"goroutine 1 [chan receive]:\nmain.main(...)\n\t",
+				"main.go:14:2\n",
+				"goroutine 2 [sync.Mutex.Lock]:\n(*sync.Mutex).Lock(...)\nmain.main$1(...)\n\t",
+				"main.go:11:10\n",
+			},
+		},
+		{
+			"nil channel",
+			`package main
+
+func main() {
+	var ch chan int
+	ch <- 1
+}
+`,
+			[]string{"goroutine 1 [chan send (nil chan)]:"},
+		},
+		{
+			"empty select",
+			`package main
+
+func main() {
+	select {}
+}
+`,
+			[]string{"goroutine 1 [select (no cases)]:"},
+		},
+		{
+			"panic in goroutine",
+			`package main
+
+func main() {
+	defer println("deferred call in main goroutine")
+	done := make(chan bool)
+	go func() {
+		panic("oops")
+	}()
+	<-done
+}
+`,
+			[]string{"panic: (string, oops)"},
+		},
+		{
+			"unlock of unlocked mutex",
+			`package main
+
+import "sync"
+
+func main() {
+	var mu sync.Mutex
+	mu.Unlock()
+}
+`,
+			[]string{"fatal error: sync: unlock of unlocked mutex"},
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			out, exitCode := runSource(t, goroot, test.src, &interp.Config{})
+			if exitCode != 2 {
+				t.Errorf("exit code = %d, want 2", exitCode)
+			}
+			for _, want := range test.want {
+				if !strings.Contains(out, want) {
+					t.Errorf("output does not contain %q:\n%s", want, out)
+				}
+			}
+			if strings.Contains(out, "deferred call") {
+				t.Errorf("deferred call ran after fatal panic:\n%s", out)
+			}
+		})
+	}
+}
+
+// TestSeed checks that a seeded schedule is deterministic, and differs
+// from the default one.
+func TestSeed(t *testing.T) {
+	const src = `package main
+
+import (
+	"fmt"
+	"sync"
+)
+
+func main() {
+	var (
+		mu    sync.Mutex
+		wg    sync.WaitGroup
+		order []int
+	)
+	for i := range 4 {
+		wg.Go(func() {
+			for range 3 {
+				mu.Lock()
+				order = append(order, i)
+				mu.Unlock()
+			}
+		})
+	}
+	wg.Wait()
+	fmt.Println(order)
+}
+`
+	goroot := makeGoroot(t)
+	run := func(seed int64) string {
+		out, exitCode := runSource(t, goroot, src, &interp.Config{Seed: seed})
+		if exitCode != 0 {
+			t.Fatalf("seed %d: exit code = %d", seed, exitCode)
+		}
+		return out
+	}
+
+	// By default, goroutines run in FIFO order until they block.
+	if got, want := run(0), "[0 0 0 1 1 1 2 2 2 3 3 3]\n"; got != want {
+		t.Errorf("default schedule: got %q, want %q", got, want)
+	}
+
+	differs := false
+	for seed := int64(1); seed <= 5; seed++ {
+		out := run(seed)
+		if again := run(seed); again != out {
+			t.Errorf("seed %d: got %q, then %q", seed, out, again)
+		}
+		if out != run(0) {
+			differs = true
+		}
+	}
+	if !differs {
+		t.Errorf("no seed produced a schedule other than the default")
+	}
+}
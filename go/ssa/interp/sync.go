@@ -0,0 +1,460 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package interp
+
+// Emulated functions of the sync and sync/atomic packages.
+//
+// The state of each Mutex, RWMutex and WaitGroup is held by the
+// scheduler, keyed by the variable's address, so that blocked
+// goroutines can be woken in FIFO order. Atomic operations are atomic
+// because only one goroutine runs at a time; each is a scheduling
+// point, so that spin loops make progress.
+
+import (
+	"go/token"
+	"go/types"
+	"maps"
+)
+
+func init() {
+	maps.Copy(externals, map[string]externalFn{
+		"(*sync.Mutex).Lock":      ext۰sync۰Mutex۰Lock,
+		"(*sync.Mutex).TryLock":   ext۰sync۰Mutex۰TryLock,
+		"(*sync.Mutex).Unlock":    ext۰sync۰Mutex۰Unlock,
+		"(*sync.RWMutex).Lock":    ext۰sync۰Mutex۰Lock,
+		"(*sync.RWMutex).TryLock": ext۰sync۰Mutex۰TryLock,
+		"(*sync.RWMutex).Unlock":  ext۰sync۰RWMutex۰Unlock,
+		"(*sync.RWMutex).RLock":   ext۰sync۰RWMutex۰RLock,
+		"(*sync.RWMutex).RUnlock": ext۰sync۰RWMutex۰RUnlock,
+		"(*sync.WaitGroup).Add":   ext۰sync۰WaitGroup۰Add,
+		"(*sync.WaitGroup).Done":  ext۰sync۰WaitGroup۰Done,
+		"(*sync.WaitGroup).Go":    ext۰sync۰WaitGroup۰Go,
+		"(*sync.WaitGroup).Wait":  ext۰sync۰WaitGroup۰Wait,
+
+		"(*sync/atomic.Bool).CompareAndSwap":       ext۰atomic۰CompareAndSwapMethod,
+		"(*sync/atomic.Bool).Load":                 ext۰atomic۰LoadMethod,
+		"(*sync/atomic.Bool).Store":                ext۰atomic۰StoreMethod,
+		"(*sync/atomic.Bool).Swap":                 ext۰atomic۰SwapMethod,
+		"(*sync/atomic.Pointer[T]).CompareAndSwap": ext۰atomic۰CompareAndSwapMethod,
+		"(*sync/atomic.Pointer[T]).Load":           ext۰atomic۰LoadMethod,
+		"(*sync/atomic.Pointer[T]).Store":          ext۰atomic۰StoreMethod,
+		"(*sync/atomic.Pointer[T]).Swap":           ext۰atomic۰SwapMethod,
+		"(*sync/atomic.Value).CompareAndSwap":      ext۰atomic۰Value۰CompareAndSwap,
+		"(*sync/atomic.Value).Load":                ext۰atomic۰LoadMethod,
+		"(*sync/atomic.Value).Store":               ext۰atomic۰Value۰Store,
+		"(*sync/atomic.Value).Swap":                ext۰atomic۰Value۰Swap,
+	})
+	for _, t := range []string{"Int32", "Int64", "Uint32", "Uint64", "Uintptr"} {
+		maps.Copy(externals, map[string]externalFn{
+			"sync/atomic.Add" + t:            ext۰atomic۰Add,
+			"sync/atomic.And" + t:            ext۰atomic۰And,
+			"sync/atomic.CompareAndSwap" + t: ext۰atomic۰CompareAndSwap,
+			"sync/atomic.Load" + t:           ext۰atomic۰Load,
+			"sync/atomic.Or" + t:             ext۰atomic۰Or,
+			"sync/atomic.Store" + t:          ext۰atomic۰Store,
+			"sync/atomic.Swap" + t:           ext۰atomic۰Swap,
+
+			"(*sync/atomic." + t + ").Add":            ext۰atomic۰AddMethod,
+			"(*sync/atomic." + t + ").And":            ext۰atomic۰AndMethod,
+			"(*sync/atomic." + t + ").CompareAndSwap": ext۰atomic۰CompareAndSwapMethod,
+			"(*sync/atomic." + t + ").Load":           ext۰atomic۰LoadMethod,
+			"(*sync/atomic." + t + ").Or":             ext۰atomic۰OrMethod,
+			"(*sync/atomic." + t + ").Store":          ext۰atomic۰StoreMethod,
+			"(*sync/atomic." + t + ").Swap":           ext۰atomic۰SwapMethod,
+		})
+	}
+}
+
+// -- sync --
+
+// An rwmutex is the state of a sync.Mutex or sync.RWMutex.
+type rwmutex struct {
+	writer  bool       // held by a writer
+	readers int        // number of readers holding the lock
+	waiters []rwwaiter // goroutines waiting for the lock, in FIFO order
+}
+
+type rwwaiter struct {
+	g     *goroutine
+	write bool
+}
+
+// mutexOf returns the state of the mutex at address addr.
+func mutexOf(fr *frame, addr value) *rwmutex {
+	locks := fr.i.sched.locks
+	m := locks[addr.(*value)]
+	if m == nil {
+		m = new(rwmutex)
+		locks[addr.(*value)] = m
+	}
+	return m
+}
+
+// grant grants the lock to waiting goroutines, in order, while it can.
+func (m *rwmutex) grant(s *scheduler) {
+	for len(m.waiters) > 0 && !m.writer {
+		w := m.waiters[0]
+		if w.write {
+			if m.readers > 0 {
+				break
+			}
+			m.writer = true
+		} else {
+			m.readers++
+		}
+		m.waiters = m.waiters[1:]
+		s.ready(w.g)
+	}
+}
+
+// lock acquires the mutex at address addr for writing or reading.
+func lock(fr *frame, addr value, write bool, reason string) {
+	s := fr.i.sched
+	s.preempt()
+	m := mutexOf(fr, addr)
+	if len(m.waiters) == 0 && !m.writer && (!write || m.readers == 0) {
+		if write {
+			m.writer = true
+		} else {
+			m.readers++
+		}
+		return
+	}
+	// The lock is handed over to the goroutine by grant.
+	m.waiters = append(m.waiters, rwwaiter{s.cur, write})
+	s.block(fr, reason, token.NoPos)
+}
+
+func ext۰sync۰Mutex۰Lock(fr *frame, args []value) value {
+	lock(fr, args[0], true, "sync.Mutex.Lock")
+	return nil
+}
+
+func ext۰sync۰Mutex۰TryLock(fr *frame, args []value) value {
+	m := mutexOf(fr, args[0])
+	if m.writer || m.readers > 0 || len(m.waiters) > 0 {
+		return false
+	}
+	m.writer = true
+	return true
+}
+
+func ext۰sync۰Mutex۰Unlock(fr *frame, args []value) value {
+	m := mutexOf(fr, args[0])
+	if !m.writer {
+		fr.i.sched.terminate(fatalError("sync: unlock of unlocked mutex"))
+	}
+	m.writer = false
+	m.grant(fr.i.sched)
+	fr.i.sched.preempt()
+	return nil
+}
+
+func ext۰sync۰RWMutex۰Unlock(fr *frame, args []value) value {
+	m := mutexOf(fr, args[0])
+	if !m.writer {
+		fr.i.sched.terminate(fatalError("sync: Unlock of unlocked RWMutex"))
+	}
+	m.writer = false
+	m.grant(fr.i.sched)
+	fr.i.sched.preempt()
+	return nil
+}
+
+func ext۰sync۰RWMutex۰RLock(fr *frame, args []value) value {
+	lock(fr, args[0], false, "sync.RWMutex.RLock")
+	return nil
+}
+
+func ext۰sync۰RWMutex۰RUnlock(fr *frame, args []value) value {
+	m := mutexOf(fr, args[0])
+	if m.readers == 0 {
+		fr.i.sched.terminate(fatalError("sync: RUnlock of unlocked RWMutex"))
+	}
+	m.readers--
+	m.grant(fr.i.sched)
+	fr.i.sched.preempt()
+	return nil
+}
+
+// A waitGroup is the state of a sync.WaitGroup.
+type waitGroup struct {
+	count   int
+	waiters []*goroutine
+}
+
+func waitGroupOf(fr *frame, addr value) *waitGroup {
+	groups := fr.i.sched.groups
+	wg := groups[addr.(*value)]
+	if wg == nil {
+		wg = new(waitGroup)
+		groups[addr.(*value)] = wg
+	}
+	return wg
+}
+
+func (wg *waitGroup) add(s *scheduler, delta int) {
+	wg.count += delta
+	if wg.count < 0 {
+		panic("sync: negative WaitGroup counter")
+	}
+	if wg.count == 0 {
+		for _, g := range wg.waiters {
+			s.ready(g)
+		}
+		wg.waiters = nil
+	}
+}
+
+func ext۰sync۰WaitGroup۰Add(fr *frame, args []value) value {
+	waitGroupOf(fr, args[0]).add(fr.i.sched, args[1].(int))
+	return nil
+}
+
+func ext۰sync۰WaitGroup۰Done(fr *frame, args []value) value {
+	waitGroupOf(fr, args[0]).add(fr.i.sched, -1)
+	fr.i.sched.preempt()
+	return nil
+}
+
+func ext۰sync۰WaitGroup۰Go(fr *frame, args []value) value {
+	// func (wg *WaitGroup) Go(f func())
+	wg := waitGroupOf(fr, args[0])
+	wg.add(fr.i.sched, 1)
+	i, f, pos := fr.i, args[1], fr.callpos
+	fr.i.sched.spawn(i, func() {
+		call(i, nil, pos, f, nil)
+		wg.add(i.sched, -1)
+	})
+	return nil
+}
+
+func ext۰sync۰WaitGroup۰Wait(fr *frame, args []value) value {
+	s := fr.i.sched
+	s.preempt()
+	wg := waitGroupOf(fr, args[0])
+	if wg.count > 0 {
+		wg.waiters = append(wg.waiters, s.cur)
+		s.block(fr, "sync.WaitGroup.Wait", token.NoPos)
+	}
+	return nil
+}
+
+// -- sync/atomic --
+
+// The atomic functions operate on the variable at their first
+// argument; the methods operate on the field v of their receiver.
+
+// atomicAddr returns the address of the variable operated on by an
+// atomic function or method.
+func atomicAddr(fr *frame, args []value) *value {
+	fr.i.sched.preempt()
+	addr := args[0].(*value)
+	recv := fr.fn.Signature.Recv()
+	if recv == nil {
+		return addr
+	}
+	st := recv.Type().(*types.Pointer).Elem().Underlying().(*types.Struct)
+	for i := range st.NumFields() {
+		if st.Field(i).Name() == "v" {
+			return &(*addr).(structure)[i]
+		}
+	}
+	panic("no field v in " + recv.Type().String())
+}
+
+// atomicResult converts the value v of the variable operated on by a
+// method to the representation of its result.
+func atomicResult(fr *frame, v value) value {
+	if v, ok := v.(uint32); ok && fr.fn.Signature.Results().At(0).Type() == types.Typ[types.Bool] {
+		return v != 0 // atomic.Bool
+	}
+	return v
+}
+
+// atomicArg converts an argument of a method to the representation
+// of the variable it operates on.
+func atomicArg(v value) value {
+	if b, ok := v.(bool); ok {
+		if b {
+			return uint32(1)
+		}
+		return uint32(0)
+	}
+	return v
+}
+
+func ext۰atomic۰Load(fr *frame, args []value) value {
+	return *atomicAddr(fr, args)
+}
+
+func ext۰atomic۰LoadMethod(fr *frame, args []value) value {
+	return atomicResult(fr, *atomicAddr(fr, args))
+}
+
+func ext۰atomic۰Store(fr *frame, args []value) value {
+	*atomicAddr(fr, args) = args[1]
+	return nil
+}
+
+func ext۰atomic۰StoreMethod(fr *frame, args []value) value {
+	*atomicAddr(fr, args) = atomicArg(args[1])
+	return nil
+}
+
+func ext۰atomic۰Swap(fr *frame, args []value) value {
+	addr := atomicAddr(fr, args)
+	old := *addr
+	*addr = args[1]
+	return old
+}
+
+func ext۰atomic۰SwapMethod(fr *frame, args []value) value {
+	addr := atomicAddr(fr, args)
+	old := *addr
+	*addr = atomicArg(args[1])
+	return atomicResult(fr, old)
+}
+
+func ext۰atomic۰CompareAndSwap(fr *frame, args []value) value {
+	addr := atomicAddr(fr, args)
+	if *addr != args[1] {
+		return false
+	}
+	*addr = args[2]
+	return true
+}
+
+func ext۰atomic۰CompareAndSwapMethod(fr *frame, args []value) value {
+	addr := atomicAddr(fr, args)
+	old, new := atomicArg(args[1]), atomicArg(args[2])
+	if *addr != old {
+		return false
+	}
+	*addr = new
+	return true
+}
+
+func atomicBinop(fr *frame, args []value, op func(x, y value) value) value {
+	addr := atomicAddr(fr, args)
+	old := *addr
+	*addr = op(old, args[1])
+	return old
+}
+
+func atomicAdd(x, y value) value {
+	switch x := x.(type) {
+	case int32:
+		return x + y.(int32)
+	case int64:
+		return x + y.(int64)
+	case uint32:
+		return x + y.(uint32)
+	case uint64:
+		return x + y.(uint64)
+	case uintptr:
+		return x + y.(uintptr)
+	}
+	panic("atomic add: invalid operand")
+}
+
+func atomicAnd(x, y value) value {
+	switch x := x.(type) {
+	case int32:
+		return x & y.(int32)
+	case int64:
+		return x & y.(int64)
+	case uint32:
+		return x & y.(uint32)
+	case uint64:
+		return x & y.(uint64)
+	case uintptr:
+		return x & y.(uintptr)
+	}
+	panic("atomic and: invalid operand")
+}
+
+func atomicOr(x, y value) value {
+	switch x := x.(type) {
+	case int32:
+		return x | y.(int32)
+	case int64:
+		return x | y.(int64)
+	case uint32:
+		return x | y.(uint32)
+	case uint64:
+		return x | y.(uint64)
+	case uintptr:
+		return x | y.(uintptr)
+	}
+	panic("atomic or: invalid operand")
+}
+
+func ext۰atomic۰Add(fr *frame, args []value) value {
+	// Add returns the new value.
+	return atomicAdd(atomicBinop(fr, args, atomicAdd), args[1])
+}
+
+func ext۰atomic۰AddMethod(fr *frame, args []value) value {
+	return ext۰atomic۰Add(fr, args)
+}
+
+func ext۰atomic۰And(fr *frame, args []value) value {
+	return atomicBinop(fr, args, atomicAnd)
+}
+
+func ext۰atomic۰AndMethod(fr *frame, args []value) value {
+	return ext۰atomic۰And(fr, args)
+}
+
+func ext۰atomic۰Or(fr *frame, args []value) value {
+	return atomicBinop(fr, args, atomicOr)
+}
+
+func ext۰atomic۰OrMethod(fr *frame, args []value) value {
+	return ext۰atomic۰Or(fr, args)
+}
+
+// atomic.Value holds an interface, whose dynamic type may not change.
+
+func ext۰atomic۰Value۰Store(fr *frame, args []value) value {
+	addr := atomicAddr(fr, args)
+	*addr = checkAtomicValue(*addr, args[1].(iface), "store")
+	return nil
+}
+
+func ext۰atomic۰Value۰Swap(fr *frame, args []value) value {
+	addr := atomicAddr(fr, args)
+	old := *addr
+	*addr = checkAtomicValue(old, args[1].(iface), "swap")
+	return old
+}
+
+func ext۰atomic۰Value۰CompareAndSwap(fr *frame, args []value) value {
+	addr := atomicAddr(fr, args)
+	old, new := args[1].(iface), args[2].(iface)
+	checkAtomicValue(*addr, new, "compare and swap")
+	if old.t != nil && !types.Identical(old.t, new.t) {
+		panic("sync/atomic: compare and swap of inconsistently typed values")
+	}
+	cur := (*addr).(iface)
+	if !cur.eq(nil, old) {
+		return false
+	}
+	*addr = new
+	return true
+}
+
+// checkAtomicValue checks that v may replace the value cur of an
+// atomic.Value, and returns it.
+func checkAtomicValue(cur value, v iface, op string) iface {
+	if v.t == nil {
+		panic("sync/atomic: " + op + " of nil value into Value")
+	}
+	if c := cur.(iface); c.t != nil && !types.Identical(c.t, v.t) {
+		panic("sync/atomic: " + op + " of inconsistently typed value into Value")
+	}
+	return v
+}
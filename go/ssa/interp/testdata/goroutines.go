@@ -0,0 +1,273 @@
+package main
+
+// Tests of goroutines, channels, select, and the sync and sync/atomic
+// packages, under the interpreter's deterministic scheduler.
+
+import (
+	"fmt"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+func unbuffered() {
+	ch := make(chan int)
+	go func() {
+		for i := range 3 {
+			ch <- i
+		}
+		close(ch)
+	}()
+	sum := 0
+	for x := range ch {
+		sum += x
+	}
+	if sum != 3 {
+		panic(fmt.Sprint("unbuffered: sum = ", sum))
+	}
+	if x, ok := <-ch; x != 0 || ok {
+		panic(fmt.Sprint("receive from closed channel: ", x, ok))
+	}
+}
+
+func buffered() {
+	ch := make(chan string, 2)
+	ch <- "a"
+	ch <- "b"
+	if len(ch) != 2 || cap(ch) != 2 {
+		panic(fmt.Sprint("len, cap = ", len(ch), cap(ch)))
+	}
+	done := make(chan bool)
+	go func() {
+		ch <- "c" // blocks until main receives
+		done <- true
+	}()
+	var got string
+	for range 3 {
+		got += <-ch
+	}
+	<-done
+	if got != "abc" {
+		panic("buffered: got " + got)
+	}
+	var nilch chan int
+	if len(nilch) != 0 || cap(nilch) != 0 {
+		panic("nil channel has non-zero len or cap")
+	}
+}
+
+func selects() {
+	a, b := make(chan int), make(chan int)
+	var never chan int // nil: never ready
+	go func() { a <- 1 }()
+	go func() { b <- 2 }()
+	sum := 0
+	for range 2 {
+		select {
+		case x := <-a:
+			sum += x
+		case x := <-b:
+			sum += x
+		case never <- 1:
+			panic("send on nil channel")
+		}
+	}
+	if sum != 3 {
+		panic(fmt.Sprint("select: sum = ", sum))
+	}
+
+	select {
+	case <-a:
+		panic("receive from idle channel")
+	default:
+	}
+
+	// A select that sends.
+	c := make(chan int, 1)
+	select {
+	case c <- 42:
+	default:
+		panic("send to empty buffered channel not ready")
+	}
+	if x := <-c; x != 42 {
+		panic(fmt.Sprint("received ", x))
+	}
+}
+
+func closedSend() {
+	defer func() {
+		if r := recover(); fmt.Sprint(r) != "send on closed channel" {
+			panic(fmt.Sprint("recovered ", r))
+		}
+	}()
+	ch := make(chan int, 1)
+	close(ch)
+	ch <- 1
+}
+
+func mutex() {
+	var (
+		mu sync.Mutex
+		wg sync.WaitGroup
+		n  int
+	)
+	for range 10 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for range 100 {
+				mu.Lock()
+				x := n
+				runtime.Gosched() // another goroutine may run, but cannot enter
+				n = x + 1
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+	if n != 1000 {
+		panic(fmt.Sprint("mutex: n = ", n))
+	}
+	if !mu.TryLock() {
+		panic("TryLock of unlocked mutex failed")
+	}
+	if mu.TryLock() {
+		panic("TryLock of locked mutex succeeded")
+	}
+	mu.Unlock()
+}
+
+func rwmutex() {
+	var (
+		rw      sync.RWMutex
+		wg      sync.WaitGroup
+		readers atomic.Int32
+	)
+	rw.RLock()
+	for range 3 {
+		wg.Go(func() {
+			rw.RLock()
+			readers.Add(1)
+			rw.RUnlock()
+		})
+	}
+	wg.Go(func() {
+		rw.Lock()
+		if n := readers.Load(); n != 3 {
+			panic(fmt.Sprint("writer ran with ", n, " readers done"))
+		}
+		rw.Unlock()
+	})
+	for readers.Load() < 3 {
+		runtime.Gosched()
+	}
+	rw.RUnlock() // lets the writer in
+	wg.Wait()
+}
+
+func atomics() {
+	var i32 int32
+	if atomic.AddInt32(&i32, 5) != 5 || atomic.LoadInt32(&i32) != 5 {
+		panic("AddInt32")
+	}
+	if atomic.CompareAndSwapInt32(&i32, 4, 7) || !atomic.CompareAndSwapInt32(&i32, 5, 7) || i32 != 7 {
+		panic("CompareAndSwapInt32")
+	}
+	if atomic.SwapInt32(&i32, 1) != 7 || atomic.OrInt32(&i32, 6) != 1 || i32 != 7 {
+		panic("SwapInt32, OrInt32")
+	}
+
+	var u64 atomic.Uint64
+	u64.Store(10)
+	if u64.Add(5) != 15 || u64.And(6) != 15 || u64.Load() != 6 {
+		panic("Uint64")
+	}
+
+	var b atomic.Bool
+	if b.Load() || b.Swap(true) || !b.Load() || !b.CompareAndSwap(true, false) || b.Load() {
+		panic("Bool")
+	}
+
+	var p atomic.Pointer[int]
+	x, y := 1, 2
+	if p.Load() != nil || !p.CompareAndSwap(nil, &x) || p.Swap(&y) != &x || *p.Load() != 2 {
+		panic("Pointer")
+	}
+
+	var v atomic.Value
+	if v.Load() != nil {
+		panic("Value.Load of empty Value")
+	}
+	v.Store("hello")
+	if v.Swap("world") != "hello" || !v.CompareAndSwap("world", "!") || v.Load() != "!" {
+		panic("Value")
+	}
+	func() {
+		defer func() {
+			if r := recover(); r == nil {
+				panic("Value.Store of inconsistent type did not panic")
+			}
+		}()
+		v.Store(1)
+	}()
+
+	// Atomic operations are scheduling points, so spin loops terminate.
+	var flag atomic.Bool
+	go flag.Store(true)
+	for !flag.Load() {
+	}
+}
+
+func once() {
+	var (
+		o  sync.Once
+		wg sync.WaitGroup
+		n  int
+	)
+	for range 5 {
+		wg.Go(func() { o.Do(func() { n++ }) })
+	}
+	wg.Wait()
+	if n != 1 {
+		panic(fmt.Sprint("Once: n = ", n))
+	}
+}
+
+func sleep() {
+	// Time is virtual: the goroutines wake in order of their deadlines.
+	ch := make(chan int, 3)
+	for _, d := range []int{3, 1, 2} {
+		go func() {
+			time.Sleep(time.Duration(d) * time.Hour)
+			ch <- d
+		}()
+	}
+	got := fmt.Sprint(<-ch, <-ch, <-ch)
+	if got != "1 2 3" {
+		panic("sleep: got " + got)
+	}
+}
+
+func goexit() {
+	done := make(chan bool)
+	go func() {
+		defer func() { done <- true }()
+		runtime.Goexit()
+		panic("unreachable")
+	}()
+	<-done
+}
+
+func main() {
+	unbuffered()
+	buffered()
+	selects()
+	closedSend()
+	mutex()
+	rwmutex()
+	atomics()
+	once()
+	sleep()
+	goexit()
+}
@@ -17,3 +17,7 @@ type Error interface {
 }
 
 func GC()
+
+func Goexit()
+
+func Gosched()
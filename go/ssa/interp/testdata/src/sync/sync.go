@@ -1,36 +1,63 @@
 package sync
 
-// Rudimentary implementation of a mutex for interp tests.
+// The state of Mutex, RWMutex and WaitGroup is held by the
+// interpreter, keyed by their addresses. The fields ensure that each
+// variable has a distinct address.
+
 type Mutex struct {
-	c chan int // Mutex is held when held c!=nil and is empty. Access is guarded by g.
+	_ int
 }
 
-func (m *Mutex) Lock() {
-	c := ch(m)
-	<-c
+func (m *Mutex) Lock()
+func (m *Mutex) TryLock() bool
+func (m *Mutex) Unlock()
+
+type Locker interface {
+	Lock()
+	Unlock()
 }
 
-func (m *Mutex) Unlock() {
-	c := ch(m)
-	c <- 1
+type RWMutex struct {
+	_ int
+}
+
+func (rw *RWMutex) Lock()
+func (rw *RWMutex) TryLock() bool
+func (rw *RWMutex) Unlock()
+func (rw *RWMutex) RLock()
+func (rw *RWMutex) RUnlock()
+
+func (rw *RWMutex) RLocker() Locker { return (*rlocker)(rw) }
+
+type rlocker RWMutex
+
+func (r *rlocker) Lock()   { (*RWMutex)(r).RLock() }
+func (r *rlocker) Unlock() { (*RWMutex)(r).RUnlock() }
+
+type WaitGroup struct {
+	_ int
 }
 
-// sequentializes Mutex.c access.
-var g = make(chan int, 1)
+func (wg *WaitGroup) Add(delta int)
+func (wg *WaitGroup) Done()
+func (wg *WaitGroup) Go(f func())
+func (wg *WaitGroup) Wait()
 
-func init() {
-	g <- 1
+type Once struct {
+	m    Mutex
+	done bool
 }
 
-// ch initializes the m.c field if needed and returns it.
-func ch(m *Mutex) chan int {
-	<-g
-	defer func() {
-		g <- 1
-	}()
-	if m.c == nil {
-		m.c = make(chan int, 1)
-		m.c <- 1
+func (o *Once) Do(f func()) {
+	o.m.Lock()
+	defer o.m.Unlock()
+	if !o.done {
+		defer func() { o.done = true }()
+		f()
 	}
-	return m.c
+}
+
+func OnceFunc(f func()) func() {
+	var once Once
+	return func() { once.Do(f) }
 }
@@ -138,6 +138,7 @@ const (
 	TypeError              DiagnosticSource = "compiler"
 	ModTidyError           DiagnosticSource = "go mod tidy"
 	CompilerOptDetailsInfo DiagnosticSource = "optimizer details" // cmd/compile -json=0,dir
+	Coverage               DiagnosticSource = "coverage"          // go test -coverprofile
 	UpgradeNotification    DiagnosticSource = "upgrade available"
 	Vulncheck              DiagnosticSource = "vulncheck imports"
 	Govulncheck            DiagnosticSource = "govulncheck"
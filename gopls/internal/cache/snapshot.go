@@ -14,6 +14,7 @@ import (
 	"go/build/constraint"
 	"go/parser"
 	"go/token"
+	"io/fs"
 	"os"
 	"path"
 	"path/filepath"
@@ -23,6 +24,7 @@ import (
 	"strings"
 	"sync"
 
+	"golang.org/x/tools/cover"
 	"golang.org/x/tools/go/types/objectpath"
 	"golang.org/x/tools/gopls/internal/cache/metadata"
 	"golang.org/x/tools/gopls/internal/cache/methodsets"
@@ -46,6 +48,7 @@ import (
 	"golang.org/x/tools/internal/event/label"
 	"golang.org/x/tools/internal/gocommand"
 	"golang.org/x/tools/internal/moremaps"
+	"golang.org/x/tools/internal/pprof"
 )
 
 // A Snapshot represents the current state for a given view.
@@ -188,6 +191,19 @@ type Snapshot struct {
 	// and tests need compiler optimization details in the diagnostics.
 	compilerOptDetails map[protocol.DocumentURI]unit
 
+	// coverageProfile, if non-nil, is the coverage profile loaded
+	// by the gopls.load_coverage command, overriding the
+	// coverageProfile setting. An empty URI hides coverage.
+	coverageProfile *protocol.DocumentURI
+
+	// pprofProfile, if non-nil, is the pprof profile selected by the
+	// gopls.toggle_profile command, overriding the pprofProfiles
+	// setting. An empty URI hides the profile.
+	pprofProfile *protocol.DocumentURI
+
+	// memos holds the promises of [Snapshot.Memoize], by key.
+	memos map[any]*memoize.Promise
+
 	// Concurrent type checking:
 	// typeCheckMu guards the ongoing type checking batch, and reference count of
 	// ongoing type checking operations.
@@ -341,6 +357,31 @@ func (s *Snapshot) BackgroundContext() context.Context {
 	return s.backgroundCtx
 }
 
+// Memoize returns the result of compute applied to the snapshot,
+// memoized under the given key for the lifetime of the snapshot.
+// It allows other packages to cache derived information, such as
+// the data types of template files, without re-computing it on
+// every request. The key should be a value of an unexported type
+// of the calling package, as for [context.Context.Value].
+//
+// As with [memoize.Promise], compute is called at most once to
+// completion; if all callers are cancelled, a later call retries.
+func (s *Snapshot) Memoize(ctx context.Context, key any, compute func(context.Context, *Snapshot) any) (any, error) {
+	s.mu.Lock()
+	p, ok := s.memos[key]
+	if !ok {
+		p = memoize.NewPromise(fmt.Sprintf("memo %T", key), func(ctx context.Context, arg any) any {
+			return compute(ctx, arg.(*Snapshot))
+		})
+		if s.memos == nil {
+			s.memos = make(map[any]*memoize.Promise)
+		}
+		s.memos[key] = p
+	}
+	s.mu.Unlock()
+	return s.awaitPromise(ctx, p)
+}
+
 // Templates returns the .tmpl files.
 func (s *Snapshot) Templates() map[protocol.DocumentURI]file.Handle {
 	s.mu.Lock()
@@ -828,6 +869,17 @@ func (s *Snapshot) fileWatchingGlobPatterns() map[protocol.RelativePattern]unit
 		patterns[protocol.RelativePattern{Pattern: glob}] = unit{}
 	}
 
+	// Watch the coverage and pprof profiles, so that their display
+	// is refreshed when tests are re-run or profiles are recorded.
+	for _, profile := range []protocol.DocumentURI{s.CoverageProfile(), s.PprofProfile()} {
+		if profile != "" {
+			patterns[protocol.RelativePattern{
+				BaseURI: profile.Dir(),
+				Pattern: path.Base(string(profile)),
+			}] = unit{}
+		}
+	}
+
 	var extensions strings.Builder
 	extensions.WriteString("go,mod,sum,work")
 	for _, ext := range s.Options().TemplateExtensions {
@@ -1542,6 +1594,28 @@ func (s *Snapshot) clone(ctx, bgCtx context.Context, changed StateChange, done f
 		modVulnHandles:    cloneWithout(s.modVulnHandles, changedFiles, &needsDiagnosis),
 		moduleUpgrades:    cloneWith(s.moduleUpgrades, changed.ModuleUpgrades),
 		vulns:             cloneWith(s.vulns, changed.Vulns),
+		coverageProfile:   s.coverageProfile,
+		pprofProfile:      s.pprofProfile,
+	}
+
+	// Update the coverage profile, and redisplay coverage if it
+	// or the content of the profile changed.
+	if changed.CoverageProfile != nil {
+		result.coverageProfile = changed.CoverageProfile
+		needsDiagnosis = true
+
+		// The profile may have been rewritten (e.g. by a test run)
+		// without a file-watching notification, so re-read it.
+		result.rereadFile(ctx, *changed.CoverageProfile)
+	}
+	if changed.PprofProfile != nil {
+		result.pprofProfile = changed.PprofProfile
+		result.rereadFile(ctx, *changed.PprofProfile)
+	}
+	if profile := result.CoverageProfile(); profile != "" {
+		if _, ok := changedFiles[profile]; ok {
+			needsDiagnosis = true
+		}
 	}
 
 	// Compute the new set of packages for which we want compiler
@@ -2207,6 +2281,162 @@ func (s *Snapshot) WantCompilerOptDetails(dir protocol.DocumentURI) bool {
 	return ok
 }
 
+// CoverageProfile returns the URI of the coverage profile whose data
+// should be displayed, or "" if none. A profile loaded by the
+// gopls.load_coverage command takes precedence over the
+// coverageProfile setting, which is relative to the workspace folder.
+func (s *Snapshot) CoverageProfile() protocol.DocumentURI {
+	if s.coverageProfile != nil {
+		return *s.coverageProfile
+	}
+	if profile := s.Options().CoverageProfile; profile != "" {
+		return s.folderFile(profile)
+	}
+	return ""
+}
+
+// folderFile returns the URI of the named file, which is resolved
+// against the workspace folder if relative.
+func (s *Snapshot) folderFile(name string) protocol.DocumentURI {
+	if !filepath.IsAbs(name) {
+		name = filepath.Join(s.view.folder.Dir.Path(), name)
+	}
+	return protocol.URIFromPath(name)
+}
+
+// rereadFile re-reads the file uri, if the snapshot has already read
+// it, in case it changed without a file-watching notification.
+// It is called during [Snapshot.clone].
+func (s *Snapshot) rereadFile(ctx context.Context, uri protocol.DocumentURI) {
+	if uri == "" {
+		return
+	}
+	if _, ok := s.files.get(uri); ok {
+		if fh, err := s.view.fs.ReadFile(ctx, uri); err == nil {
+			s.files.set(uri, fh)
+		}
+	}
+}
+
+// Coverage returns the parsed contents of the current coverage
+// profile (see [Snapshot.CoverageProfile]), or nil if there is none.
+//
+// The profile is read through the snapshot, so a change to the file
+// results in a new snapshot with fresh coverage data. The result is
+// memoized for the lifetime of the snapshot.
+func (s *Snapshot) Coverage(ctx context.Context) ([]*cover.Profile, error) {
+	type result struct {
+		profiles []*cover.Profile
+		err      error
+	}
+	v, err := s.Memoize(ctx, coverageKey{}, func(ctx context.Context, s *Snapshot) any {
+		profiles, err := s.readCoverage(ctx)
+		return result{profiles, err}
+	})
+	if err != nil {
+		return nil, err
+	}
+	res := v.(result)
+	return res.profiles, res.err
+}
+
+// coverageKey is the key of the memoized result of [Snapshot.Coverage].
+type coverageKey struct{}
+
+// readCoverage reads and parses the current coverage profile.
+func (s *Snapshot) readCoverage(ctx context.Context) ([]*cover.Profile, error) {
+	uri := s.CoverageProfile()
+	if uri == "" {
+		return nil, nil
+	}
+	fh, err := s.ReadFile(ctx, uri)
+	if err != nil {
+		return nil, err
+	}
+	content, err := fh.Content()
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) { // e.g. tests not yet run
+			return nil, nil
+		}
+		return nil, err
+	}
+	profiles, err := cover.ParseProfilesFromReader(bytes.NewReader(content))
+	if err != nil {
+		return nil, fmt.Errorf("parsing coverage profile %s: %v", uri.Path(), err)
+	}
+	return profiles, nil
+}
+
+// PprofProfiles returns the URIs of the pprof profiles named by the
+// pprofProfiles setting.
+func (s *Snapshot) PprofProfiles() []protocol.DocumentURI {
+	var uris []protocol.DocumentURI
+	for _, name := range s.Options().PprofProfiles {
+		uris = append(uris, s.folderFile(name))
+	}
+	return uris
+}
+
+// PprofProfile returns the URI of the pprof profile whose hot spots
+// should be displayed, or "" if none. A profile selected by the
+// gopls.toggle_profile command takes precedence over the first
+// element of the pprofProfiles setting.
+func (s *Snapshot) PprofProfile() protocol.DocumentURI {
+	if s.pprofProfile != nil {
+		return *s.pprofProfile
+	}
+	if uris := s.PprofProfiles(); len(uris) > 0 {
+		return uris[0]
+	}
+	return ""
+}
+
+// Pprof returns the parsed contents of the current pprof profile
+// (see [Snapshot.PprofProfile]), or nil if there is none.
+// The result is memoized for the lifetime of the snapshot.
+func (s *Snapshot) Pprof(ctx context.Context) (*pprof.Profile, error) {
+	type result struct {
+		profile *pprof.Profile
+		err     error
+	}
+	v, err := s.Memoize(ctx, pprofKey{}, func(ctx context.Context, s *Snapshot) any {
+		profile, err := s.readPprof(ctx)
+		return result{profile, err}
+	})
+	if err != nil {
+		return nil, err
+	}
+	res := v.(result)
+	return res.profile, res.err
+}
+
+// pprofKey is the key of the memoized result of [Snapshot.Pprof].
+type pprofKey struct{}
+
+// readPprof reads and parses the current pprof profile.
+func (s *Snapshot) readPprof(ctx context.Context) (*pprof.Profile, error) {
+	uri := s.PprofProfile()
+	if uri == "" {
+		return nil, nil
+	}
+	fh, err := s.ReadFile(ctx, uri)
+	if err != nil {
+		return nil, err
+	}
+	content, err := fh.Content()
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	profile, err := pprof.Parse(content)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", uri.Path(), err)
+	}
+	return profile, nil
+}
+
 // A CodeLensSourceFunc is a function that reports CodeLenses (range-associated
 // commands) for a given file.
 type CodeLensSourceFunc func(context.Context, *Snapshot, file.Handle) ([]protocol.CodeLens, error)
@@ -9,6 +9,7 @@ import (
 	"maps"
 	"slices"
 	"strings"
+	"sync"
 
 	"golang.org/x/tools/gopls/internal/cache/metadata"
 	"golang.org/x/tools/gopls/internal/cache/symbols"
@@ -23,7 +24,8 @@ type goplsSource struct {
 	snapshot *Snapshot
 
 	// set by each invocation of ResolveReferences
-	ctx context.Context
+	ctx   context.Context
+	prefs func() *imports.Preferences // ranking of candidates for the file
 }
 
 func (s *Snapshot) NewGoplsSource() *goplsSource {
@@ -45,11 +47,14 @@ type result struct {
 // ResolveReferences tries to find resolving imports in the workspace, and failing
 // that, in the module cache. It uses heuristics to decide among alternatives.
 // The heuristics will usually prefer a v2 version, if there is one.
+// Before other heuristics, it prefers the import paths pinned by the
+// user's settings and those already imported by the most packages of
+// the file's module.
 // TODO: It does not take advantage of hints provided by the user:
 // 1. syntactic context: pkg.Name().Foo
-// 3. already imported files in the same module
 func (s *goplsSource) ResolveReferences(ctx context.Context, filename string, missing imports.References) ([]*imports.Result, error) {
 	s.ctx = ctx
+	s.prefs = sync.OnceValue(func() *imports.Preferences { return s.preferences(filename) })
 	// get results from the workspace. There will at most one for each package name
 	fromWS, err := s.resolveWorkspaceReferences(filename, missing)
 	if err != nil {
@@ -201,13 +206,13 @@ func (s *goplsSource) resolveWorkspaceReferences(filename string, missing import
 				newv = append(newv, v[i])
 			}
 		}
-		ans = append(ans, bestImport(filename, newv))
+		ans = append(ans, bestImport(filename, s.prefs, newv))
 	}
 	return ans, nil
 }
 
 // for each package name, choose one using heuristics
-func bestImport(filename string, got []found) *imports.Result {
+func bestImport(filename string, prefs func() *imports.Preferences, got []found) *imports.Result {
 	if len(got) == 1 {
 		return got[0].res
 	}
@@ -232,6 +237,12 @@ func bestImport(filename string, got []found) *imports.Result {
 		got = leftovers // filtered some out
 	}
 
+	// prefer pinned imports and those the module already uses
+	got = preferred(prefs(), got, func(g found) string { return g.res.Import.ImportPath })
+	if len(got) == 1 {
+		return got[0].res
+	}
+
 	// TODO: if there are versions (like /v2) prefer them
 
 	// use distance to common ancestor with filename
@@ -253,6 +264,11 @@ func bestImport(filename string, got []found) *imports.Result {
 
 // choose the best result for the package named nm from the module cache
 func (s *goplsSource) bestCache(nm string, got []*result) *imports.Result {
+	if len(got) == 1 {
+		return got[0].res
+	}
+	// prefer pinned imports and those the module already uses
+	got = preferred(s.prefs(), got, func(r *result) string { return r.res.Import.ImportPath })
 	if len(got) == 1 {
 		return got[0].res
 	}
@@ -308,6 +324,54 @@ func (s *goplsSource) fromGoMod(got []*result) *imports.Result {
 	return nil
 }
 
+// preferences returns the ranking of candidate imports for filename:
+// the import paths pinned by the user's settings, and the number of
+// packages of the file's module that import each path.
+func (s *goplsSource) preferences(filename string) *imports.Preferences {
+	prefs := &imports.Preferences{Pinned: s.snapshot.Options().PinnedImports}
+	mp, err := s.snapshot.NarrowestMetadataForFile(s.ctx, protocol.URIFromPath(filename))
+	if err != nil || mp.Module == nil {
+		return prefs
+	}
+	// Count each importing package once, whatever its variants.
+	importers := make(map[metadata.ImportPath]map[metadata.PackagePath]bool)
+	for _, pkg := range s.snapshot.MetadataGraph().Packages {
+		if pkg.Module == nil || pkg.Module.GoMod != mp.Module.GoMod || pkg.IsIntermediateTestVariant() {
+			continue
+		}
+		for path := range pkg.DepsByImpPath {
+			if importers[path] == nil {
+				importers[path] = make(map[metadata.PackagePath]bool)
+			}
+			importers[path][pkg.PkgPath] = true
+		}
+	}
+	prefs.Uses = make(map[imports.ImportPath]int, len(importers))
+	for path, pkgs := range importers {
+		prefs.Uses[string(path)] = len(pkgs)
+	}
+	return prefs
+}
+
+// preferred returns the elements of got whose import path has the
+// highest positive score, or got if no import path scores above zero.
+func preferred[T any](prefs *imports.Preferences, got []T, path func(T) string) []T {
+	best := 0
+	for _, g := range got {
+		best = max(best, prefs.Score(path(g)))
+	}
+	if best == 0 {
+		return got
+	}
+	var ans []T
+	for _, g := range got {
+		if prefs.Score(path(g)) == best {
+			ans = append(ans, g)
+		}
+	}
+	return ans
+}
+
 func commonpref(filename string, path string) int {
 	k := 0
 	for ; k < len(filename) && k < len(path) && filename[k] == path[k]; k++ {
@@ -754,6 +754,8 @@ type StateChange struct {
 	ModuleUpgrades     map[protocol.DocumentURI]map[string]string
 	Vulns              map[protocol.DocumentURI]*vulncheck.Result
 	CompilerOptDetails map[protocol.DocumentURI]bool // package directory -> whether or not we want details
+	CoverageProfile    *protocol.DocumentURI         // if set, the coverage profile to display ("" => none)
+	PprofProfile       *protocol.DocumentURI         // if set, the pprof profile to display ("" => none)
 }
 
 // InvalidateView processes the provided state change, invalidating any derived
@@ -18,7 +18,7 @@ import (
 
 // NonZero returns a T set to some appropriate nonzero value:
 //   - Values of basic type are set to an arbitrary non-zero value.
-//   - Struct fields are set to a non-zero value.
+//   - Exported struct fields are set to a non-zero value.
 //   - Array indices are set to a non-zero value.
 //   - Pointers point to a non-zero value.
 //   - Maps and slices are given a non-zero element.
@@ -82,7 +82,9 @@ func nonZeroValue(t reflect.Type, seen []reflect.Type) reflect.Value {
 
 	case reflect.Struct:
 		for i := 0; i < v.NumField(); i++ {
-			v.Field(i).Set(nonZeroValue(t.Field(i).Type, seen))
+			if t.Field(i).IsExported() {
+				v.Field(i).Set(nonZeroValue(t.Field(i).Type, seen))
+			}
 		}
 
 	default: // Chan, Func, Interface, UnsafePointer
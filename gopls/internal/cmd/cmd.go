@@ -311,6 +311,7 @@ func (app *application) featureCommands() []command {
 		&signature{app: app},
 		&stats{app: app},
 		&symbols{app: app},
+		newSymbolIndex(app),
 
 		&workspaceSymbol{app: app},
 	}
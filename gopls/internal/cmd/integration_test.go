@@ -40,6 +40,7 @@ import (
 	"golang.org/x/tools/gopls/internal/cmd"
 	"golang.org/x/tools/gopls/internal/debug"
 	"golang.org/x/tools/gopls/internal/protocol"
+	protocolcommand "golang.org/x/tools/gopls/internal/protocol/command"
 	"golang.org/x/tools/gopls/internal/util/bug"
 	"golang.org/x/tools/gopls/internal/version"
 	"golang.org/x/tools/internal/testenv"
@@ -1102,6 +1103,50 @@ func someFunctionName()
 	}
 }
 
+func TestSymbolIndex(t *testing.T) {
+	t.Parallel()
+
+	tree := writeTree(t, `
+-- go.mod --
+module example.com
+go 1.18
+
+-- a.go --
+package a
+type SomeTypeName int
+func SomeFunctionName(x int) error { return nil }
+func someUnexportedName()
+`)
+	// no pattern
+	{
+		res := gopls(t, tree, "symbol_index", "query")
+		res.checkExit(false)
+		res.checkStderr("expects 1 argument")
+	}
+	// invalid kind
+	{
+		res := gopls(t, tree, "symbol_index", "query", "-kind=method", "SomeName")
+		res.checkExit(false)
+		res.checkStderr(`invalid symbol kind "method"`)
+	}
+	// success
+	{
+		res := gopls(t, tree, "symbol_index", "query", "-kind=func", "-limit=1", "SomeFunctionName")
+		res.checkExit(true)
+		res.checkStdout(`example.com.SomeFunctionName +func\(x int\) error +.*a.go:3:6-22`)
+	}
+	// JSON
+	{
+		res := gopls(t, tree, "symbol_index", "query", "-json", "-kind=type", "-limit=1", "SomeTypeName")
+		res.checkExit(true)
+		var symbols []protocolcommand.IndexSymbol
+		res.toJSON(&symbols)
+		if len(symbols) != 1 || symbols[0].ImportPath != "example.com" || symbols[0].Kind != "type" || symbols[0].Location == nil {
+			t.Errorf("got %+v, want example.com.SomeTypeName", symbols)
+		}
+	}
+}
+
 func TestCommandLineErrors(t *testing.T) {
 	testenv.NeedsGoBuild(t)
 	t.Parallel()
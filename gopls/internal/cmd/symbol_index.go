@@ -0,0 +1,138 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	protocolcommand "golang.org/x/tools/gopls/internal/protocol/command"
+)
+
+// symbolIndex implements the symbol_index verb for gopls.
+type symbolIndex struct {
+	app *application
+	subcommands
+}
+
+func newSymbolIndex(app *application) *symbolIndex {
+	return &symbolIndex{
+		app: app,
+		subcommands: subcommands{
+			&symbolIndexQuery{app: app},
+		},
+	}
+}
+
+func (s *symbolIndex) Name() string   { return "symbol_index" }
+func (s *symbolIndex) Parent() string { return s.app.Name() }
+func (s *symbolIndex) ShortHelp() string {
+	return "search symbols of the workspace and module cache"
+}
+
+// symbolIndexQuery is a symbol_index subcommand to search symbols by name.
+type symbolIndexQuery struct {
+	Kind  string `flag:"kind" help:"comma-separated list of symbol kinds to report: func, type, var, or const"`
+	Limit int    `flag:"limit" help:"maximum number of results"`
+	JSON  bool   `flag:"json" help:"print the results as JSON"`
+
+	app *application
+}
+
+func (q *symbolIndexQuery) Name() string   { return "query" }
+func (q *symbolIndexQuery) Parent() string { return "gopls symbol_index" }
+func (q *symbolIndexQuery) Usage() string  { return "[query-flags] <pattern>" }
+func (q *symbolIndexQuery) ShortHelp() string {
+	return "print the symbols whose names match a fuzzy pattern"
+}
+
+func (q *symbolIndexQuery) DetailedHelp(f *flag.FlagSet) {
+	fmt.Fprint(f.Output(), `
+The query subcommand searches the exported package-level symbols of
+the workspace packages, and of the index of the module cache used for
+unimported completions, for names that match a fuzzy pattern. For each
+symbol, it prints the qualified name, the signature of a function or
+the kind of another symbol, the version of the providing module, and
+the declaration of a workspace symbol. It requires no network access.
+
+Example:
+
+	$ gopls symbol_index query -kind=func -limit=5 unmarshal
+
+query-flags:
+`)
+	printFlagDefaults(f)
+}
+
+func (q *symbolIndexQuery) Run(ctx context.Context, args ...string) error {
+	if len(args) != 1 {
+		return commandLineErrorf("query expects 1 argument")
+	}
+	if q.Limit < 0 {
+		return commandLineErrorf("negative limit %d", q.Limit)
+	}
+	queryArgs := protocolcommand.QuerySymbolIndexArgs{
+		Query: args[0],
+		Limit: q.Limit,
+	}
+	if q.Kind != "" {
+		queryArgs.Kinds = strings.Split(q.Kind, ",")
+	}
+
+	cli, _, err := q.app.connect(ctx)
+	if err != nil {
+		return err
+	}
+	defer cli.terminate(ctx)
+
+	res, err := executeCommand(ctx, cli.server, protocolcommand.NewQuerySymbolIndexCommand("", queryArgs))
+	if err != nil {
+		return err
+	}
+	// The result may be a Go value or JSON, depending on the server.
+	data, err := json.Marshal(res)
+	if err != nil {
+		return err
+	}
+	var result protocolcommand.QuerySymbolIndexResult
+	if err := json.Unmarshal(data, &result); err != nil {
+		return err
+	}
+
+	if q.JSON {
+		data, err := json.MarshalIndent(result.Symbols, "", "\t")
+		if err != nil {
+			return err
+		}
+		fmt.Printf("%s\n", data)
+		return nil
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 8, 1, ' ', 0)
+	for _, s := range result.Symbols {
+		desc := s.Kind
+		if s.Signature != "" {
+			desc = s.Signature
+		}
+		where := s.Version
+		if s.Location != nil {
+			f, err := cli.openFile(ctx, s.Location.URI)
+			if err != nil {
+				return err
+			}
+			span, err := f.locationSpan(*s.Location)
+			if err != nil {
+				return err
+			}
+			where = strings.TrimSpace(fmt.Sprintf("%s %v", where, span))
+		}
+		fmt.Fprintf(w, "%s.%s\t%s\t%s\n", s.ImportPath, s.Name, desc, where)
+	}
+	return w.Flush()
+}
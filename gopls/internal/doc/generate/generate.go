@@ -253,10 +253,16 @@ func loadOptions(category reflect.Value, optsType types.Object, pkg *packages.Pa
 		// Notable edge cases:
 		// - any (e.g. in linksInHover) is really a sum of false | true | "internal".
 		// - time.Duration is really a string with a particular syntax.
+		// - structs (e.g. in postfixSnippets) are JSON objects.
 		typ := typesField.Type().String()
 		if _, ok := enums[typesField.Type()]; ok {
 			typ = "enum"
 		}
+		if s, ok := typesField.Type().(*types.Slice); ok {
+			if _, ok := s.Elem().Underlying().(*types.Struct); ok {
+				typ = "[]object"
+			}
+		}
 		name := lowerFirst(typesField.Name())
 
 		// enum-keyed maps
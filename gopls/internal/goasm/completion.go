@@ -0,0 +1,313 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package goasm
+
+import (
+	"context"
+	"fmt"
+	"go/build"
+	"go/types"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/tools/gopls/internal/cache"
+	"golang.org/x/tools/gopls/internal/cache/metadata"
+	"golang.org/x/tools/gopls/internal/file"
+	"golang.org/x/tools/gopls/internal/protocol"
+	"golang.org/x/tools/gopls/internal/util/asm"
+	"golang.org/x/tools/internal/event"
+	"golang.org/x/tools/internal/typesinternal"
+)
+
+// Completion handles the textDocument/completion request for Go
+// assembly files.
+//
+// It offers two kinds of completion for the partial word before the
+// cursor:
+//   - a word containing a middle dot, such as ·fo or math∕bits·Len,
+//     is completed with the package-level Go declarations of the
+//     current package or of the named dependency;
+//   - any other word within a TEXT function is completed with the
+//     frame slots, such as x+8(FP), of the arguments and results of
+//     the function's Go declaration.
+func Completion(ctx context.Context, snapshot *cache.Snapshot, fh file.Handle, pos protocol.Position) (*protocol.CompletionList, error) {
+	ctx, done := event.Start(ctx, "goasm.Completion")
+	defer done()
+
+	list := &protocol.CompletionList{Items: []protocol.CompletionItem{}}
+
+	mp, err := snapshot.NarrowestMetadataForFile(ctx, fh.URI())
+	if err != nil {
+		return list, nil // no Go package: nothing to complete
+	}
+	content, err := fh.Content()
+	if err != nil {
+		return nil, err
+	}
+	f := asm.Parse(fh.URI(), content)
+	offset, err := f.Mapper.PositionOffset(pos)
+	if err != nil {
+		return nil, err
+	}
+
+	start := wordStart(content, offset)
+	word := string(content[start:offset])
+
+	var items []protocol.CompletionItem
+	if i := strings.LastIndex(word, "·"); i >= 0 {
+		qual, prefix := word[:i], word[i+len("·"):]
+		pkg, err := symbolPackage(ctx, snapshot, mp, qual)
+		if err != nil {
+			return nil, err
+		}
+		if pkg != nil {
+			rng, err := f.Mapper.OffsetRange(offset-len(prefix), offset)
+			if err != nil {
+				return nil, err
+			}
+			items = symbolCompletions(pkg, qual == "", prefix, rng)
+		}
+	} else {
+		pkgs, err := snapshot.TypeCheck(ctx, mp.ID)
+		if err != nil {
+			return nil, err
+		}
+		fn := enclosingFunc(f, pkgs[0], offset)
+		if fn != nil {
+			rng, err := f.Mapper.OffsetRange(start, offset)
+			if err != nil {
+				return nil, err
+			}
+			arch := frameArch(fh.URI(), snapshot.View().GOARCH())
+			for _, slot := range frameSlots(fn.Signature(), arch) {
+				if !strings.HasPrefix(slot.name, word) {
+					continue
+				}
+				text := fmt.Sprintf("%s+%d(FP)", slot.name, slot.offset)
+				items = append(items, protocol.CompletionItem{
+					Label:    text,
+					Kind:     protocol.VariableCompletion,
+					Detail:   slot.detail,
+					TextEdit: &protocol.Or_CompletionItem_textEdit{Value: protocol.TextEdit{Range: rng, NewText: text}},
+					SortText: fmt.Sprintf("%05d", slot.offset),
+				})
+			}
+		}
+	}
+	if items != nil {
+		list.Items = items
+	}
+	return list, nil
+}
+
+// wordStart returns the offset of the start of the assembly word
+// (identifier, possibly qualified by a package path and middle dot)
+// that ends at offset.
+func wordStart(content []byte, offset int) int {
+	start := offset
+	for start > 0 {
+		r, size := utf8.DecodeLastRune(content[:start])
+		if !(r == '_' || r == '·' || r == '∕' || r == '.' || r == '/' ||
+			unicode.IsLetter(r) || unicode.IsDigit(r)) {
+			break
+		}
+		start -= size
+	}
+	return start
+}
+
+// symbolPackage returns the type-checked package denoted by the
+// qualifier of an assembly symbol: the current package if qual is
+// empty, otherwise the dependency whose path is qual (with ∕ for /
+// and · for .).
+func symbolPackage(ctx context.Context, snapshot *cache.Snapshot, mp *metadata.Package, qual string) (*cache.Package, error) {
+	id := mp.ID
+	if qual != "" {
+		path := metadata.PackagePath(strings.NewReplacer("∕", "/", "·", ".").Replace(qual))
+		id = ""
+		for dep := range snapshot.MetadataGraph().ForwardReflexiveTransitiveClosure(mp.ID) {
+			if dep.PkgPath == path {
+				id = dep.ID
+				break
+			}
+		}
+		if id == "" {
+			return nil, nil
+		}
+	}
+	pkgs, err := snapshot.TypeCheck(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return pkgs[0], nil
+}
+
+// symbolCompletions returns completion items for the package-level
+// declarations of pkg whose names start with prefix. Unexported names
+// are offered only within the current package.
+func symbolCompletions(pkg *cache.Package, local bool, prefix string, rng protocol.Range) []protocol.CompletionItem {
+	qual := typesinternal.NameRelativeTo(pkg.Types())
+	scope := pkg.Types().Scope()
+	var items []protocol.CompletionItem
+	for _, name := range scope.Names() { // sorted
+		obj := scope.Lookup(name)
+		if !strings.HasPrefix(name, prefix) || !local && !obj.Exported() {
+			continue
+		}
+		var kind protocol.CompletionItemKind
+		switch obj.(type) {
+		case *types.Func:
+			kind = protocol.FunctionCompletion
+		case *types.Var:
+			kind = protocol.VariableCompletion
+		case *types.Const:
+			kind = protocol.ConstantCompletion
+		case *types.TypeName:
+			kind = protocol.ClassCompletion
+		default:
+			continue
+		}
+		items = append(items, protocol.CompletionItem{
+			Label:    name,
+			Kind:     kind,
+			Detail:   types.ObjectString(obj, qual),
+			TextEdit: &protocol.Or_CompletionItem_textEdit{Value: protocol.TextEdit{Range: rng, NewText: name}},
+		})
+	}
+	return items
+}
+
+// enclosingFunc returns the Go declaration, in pkg, of the TEXT
+// function of f that encloses offset, or nil if there is none.
+func enclosingFunc(f *asm.File, pkg *cache.Package, offset int) *types.Func {
+	lo, hi := f.FunctionRange(offset)
+	for _, id := range f.Idents {
+		if id.Kind != asm.Text || id.Offset < lo || id.Offset >= hi {
+			continue
+		}
+		name, ok := strings.CutPrefix(id.Name, ".")
+		if !ok {
+			return nil // not a symbol of the current package
+		}
+		fn, _ := pkg.Types().Scope().Lookup(name).(*types.Func)
+		return fn
+	}
+	return nil
+}
+
+// frameArch returns the architecture whose frame layout applies to an
+// assembly file: the GOARCH suffix of its name, if any, or else def.
+func frameArch(uri protocol.DocumentURI, def string) string {
+	base := strings.TrimSuffix(uri.Base(), ".s")
+	if i := strings.LastIndexByte(base, '_'); i >= 0 {
+		if arch := base[i+1:]; types.SizesFor("gc", arch) != nil {
+			return arch
+		}
+	}
+	if def == "" {
+		def = build.Default.GOARCH
+	}
+	return def
+}
+
+// A frameSlot is a named argument or result slot of the frame of an
+// assembly function, or a component of one such as s_len.
+type frameSlot struct {
+	name   string
+	offset int
+	detail string
+}
+
+// frameSlots returns the frame slots of a function with signature sig
+// on the given architecture, following the ABI0 layout and naming
+// conventions checked by the asmdecl analyzer: unnamed parameters are
+// called arg, arg1, ... and unnamed results ret, ret1, ...; multiword
+// values have components such as s_base, s_len and s_cap.
+func frameSlots(sig *types.Signature, arch string) []frameSlot {
+	sizes := types.SizesFor("gc", arch)
+	if sizes == nil {
+		return nil
+	}
+	ptrSize := int(sizes.Sizeof(types.Typ[types.UnsafePointer]))
+	intSize := int(sizes.Sizeof(types.Typ[types.Int]))
+	maxAlign := int(sizes.Alignof(types.Typ[types.Int64]))
+
+	var (
+		slots  []frameSlot
+		offset int
+	)
+	var components func(name string, t types.Type, off int)
+	components = func(name string, t types.Type, off int) {
+		slots = append(slots, frameSlot{name, off, t.String()})
+		switch u := t.Underlying().(type) {
+		case *types.Basic:
+			switch u.Kind() {
+			case types.String:
+				slots = append(slots,
+					frameSlot{name + "_base", off, "string base"},
+					frameSlot{name + "_len", off + ptrSize, "string len"})
+			case types.Complex64, types.Complex128:
+				half := int(sizes.Sizeof(t)) / 2
+				slots = append(slots,
+					frameSlot{name + "_real", off, fmt.Sprintf("real(%s)", u)},
+					frameSlot{name + "_imag", off + half, fmt.Sprintf("imag(%s)", u)})
+			}
+		case *types.Slice:
+			slots = append(slots,
+				frameSlot{name + "_base", off, "slice base"},
+				frameSlot{name + "_len", off + ptrSize, "slice len"},
+				frameSlot{name + "_cap", off + ptrSize + intSize, "slice cap"})
+		case *types.Interface:
+			first := "_itable"
+			if u.Empty() {
+				first = "_type"
+			}
+			slots = append(slots,
+				frameSlot{name + first, off, "interface " + first[1:]},
+				frameSlot{name + "_data", off + ptrSize, "interface data"})
+		case *types.Struct:
+			fields := make([]*types.Var, u.NumFields())
+			for i := range fields {
+				fields[i] = u.Field(i)
+			}
+			offsets := sizes.Offsetsof(fields)
+			for i, f := range fields {
+				components(name+"_"+f.Name(), f.Type(), off+int(offsets[i]))
+			}
+		case *types.Array:
+			elemSize := int(sizes.Sizeof(types.NewArray(u.Elem(), 2))) / 2
+			for i := range int(u.Len()) {
+				components(name+"_"+strconv.Itoa(i), u.Elem(), off+i*elemSize)
+			}
+		}
+	}
+	add := func(tuple *types.Tuple, unnamed string) {
+		for i := range tuple.Len() {
+			v := tuple.At(i)
+			t := v.Type()
+			align := int(sizes.Alignof(t))
+			offset += -offset & (align - 1)
+			name := v.Name()
+			if name == "" {
+				name = unnamed
+				if i > 0 {
+					name += strconv.Itoa(i)
+				}
+			}
+			components(name, t, offset)
+			offset += int(sizes.Sizeof(t))
+		}
+	}
+	add(sig.Params(), "arg")
+	if sig.Results().Len() > 0 {
+		offset += -offset & (maxAlign - 1)
+		add(sig.Results(), "ret")
+	}
+	sort.SliceStable(slots, func(i, j int) bool { return slots[i].offset < slots[j].offset })
+	return slots
+}
@@ -0,0 +1,150 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package goasm
+
+import (
+	"context"
+	"go/token"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/passes/asmdecl"
+	"golang.org/x/tools/go/analysis/passes/framepointer"
+	"golang.org/x/tools/gopls/internal/cache"
+	"golang.org/x/tools/gopls/internal/cache/metadata"
+	"golang.org/x/tools/gopls/internal/file"
+	"golang.org/x/tools/gopls/internal/protocol"
+	"golang.org/x/tools/gopls/internal/util/safetoken"
+	"golang.org/x/tools/internal/event"
+)
+
+// analyzers are the analyzers whose diagnostics are reported in
+// assembly files. They must not require other analyzers or facts.
+var analyzers = []*analysis.Analyzer{
+	asmdecl.Analyzer,
+	framepointer.Analyzer,
+}
+
+// Diagnostics computes diagnostics for the open assembly files of the
+// snapshot by running the asmdecl and framepointer analyzers on their
+// packages. Unlike other analysis diagnostics, these are computed on
+// demand against the current (unsaved) file contents.
+func Diagnostics(ctx context.Context, snapshot *cache.Snapshot) (map[protocol.DocumentURI][]*cache.Diagnostic, error) {
+	ctx, done := event.Start(ctx, "goasm.Diagnostics")
+	defer done()
+
+	// Group open assembly files by package.
+	var (
+		order   []*metadata.Package
+		byPkg   = make(map[metadata.PackageID][]file.Handle)
+		reports = make(map[protocol.DocumentURI][]*cache.Diagnostic)
+	)
+	for _, o := range snapshot.Overlays() {
+		if snapshot.FileKind(o) != file.Asm {
+			continue
+		}
+		reports[o.URI()] = nil // clear stale diagnostics
+		mp, err := snapshot.NarrowestMetadataForFile(ctx, o.URI())
+		if err != nil {
+			continue // assembly file outside any package
+		}
+		if _, ok := byPkg[mp.ID]; !ok {
+			order = append(order, mp)
+		}
+		byPkg[mp.ID] = append(byPkg[mp.ID], o)
+	}
+
+	for _, mp := range order {
+		pkgs, err := snapshot.TypeCheck(ctx, mp.ID)
+		if err != nil {
+			return nil, err
+		}
+		for _, fh := range byPkg[mp.ID] {
+			diags, err := diagnoseFile(pkgs[0], fh)
+			if err != nil {
+				return nil, err
+			}
+			reports[fh.URI()] = diags
+		}
+	}
+	return reports, nil
+}
+
+// diagnoseFile runs the analyzers on a single assembly file of pkg.
+func diagnoseFile(pkg *cache.Package, fh file.Handle) ([]*cache.Diagnostic, error) {
+	content, err := fh.Content()
+	if err != nil {
+		return nil, err
+	}
+	mapper := protocol.NewMapper(fh.URI(), content)
+
+	// The analyzers add the assembly file to the FileSet. Use a
+	// private FileSet, whose first file lies beyond all positions of
+	// the package's FileSet, so that positions in the Go files (which
+	// we don't report) cannot be confused with positions in the
+	// assembly file, and so that the shared FileSet is not mutated.
+	fset := token.NewFileSet()
+	fset.AddFile("", pkg.FileSet().Base(), 0)
+
+	var diags []*cache.Diagnostic
+	for _, a := range analyzers {
+		pass := &analysis.Pass{
+			Analyzer:   a,
+			Fset:       fset,
+			Files:      pkg.Syntax(),
+			OtherFiles: []string{fh.URI().Path()},
+			Pkg:        pkg.Types(),
+			TypesInfo:  pkg.TypesInfo(),
+			TypesSizes: pkg.TypesSizes(),
+			ResultOf:   map[*analysis.Analyzer]any{},
+			ReadFile: func(filename string) ([]byte, error) {
+				return content, nil
+			},
+			Report: func(d analysis.Diagnostic) {
+				tf := fset.File(d.Pos)
+				if tf == nil || tf.Name() != fh.URI().Path() {
+					return // not in the assembly file
+				}
+				line := safetoken.Line(tf, d.Pos)
+				rng, err := lineRange(mapper, tf, line)
+				if err != nil {
+					return
+				}
+				diags = append(diags, &cache.Diagnostic{
+					URI:      fh.URI(),
+					Range:    rng,
+					Severity: protocol.SeverityWarning,
+					Source:   cache.DiagnosticSource(a.Name),
+					Code:     a.Name,
+					CodeHref: a.URL,
+					Message:  d.Message,
+				})
+			},
+		}
+		if _, err := a.Run(pass); err != nil {
+			// Analyzer errors (e.g. an unknown architecture)
+			// are not the user's concern.
+			continue
+		}
+	}
+	return diags, nil
+}
+
+// lineRange returns the range of the (1-based) line of tf, excluding
+// the newline.
+func lineRange(m *protocol.Mapper, tf *token.File, line int) (protocol.Range, error) {
+	start, err := safetoken.Offset(tf, tf.LineStart(line))
+	if err != nil {
+		return protocol.Range{}, err
+	}
+	end := len(m.Content)
+	if line < tf.LineCount() {
+		next, err := safetoken.Offset(tf, tf.LineStart(line+1))
+		if err != nil {
+			return protocol.Range{}, err
+		}
+		end = next - len("\n")
+	}
+	return m.OffsetRange(start, end)
+}
@@ -731,7 +731,7 @@ func AddTestForFunc(ctx context.Context, snapshot *cache.Snapshot, loc protocol.
 				FixType: imports.AddImport,
 			})
 		}
-		importEdits, err := ComputeImportFixEdits(snapshot.Options().Local, testPGF.Src, importFixes...)
+		importEdits, err := ComputeImportFixEdits(snapshot.Options(), pkg.Metadata(), testPGF.Src, importFixes...)
 		if err != nil {
 			return nil, nil, fmt.Errorf("could not compute the import fix edits: %w", err)
 		}
@@ -7,6 +7,7 @@ package golang
 import (
 	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"go/ast"
 	"go/format"
@@ -14,12 +15,16 @@ import (
 	"go/token"
 	"go/types"
 	"regexp"
+	"slices"
+	"strings"
 
 	goastutil "golang.org/x/tools/go/ast/astutil"
+	"golang.org/x/tools/go/ast/inspector"
 	"golang.org/x/tools/gopls/internal/cache"
 	"golang.org/x/tools/gopls/internal/cache/parsego"
 	"golang.org/x/tools/gopls/internal/file"
 	"golang.org/x/tools/gopls/internal/protocol"
+	"golang.org/x/tools/gopls/internal/protocol/command"
 	"golang.org/x/tools/gopls/internal/util/bug"
 	"golang.org/x/tools/gopls/internal/util/safetoken"
 	"golang.org/x/tools/gopls/internal/util/tokeninternal"
@@ -105,6 +110,24 @@ import (
 // to teach the inliner to recognize when a variable is redundant (r1 and r2,
 // in this case), lifting declarations. That's probably a very useful skill for
 // the inliner to have.
+//
+// Until it does, changes to results are applied directly: the wrapper has the
+// new results, and after each call is inlined, the statement that receives
+// the results of the delegated call (such as x, y := Foo0()) is updated to
+// match (see updateResultUses). The return statements of the function itself
+// are updated when its declaration is rewritten (see rewriteDecl).
+//
+// Other changes fit the wrapper naturally. To add a parameter, the wrapper
+// passes its default value to the delegate, and the inliner substitutes this
+// value at each call site. To convert a function to a method, the wrapper
+// calls the delegate as a method of one of its parameters:
+//
+// 	func Foo1(a int, t *T) {
+// 		t.Foo0(a)
+// 	}
+//
+// and to convert a method to a function, it passes its receiver as an
+// argument.
 
 // removeParam computes a refactoring to remove the parameter indicated by the
 // given range.
@@ -119,32 +142,53 @@ func removeParam(ctx context.Context, snapshot *cache.Snapshot, fh file.Handle,
 		return nil, fmt.Errorf("no param found")
 	}
 	// Write a transformation to remove the param.
-	var newParams []int
+	var newParams []command.ChangeSignatureParam
 	for i := 0; i < info.decl.Type.Params.NumFields(); i++ {
 		if i != info.paramIndex {
-			newParams = append(newParams, i)
+			newParams = append(newParams, command.ChangeSignatureParam{OldIndex: i})
 		}
 	}
-	return ChangeSignature(ctx, snapshot, pkg, pgf, rng, newParams)
+	return ChangeSignature(ctx, snapshot, pkg, pgf, rng, SignatureChange{Params: newParams})
+}
+
+// A SignatureChange describes a change to the signature of a function or
+// method. See [ChangeSignature].
+type SignatureChange struct {
+	// Params describes the parameters of the new signature, as in
+	// [command.ChangeSignatureArgs]. For a method, the old index -1
+	// denotes the receiver; using it converts the method to a function.
+	Params []command.ChangeSignatureParam
+
+	// Results describes the results of the new signature.
+	// If nil, the results are unchanged.
+	Results []command.ChangeSignatureParam
+
+	// Recv, if non-nil, is the index of the old parameter that becomes
+	// the receiver of the new signature, converting a function to a
+	// method. For a method, the index -1 (its own receiver) is a no-op.
+	Recv *int
 }
 
 // ChangeSignature computes a refactoring to update the signature according to
-// the provided parameter transformation, for the signature definition
-// surrounding rng.
+// the provided change, for the signature definition surrounding rng.
 //
-// newParams expresses the new parameters for the signature in terms of the old
-// parameters. Each entry in newParams is the index of the new parameter in the
-// original parameter list. For example, given func Foo(a, b, c int) and newParams
-// [2, 0, 1], the resulting changed signature is Foo(c, a, b int). If newParams
-// omits an index of the original signature, that parameter is removed.
+// The new parameters and results are expressed in terms of the old ones (see
+// [command.ChangeSignatureArgs]). For example, given func Foo(a, b, c int)
+// and new parameters [2, 0, 1], the resulting changed signature is
+// Foo(c, a, b int). If the new parameters omit an index of the original
+// signature, that parameter is removed. A new parameter such as "d int = 1"
+// is added, and every call passes it the given value (or the zero value of
+// its type, if none is given). Similarly, a new result such as "err error"
+// is added to every return statement, and a removed result is removed from
+// every return statement; in both cases, assignments from calls are updated
+// accordingly.
 //
 // This operation is a work in progress. Remaining TODO:
-//   - Handle adding parameters.
-//   - Handle adding/removing/reordering results.
+//   - Handle calls whose results are used in an expression.
 //   - Improve the extra newlines in output.
 //   - Stream type checking via ForEachPackage.
 //   - Avoid unnecessary additional type checking.
-func ChangeSignature(ctx context.Context, snapshot *cache.Snapshot, pkg *cache.Package, pgf *parsego.File, rng protocol.Range, newParams []int) ([]protocol.DocumentChange, error) {
+func ChangeSignature(ctx context.Context, snapshot *cache.Snapshot, pkg *cache.Package, pgf *parsego.File, rng protocol.Range, change SignatureChange) ([]protocol.DocumentChange, error) {
 	// Changes to our heuristics for whether we can remove a parameter must also
 	// be reflected in the canRemoveParameter helper.
 	if perrors, terrors := pkg.ParseErrors(), pkg.TypeErrors(); len(perrors) > 0 || len(terrors) > 0 {
@@ -161,72 +205,136 @@ func ChangeSignature(ctx context.Context, snapshot *cache.Snapshot, pkg *cache.P
 	if info == nil || info.decl == nil {
 		return nil, fmt.Errorf("failed to find declaration")
 	}
+	decl := info.decl
 
 	// Step 1: create the new declaration, which is a copy of the original decl
 	// with the rewritten signature.
 
 	// Flatten, transform and regroup fields, using the flatField intermediate
-	// representation. A flatField is the result of flattening an *ast.FieldList
-	// along with type information.
-	type flatField struct {
-		name     string // empty if the field is unnamed
-		typeExpr ast.Expr
-		typ      types.Type
+	// representation.
+	oldParams, err := flattenFields(pkg.TypesInfo(), decl.Type.Params)
+	if err != nil {
+		return nil, err
+	}
+	oldResults, err := flattenFields(pkg.TypesInfo(), decl.Type.Results)
+	if err != nil {
+		return nil, err
+	}
+	var oldRecv *flatField
+	if decl.Recv.NumFields() > 0 {
+		recv, err := flattenFields(pkg.TypesInfo(), decl.Recv)
+		if err != nil {
+			return nil, err
+		}
+		oldRecv = &recv[0]
+		oldRecv.old = -1
 	}
 
-	var newParamFields []flatField
-	for id, field := range astutil.FlatFields(info.decl.Type.Params) {
-		typ := pkg.TypesInfo().TypeOf(field.Type)
-		if typ == nil {
-			return nil, fmt.Errorf("missing field type for field #%d", len(newParamFields))
+	// Select the new parameter and result fields.
+	newParams, err := selectFields(pkg, pgf, decl, change.Params, oldParams, oldRecv)
+	if err != nil {
+		return nil, fmt.Errorf("invalid parameters: %v", err)
+	}
+	newResults := oldResults
+	if change.Results != nil {
+		newResults, err = selectFields(pkg, pgf, decl, change.Results, oldResults, nil)
+		if err != nil {
+			return nil, fmt.Errorf("invalid results: %v", err)
 		}
-		field := flatField{
-			typeExpr: field.Type,
-			typ:      typ,
+	}
+
+	// Determine the new receiver, if any.
+	toFunc := slices.ContainsFunc(newParams, func(f flatField) bool { return !f.isNew() && f.old == -1 })
+	var newRecv *flatField
+	switch {
+	case change.Recv != nil && oldRecv != nil:
+		if *change.Recv != -1 || toFunc {
+			return nil, fmt.Errorf("%s is already a method", decl.Name.Name)
 		}
-		if id != nil {
-			field.name = id.Name
+		newRecv = oldRecv
+
+	case change.Recv != nil:
+		i := *change.Recv
+		if i < 0 || i >= len(oldParams) {
+			return nil, fmt.Errorf("invalid receiver index %d", i)
 		}
-		newParamFields = append(newParamFields, field)
+		if slices.ContainsFunc(newParams, func(f flatField) bool { return !f.isNew() && f.old == i }) {
+			return nil, fmt.Errorf("parameter %d cannot be both a parameter and the receiver", i)
+		}
+		if err := checkToMethod(pkg, decl, oldParams[i].typ); err != nil {
+			return nil, err
+		}
+		newRecv = &oldParams[i]
+
+	case toFunc:
+		if err := checkToFunc(pkg, decl); err != nil {
+			return nil, err
+		}
+
+	default:
+		newRecv = oldRecv
 	}
 
-	// Select the new parameter fields.
-	newParamFields, ok := selectElements(newParamFields, newParams)
-	if !ok {
-		return nil, fmt.Errorf("failed to apply parameter transformation %v", newParams)
+	// A variadic parameter must remain last.
+	if decl.Type.Params.NumFields() > 0 {
+		last := decl.Type.Params.List[len(decl.Type.Params.List)-1]
+		if _, ok := last.Type.(*ast.Ellipsis); ok {
+			i := slices.IndexFunc(newParams, func(f flatField) bool { return !f.isNew() && f.old == len(oldParams)-1 })
+			if i >= 0 && i != len(newParams)-1 {
+				return nil, fmt.Errorf("variadic parameter must be last")
+			}
+		}
 	}
 
-	// writeFields performs the regrouping of named fields.
-	writeFields := func(flatFields []flatField) *ast.FieldList {
-		list := new(ast.FieldList)
-		for i, f := range flatFields {
-			var field *ast.Field
-			if i > 0 && f.name != "" && flatFields[i-1].name != "" && types.Identical(f.typ, flatFields[i-1].typ) {
-				// Group named fields if they have the same type.
-				field = list.List[len(list.List)-1]
-			} else {
-				// Otherwise, create a new field.
-				field = &ast.Field{
-					Type: astutil.CloneNode(f.typeExpr),
-				}
-				list.List = append(list.List, field)
+	// Check that the names of the new signature are distinct, and that
+	// the names of new fields don't capture references within the body.
+	{
+		all := slices.Concat(newParams, newResults)
+		if newRecv != nil {
+			all = append(all, *newRecv)
+		}
+		seen := make(map[string]bool)
+		for _, f := range all {
+			if f.name == "" || f.name == "_" {
+				continue
 			}
-			if f.name != "" {
-				field.Names = append(field.Names, ast.NewIdent(f.name))
+			if seen[f.name] {
+				return nil, fmt.Errorf("duplicate name %s in new signature", f.name)
+			}
+			seen[f.name] = true
+			if f.isNew() {
+				if err := checkNewName(pkg.TypesInfo(), decl, f.name); err != nil {
+					return nil, err
+				}
 			}
 		}
-		return list
 	}
 
-	newDecl := astutil.CloneNode(info.decl)
-	newDecl.Type.Params = writeFields(newParamFields)
+	newDecl := astutil.CloneNode(decl)
+	newDecl.Type.Params = writeFields(newParams)
+	newDecl.Recv = nil
+	if newRecv != nil {
+		newDecl.Recv = writeFields([]flatField{*newRecv})
+	}
+
+	// If the results change, plan the changes to the return statements.
+	var results *resultsChange
+	if !isIdentity(newResults, len(oldResults)) {
+		newDecl.Type.Results = writeFields(newResults)
+		results, err = planReturns(pkg, pgf, decl, oldResults, newResults)
+		if err != nil {
+			return nil, err
+		}
+	}
 
 	// Step 2: build a wrapper function calling the new declaration.
 
 	var (
-		params   = astutil.CloneNode(info.decl.Type.Params) // parameters of wrapper func: "_" names must be modified
-		args     = make([]ast.Expr, len(newParams))         // arguments to the delegated call
-		variadic = false                                    // whether the signature is variadic
+		recv     = astutil.CloneNode(decl.Recv)        // receiver of wrapper func: "_" name must be modified
+		params   = astutil.CloneNode(decl.Type.Params) // parameters of wrapper func: "_" names must be modified
+		args     = make([]ast.Expr, len(newParams))    // arguments to the delegated call
+		callRecv ast.Expr                              // receiver of the delegated call, if a method
+		variadic = false                               // whether the signature is variadic
 	)
 	{
 		// Record names used by non-blank parameters, just in case the user had a
@@ -246,25 +354,50 @@ func ChangeSignature(ctx context.Context, snapshot *cache.Snapshot, pkg *cache.P
 				fld.Names = append(fld.Names, ast.NewIdent("_")) // will be named below
 			}
 		}
-		// oldParams maps parameters to their argument in the delegated call.
-		// In other words, it is the inverse of newParams, but it is represented as
-		// a map rather than a slice, as not every old param need exist in
-		// newParams.
-		oldParams := make(map[int]int)
-		for new, old := range newParams {
-			oldParams[old] = new
+
+		// Get the receiver name, creating it if necessary.
+		var recvName string
+		if recv.NumFields() > 0 {
+			if names := recv.List[0].Names; len(names) > 0 && names[0].Name != "_" {
+				recvName = names[0].Name
+			} else {
+				// Create unique name for the temporary receiver, which will be inlined away.
+				//
+				// We use the lexical scope of the original function to avoid conflicts
+				// with (e.g.) named result variables. However, since the parameter syntax
+				// may have been modified/renamed from the original function, we must
+				// reject those names too.
+				scope := pkg.TypesInfo().Scopes[decl.Type]
+				if scope == nil {
+					return nil, bug.Errorf("missing function scope for %v", decl.Name.Name)
+				}
+				for i := 0; ; i++ {
+					recvName = fmt.Sprintf("r%d", i)
+					_, obj := scope.LookupParent(recvName, token.NoPos)
+					if obj == nil && !nonBlankNames[recvName] {
+						break
+					}
+				}
+				recv.List[0].Names = []*ast.Ident{{Name: recvName}}
+			}
+			nonBlankNames[recvName] = true
+		}
+
+		// Name the blank (_) parameters, so the delegating wrapper can refer to
+		// them.
+		used := make(map[int]bool) // old parameters used by the delegated call
+		for _, f := range newParams {
+			if !f.isNew() {
+				used[f.old] = true
+			}
+		}
+		if newRecv != nil {
+			used[newRecv.old] = true
 		}
+		var paramNames []string // name of each old parameter in the wrapper
 		blanks := 0
-		paramIndex := 0 // global param index.
-		for id, field := range astutil.FlatFields(params) {
-			argIndex, ok := oldParams[paramIndex]
-			paramIndex++
-			if !ok {
-				continue // parameter is removed
-			}
-			if id.Name == "_" { // from above: every field has names
-				// Create names for blank (_) parameters so the delegating wrapper
-				// can refer to them.
+		for id := range astutil.FlatFields(params) {
+			if id.Name == "_" && used[len(paramNames)] { // from above: every field has names
 				for {
 					// These names will not be seen by the user, so give them an
 					// arbitrary name.
@@ -276,10 +409,36 @@ func ChangeSignature(ctx context.Context, snapshot *cache.Snapshot, pkg *cache.P
 					}
 				}
 			}
-			args[argIndex] = ast.NewIdent(id.Name)
-			// Record whether the call has an ellipsis.
-			// (Only the last loop iteration matters.)
-			_, variadic = field.Type.(*ast.Ellipsis)
+			paramNames = append(paramNames, id.Name)
+		}
+
+		for i, f := range newParams {
+			switch {
+			case f.isNew():
+				// The value is evaluated in the scope of the wrapper, so it must
+				// not refer to any of its parameters.
+				for _, name := range freeNames(f.value) {
+					if slices.Contains(paramNames, name) || name == recvName {
+						return nil, fmt.Errorf("value %s of new parameter refers to %s, which is shadowed by a parameter", f.value, name)
+					}
+				}
+				args[i] = ast.NewIdent(f.value) // (an expression, formatted as text)
+			case f.old == -1:
+				args[i] = ast.NewIdent(recvName)
+			default:
+				args[i] = ast.NewIdent(paramNames[f.old])
+			}
+		}
+		if newRecv != nil {
+			if newRecv.old == -1 {
+				callRecv = ast.NewIdent(recvName)
+			} else {
+				callRecv = ast.NewIdent(paramNames[newRecv.old])
+			}
+		}
+		if n := len(newParams); n > 0 {
+			last := newParams[n-1]
+			variadic = !last.isNew() && last.old >= 0 && is[*ast.Ellipsis](last.typeExpr)
 		}
 	}
 
@@ -290,11 +449,14 @@ func ChangeSignature(ctx context.Context, snapshot *cache.Snapshot, pkg *cache.P
 		snapshot: snapshot,
 		pkg:      pkg,
 		pgf:      pgf,
-		origDecl: info.decl,
+		origDecl: decl,
 		newDecl:  newDecl,
+		recv:     recv,
 		params:   params,
+		callRecv: callRecv,
 		callArgs: args,
 		variadic: variadic,
+		results:  results,
 	})
 	if err != nil {
 		return nil, err
@@ -305,7 +467,7 @@ func ChangeSignature(ctx context.Context, snapshot *cache.Snapshot, pkg *cache.P
 	// of the inlining should have changed the location of the original
 	// declaration.
 	{
-		idx := findDecl(pgf.File, info.decl)
+		idx := findDecl(pgf.File, decl)
 		if idx < 0 {
 			return nil, bug.Errorf("didn't find original decl")
 		}
@@ -315,7 +477,7 @@ func ChangeSignature(ctx context.Context, snapshot *cache.Snapshot, pkg *cache.P
 			src = pgf.Src
 		}
 		fset := tokeninternal.FileSetFor(pgf.Tok)
-		src, err := rewriteSignature(fset, idx, src, newDecl)
+		src, err := rewriteSignature(fset, idx, src, newDecl, results)
 		if err != nil {
 			return nil, err
 		}
@@ -345,8 +507,396 @@ func ChangeSignature(ctx context.Context, snapshot *cache.Snapshot, pkg *cache.P
 	return changes, nil
 }
 
-// rewriteSignature rewrites the signature of the declIdx'th declaration in src
-// to use the signature of newDecl (described by fset).
+// A flatField is a single parameter or result of a signature: the result of
+// flattening an *ast.FieldList along with type information.
+type flatField struct {
+	name     string // empty if the field is unnamed
+	typeExpr ast.Expr
+	typ      types.Type
+	old      int    // index of the field in the old signature (-1 for the receiver)
+	value    string // for a new field, its value in calls or return statements
+	explicit bool   // for a new field, whether value was given explicitly
+}
+
+// isNew reports whether the field is not in the old signature.
+func (f flatField) isNew() bool { return f.value != "" }
+
+// flattenFields returns the flattened fields of list.
+func flattenFields(info *types.Info, list *ast.FieldList) ([]flatField, error) {
+	var fields []flatField
+	for id, field := range astutil.FlatFields(list) {
+		typ := info.TypeOf(field.Type)
+		if typ == nil {
+			return nil, fmt.Errorf("missing field type for field #%d", len(fields))
+		}
+		f := flatField{
+			typeExpr: field.Type,
+			typ:      typ,
+			old:      len(fields),
+		}
+		if id != nil {
+			f.name = id.Name
+		}
+		fields = append(fields, f)
+	}
+	return fields, nil
+}
+
+// selectFields returns the fields described by spec, which refers to the
+// old fields by index (or, if recv is non-nil, to recv by the index -1) and
+// describes new fields of the function declaration decl.
+func selectFields(pkg *cache.Package, pgf *parsego.File, decl *ast.FuncDecl, spec []command.ChangeSignatureParam, old []flatField, recv *flatField) ([]flatField, error) {
+	var (
+		fields []flatField
+		seen   = make(map[int]bool)
+	)
+	for _, elem := range spec {
+		if elem.NewField != "" {
+			f, err := parseNewField(pkg, pgf, decl, elem.NewField)
+			if err != nil {
+				return nil, err
+			}
+			fields = append(fields, f)
+			continue
+		}
+		i := elem.OldIndex
+		if seen[i] {
+			return nil, fmt.Errorf("index %d used more than once", i)
+		}
+		seen[i] = true
+		switch {
+		case i == -1 && recv != nil:
+			fields = append(fields, *recv)
+		case i >= 0 && i < len(old):
+			fields = append(fields, old[i])
+		default:
+			return nil, fmt.Errorf("index %d out of range", i)
+		}
+	}
+	return fields, nil
+}
+
+// parseNewField parses the description of a new field of the function
+// declaration decl, such as "x int" or "x int = 1", in which the type and
+// value are resolved in the scope of the file. If no value is given, the
+// value is the zero value of the type.
+func parseNewField(pkg *cache.Package, pgf *parsego.File, decl *ast.FuncDecl, desc string) (flatField, error) {
+	fieldText, valueText, explicit := strings.Cut(desc, "=")
+	fset := token.NewFileSet()
+	expr, err := parser.ParseExprFrom(fset, "", "func("+fieldText+")", 0)
+	if err != nil {
+		return flatField{}, fmt.Errorf("invalid field %q: %v", desc, err)
+	}
+	ftype, ok := expr.(*ast.FuncType)
+	if !ok || len(ftype.Params.List) != 1 || len(ftype.Params.List[0].Names) > 1 {
+		return flatField{}, fmt.Errorf("invalid field %q: want an optional name and a type", desc)
+	}
+	field := ftype.Params.List[0]
+	if is[*ast.Ellipsis](field.Type) {
+		return flatField{}, fmt.Errorf("invalid field %q: new variadic fields are not supported", desc)
+	}
+	typeText := FormatNode(fset, field.Type)
+	tv, err := types.Eval(pkg.FileSet(), pkg.Types(), decl.Pos(), typeText)
+	if err != nil {
+		return flatField{}, fmt.Errorf("invalid type in field %q: %v", desc, err)
+	}
+	if !tv.IsType() {
+		return flatField{}, fmt.Errorf("invalid field %q: %s is not a type", desc, typeText)
+	}
+	f := flatField{
+		// The type and value are formatted as text, which is free of
+		// positions from an unrelated file.
+		typeExpr: ast.NewIdent(typeText),
+		typ:      tv.Type,
+		explicit: explicit,
+	}
+	if len(field.Names) > 0 {
+		f.name = field.Names[0].Name
+	}
+	if explicit {
+		valueText = strings.TrimSpace(valueText)
+		v, err := types.Eval(pkg.FileSet(), pkg.Types(), decl.Pos(), valueText)
+		if err != nil {
+			return flatField{}, fmt.Errorf("invalid value in field %q: %v", desc, err)
+		}
+		if !v.IsValue() || !types.AssignableTo(v.Type, f.typ) {
+			return flatField{}, fmt.Errorf("invalid field %q: %s is not a value of type %s", desc, valueText, typeText)
+		}
+		f.value = valueText
+	} else {
+		zero, ok := typesinternal.ZeroString(f.typ, typesinternal.FileQualifier(pgf.File, pkg.Types()))
+		if !ok {
+			return flatField{}, fmt.Errorf("field %q requires a value", desc)
+		}
+		f.value = zero
+	}
+	return f, nil
+}
+
+// writeFields performs the regrouping of named fields.
+func writeFields(flatFields []flatField) *ast.FieldList {
+	// If any field is named, all must be.
+	named := slices.ContainsFunc(flatFields, func(f flatField) bool { return f.name != "" })
+	list := new(ast.FieldList)
+	for i, f := range flatFields {
+		name := f.name
+		if named && name == "" {
+			name = "_"
+		}
+		var field *ast.Field
+		if i > 0 && named && types.Identical(f.typ, flatFields[i-1].typ) {
+			// Group named fields if they have the same type.
+			field = list.List[len(list.List)-1]
+		} else {
+			// Otherwise, create a new field.
+			field = &ast.Field{
+				Type: astutil.CloneNode(f.typeExpr),
+			}
+			list.List = append(list.List, field)
+		}
+		if name != "" {
+			field.Names = append(field.Names, ast.NewIdent(name))
+		}
+	}
+	return list
+}
+
+// isIdentity reports whether fields are the n old fields, in order.
+func isIdentity(fields []flatField, n int) bool {
+	if len(fields) != n {
+		return false
+	}
+	for i, f := range fields {
+		if f.isNew() || f.old != i {
+			return false
+		}
+	}
+	return true
+}
+
+// checkNewName reports an error if name, a new parameter or result of decl,
+// would capture a reference to another object within its body.
+func checkNewName(info *types.Info, decl *ast.FuncDecl, name string) error {
+	if scope := info.Scopes[decl.Type]; scope != nil && scope.Lookup(name) != nil {
+		return fmt.Errorf("new name %s conflicts with an existing declaration of %s", name, decl.Name.Name)
+	}
+	var err error
+	ast.Inspect(decl.Body, func(n ast.Node) bool {
+		if id, ok := n.(*ast.Ident); ok && id.Name == name && err == nil {
+			if obj := info.Uses[id]; obj != nil && !(decl.Pos() <= obj.Pos() && obj.Pos() < decl.End()) {
+				err = fmt.Errorf("new name %s would shadow a reference to %s in the body of %s", name, name, decl.Name.Name)
+			}
+		}
+		return err == nil
+	})
+	return err
+}
+
+// freeNames returns the names of identifiers referenced by the expression
+// text, excluding field and method selectors.
+func freeNames(text string) []string {
+	expr, err := parser.ParseExpr(text)
+	if err != nil {
+		return nil // (already type-checked)
+	}
+	var names []string
+	ast.Inspect(expr, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.SelectorExpr:
+			ast.Inspect(n.X, func(n ast.Node) bool {
+				if id, ok := n.(*ast.Ident); ok {
+					names = append(names, id.Name)
+				}
+				return true
+			})
+			return false
+		case *ast.Ident:
+			names = append(names, n.Name)
+		}
+		return true
+	})
+	return names
+}
+
+// checkToMethod reports an error if the function decl cannot become a method
+// whose receiver has type recv.
+func checkToMethod(pkg *cache.Package, decl *ast.FuncDecl, recv types.Type) error {
+	if decl.Type.TypeParams != nil {
+		return fmt.Errorf("cannot convert generic function %s to a method", decl.Name.Name)
+	}
+	named, _ := types.Unalias(typesinternal.Unpointer(recv)).(*types.Named)
+	if named == nil || named.Obj().Pkg() != pkg.Types() {
+		return fmt.Errorf("receiver type %s is not a named type declared in package %s", recv, pkg.Types().Name())
+	}
+	if named.TypeParams().Len() > 0 {
+		return fmt.Errorf("cannot add a method to generic type %s", named.Obj().Name())
+	}
+	switch named.Underlying().(type) {
+	case *types.Interface, *types.Pointer:
+		return fmt.Errorf("invalid receiver type %s", recv)
+	}
+	if obj, _, _ := types.LookupFieldOrMethod(named, true, pkg.Types(), decl.Name.Name); obj != nil {
+		return fmt.Errorf("type %s already has a field or method named %s", named.Obj().Name(), decl.Name.Name)
+	}
+	return nil
+}
+
+// checkToFunc reports an error if the method decl cannot become a
+// package-level function.
+func checkToFunc(pkg *cache.Package, decl *ast.FuncDecl) error {
+	if fn, ok := pkg.TypesInfo().Defs[decl.Name].(*types.Func); ok {
+		recv := fn.Signature().Recv()
+		if _, named := typesinternal.ReceiverNamed(recv); named != nil && named.TypeParams().Len() > 0 {
+			return fmt.Errorf("cannot convert method %s of generic type %s to a function", decl.Name.Name, named.Obj().Name())
+		}
+	}
+	if pkg.Types().Scope().Lookup(decl.Name.Name) != nil {
+		return fmt.Errorf("%s is already declared in package %s", decl.Name.Name, pkg.Types().Name())
+	}
+	return nil
+}
+
+// A resultsChange describes a change to the results of a function, and how
+// to update its return statements accordingly.
+type resultsChange struct {
+	old      int         // number of old results
+	results  []flatField // new results
+	prologue []string    // statements to insert at the start of the body
+
+	// blanks maps the name of each removed named result that is only
+	// assigned to the ordinals, among identifiers of that name in the
+	// body, of its assignments, which become assignments to _.
+	blanks map[string][]int
+}
+
+// planReturns checks that the return statements of decl can be updated from
+// the old to the new results, and returns the plan for doing so.
+func planReturns(pkg *cache.Package, pgf *parsego.File, decl *ast.FuncDecl, oldResults, newResults []flatField) (*resultsChange, error) {
+	info := pkg.TypesInfo()
+	rc := &resultsChange{old: len(oldResults), results: newResults}
+
+	var (
+		returns []*ast.ReturnStmt
+		bare    = false // function has bare returns with named results
+	)
+	if decl.Body == nil {
+		return nil, fmt.Errorf("cannot change the results of %s, which has no body", decl.Name.Name)
+	}
+	ast.Inspect(decl.Body, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.FuncLit:
+			return false
+		case *ast.ReturnStmt:
+			returns = append(returns, n)
+			if len(n.Results) == 0 && len(oldResults) > 0 {
+				bare = true
+			}
+		}
+		return true
+	})
+
+	kept := make(map[int]bool)
+	for _, f := range newResults {
+		if !f.isNew() {
+			kept[f.old] = true
+		}
+	}
+
+	for _, ret := range returns {
+		pos := safetoken.StartPosition(pkg.FileSet(), ret.Pos())
+		if len(ret.Results) == 1 && len(oldResults) > 1 {
+			return nil, fmt.Errorf("%s: cannot update return statement with a multi-valued operand", pos)
+		}
+		for i, res := range ret.Results {
+			if !kept[i] && !typesinternal.NoEffects(info, res) {
+				return nil, fmt.Errorf("%s: cannot remove result %s, which may have side effects", pos, types.ExprString(res))
+			}
+		}
+		// The values of new results must mean the same here as at the
+		// declaration.
+		if len(ret.Results) > 0 || len(oldResults) == 0 {
+			scope := pkg.Types().Scope().Innermost(ret.Pos())
+			for _, f := range newResults {
+				if !f.isNew() {
+					continue
+				}
+				for _, name := range freeNames(f.value) {
+					if _, obj := scope.LookupParent(name, ret.Pos()); obj != nil && decl.Pos() <= obj.Pos() && obj.Pos() < decl.End() {
+						return nil, fmt.Errorf("%s: value %s of new result refers to %s, which is shadowed here", pos, f.value, name)
+					}
+				}
+			}
+		}
+	}
+
+	// With bare returns, the new results must be named, and new results
+	// must be initialized to their values.
+	if bare {
+		for _, f := range newResults {
+			if f.name == "" || f.name == "_" {
+				return nil, fmt.Errorf("cannot update bare return statements of %s: new results must be named", decl.Name.Name)
+			}
+			if f.isNew() && f.explicit {
+				rc.prologue = append(rc.prologue, fmt.Sprintf("%s = %s", f.name, f.value))
+			}
+		}
+	}
+
+	// Removed named results that are used by the body become local
+	// variables, unless they are only assigned.
+	i := -1
+	for id, field := range astutil.FlatFields(decl.Type.Results) {
+		i++
+		if id == nil || id.Name == "_" || kept[i] {
+			continue
+		}
+		obj := info.Defs[id]
+		var (
+			assigned = make(map[*ast.Ident]bool) // operands of assignments
+			assigns  []int                       // ordinals of assignments to obj
+			used     = false                     // whether obj has other uses
+			ordinal  = 0
+		)
+		ast.Inspect(decl.Body, func(n ast.Node) bool {
+			switch n := n.(type) {
+			case *ast.AssignStmt:
+				if n.Tok == token.ASSIGN {
+					for _, lhs := range n.Lhs {
+						if lhs, ok := lhs.(*ast.Ident); ok {
+							assigned[lhs] = true
+						}
+					}
+				}
+			case *ast.Ident:
+				if n.Name != id.Name {
+					break
+				}
+				if obj != nil && info.Uses[n] == obj {
+					if assigned[n] {
+						assigns = append(assigns, ordinal)
+					} else {
+						used = true
+					}
+				}
+				ordinal++
+			}
+			return true
+		})
+		if used {
+			rc.prologue = append(rc.prologue, fmt.Sprintf("var %s %s", id.Name, formatNodeFile(pgf.Tok, field.Type)))
+		} else if len(assigns) > 0 {
+			if rc.blanks == nil {
+				rc.blanks = make(map[string][]int)
+			}
+			rc.blanks[id.Name] = assigns
+		}
+	}
+	return rc, nil
+}
+
+// rewriteSignature rewrites the declIdx'th declaration in src to use the
+// signature of newDecl (described by fset), updating its return statements if
+// results is non-nil.
 //
 // TODO(rfindley): I think this operation could be generalized, for example by
 // using a concept of a 'nodepath' to correlate nodes between two related
@@ -355,7 +905,7 @@ func ChangeSignature(ctx context.Context, snapshot *cache.Snapshot, pkg *cache.P
 // Note that with its current application, rewriteSignature is expected to
 // succeed. Separate bug.Errorf calls are used below (rather than one call at
 // the callsite) in order to have greater precision.
-func rewriteSignature(fset *token.FileSet, declIdx int, src0 []byte, newDecl *ast.FuncDecl) ([]byte, error) {
+func rewriteSignature(fset *token.FileSet, declIdx int, src0 []byte, newDecl *ast.FuncDecl, results *resultsChange) ([]byte, error) {
 	// Parse the new file0 content, to locate the original params.
 	file0, err := parser.ParseFile(fset, "", src0, parser.ParseComments|parser.SkipObjectResolution)
 	if err != nil {
@@ -367,39 +917,171 @@ func rewriteSignature(fset *token.FileSet, declIdx int, src0 []byte, newDecl *as
 	if decl0 == nil || decl0.Name.Name != newDecl.Name.Name {
 		return nil, bug.Errorf("inlining affected declaration order: found %v, not func %s", decl0, newDecl.Name.Name)
 	}
-	opening0, closing0, err := safetoken.Offsets(fset.File(decl0.Pos()), decl0.Type.Params.Opening, decl0.Type.Params.Closing)
+	newSrc, err := rewriteDecl(fset, src0, decl0, newDecl, results)
 	if err != nil {
-		return nil, bug.Errorf("can't find params: %v", err)
+		return nil, err
 	}
+	if len(file0.Imports) > 0 {
+		formatted, err := imports.Process("output", newSrc, nil)
+		if err != nil {
+			return nil, bug.Errorf("imports.Process failed: %v", err)
+		}
+		newSrc = formatted
+	} else if results != nil {
+		formatted, err := format.Source(newSrc)
+		if err != nil {
+			return nil, bug.Errorf("formatting rewritten declaration: %v", err)
+		}
+		newSrc = formatted
+	}
+	return newSrc, nil
+}
 
-	// Format the modified signature and apply a textual replacement. This
-	// minimizes comment disruption.
-	formattedType := FormatNode(fset, newDecl.Type)
-	expr, err := parser.ParseExprFrom(fset, "", []byte(formattedType), 0)
-	if err != nil {
+// rewriteDecl rewrites the declaration decl0 within src (described by fset)
+// to have the name, receiver and signature of newDecl, and, if results is
+// non-nil, updates its return statements.
+//
+// Only the changed parts of the declaration are replaced, using the
+// formatted syntax of newDecl. This minimizes comment disruption.
+func rewriteDecl(fset *token.FileSet, src []byte, decl0, newDecl *ast.FuncDecl, results *resultsChange) ([]byte, error) {
+	tok := fset.File(decl0.Pos())
+	offset := func(pos token.Pos) (int, error) {
+		return safetoken.Offset(tok, pos)
+	}
+
+	// Format the modified header.
+	header := FormatNode(fset, &ast.FuncDecl{
+		Recv: newDecl.Recv,
+		Name: newDecl.Name,
+		Type: newDecl.Type,
+	})
+	const prefix = "package p\n"
+	file1, err := parser.ParseFile(token.NewFileSet(), "", prefix+header, parser.SkipObjectResolution)
+	if err != nil || len(file1.Decls) != 1 {
 		return nil, bug.Errorf("parsing modified signature: %v", err)
 	}
-	newType := expr.(*ast.FuncType)
-	opening1, closing1, err := safetoken.Offsets(fset.File(newType.Pos()), newType.Params.Opening, newType.Params.Closing)
+	decl1 := file1.Decls[0].(*ast.FuncDecl)
+	offset1 := func(pos token.Pos) int {
+		return int(pos) - 1 - len(prefix) // (a single file, with base 1)
+	}
+
+	// Choose the extent of the replacement: the parameters, plus the name
+	// and receiver if they changed, plus the results if they changed.
+	start0, start1 := decl0.Type.Params.Opening, decl1.Type.Params.Opening
+	if decl0.Recv.NumFields() != decl1.Recv.NumFields() || decl0.Name.Name != decl1.Name.Name {
+		start0, start1 = decl0.Name.Pos(), decl1.Name.Pos()
+		if decl0.Recv != nil {
+			start0 = decl0.Recv.Opening
+		}
+		if decl1.Recv != nil {
+			start1 = decl1.Recv.Opening
+		}
+	}
+	end0, end1 := decl0.Type.Params.Closing+1, decl1.Type.Params.Closing+1
+	if results != nil {
+		end0, end1 = decl0.Type.End(), decl1.Type.End()
+	}
+	start, err := offset(start0)
+	if err != nil {
+		return nil, bug.Errorf("can't find signature: %v", err)
+	}
+	end, err := offset(end0)
 	if err != nil {
-		return nil, bug.Errorf("param offsets: %v", err)
+		return nil, bug.Errorf("can't find signature: %v", err)
 	}
-	newParams := formattedType[opening1 : closing1+1]
+	edits := []diff.Edit{{
+		Start: start,
+		End:   end,
+		New:   header[offset1(start1):offset1(end1)],
+	}}
 
-	// Splice.
-	var buf bytes.Buffer
-	buf.Write(src0[:opening0])
-	buf.WriteString(newParams)
-	buf.Write(src0[closing0+1:])
-	newSrc := buf.Bytes()
-	if len(file0.Imports) > 0 {
-		formatted, err := imports.Process("output", newSrc, nil)
+	if results != nil && decl0.Body != nil {
+		// Update the return statements.
+		var err error
+		ast.Inspect(decl0.Body, func(n ast.Node) bool {
+			switch n := n.(type) {
+			case *ast.FuncLit:
+				return false
+			case *ast.ReturnStmt:
+				if len(n.Results) == 0 && results.old > 0 {
+					return true // bare return with named results
+				}
+				if len(n.Results) != results.old {
+					err = bug.Errorf("return statement has %d results, want %d", len(n.Results), results.old)
+					return false
+				}
+				var exprs []string
+				for _, f := range results.results {
+					if f.isNew() {
+						exprs = append(exprs, f.value)
+						continue
+					}
+					start, end, err2 := safetoken.Offsets(tok, n.Results[f.old].Pos(), n.Results[f.old].End())
+					if err2 != nil {
+						err = err2
+						return false
+					}
+					exprs = append(exprs, string(src[start:end]))
+				}
+				start, end, err2 := safetoken.Offsets(tok, n.Pos(), n.End())
+				if err2 != nil {
+					err = err2
+					return false
+				}
+				text := "return"
+				if len(exprs) > 0 {
+					text += " " + strings.Join(exprs, ", ")
+				} else if list := decl0.Body.List; list[len(list)-1] == n {
+					// Delete a final return statement that no longer returns anything.
+					from := decl0.Body.Lbrace + 1
+					if len(list) > 1 {
+						from = list[len(list)-2].End()
+					}
+					start, err = offset(from)
+					if err != nil {
+						return false
+					}
+					text = ""
+				}
+				edits = append(edits, diff.Edit{Start: start, End: end, New: text})
+			}
+			return err == nil
+		})
 		if err != nil {
-			return nil, bug.Errorf("imports.Process failed: %v", err)
+			return nil, err
+		}
+		if len(results.blanks) > 0 {
+			// Replace assignments to removed results by assignments to _.
+			ordinals := make(map[string]int)
+			ast.Inspect(decl0.Body, func(n ast.Node) bool {
+				if id, ok := n.(*ast.Ident); ok {
+					if blanks, ok := results.blanks[id.Name]; ok {
+						if slices.Contains(blanks, ordinals[id.Name]) {
+							edits = append(edits, diff.Edit{
+								Start: int(id.Pos() - tok.Pos(0)),
+								End:   int(id.End() - tok.Pos(0)),
+								New:   "_",
+							})
+						}
+						ordinals[id.Name]++
+					}
+				}
+				return true
+			})
+		}
+		if len(results.prologue) > 0 {
+			lbrace, err := offset(decl0.Body.Lbrace)
+			if err != nil {
+				return nil, err
+			}
+			edits = append(edits, diff.Edit{
+				Start: lbrace + 1,
+				End:   lbrace + 1,
+				New:   "\n" + strings.Join(results.prologue, "\n"),
+			})
 		}
-		newSrc = formatted
 	}
-	return newSrc, nil
+	return diff.ApplyBytes(src, edits)
 }
 
 // paramInfo records information about a param identified by a position.
@@ -481,9 +1163,11 @@ type signatureRewrite struct {
 	pkg               *cache.Package
 	pgf               *parsego.File
 	origDecl, newDecl *ast.FuncDecl
-	params            *ast.FieldList
+	recv, params      *ast.FieldList
+	callRecv          ast.Expr // receiver of the delegated call, or nil
 	callArgs          []ast.Expr
 	variadic          bool
+	results           *resultsChange // non-nil if the results change
 }
 
 // rewriteCalls returns the document changes required to rewrite the
@@ -492,11 +1176,11 @@ type signatureRewrite struct {
 // This is a rather complicated factoring of the rewrite operation, but is able
 // to describe arbitrary rewrites. Specifically, rewriteCalls creates a
 // synthetic copy of pkg, where the original function declaration is changed to
-// be a trivial wrapper around the new declaration. params and callArgs are
-// used to perform this delegation: params must have the same type as origDecl,
-// but may have renamed parameters (such as is required for delegating blank
-// parameters). callArgs are the arguments of the delegated call (i.e. using
-// params).
+// be a trivial wrapper around the new declaration. recv, params and callArgs
+// are used to perform this delegation: recv and params must have the same
+// types as origDecl, but may have renamed parameters (such as is required for
+// delegating blank parameters). callArgs are the arguments of the delegated
+// call (i.e. using params), and callRecv, if non-nil, its receiver.
 //
 // For example, consider removing the unused 'b' parameter below, rewriting
 //
@@ -523,9 +1207,8 @@ type signatureRewrite struct {
 // of *how* this rewriting is performed. For example, as of writing it names
 // the synthetic delegate G_o_p_l_s_foo, but the caller need not know this.
 //
-// By passing an entirely new declaration, rewriteCalls may be used for
-// signature refactorings that may affect the function body, such as removing
-// or adding return values.
+// If the results change, the wrapper has the new results, and the uses of
+// the results of each call are updated by updateResultUses.
 func rewriteCalls(ctx context.Context, rw signatureRewrite) (map[protocol.DocumentURI][]byte, error) {
 	// tag is a unique prefix that is added to the delegated declaration.
 	//
@@ -545,46 +1228,15 @@ func rewriteCalls(ctx context.Context, rw signatureRewrite) (map[protocol.Docume
 		}
 
 		wrapper := astutil.CloneNode(rw.origDecl)
+		wrapper.Recv = rw.recv
 		wrapper.Type.Params = rw.params
-
-		// Get the receiver name, creating it if necessary.
-		var recv string // nonempty => call is a method call with receiver recv
-		if wrapper.Recv.NumFields() > 0 {
-			if len(wrapper.Recv.List[0].Names) > 0 {
-				recv = wrapper.Recv.List[0].Names[0].Name
-			} else {
-				// Create unique name for the temporary receiver, which will be inlined away.
-				//
-				// We use the lexical scope of the original function to avoid conflicts
-				// with (e.g.) named result variables. However, since the parameter syntax
-				// may have been modified/renamed from the original function, we must
-				// reject those names too.
-				usedParams := make(map[string]bool)
-				for _, fld := range wrapper.Type.Params.List {
-					for _, name := range fld.Names {
-						usedParams[name.Name] = true
-					}
-				}
-				scope := rw.pkg.TypesInfo().Scopes[rw.origDecl.Type]
-				if scope == nil {
-					return nil, bug.Errorf("missing function scope for %v", rw.origDecl.Name.Name)
-				}
-				for i := 0; ; i++ {
-					recv = fmt.Sprintf("r%d", i)
-					_, obj := scope.LookupParent(recv, token.NoPos)
-					if obj == nil && !usedParams[recv] {
-						break
-					}
-				}
-				wrapper.Recv.List[0].Names = []*ast.Ident{{Name: recv}}
-			}
-		}
+		wrapper.Type.Results = astutil.CloneNode(rw.newDecl.Type.Results)
 
 		name := &ast.Ident{Name: delegate.Name.Name}
 		var fun ast.Expr = name
-		if recv != "" {
+		if rw.callRecv != nil {
 			fun = &ast.SelectorExpr{
-				X:   &ast.Ident{Name: recv},
+				X:   rw.callRecv,
 				Sel: name,
 			}
 		}
@@ -612,12 +1264,12 @@ func rewriteCalls(ctx context.Context, rw signatureRewrite) (map[protocol.Docume
 
 		fset := tokeninternal.FileSetFor(rw.pgf.Tok)
 		var err error
-		modifiedSrc, err = replaceFileDecl(rw.pgf, rw.origDecl, delegate)
+		modifiedSrc, err = rewriteDecl(fset, rw.pgf.Src, rw.origDecl, delegate, rw.results)
 		if err != nil {
 			return nil, err
 		}
 		// TODO(rfindley): we can probably get away with one fewer parse operations
-		// by returning the modified AST from replaceDecl. Investigate if that is
+		// by returning the modified AST from rewriteDecl. Investigate if that is
 		// accurate.
 		modifiedSrc = append(modifiedSrc, []byte("\n\n"+FormatNode(fset, wrapper))...)
 		modifiedFile, err = parser.ParseFile(rw.pkg.FileSet(), rw.pgf.URI.Path(), modifiedSrc, parser.ParseComments|parser.SkipObjectResolution)
@@ -628,9 +1280,10 @@ func rewriteCalls(ctx context.Context, rw signatureRewrite) (map[protocol.Docume
 	}
 
 	// Type check pkg again with the modified file, to compute the synthetic
-	// callee.
+	// callee. If the results change, calls of the wrapper within pkg no longer
+	// type check, but that doesn't affect the analysis of the callee.
 	logf := logger(ctx, "change signature", rw.snapshot.Options().VerboseOutput)
-	pkg2, info, err := reTypeCheck(logf, rw.pkg, map[protocol.DocumentURI]*ast.File{rw.pgf.URI: modifiedFile}, false)
+	pkg2, info, err := reTypeCheck(logf, rw.pkg, map[protocol.DocumentURI]*ast.File{rw.pgf.URI: modifiedFile}, rw.results != nil)
 	if err != nil {
 		return nil, err
 	}
@@ -639,7 +1292,13 @@ func rewriteCalls(ctx context.Context, rw signatureRewrite) (map[protocol.Docume
 		return nil, fmt.Errorf("analyzing callee: %v", err)
 	}
 
-	post := func(got []byte) []byte { return bytes.ReplaceAll(got, []byte(tag), nil) }
+	post := func(uri protocol.DocumentURI, got []byte) ([]byte, error) {
+		got, err := updateResultUses(uri, got, tag+rw.newDecl.Name.Name, rw.results)
+		if err != nil {
+			return nil, err
+		}
+		return bytes.ReplaceAll(got, []byte(tag), nil), nil
+	}
 	opts := &inline.Options{
 		Logf:          logf,
 		IgnoreEffects: true,
@@ -647,6 +1306,177 @@ func rewriteCalls(ctx context.Context, rw signatureRewrite) (map[protocol.Docume
 	return inlineAllCalls(ctx, rw.snapshot, rw.pkg, rw.pgf, rw.origDecl, calleeInfo, post, opts)
 }
 
+// updateResultUses updates the calls to the delegate function named name
+// within the content of the file uri, which has just been inlined.
+//
+// If results is non-nil, it updates the statement that uses the results of
+// each call, or reports an error if it cannot. For example, if the second of
+// two results is removed, then
+//
+//	x, _ := f()
+//
+// becomes
+//
+//	x := f()
+//
+// but a removed result must not be assigned to a variable.
+//
+// Also, it simplifies a method call whose receiver is &x to x.f().
+func updateResultUses(uri protocol.DocumentURI, content []byte, name string, results *resultsChange) ([]byte, error) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, uri.Path(), content, parser.ParseComments|parser.SkipObjectResolution)
+	if err != nil {
+		return nil, bug.Errorf("inlined file failed to parse: %v", err)
+	}
+	tok := fset.File(file.FileStart)
+	text := func(n ast.Node) string {
+		start, end, _ := safetoken.Offsets(tok, n.Pos(), n.End())
+		return string(content[start:end])
+	}
+	replace := func(pos, end token.Pos, new string) diff.Edit {
+		return diff.Edit{
+			Start: int(pos - file.FileStart),
+			End:   int(end - file.FileStart),
+			New:   new,
+		}
+	}
+
+	var (
+		edits []diff.Edit
+		errs  []error
+	)
+	for c := range inspector.New([]*ast.File{file}).Root().Preorder((*ast.CallExpr)(nil)) {
+		call := c.Node().(*ast.CallExpr)
+		newCall := text(call)
+		switch fun := ast.Unparen(call.Fun).(type) {
+		case *ast.Ident:
+			if fun.Name != name {
+				continue
+			}
+		case *ast.SelectorExpr:
+			if fun.Sel.Name != name {
+				continue
+			}
+			// (&x).f() => x.f()
+			if u, ok := ast.Unparen(fun.X).(*ast.UnaryExpr); ok && u.Op == token.AND && !is[*ast.CompositeLit](u.X) {
+				newCall = text(u.X) + string(content[fun.X.End()-file.FileStart:call.End()-file.FileStart])
+			}
+		default:
+			continue
+		}
+
+		// If the call no longer has results, the inliner may have wrapped
+		// it in a function literal to preserve a use of its results:
+		//
+		//	x := func() { f() }()
+		//
+		// In that case, the use is that of the literal call.
+		var use ast.Expr = call
+		if results != nil && len(results.results) == 0 {
+			if lit, outer := immediatelyCalledLiteral(c); lit != nil {
+				use, c = outer.Node().(*ast.CallExpr), outer
+			}
+		}
+		if newCall != text(use) {
+			edits = append(edits, replace(use.Pos(), use.End(), newCall))
+		}
+		if results == nil {
+			continue
+		}
+
+		// Find the statement that uses the results.
+		pos := safetoken.StartPosition(fset, call.Pos())
+		parent := c.Parent()
+		for is[*ast.ParenExpr](parent.Node()) {
+			parent = parent.Parent()
+		}
+		var lhs []ast.Expr // operands assigned the results
+		switch parent := parent.Node().(type) {
+		case *ast.ExprStmt, *ast.GoStmt, *ast.DeferStmt:
+			continue // results are discarded
+
+		case *ast.AssignStmt:
+			if len(parent.Rhs) != 1 || (parent.Tok != token.ASSIGN && parent.Tok != token.DEFINE) {
+				errs = append(errs, fmt.Errorf("%s: cannot update results of call in %s statement", pos, parent.Tok))
+				continue
+			}
+			lhs = parent.Lhs
+
+		case *ast.ValueSpec:
+			if len(parent.Values) != 1 {
+				errs = append(errs, fmt.Errorf("%s: cannot update results of call in multi-valued declaration", pos))
+				continue
+			}
+			if parent.Type != nil && slices.ContainsFunc(results.results, flatField.isNew) {
+				errs = append(errs, fmt.Errorf("%s: cannot add results to call in declaration with type %s", pos, text(parent.Type)))
+				continue
+			}
+			for _, id := range parent.Names {
+				lhs = append(lhs, id)
+			}
+
+		default:
+			errs = append(errs, fmt.Errorf("%s: cannot update results of call used in an expression", pos))
+			continue
+		}
+
+		// Compute the operands of the new results.
+		kept := make(map[int]bool)
+		var newLHS []string
+		for _, f := range results.results {
+			if f.isNew() {
+				newLHS = append(newLHS, "_")
+			} else {
+				kept[f.old] = true
+				newLHS = append(newLHS, text(lhs[f.old]))
+			}
+		}
+		for i, e := range lhs {
+			if id, ok := e.(*ast.Ident); !kept[i] && !(ok && id.Name == "_") {
+				errs = append(errs, fmt.Errorf("%s: cannot remove result assigned to %s", pos, text(e)))
+			}
+		}
+		switch stmt := parent.Node(); {
+		case len(newLHS) > 0:
+			edits = append(edits, replace(lhs[0].Pos(), lhs[len(lhs)-1].End(), strings.Join(newLHS, ", ")))
+		case is[*ast.AssignStmt](stmt):
+			// _ = f() => f()
+			edits = append(edits, replace(stmt.Pos(), use.Pos(), ""))
+		default:
+			errs = append(errs, fmt.Errorf("%s: cannot remove all results of call in declaration", pos))
+		}
+	}
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+	return diff.ApplyBytes(content, edits)
+}
+
+// immediatelyCalledLiteral reports whether the call at cur is the sole
+// statement of a function literal without parameters or results that is
+// immediately called, and if so returns the literal and the cursor of its
+// call.
+func immediatelyCalledLiteral(cur inspector.Cursor) (*ast.FuncLit, inspector.Cursor) {
+	stmt := cur.Parent()
+	body, ok := stmt.Parent().Node().(*ast.BlockStmt)
+	if !ok || !is[*ast.ExprStmt](stmt.Node()) || len(body.List) != 1 {
+		return nil, inspector.Cursor{}
+	}
+	litCur := stmt.Parent().Parent()
+	lit, ok := litCur.Node().(*ast.FuncLit)
+	if !ok || lit.Type.Params.NumFields() > 0 || lit.Type.Results.NumFields() > 0 {
+		return nil, inspector.Cursor{}
+	}
+	outer := litCur.Parent()
+	for is[*ast.ParenExpr](outer.Node()) {
+		outer = outer.Parent()
+	}
+	if call, ok := outer.Node().(*ast.CallExpr); !ok || len(call.Args) > 0 || ast.Unparen(call.Fun) != lit {
+		return nil, inspector.Cursor{}
+	}
+	return lit, outer
+}
+
 // reTypeCheck re-type checks orig with new file contents defined by fileMask.
 //
 // It expects that any newly added imports are already present in the
@@ -754,44 +1584,6 @@ func reTypeCheck(logf func(string, ...any), orig *cache.Package, fileMask map[pr
 // TODO(golang/go#63472): this looks wrong with the new Go version syntax.
 var goVersionRx = regexp.MustCompile(`^go([1-9][0-9]*)\.(0|[1-9][0-9]*)$`)
 
-// selectElements returns a new array of elements of s indicated by the
-// provided list of indices. It returns false if any index was out of bounds.
-//
-// For example, given the slice []string{"a", "b", "c", "d"}, the
-// indices []int{3, 0, 1} results in the slice []string{"d", "a", "b"}.
-func selectElements[T any](s []T, indices []int) ([]T, bool) {
-	res := make([]T, len(indices))
-	for i, index := range indices {
-		if index < 0 || index >= len(s) {
-			return nil, false
-		}
-		res[i] = s[index]
-	}
-	return res, true
-}
-
-// replaceFileDecl replaces old with new in the file described by pgf.
-//
-// TODO(rfindley): generalize, and combine with rewriteSignature.
-func replaceFileDecl(pgf *parsego.File, old, new ast.Decl) ([]byte, error) {
-	i := findDecl(pgf.File, old)
-	if i == -1 {
-		return nil, bug.Errorf("didn't find old declaration")
-	}
-	start, end, err := safetoken.Offsets(pgf.Tok, old.Pos(), old.End())
-	if err != nil {
-		return nil, err
-	}
-	var out bytes.Buffer
-	out.Write(pgf.Src[:start])
-	fset := tokeninternal.FileSetFor(pgf.Tok)
-	if err := format.Node(&out, fset, new); err != nil {
-		return nil, bug.Errorf("formatting new node: %v", err)
-	}
-	out.Write(pgf.Src[end:])
-	return out.Bytes(), nil
-}
-
 // findDecl finds the index of decl in file.Decls.
 //
 // TODO: use slices.Index when it is available.
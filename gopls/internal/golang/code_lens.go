@@ -26,6 +26,8 @@ func CodeLensSources() map[settings.CodeLensSource]cache.CodeLensSourceFunc {
 		settings.CodeLensGenerate:      goGenerateCodeLens, // commands: Generate
 		settings.CodeLensTest:          runTestCodeLens,    // commands: Test
 		settings.CodeLensRegenerateCgo: regenerateCgoLens,  // commands: RegenerateCgo
+		settings.CodeLensCoverage:      coverageCodeLens,   // commands: LoadCoverage
+		settings.CodeLensHotSpots:      hotSpotsCodeLens,   // commands: ToggleProfile
 	}
 }
 
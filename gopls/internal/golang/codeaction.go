@@ -248,6 +248,8 @@ var codeActionProducers = [...]codeActionProducer{
 	{kind: settings.GoTest, fn: goTest, needPkg: true},
 	{kind: settings.GoToggleCompilerOptDetails, fn: toggleCompilerOptDetails},
 	{kind: settings.RefactorExtractFunction, fn: refactorExtractFunction},
+	{kind: settings.RefactorExtractInterface, fn: refactorExtractInterface, needPkg: true},
+	{kind: settings.RefactorExtractInterfaceAll, fn: refactorExtractInterfaceAll, needPkg: true},
 	{kind: settings.RefactorExtractMethod, fn: refactorExtractMethod},
 	{kind: settings.RefactorExtractToNewFile, fn: refactorExtractToNewFile},
 	{kind: settings.RefactorExtractConstant, fn: refactorExtractVariable, needPkg: true},
@@ -259,8 +261,10 @@ var codeActionProducers = [...]codeActionProducer{
 	{kind: settings.RefactorMoveType, fn: refactorMoveType, needPkg: true},
 	{kind: settings.RefactorMoveDeclaration, fn: refactorMoveDeclaration, needPkg: true},
 	{kind: settings.RefactorRewriteChangeQuote, fn: refactorRewriteChangeQuote},
+	{kind: settings.RefactorRewriteExample, fn: refactorRewriteExample, needPkg: true},
 	{kind: settings.RefactorRewriteFillStruct, fn: refactorRewriteFillStruct, needPkg: true},
 	{kind: settings.RefactorRewriteFillSwitch, fn: refactorRewriteFillSwitch, needPkg: true},
+	{kind: settings.RefactorRewriteGeneralize, fn: refactorRewriteGeneralize, needPkg: true},
 	{kind: settings.RefactorRewriteImplementInterface, fn: refactorRewriteImplementInterface, needPkg: true},
 	{kind: settings.RefactorRewriteInvertIf, fn: refactorRewriteInvertIf},
 	{kind: settings.RefactorRewriteJoinLines, fn: refactorRewriteJoinLines, needPkg: true},
@@ -503,6 +507,30 @@ func refactorExtractMethod(ctx context.Context, req *codeActionsRequest) error {
 	return nil
 }
 
+// refactorExtractInterface produces "Extract interface" code actions.
+// See [extractInterfaceOne] for command implementation.
+func refactorExtractInterface(ctx context.Context, req *codeActionsRequest) error {
+	if param, err := canExtractInterface(req.pkg, req.pgf, req.start, req.end); err == nil {
+		req.addApplyFixAction(fmt.Sprintf("Extract interface for parameter %s", param.field.Names[0].Name), fixExtractInterface, req.loc)
+	}
+	return nil
+}
+
+// refactorExtractInterfaceAll produces "Extract interface for all
+// parameters" code actions, when other parameters in the package have
+// the same type as the selected one.
+// See [extractInterfaceAll] for command implementation.
+func refactorExtractInterfaceAll(ctx context.Context, req *codeActionsRequest) error {
+	if param, err := canExtractInterface(req.pkg, req.pgf, req.start, req.end); err == nil {
+		typ := req.pkg.TypesInfo().TypeOf(param.field.Type)
+		if len(interfaceParams(req.pkg, typ)) > 1 {
+			qual := typesinternal.FileQualifier(req.pgf.File, req.pkg.Types())
+			req.addApplyFixAction(fmt.Sprintf("Extract interface for all parameters of type %s", types.TypeString(typ, qual)), fixExtractInterfaceAll, req.loc)
+		}
+	}
+	return nil
+}
+
 // refactorExtractVariable produces "Extract variable|constant" code actions.
 // See [extractVariable] for command implementation.
 func refactorExtractVariable(ctx context.Context, req *codeActionsRequest) error {
@@ -634,6 +662,34 @@ func refactorRewriteRemoveUnusedParam(ctx context.Context, req *codeActionsReque
 	return nil
 }
 
+// refactorRewriteGeneralize produces "Generalize" code actions.
+// See [generalize] for command implementation.
+func refactorRewriteGeneralize(ctx context.Context, req *codeActionsRequest) error {
+	if g, err := canGeneralize(req.pkg, req.pgf, req.start, req.end); err == nil {
+		var title string
+		if len(g.decls) > 1 {
+			title = fmt.Sprintf("Merge into generic function %s", g.decls[0].Name.Name)
+		} else {
+			qual := typesinternal.FileQualifier(req.pgf.File, req.pkg.Types())
+			title = fmt.Sprintf("Generalize %s into a type parameter", types.TypeString(g.types[0], qual))
+		}
+		req.addApplyFixAction(title, fixGeneralize, req.loc)
+	}
+	return nil
+}
+
+// refactorRewriteExample produces "Apply example-based rewrite" code actions.
+// See [server.commandHandler.ExampleRewrite] for command implementation.
+func refactorRewriteExample(ctx context.Context, req *codeActionsRequest) error {
+	if len(templateRules(req.pkg, req.pgf, req.start, req.end)) > 0 {
+		cmd := command.NewExampleRewriteCommand("Apply example-based rewrite to workspace", command.ExampleRewriteArgs{
+			Template: req.loc,
+		})
+		req.addCommandAction(cmd, false)
+	}
+	return nil
+}
+
 func refactorRewriteMoveParamLeft(ctx context.Context, req *codeActionsRequest) error {
 	if info := findParam(req.pgf, req.loc.Range); info != nil &&
 		info.paramIndex > 0 &&
@@ -1130,10 +1186,12 @@ func goTest(ctx context.Context, req *codeActionsRequest) error {
 		return nil
 	}
 
+	// While coverage is displayed, keep it up to date.
 	cmd := command.NewRunTestsCommand("Run tests and benchmarks", command.RunTestsArgs{
 		URI:        req.loc.URI,
 		Tests:      tests,
 		Benchmarks: benchmarks,
+		Coverage:   req.snapshot.CoverageProfile() != "",
 	})
 	req.addCommandAction(cmd, false)
 	return nil
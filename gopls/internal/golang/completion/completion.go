@@ -117,6 +117,7 @@ type completionOptions struct {
 	placeholders          bool
 	snippets              bool
 	postfix               bool
+	postfixSnippets       []settings.PostfixSnippet
 	matcher               settings.Matcher
 	budget                time.Duration
 	completeFunctionCalls bool
@@ -640,6 +641,7 @@ func Completion(ctx context.Context, snapshot *cache.Snapshot, fh file.Handle, p
 			budget:                opts.CompletionBudget,
 			snippets:              opts.InsertTextFormat == protocol.SnippetTextFormat,
 			postfix:               opts.ExperimentalPostfixCompletions,
+			postfixSnippets:       opts.PostfixSnippets,
 			completeFunctionCalls: opts.CompleteFunctionCalls,
 		},
 		// default to a matcher that always matches
@@ -12,6 +12,7 @@ import (
 	"go/types"
 	"log"
 	"reflect"
+	"slices"
 	"strings"
 	"sync"
 	"text/template"
@@ -20,6 +21,7 @@ import (
 	"golang.org/x/tools/gopls/internal/golang"
 	"golang.org/x/tools/gopls/internal/golang/completion/snippet"
 	"golang.org/x/tools/gopls/internal/protocol"
+	"golang.org/x/tools/gopls/internal/settings"
 	"golang.org/x/tools/gopls/internal/util/safetoken"
 	"golang.org/x/tools/internal/event"
 	"golang.org/x/tools/internal/imports"
@@ -43,9 +45,31 @@ type postfixTmpl struct {
 	// facilities available to the template.
 	body string
 
+	// types, if non-empty, restricts the snippet to receivers of
+	// these kinds; see [settings.PostfixSnippetTypes].
+	types []string
+
 	tmpl *template.Template
 }
 
+// matches reports whether the snippet applies to the receiver of args.
+func (rule *postfixTmpl) matches(args *postfixTmplArgs) bool {
+	if len(rule.types) == 0 {
+		return true
+	}
+	for _, kind := range rule.types {
+		if kind == "error" {
+			// go/types predicates are undefined on types.Typ[types.Invalid].
+			if !types.Identical(args.Type, types.Typ[types.Invalid]) && types.Implements(args.Type, errorIntf) {
+				return true
+			}
+		} else if args.Kind() == kind {
+			return true
+		}
+	}
+	return false
+}
+
 // postfixTmplArgs are the template execution arguments available to
 // the postfix snippet templates.
 type postfixTmplArgs struct {
@@ -586,7 +610,7 @@ func (c *completer) addPostfixSnippetCandidates(ctx context.Context, sel *ast.Se
 		afterDot = c.pos
 	}
 
-	for _, rule := range postfixTmpls {
+	for _, rule := range c.postfixRules(ctx) {
 		// When completing foo.print<>, "print" is naturally overwritten,
 		// but we need to also remove "foo." so the snippet has a clean
 		// slate.
@@ -610,6 +634,10 @@ func (c *completer) addPostfixSnippetCandidates(ctx context.Context, sel *ast.Se
 			placeholders:   c.opts.placeholders,
 		}
 
+		if !rule.matches(&tmplArgs) {
+			continue
+		}
+
 		// Feed the template straight into the snippet builder. This
 		// allows templates to build snippets as they are executed.
 		err = rule.tmpl.Execute(&tmplArgs.snip, &tmplArgs)
@@ -645,9 +673,7 @@ func initPostfixRules() {
 		var idx int
 		for _, rule := range postfixTmpls {
 			var err error
-			rule.tmpl, err = template.New("postfix_snippet").Funcs(template.FuncMap{
-				"inc": inc,
-			}).Parse(rule.body)
+			rule.tmpl, err = parsePostfixTemplate("postfix_snippet", rule.body)
 			if err != nil {
 				log.Panicf("error parsing postfix snippet template: %v", err)
 			}
@@ -658,8 +684,40 @@ func initPostfixRules() {
 	})
 }
 
-func inc(i int) int {
-	return i + 1
+// parsePostfixTemplate parses the body of a built-in postfix snippet.
+func parsePostfixTemplate(name, body string) (*template.Template, error) {
+	return template.New(name).Funcs(settings.PostfixFuncs).Parse(body)
+}
+
+// postfixRules returns the built-in postfix snippets together with
+// those defined by the postfixSnippets setting, which replace any
+// built-in snippets of the same label.
+func (c *completer) postfixRules(ctx context.Context) []postfixTmpl {
+	if len(c.opts.postfixSnippets) == 0 {
+		return postfixTmpls
+	}
+	var user []postfixTmpl
+	for _, snip := range c.opts.postfixSnippets {
+		tmpl, err := snip.Template()
+		if err != nil {
+			event.Error(ctx, "error parsing user postfix snippet", err)
+			continue
+		}
+		user = append(user, postfixTmpl{
+			label:   snip.Label,
+			details: snip.Details,
+			body:    snip.Body,
+			types:   snip.Types,
+			tmpl:    tmpl,
+		})
+	}
+	var rules []postfixTmpl
+	for _, rule := range postfixTmpls {
+		if !slices.ContainsFunc(user, func(u postfixTmpl) bool { return u.label == rule.label }) {
+			rules = append(rules, rule)
+		}
+	}
+	return append(rules, user...)
 }
 
 // importIfNeeded returns the package identifier and any necessary
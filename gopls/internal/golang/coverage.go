@@ -0,0 +1,183 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package golang
+
+// This file defines the display of test coverage profiles.
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"go/ast"
+	"slices"
+
+	"golang.org/x/tools/cover"
+	"golang.org/x/tools/gopls/internal/cache"
+	"golang.org/x/tools/gopls/internal/cache/parsego"
+	"golang.org/x/tools/gopls/internal/file"
+	"golang.org/x/tools/gopls/internal/protocol"
+	"golang.org/x/tools/gopls/internal/protocol/command"
+	"golang.org/x/tools/internal/event"
+)
+
+// CoverageDiagnostics returns a diagnostic for each block of the open
+// Go files described by the snapshot's coverage profile (see
+// [cache.Snapshot.CoverageProfile]). The diagnostics are hints; those
+// of uncovered blocks are tagged "unnecessary", which most clients
+// display by fading the uncovered code, and those of covered blocks
+// are untagged, which most clients display as a subtle mark.
+func CoverageDiagnostics(ctx context.Context, snapshot *cache.Snapshot) (map[protocol.DocumentURI][]*cache.Diagnostic, error) {
+	ctx, done := event.Start(ctx, "golang.CoverageDiagnostics")
+	defer done()
+
+	profiles, err := snapshot.Coverage(ctx)
+	if err != nil || profiles == nil {
+		return nil, err
+	}
+	reports := make(map[protocol.DocumentURI][]*cache.Diagnostic)
+	for _, o := range snapshot.Overlays() {
+		if snapshot.FileKind(o) != file.Go {
+			continue
+		}
+		p := fileProfile(ctx, snapshot, profiles, o.URI())
+		if p == nil {
+			continue
+		}
+		content, err := o.Content()
+		if err != nil {
+			return nil, err
+		}
+		mapper := protocol.NewMapper(o.URI(), content)
+		for _, b := range coverBlocks(p, content) {
+			rng, err := mapper.OffsetRange(b.start, b.end)
+			if err != nil {
+				return nil, err
+			}
+			diag := &cache.Diagnostic{
+				URI:      o.URI(),
+				Range:    rng,
+				Severity: protocol.SeverityHint,
+				Source:   cache.Coverage,
+				Message:  "covered by tests",
+			}
+			if b.Count == 0 {
+				diag.Message = "not covered by tests"
+				diag.Tags = []protocol.DiagnosticTag{protocol.Unnecessary}
+			}
+			reports[o.URI()] = append(reports[o.URI()], diag)
+		}
+	}
+	return reports, nil
+}
+
+// coverageCodeLens annotates each function declaration with the
+// fraction of its statements covered by the snapshot's coverage
+// profile. The lens's command reloads the profile.
+func coverageCodeLens(ctx context.Context, snapshot *cache.Snapshot, fh file.Handle) ([]protocol.CodeLens, error) {
+	profiles, err := snapshot.Coverage(ctx)
+	if err != nil || profiles == nil {
+		return nil, err
+	}
+	p := fileProfile(ctx, snapshot, profiles, fh.URI())
+	if p == nil {
+		return nil, nil
+	}
+	pgf, err := snapshot.ParseGo(ctx, fh, parsego.Full)
+	if err != nil {
+		return nil, err
+	}
+	blocks := coverBlocks(p, pgf.Src)
+
+	var lenses []protocol.CodeLens
+	for _, decl := range pgf.File.Decls {
+		fn, ok := decl.(*ast.FuncDecl)
+		if !ok || fn.Body == nil {
+			continue
+		}
+		start, end, err := pgf.NodeOffsets(fn)
+		if err != nil {
+			return nil, err
+		}
+		var covered, total int
+		for _, b := range blocks {
+			if start <= b.start && b.end <= end {
+				total += b.NumStmt
+				if b.Count > 0 {
+					covered += b.NumStmt
+				}
+			}
+		}
+		if total == 0 {
+			continue
+		}
+		rng, err := pgf.PosRange(fn.Pos(), fn.Pos())
+		if err != nil {
+			return nil, err
+		}
+		title := fmt.Sprintf("coverage: %.1f%% (%d/%d statements)", 100*float64(covered)/float64(total), covered, total)
+		cmd := command.NewLoadCoverageCommand(title, command.LoadCoverageArgs{
+			URI:     fh.URI(),
+			Profile: snapshot.CoverageProfile(),
+		})
+		lenses = append(lenses, protocol.CodeLens{Range: rng, Command: cmd})
+	}
+	return lenses, nil
+}
+
+// fileProfile returns the element of profiles that describes the Go
+// file uri, or nil if there is none.
+//
+// Profiles identify files by the import path of their package and
+// their base name (e.g. "example.com/m/p/p.go"), or, for packages
+// outside any module, by their absolute file name.
+func fileProfile(ctx context.Context, snapshot *cache.Snapshot, profiles []*cover.Profile, uri protocol.DocumentURI) *cover.Profile {
+	var name string
+	if mp, err := snapshot.NarrowestMetadataForFile(ctx, uri); err == nil {
+		name = string(mp.PkgPath) + "/" + uri.Base()
+	}
+	for _, p := range profiles {
+		if p.FileName == name || p.FileName == uri.Path() {
+			return p
+		}
+	}
+	return nil
+}
+
+// A coverBlock is a block of a coverage profile, located within the
+// current content of its file.
+type coverBlock struct {
+	start, end int // byte offsets
+	cover.ProfileBlock
+}
+
+// coverBlocks returns the blocks of profile p located within src,
+// using [cover.Profile.Boundaries]. Blocks that lie beyond the end of
+// src (for example, because the file was edited after the profile
+// was recorded) are omitted.
+func coverBlocks(p *cover.Profile, src []byte) []coverBlock {
+	// Boundaries sorts the boundaries by offset, but their indices
+	// record the original order, in which the start (if found) and
+	// end of each block appear in turn.
+	boundaries := p.Boundaries(src)
+	slices.SortFunc(boundaries, func(x, y cover.Boundary) int { return cmp.Compare(x.Index, y.Index) })
+
+	var (
+		blocks []coverBlock
+		bi     int // index of current block of p
+		start  = -1
+	)
+	for _, b := range boundaries {
+		if b.Start {
+			start = b.Offset
+			continue
+		}
+		if start >= 0 && bi < len(p.Blocks) {
+			blocks = append(blocks, coverBlock{start, b.Offset, p.Blocks[bi]})
+		}
+		start = -1
+		bi++
+	}
+	return blocks
+}
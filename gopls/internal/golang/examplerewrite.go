@@ -0,0 +1,355 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package golang
+
+// This file defines the example-based rewrite command, which applies
+// a refactor/eg template to the workspace.
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"strings"
+
+	goastutil "golang.org/x/tools/go/ast/astutil"
+	"golang.org/x/tools/gopls/internal/cache"
+	"golang.org/x/tools/gopls/internal/cache/metadata"
+	"golang.org/x/tools/gopls/internal/cache/parsego"
+	"golang.org/x/tools/gopls/internal/protocol"
+	"golang.org/x/tools/gopls/internal/util/bug"
+	"golang.org/x/tools/internal/astutil"
+	"golang.org/x/tools/internal/diff"
+	"golang.org/x/tools/internal/event"
+	"golang.org/x/tools/refactor/eg"
+)
+
+// ExampleRewrite applies the example-based refactoring defined by the
+// before and after functions of the template file at loc (see package
+// golang.org/x/tools/refactor/eg) to every package of the workspace.
+// Only the rules whose functions overlap the range of loc are applied.
+//
+// It returns the changes, and the location of each changed region.
+func ExampleRewrite(ctx context.Context, snapshot *cache.Snapshot, loc protocol.Location) ([]protocol.DocumentChange, []protocol.Location, error) {
+	ctx, done := event.Start(ctx, "golang.ExampleRewrite")
+	defer done()
+
+	tmplPkg, tmplPGF, err := NarrowestPackageForFile(ctx, snapshot, loc.URI)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(tmplPkg.ParseErrors())+len(tmplPkg.TypeErrors()) > 0 {
+		return nil, nil, fmt.Errorf("template package %s has errors", tmplPkg.Metadata().PkgPath)
+	}
+	start, end, err := tmplPGF.RangePos(loc.Range)
+	if err != nil {
+		return nil, nil, err
+	}
+	rules := templateRules(tmplPkg, tmplPGF, start, end)
+	if len(rules) == 0 {
+		return nil, nil, fmt.Errorf("no before and after functions found in template")
+	}
+
+	// Type-check all workspace packages.
+	mps, err := snapshot.WorkspaceMetadata(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	metadata.RemoveIntermediateTestVariants(&mps)
+	var ids []PackageID
+	for _, mp := range mps {
+		ids = append(ids, mp.ID)
+	}
+	pkgs, err := snapshot.TypeCheck(ctx, ids...)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var (
+		changes []protocol.DocumentChange
+		matches []protocol.Location
+		seen    = map[protocol.DocumentURI]bool{loc.URI: true} // don't rewrite the template
+	)
+	for _, pkg := range pkgs {
+		// eg assumes its input is well-typed.
+		if len(pkg.ParseErrors())+len(pkg.TypeErrors()) > 0 {
+			continue
+		}
+		var files []*parsego.File
+		for _, pgf := range pkg.CompiledGoFiles() {
+			if !seen[pgf.URI] {
+				seen[pgf.URI] = true
+				files = append(files, pgf)
+			}
+		}
+		if len(files) == 0 {
+			continue
+		}
+
+		xform, err := templateTransformer(tmplPkg, tmplPGF, pkg, rules)
+		if err != nil {
+			return nil, nil, fmt.Errorf("in package %s: %v", pkg.Metadata().PkgPath, err)
+		}
+		for _, pgf := range files {
+			got, err := transformFile(xform, pkg, pgf)
+			if err != nil {
+				return nil, nil, err
+			}
+			if got == nil {
+				continue // no matches
+			}
+			edits := diff.Bytes(pgf.Src, got)
+			pedits, err := protocol.EditsFromDiffEdits(pgf.Mapper, edits)
+			if err != nil {
+				return nil, nil, err
+			}
+			fh, err := snapshot.ReadFile(ctx, pgf.URI)
+			if err != nil {
+				return nil, nil, err
+			}
+			changes = append(changes, protocol.DocumentChangeEdit(fh, pedits))
+
+			// Report each changed line range as a match.
+			for _, edit := range diff.Lines(string(pgf.Src), string(got)) {
+				loc, err := pgf.Mapper.OffsetLocation(edit.Start, edit.End)
+				if err != nil {
+					return nil, nil, err
+				}
+				matches = append(matches, loc)
+			}
+		}
+	}
+	return changes, matches, nil
+}
+
+// templateRules returns the before functions of the template in pgf
+// whose declarations, or those of their corresponding after functions,
+// overlap the range [start, end). A before function must have an after
+// function with the same suffix and an identical signature.
+func templateRules(pkg *cache.Package, pgf *parsego.File, start, end token.Pos) []*ast.FuncDecl {
+	decls := make(map[string]*ast.FuncDecl)
+	for _, decl := range pgf.File.Decls {
+		if decl, ok := decl.(*ast.FuncDecl); ok && decl.Recv == nil {
+			decls[decl.Name.Name] = decl
+		}
+	}
+	var rules []*ast.FuncDecl
+	for _, decl := range pgf.File.Decls {
+		before, ok := decl.(*ast.FuncDecl)
+		if !ok || before.Recv != nil || !strings.HasPrefix(before.Name.Name, "before") {
+			continue
+		}
+		after := decls["after"+strings.TrimPrefix(before.Name.Name, "before")]
+		if after == nil || before.Body == nil || after.Body == nil {
+			continue
+		}
+		if !overlaps(before, start, end) && !overlaps(after, start, end) {
+			continue
+		}
+		beforeObj := pkg.TypesInfo().Defs[before.Name]
+		afterObj := pkg.TypesInfo().Defs[after.Name]
+		if beforeObj == nil || afterObj == nil || !types.Identical(beforeObj.Type(), afterObj.Type()) {
+			continue
+		}
+		rules = append(rules, before)
+	}
+	return rules
+}
+
+// overlaps reports whether node n overlaps the range [start, end].
+func overlaps(n ast.Node, start, end token.Pos) bool {
+	return n.Pos() <= end && start <= n.End()
+}
+
+// templateTransformer returns an eg.Transformer for the given rules of
+// the template file, whose objects are commensurable with those of pkg.
+//
+// Packages type-checked by gopls may belong to different importers, so
+// unless the template belongs to pkg itself, the template file is
+// type-checked anew, resolving its imports to the dependencies of pkg.
+func templateTransformer(tmplPkg *cache.Package, tmplPGF *parsego.File, pkg *cache.Package, rules []*ast.FuncDecl) (*eg.Transformer, error) {
+	file, typesPkg, info := tmplPGF.File, tmplPkg.Types(), tmplPkg.TypesInfo()
+	if pgf, err := pkg.File(tmplPGF.URI); err == nil {
+		file, typesPkg, info = pgf.File, pkg.Types(), pkg.TypesInfo()
+	} else {
+		info = &types.Info{
+			Types:      make(map[ast.Expr]types.TypeAndValue),
+			Defs:       make(map[*ast.Ident]types.Object),
+			Uses:       make(map[*ast.Ident]types.Object),
+			Selections: make(map[*ast.SelectorExpr]*types.Selection),
+		}
+		cfg := &types.Config{
+			Importer: importerFunc(func(path string) (*types.Package, error) {
+				if path == pkg.Types().Path() {
+					return pkg.Types(), nil
+				}
+				if p := pkg.DependencyTypes(PackagePath(path)); p != nil {
+					return p, nil
+				}
+				// A package that pkg does not depend upon may be
+				// referenced only by the replacements, so the
+				// template's own realm suffices.
+				if p := tmplPkg.DependencyTypes(PackagePath(path)); p != nil {
+					return p, nil
+				}
+				return nil, fmt.Errorf("package %q not found", path)
+			}),
+			Sizes: pkg.TypesSizes(),
+		}
+		var err error
+		typesPkg, err = cfg.Check(tmplPkg.Types().Path(), tmplPkg.FileSet(), []*ast.File{file}, info)
+		if err != nil {
+			return nil, fmt.Errorf("type-checking template: %v", err)
+		}
+	}
+
+	// Filter the template down to the selected rules.
+	names := make(map[string]bool)
+	for _, before := range rules {
+		names[before.Name.Name] = true
+		names["after"+strings.TrimPrefix(before.Name.Name, "before")] = true
+	}
+	filtered := *file // shallow copy
+	filtered.Decls = nil
+	for _, decl := range file.Decls {
+		if fn, ok := decl.(*ast.FuncDecl); ok && fn.Recv == nil &&
+			(strings.HasPrefix(fn.Name.Name, "before") || strings.HasPrefix(fn.Name.Name, "after")) &&
+			!names[fn.Name.Name] {
+			continue
+		}
+		filtered.Decls = append(filtered.Decls, decl)
+	}
+
+	return eg.NewTransformer(pkg.FileSet(), typesPkg, &filtered, info, false)
+}
+
+// importerFunc is an implementation of types.Importer.
+type importerFunc func(path string) (*types.Package, error)
+
+func (f importerFunc) Import(path string) (*types.Package, error) { return f(path) }
+
+// transformFile applies the transformer to a copy of the syntax of
+// pgf, and returns the formatted result, or nil if there were no
+// matches.
+func transformFile(xform *eg.Transformer, pkg *cache.Package, pgf *parsego.File) (_ []byte, err error) {
+	// The syntax and type information of packages are shared,
+	// so make a copy for the transformer to mutate.
+	file, info := cloneSyntax(pgf.File, pkg.TypesInfo())
+
+	defer func() {
+		if x := recover(); x != nil {
+			err = bug.Errorf("example-based rewrite of %s failed: %v", pgf.URI, x)
+		}
+	}()
+	if xform.Transform(info, pkg.Types(), file) == 0 {
+		return nil, nil
+	}
+	var buf bytes.Buffer
+	if err := format.Node(&buf, pkg.FileSet(), file); err != nil {
+		return nil, err
+	}
+
+	// eg does not remove imports that are no longer used.
+	// Delete them from a fresh parse of the output, since
+	// deleting imports modifies the line table of the file.
+	var unused []*ast.ImportSpec
+	for _, imp := range pgf.File.Imports {
+		if pkgName := pkg.TypesInfo().PkgNameOf(imp); pkgName != nil &&
+			usesPkgName(pgf.File, pkg.TypesInfo(), pkgName) &&
+			!usesPkgName(file, info, pkgName) {
+			unused = append(unused, imp)
+		}
+	}
+	if len(unused) > 0 {
+		fset := token.NewFileSet()
+		f, err := parser.ParseFile(fset, pgf.URI.Path(), buf.Bytes(), parser.ParseComments|parser.SkipObjectResolution)
+		if err != nil {
+			return nil, err
+		}
+		for _, imp := range unused {
+			name := ""
+			if imp.Name != nil {
+				name = imp.Name.Name
+			}
+			goastutil.DeleteNamedImport(fset, f, name, string(metadata.UnquoteImportPath(imp)))
+		}
+		buf.Reset()
+		if err := format.Node(&buf, fset, f); err != nil {
+			return nil, err
+		}
+	}
+	return buf.Bytes(), nil
+}
+
+// usesPkgName reports whether file refers to the imported package
+// name. Identifiers without type information, such as those added by
+// eg, are assumed to refer to it if they have the same name.
+func usesPkgName(file *ast.File, info *types.Info, pkgName *types.PkgName) bool {
+	found := false
+	ast.Inspect(file, func(n ast.Node) bool {
+		if sel, ok := n.(*ast.SelectorExpr); ok && !found {
+			if id, ok := sel.X.(*ast.Ident); ok && id.Name == pkgName.Name() {
+				obj, ok := info.Uses[id]
+				found = !ok || obj == pkgName
+			}
+		}
+		return !found
+	})
+	return found
+}
+
+// cloneSyntax returns a deep copy of file, and of the subset of info
+// used by eg for its nodes.
+func cloneSyntax(file *ast.File, info *types.Info) (*ast.File, *types.Info) {
+	clone := astutil.CloneNode(file)
+	cinfo := &types.Info{
+		Types:      make(map[ast.Expr]types.TypeAndValue),
+		Defs:       make(map[*ast.Ident]types.Object),
+		Uses:       make(map[*ast.Ident]types.Object),
+		Selections: make(map[*ast.SelectorExpr]*types.Selection),
+	}
+
+	// The two trees have the same shape, so a preorder
+	// traversal visits corresponding nodes in the same order.
+	var nodes []ast.Node
+	ast.Inspect(file, func(n ast.Node) bool {
+		if n != nil {
+			nodes = append(nodes, n)
+		}
+		return true
+	})
+	i := 0
+	ast.Inspect(clone, func(n ast.Node) bool {
+		if n == nil {
+			return true
+		}
+		old := nodes[i]
+		i++
+		if e, ok := old.(ast.Expr); ok {
+			if tv, ok := info.Types[e]; ok {
+				cinfo.Types[n.(ast.Expr)] = tv
+			}
+		}
+		switch old := old.(type) {
+		case *ast.Ident:
+			if obj, ok := info.Defs[old]; ok {
+				cinfo.Defs[n.(*ast.Ident)] = obj
+			}
+			if obj, ok := info.Uses[old]; ok {
+				cinfo.Uses[n.(*ast.Ident)] = obj
+			}
+		case *ast.SelectorExpr:
+			if sel, ok := info.Selections[old]; ok {
+				cinfo.Selections[n.(*ast.SelectorExpr)] = sel
+			}
+		}
+		return true
+	})
+	return clone, cinfo
+}
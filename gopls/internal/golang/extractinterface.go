@@ -0,0 +1,437 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package golang
+
+// This file defines the "Extract interface" code actions.
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+	"slices"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/ast/inspector"
+	"golang.org/x/tools/gopls/internal/cache"
+	"golang.org/x/tools/gopls/internal/cache/parsego"
+	"golang.org/x/tools/gopls/internal/file"
+	"golang.org/x/tools/internal/refactor"
+	"golang.org/x/tools/internal/typesinternal"
+)
+
+// An interfaceParam is a parameter field of a function declaration
+// whose concrete type may be replaced by an interface.
+type interfaceParam struct {
+	pgf     *parsego.File
+	decl    *ast.FuncDecl
+	field   *ast.Field
+	methods []*types.Func // methods used on the parameters of field
+}
+
+// canExtractInterface reports whether the selection is a parameter of
+// a function declaration whose type, a named type T or a pointer *T,
+// can be replaced by an interface of the methods used on it within
+// the function.
+func canExtractInterface(pkg *cache.Package, pgf *parsego.File, start, end token.Pos) (*interfaceParam, error) {
+	rng, err := pgf.PosRange(start, end)
+	if err != nil {
+		return nil, err
+	}
+	info := findParam(pgf, rng)
+	if info == nil || info.field == nil || info.name == nil {
+		return nil, fmt.Errorf("no parameter selected")
+	}
+	if info.decl.Recv != nil {
+		return nil, fmt.Errorf("cannot extract an interface for a method parameter")
+	}
+	typ := pkg.TypesInfo().TypeOf(info.field.Type)
+	if !isExtractableType(typ) {
+		return nil, fmt.Errorf("parameter type %s is not a concrete named type or pointer to one", typ)
+	}
+	param, err := usedMethods(pkg, pgf, info.decl, info.field)
+	if err != nil {
+		return nil, err
+	}
+	if len(param.methods) == 0 {
+		return nil, fmt.Errorf("no methods of %s are used", info.name.Name)
+	}
+	return param, nil
+}
+
+// isExtractableType reports whether t is a named non-interface
+// type, or a pointer to one.
+func isExtractableType(t types.Type) bool {
+	if ptr, ok := types.Unalias(t).(*types.Pointer); ok {
+		t = ptr.Elem()
+	}
+	named, ok := types.Unalias(t).(*types.Named)
+	return ok && !types.IsInterface(named)
+}
+
+// usedMethods returns the parameter field of decl, along with the
+// methods used on its parameters. It reports an error if the
+// parameters are used in other ways, or if decl is used other than
+// in a call within pkg, since in either case the type of the
+// parameters cannot be changed to an interface. Uses in other
+// packages are checked by [checkCalls].
+func usedMethods(pkg *cache.Package, pgf *parsego.File, decl *ast.FuncDecl, field *ast.Field) (*interfaceParam, error) {
+	info := pkg.TypesInfo()
+	if decl.Body == nil {
+		return nil, fmt.Errorf("function %s has no body", decl.Name.Name)
+	}
+	if variadic, ok := field.Type.(*ast.Ellipsis); ok {
+		return nil, fmt.Errorf("cannot extract an interface for variadic parameter of type %s", types.ExprString(variadic.Elt))
+	}
+	typ := info.TypeOf(field.Type)
+	mset := types.NewMethodSet(typ)
+
+	objs := make(map[types.Object]bool)
+	for _, id := range field.Names {
+		if obj := info.Defs[id]; obj != nil {
+			objs[obj] = true
+		}
+	}
+	if len(objs) == 0 {
+		return nil, fmt.Errorf("parameter is unnamed")
+	}
+
+	param := &interfaceParam{pgf: pgf, decl: decl, field: field}
+	var err error
+	curBody, _ := pgf.Cursor().FindNode(decl.Body)
+	for cur := range curBody.Preorder((*ast.Ident)(nil)) {
+		id := cur.Node().(*ast.Ident)
+		if !objs[info.Uses[id]] {
+			continue
+		}
+		// A nil pointer would become a non-nil interface.
+		if bin, ok := cur.Parent().Node().(*ast.BinaryExpr); ok &&
+			(info.Types[bin.X].IsNil() || info.Types[bin.Y].IsNil()) {
+			err = fmt.Errorf("%s is compared with nil", id.Name)
+			break
+		}
+		sel, ok := cur.Parent().Node().(*ast.SelectorExpr)
+		if !ok || sel.X != id {
+			err = fmt.Errorf("%s is used other than to call a method", id.Name)
+			break
+		}
+		selection := info.Selections[sel]
+		if selection == nil || selection.Kind() != types.MethodVal {
+			err = fmt.Errorf("%s is used other than to call a method", id.Name)
+			break
+		}
+		method := selection.Obj().(*types.Func)
+		if mset.Lookup(method.Pkg(), method.Name()) == nil {
+			err = fmt.Errorf("method %s is not in the method set of %s", method.Name(), typ)
+			break
+		}
+		if !method.Exported() && method.Pkg() != pkg.Types() {
+			err = fmt.Errorf("method %s is not exported", method.Name())
+			break
+		}
+		if !accessibleType(method.Signature(), pkg.Types()) {
+			err = fmt.Errorf("signature of method %s refers to inaccessible types", method.Name())
+			break
+		}
+		if !slices.Contains(param.methods, method) {
+			param.methods = append(param.methods, method)
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// The function must be used only in calls,
+	// whose arguments remain assignable to the interface.
+	fn := info.Defs[decl.Name]
+	for _, pgf := range pkg.CompiledGoFiles() {
+		for cur := range pgf.Cursor().Preorder((*ast.Ident)(nil)) {
+			id := cur.Node().(*ast.Ident)
+			if info.Uses[id] == fn && !isCalled(cur) {
+				return nil, fmt.Errorf("function %s is used other than in a call", decl.Name.Name)
+			}
+		}
+	}
+	return param, nil
+}
+
+// isCalled reports whether the identifier at cur, a reference to a
+// function, is the operator of a call, possibly qualified by its
+// package, parenthesized, or explicitly instantiated.
+func isCalled(cur inspector.Cursor) bool {
+	if sel, ok := cur.Parent().Node().(*ast.SelectorExpr); ok && sel.Sel == cur.Node() {
+		cur = cur.Parent()
+	}
+	for is[*ast.IndexExpr](cur.Parent().Node()) ||
+		is[*ast.IndexListExpr](cur.Parent().Node()) ||
+		is[*ast.ParenExpr](cur.Parent().Node()) {
+		cur = cur.Parent()
+	}
+	call, ok := cur.Parent().Node().(*ast.CallExpr)
+	return ok && call.Fun == cur.Node()
+}
+
+// checkCalls reports an error if the function declared by decl in
+// pgf is referenced anywhere in the workspace, including in other
+// packages and in tests, other than as the operator of a call.
+// A function value, for example, has a type that would change.
+func checkCalls(ctx context.Context, snapshot *cache.Snapshot, pgf *parsego.File, decl *ast.FuncDecl) error {
+	rng, err := pgf.NodeRange(decl.Name)
+	if err != nil {
+		return err
+	}
+	fh, err := snapshot.ReadFile(ctx, pgf.URI)
+	if err != nil {
+		return err
+	}
+	refs, err := References(ctx, snapshot, fh, rng, false)
+	if err != nil {
+		return fmt.Errorf("finding references to %s: %v", decl.Name.Name, err)
+	}
+	for _, ref := range refs {
+		fh, err := snapshot.ReadFile(ctx, ref.URI)
+		if err != nil {
+			return err
+		}
+		if snapshot.FileKind(fh) != file.Go {
+			return fmt.Errorf("function %s is referenced from %s", decl.Name.Name, ref.URI.Base())
+		}
+		refpgf, err := snapshot.ParseGo(ctx, fh, parsego.Full)
+		if err != nil {
+			return err
+		}
+		start, end, err := refpgf.RangePos(ref.Range)
+		if err != nil {
+			return err
+		}
+		cur, ok := refpgf.Cursor().FindByPos(start, end)
+		if !ok || !is[*ast.Ident](cur.Node()) || !isCalled(cur) {
+			return fmt.Errorf("function %s is used other than in a call in %s", decl.Name.Name, ref.URI.Base())
+		}
+	}
+	return nil
+}
+
+// accessibleType reports whether all named types mentioned by t are
+// accessible from pkg.
+func accessibleType(t types.Type, pkg *types.Package) bool {
+	switch t := types.Unalias(t).(type) {
+	case *types.Named:
+		obj := t.Obj()
+		if obj.Pkg() != nil && obj.Pkg() != pkg && !obj.Exported() {
+			return false
+		}
+		for t := range t.TypeArgs().Types() {
+			if !accessibleType(t, pkg) {
+				return false
+			}
+		}
+		return true
+	case *types.Pointer:
+		return accessibleType(t.Elem(), pkg)
+	case *types.Slice:
+		return accessibleType(t.Elem(), pkg)
+	case *types.Array:
+		return accessibleType(t.Elem(), pkg)
+	case *types.Chan:
+		return accessibleType(t.Elem(), pkg)
+	case *types.Map:
+		return accessibleType(t.Key(), pkg) && accessibleType(t.Elem(), pkg)
+	case *types.Signature:
+		return accessibleType(t.Params(), pkg) && accessibleType(t.Results(), pkg)
+	case *types.Tuple:
+		for v := range t.Variables() {
+			if !accessibleType(v.Type(), pkg) {
+				return false
+			}
+		}
+		return true
+	case *types.Struct:
+		for field := range t.Fields() {
+			if !field.Exported() && field.Pkg() != pkg || !accessibleType(field.Type(), pkg) {
+				return false
+			}
+		}
+		return true
+	case *types.Interface:
+		for method := range t.Methods() {
+			if !method.Exported() && method.Pkg() != pkg || !accessibleType(method.Type(), pkg) {
+				return false
+			}
+		}
+		return true
+	}
+	return true // basic types, type parameters
+}
+
+// interfaceParams returns the parameter fields of all function
+// declarations in pkg whose type is identical to typ and may be
+// replaced by an interface, ignoring the others.
+func interfaceParams(pkg *cache.Package, typ types.Type) []*interfaceParam {
+	var params []*interfaceParam
+	for _, pgf := range pkg.CompiledGoFiles() {
+		for _, decl := range pgf.File.Decls {
+			decl, ok := decl.(*ast.FuncDecl)
+			if !ok || decl.Recv != nil {
+				continue
+			}
+			for _, field := range decl.Type.Params.List {
+				if !types.Identical(pkg.TypesInfo().TypeOf(field.Type), typ) {
+					continue
+				}
+				if param, err := usedMethods(pkg, pgf, decl, field); err == nil && len(param.methods) > 0 {
+					params = append(params, param)
+				}
+			}
+		}
+	}
+	return params
+}
+
+// extractInterfaceOne is a [fixer] that replaces the type of the
+// selected parameter by a new interface type declaring the methods
+// used on it.
+func extractInterfaceOne(ctx context.Context, snapshot *cache.Snapshot, pkg *cache.Package, pgf *parsego.File, start, end token.Pos) (*token.FileSet, *analysis.SuggestedFix, error) {
+	param, err := canExtractInterface(pkg, pgf, start, end)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := checkCalls(ctx, snapshot, param.pgf, param.decl); err != nil {
+		return nil, nil, err
+	}
+	return extractInterface(pkg, param, []*interfaceParam{param})
+}
+
+// extractInterfaceAll is a [fixer] that replaces the type of the
+// selected parameter, and of all other parameters of the same type in
+// functions of the package that use only its methods, by a new
+// interface type declaring the methods used on any of them.
+// Unlike most fixers, it edits every file of the package.
+func extractInterfaceAll(ctx context.Context, snapshot *cache.Snapshot, pkg *cache.Package, pgf *parsego.File, start, end token.Pos) (*token.FileSet, *analysis.SuggestedFix, error) {
+	param, err := canExtractInterface(pkg, pgf, start, end)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := checkCalls(ctx, snapshot, param.pgf, param.decl); err != nil {
+		return nil, nil, err
+	}
+	// Skip the functions that are used other than in calls elsewhere.
+	var (
+		params  []*interfaceParam
+		checked = map[*ast.FuncDecl]bool{param.decl: true}
+	)
+	for _, p := range interfaceParams(pkg, pkg.TypesInfo().TypeOf(param.field.Type)) {
+		ok, seen := checked[p.decl]
+		if !seen {
+			ok = checkCalls(ctx, snapshot, p.pgf, p.decl) == nil
+			checked[p.decl] = ok
+		}
+		if ok {
+			params = append(params, p)
+		}
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, nil, err
+	}
+	return extractInterface(pkg, param, params)
+}
+
+// extractInterface declares a new interface type before the
+// declaration of the selected parameter, and uses it as the type of
+// each of params.
+func extractInterface(pkg *cache.Package, selected *interfaceParam, params []*interfaceParam) (*token.FileSet, *analysis.SuggestedFix, error) {
+	var (
+		info    = pkg.TypesInfo()
+		pgf     = selected.pgf
+		decl    = selected.decl
+		typ     = info.TypeOf(selected.field.Type)
+		methods []*types.Func
+	)
+	for _, param := range params {
+		for _, method := range param.methods {
+			if !slices.Contains(methods, method) {
+				methods = append(methods, method)
+			}
+		}
+	}
+	slices.SortFunc(methods, func(x, y *types.Func) int {
+		return strings.Compare(x.Name(), y.Name())
+	})
+
+	// Choose a name that is not declared in the scope of any of the
+	// functions whose parameters use it. It is exported if any of them is.
+	name := interfaceName(typ, slices.ContainsFunc(params, func(param *interfaceParam) bool {
+		return param.decl.Name.IsExported()
+	}))
+	for i := 0; ; i++ {
+		candidate := name
+		if i > 0 {
+			candidate = fmt.Sprintf("%s%d", name, i)
+		}
+		if !slices.ContainsFunc(params, func(param *interfaceParam) bool {
+			_, obj := info.Scopes[param.decl.Type].LookupParent(candidate, token.NoPos)
+			return obj != nil
+		}) {
+			name = candidate
+			break
+		}
+	}
+
+	// Format the methods, adding imports as needed.
+	var (
+		edits   []analysis.TextEdit
+		imports = make(map[string]bool)
+		buf     bytes.Buffer
+	)
+	qual := func(p *types.Package) string {
+		if p == pkg.Types() {
+			return ""
+		}
+		prefix, importEdits := refactor.AddImport(info, pgf.File, p.Name(), p.Path(), "", decl.Pos())
+		if !imports[p.Path()] {
+			imports[p.Path()] = true
+			edits = append(edits, importEdits...)
+		}
+		return strings.TrimSuffix(prefix, ".")
+	}
+	fmt.Fprintf(&buf, "type %s interface {\n", name)
+	for _, method := range methods {
+		sig := types.TypeString(method.Signature(), qual)
+		fmt.Fprintf(&buf, "\t%s%s\n", method.Name(), strings.TrimPrefix(sig, "func"))
+	}
+	buf.WriteString("}\n\n")
+
+	pos := decl.Pos()
+	if decl.Doc != nil {
+		pos = decl.Doc.Pos()
+	}
+	edits = append(edits, analysis.TextEdit{Pos: pos, End: pos, NewText: buf.Bytes()})
+	for _, param := range params {
+		edits = append(edits, analysis.TextEdit{
+			Pos:     param.field.Type.Pos(),
+			End:     param.field.Type.End(),
+			NewText: []byte(name),
+		})
+	}
+	return pkg.FileSet(), &analysis.SuggestedFix{TextEdits: edits}, nil
+}
+
+// interfaceName returns the preferred name of an interface type
+// abstracting the named type T (or *T) typ: TInterface.
+func interfaceName(typ types.Type, exported bool) string {
+	name := typesinternal.Unpointer(typ).(*types.Named).Obj().Name() + "Interface"
+	r, size := utf8.DecodeRuneInString(name)
+	if exported {
+		r = unicode.ToUpper(r)
+	} else {
+		r = unicode.ToLower(r)
+	}
+	return string(r) + name[size:]
+}
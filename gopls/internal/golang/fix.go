@@ -54,6 +54,9 @@ const (
 	fixExtractVariableAll      = "extract_variable_all"
 	fixExtractFunction         = "extract_function"
 	fixExtractMethod           = "extract_method"
+	fixExtractInterface        = "extract_interface"
+	fixExtractInterfaceAll     = "extract_interface_all"
+	fixGeneralize              = "generalize"
 	fixInlineCall              = "inline_call" // keep consistent with go/analysis/passes/inline Diagnostic.Category
 	fixInlineVariable          = "inline_variable"
 	fixInvertIfCondition       = "invert_if_condition"
@@ -88,6 +91,9 @@ func ApplyFix(ctx context.Context, fix string, snapshot *cache.Snapshot, fh file
 	if fix == unusedparams.FixCategory {
 		return removeParam(ctx, snapshot, fh, rng)
 	}
+	if fix == fixGeneralize {
+		return generalize(ctx, snapshot, fh, rng)
+	}
 
 	fixers := map[string]fixer{
 		// Fixes for analyzer-provided diagnostics.
@@ -98,6 +104,8 @@ func ApplyFix(ctx context.Context, fix string, snapshot *cache.Snapshot, fh file
 		// constructed directly by logic in server/code_action.
 		fixExtractFunction:         singleFile(extractFunction),
 		fixExtractMethod:           singleFile(extractMethod),
+		fixExtractInterface:        extractInterfaceOne,
+		fixExtractInterfaceAll:     extractInterfaceAll,
 		fixExtractVariable:         singleFile(extractVariableOne),
 		fixExtractVariableAll:      singleFile(extractVariableAll),
 		fixInlineCall:              inlineCall,
@@ -140,6 +148,10 @@ func ApplyFix(ctx context.Context, fix string, snapshot *cache.Snapshot, fh file
 				bug.Report("no token.File for TextEdit.Pos (#68818)")
 			case fixExtractMethod:
 				bug.Report("no token.File for TextEdit.Pos (#68818)")
+			case fixExtractInterface:
+				bug.Report("no token.File for TextEdit.Pos (#68818)")
+			case fixExtractInterfaceAll:
+				bug.Report("no token.File for TextEdit.Pos (#68818)")
 			case fixExtractVariable:
 				bug.Report("no token.File for TextEdit.Pos (#68818)")
 			case fixExtractVariableAll:
@@ -18,9 +18,11 @@ import (
 	"text/scanner"
 
 	"golang.org/x/tools/gopls/internal/cache"
+	"golang.org/x/tools/gopls/internal/cache/metadata"
 	"golang.org/x/tools/gopls/internal/cache/parsego"
 	"golang.org/x/tools/gopls/internal/file"
 	"golang.org/x/tools/gopls/internal/protocol"
+	"golang.org/x/tools/gopls/internal/settings"
 	"golang.org/x/tools/gopls/internal/util/safetoken"
 	"golang.org/x/tools/gopls/internal/util/tokeninternal"
 	"golang.org/x/tools/internal/diff"
@@ -130,6 +132,9 @@ func computeImportEdits(ctx context.Context, pgf *parsego.File, snapshot *cache.
 	goroot := snapshot.View().Folder().Env.GOROOT
 	filename := pgf.URI.Path()
 
+	mp, _ := snapshot.NarrowestMetadataForFile(ctx, pgf.URI) // nil on error
+	setImportGroups(options, snapshot.Options(), mp)
+
 	source := snapshot.NewGoplsSource()
 	// imports require a current metadata graph
 	// TODO(rfindley): improve the API
@@ -159,10 +164,11 @@ func computeImportEdits(ctx context.Context, pgf *parsego.File, snapshot *cache.
 	return allFixEdits, editsPerFix, nil
 }
 
-// ComputeImportFixEdits returns text edits for a single import fix.
-func ComputeImportFixEdits(localPrefix string, src []byte, fixes ...*imports.ImportFix) ([]protocol.TextEdit, error) {
-	options := &imports.Options{
-		LocalPrefix: localPrefix,
+// ComputeImportFixEdits returns text edits for a single import fix
+// to a file of package mp (which may be nil), grouping imports
+// according to the user's settings.
+func ComputeImportFixEdits(options *settings.Options, mp *metadata.Package, src []byte, fixes ...*imports.ImportFix) ([]protocol.TextEdit, error) {
+	opts := &imports.Options{
 		// Defaults.
 		AllErrors:  true,
 		Comments:   true,
@@ -171,7 +177,20 @@ func ComputeImportFixEdits(localPrefix string, src []byte, fixes ...*imports.Imp
 		TabIndent:  true,
 		TabWidth:   8,
 	}
-	return computeFixEdits(src, options, fixes)
+	setImportGroups(opts, options, mp)
+	return computeFixEdits(src, opts, fixes)
+}
+
+// setImportGroups sets the import grouping policy of opts from the
+// user's settings, for a file of package mp (which may be nil).
+func setImportGroups(opts *imports.Options, options *settings.Options, mp *metadata.Package) {
+	opts.LocalPrefix = options.Local
+	if options.ImportGroups != nil {
+		opts.Groups, _ = imports.ParseGroups(options.ImportGroups) // validated by settings
+		if mp != nil && mp.Module != nil {
+			opts.ModulePath = mp.Module.Path
+		}
+	}
 }
 
 func computeFixEdits(src []byte, options *imports.Options, fixes []*imports.ImportFix) ([]protocol.TextEdit, error) {
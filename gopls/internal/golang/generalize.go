@@ -0,0 +1,500 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package golang
+
+// This file defines the "Generalize" code action, which makes a function
+// generic in one of the types it uses, optionally merging near-duplicate
+// functions that differ only in that type.
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"slices"
+	"strings"
+
+	"golang.org/x/tools/gopls/internal/cache"
+	"golang.org/x/tools/gopls/internal/cache/parsego"
+	"golang.org/x/tools/gopls/internal/file"
+	"golang.org/x/tools/gopls/internal/protocol"
+	"golang.org/x/tools/gopls/internal/util/bug"
+	"golang.org/x/tools/gopls/internal/util/cursorutil"
+	"golang.org/x/tools/gopls/internal/util/safetoken"
+	"golang.org/x/tools/internal/astutil"
+	"golang.org/x/tools/internal/diff"
+	"golang.org/x/tools/internal/moremaps"
+	"golang.org/x/tools/internal/refactor"
+	"golang.org/x/tools/internal/typesinternal"
+	"golang.org/x/tools/internal/versions"
+)
+
+// A generalization describes a function to make generic in one of its
+// types, and the near-duplicates of it, if any, to merge into it.
+type generalization struct {
+	decls   []*ast.FuncDecl   // the primary function, followed by its duplicates
+	types   []types.Type      // for each decl, the type replaced by the type parameter
+	replace map[ast.Expr]bool // type expressions within decls[0] to replace
+}
+
+// canGeneralize reports whether the selection is a type in the
+// parameters of a function declaration, which may be replaced by a type
+// parameter, or two or more complete function declarations that differ
+// only in one type, which may be merged into one generic function.
+func canGeneralize(pkg *cache.Package, pgf *parsego.File, start, end token.Pos) (*generalization, error) {
+	info := pkg.TypesInfo()
+	if !versions.AtLeast(info.FileVersions[pgf.File], versions.Go1_18) {
+		return nil, fmt.Errorf("type parameters require go1.18 or later")
+	}
+
+	// Are several complete function declarations selected?
+	var decls []*ast.FuncDecl
+	for _, decl := range pgf.File.Decls {
+		if start <= decl.Pos() && decl.End() <= end {
+			decl, ok := decl.(*ast.FuncDecl)
+			if !ok {
+				return nil, fmt.Errorf("selection contains declarations other than functions")
+			}
+			decls = append(decls, decl)
+		}
+	}
+	if len(decls) > 1 {
+		return canMerge(info, decls)
+	}
+
+	// Is a type within the parameters of a function selected?
+	curSel, ok := pgf.Cursor().FindByPos(start, end)
+	if !ok {
+		return nil, fmt.Errorf("no selection")
+	}
+	decl, _ := cursorutil.FirstEnclosing[*ast.FuncDecl](curSel)
+	if decl == nil || !(decl.Type.Params.Pos() <= start && end <= decl.Type.Params.End()) {
+		return nil, fmt.Errorf("selection is not within the parameters of a function")
+	}
+	if err := checkGeneralizable(decl); err != nil {
+		return nil, err
+	}
+	var typ types.Type
+	for cur := range curSel.Enclosing() {
+		e, _ := cur.Node().(ast.Expr)
+		field, isField := cur.Node().(*ast.Field)
+		if isField {
+			e = field.Type // a parameter name selects its type
+		}
+		if e != nil && isTypeName(info, e) {
+			typ = info.TypeOf(e)
+		}
+		if typ != nil || isField || e == nil {
+			break
+		}
+	}
+	if typ == nil {
+		return nil, fmt.Errorf("selection is not a named or predeclared type")
+	}
+	if types.IsInterface(typ) {
+		return nil, fmt.Errorf("cannot generalize interface type %s", typ)
+	}
+
+	// Replace all uses of the type in the declaration.
+	g := &generalization{
+		decls:   []*ast.FuncDecl{decl},
+		types:   []types.Type{typ},
+		replace: make(map[ast.Expr]bool),
+	}
+	for _, n := range []ast.Node{decl.Type, decl.Body} {
+		ast.Inspect(n, func(n ast.Node) bool {
+			if e, ok := n.(ast.Expr); ok && isTypeName(info, e) {
+				if types.Identical(info.TypeOf(e), typ) {
+					g.replace[e] = true
+				}
+				return false
+			}
+			return true
+		})
+	}
+	return g, nil
+}
+
+// canMerge reports whether the function declarations differ only in
+// the type denoted by some of their identifiers, and if so returns the
+// generalization that merges them.
+func canMerge(info *types.Info, decls []*ast.FuncDecl) (*generalization, error) {
+	for _, decl := range decls {
+		if err := checkGeneralizable(decl); err != nil {
+			return nil, err
+		}
+	}
+	d0 := decls[0]
+	g := &generalization{
+		decls: decls,
+		types: make([]types.Type, len(decls)),
+	}
+	for i, d := range decls[1:] {
+		var (
+			t0, ti  types.Type
+			replace = make(map[ast.Expr]bool)
+		)
+		identical := func(x, y *ast.Ident) bool {
+			if isTypeName(info, x) && isTypeName(info, y) {
+				tx, ty := info.TypeOf(x), info.TypeOf(y)
+				if types.Identical(tx, ty) {
+					return true
+				}
+				if t0 == nil {
+					t0, ti = tx, ty
+				}
+				replace[x] = true
+				return types.Identical(t0, tx) && types.Identical(ti, ty)
+			}
+			// A recursive call.
+			if info.Uses[x] == info.Defs[d0.Name] && info.Uses[y] == info.Defs[d.Name] {
+				return true
+			}
+			return x.Name == y.Name
+		}
+		if !astutil.Equal(d0.Type, d.Type, identical) || !astutil.Equal(d0.Body, d.Body, identical) {
+			return nil, fmt.Errorf("%s and %s differ other than in a type", d0.Name.Name, d.Name.Name)
+		}
+		if t0 == nil {
+			return nil, fmt.Errorf("%s and %s do not differ", d0.Name.Name, d.Name.Name)
+		}
+		if g.types[0] == nil {
+			g.types[0], g.replace = t0, replace
+		} else if !types.Identical(g.types[0], t0) || !moremaps.SameKeys(g.replace, replace) {
+			return nil, fmt.Errorf("%s and %s differ from %s in different ways", decls[1].Name.Name, d.Name.Name, d0.Name.Name)
+		}
+		g.types[i+1] = ti
+	}
+	return g, nil
+}
+
+// checkGeneralizable reports an error if decl cannot become generic.
+func checkGeneralizable(decl *ast.FuncDecl) error {
+	switch {
+	case decl.Recv != nil:
+		return fmt.Errorf("method %s cannot have type parameters", decl.Name.Name)
+	case decl.Type.TypeParams != nil:
+		return fmt.Errorf("function %s is already generic", decl.Name.Name)
+	case decl.Body == nil:
+		return fmt.Errorf("function %s has no body", decl.Name.Name)
+	}
+	return nil
+}
+
+// isTypeName reports whether e is an identifier or qualified identifier
+// that denotes a type.
+func isTypeName(info *types.Info, e ast.Expr) bool {
+	switch e.(type) {
+	case *ast.Ident, *ast.SelectorExpr:
+		tv, ok := info.Types[e]
+		return ok && tv.IsType()
+	}
+	return false
+}
+
+// orderedConstraint is the definition of [cmp.Ordered], which is used
+// for type checking in case cmp is not yet imported.
+const orderedConstraint = "interface{ ~int | ~int8 | ~int16 | ~int32 | ~int64 | ~uint | ~uint8 | ~uint16 | ~uint32 | ~uint64 | ~uintptr | ~float32 | ~float64 | ~string }"
+
+// generalize replaces the selected type in the parameters of a function
+// by a type parameter, or merges the selected functions into the first
+// of them, made generic in the type in which they differ.
+//
+// The constraint of the type parameter is the first of any, comparable,
+// [cmp.Ordered], or the union of the replaced types, with which the
+// declaring package type checks. Each reference to the functions is
+// updated, with explicit type arguments where they cannot be inferred.
+func generalize(ctx context.Context, snapshot *cache.Snapshot, fh file.Handle, rng protocol.Range) ([]protocol.DocumentChange, error) {
+	pkg, pgf, err := NarrowestPackageForFile(ctx, snapshot, fh.URI())
+	if err != nil {
+		return nil, err
+	}
+	start, end, err := pgf.RangePos(rng)
+	if err != nil {
+		return nil, err
+	}
+	g, err := canGeneralize(pkg, pgf, start, end)
+	if err != nil {
+		return nil, err
+	}
+	if len(pkg.ParseErrors())+len(pkg.TypeErrors()) > 0 {
+		return nil, fmt.Errorf("cannot generalize: package %s has errors", pkg.Metadata().PkgPath)
+	}
+	var (
+		info    = pkg.TypesInfo()
+		primary = g.decls[0]
+		edits   = make(map[protocol.DocumentURI][]diff.Edit)
+	)
+	offset := func(pos token.Pos) int {
+		return int(pos) - pgf.Tok.Base()
+	}
+
+	// Choose a name for the type parameter that is not used in the function.
+	tparam := "T"
+	for i := 1; mentionsName(primary, tparam); i++ {
+		tparam = fmt.Sprintf("T%d", i)
+	}
+
+	// Replace the type in the primary function, and delete the others.
+	for e := range g.replace {
+		edits[pgf.URI] = append(edits[pgf.URI], diff.Edit{Start: offset(e.Pos()), End: offset(e.End()), New: tparam})
+	}
+	for _, decl := range g.decls[1:] {
+		start := decl.Pos()
+		if decl.Doc != nil {
+			start = decl.Doc.Pos()
+		}
+		// Delete the rest of the line, if it is only a comment, and the
+		// following blank line.
+		end := offset(decl.End())
+		if eol := bytes.IndexByte(pgf.Src[end:], '\n'); eol >= 0 {
+			if rest := bytes.TrimSpace(pgf.Src[end : end+eol]); len(rest) == 0 || bytes.HasPrefix(rest, []byte("//")) {
+				end += eol + 1
+				if end < len(pgf.Src) && pgf.Src[end] == '\n' {
+					end++
+				}
+			}
+		}
+		edits[pgf.URI] = append(edits[pgf.URI], diff.Edit{Start: offset(start), End: end, New: ""})
+	}
+
+	// Update references to the functions.
+	mentions := make([]bool, 0, primary.Type.Params.NumFields()) // whether each parameter mentions the type
+	for _, field := range primary.Type.Params.List {
+		found := false
+		ast.Inspect(field.Type, func(n ast.Node) bool {
+			if e, ok := n.(ast.Expr); ok && g.replace[e] {
+				found = true
+			}
+			return !found
+		})
+		for range max(1, len(field.Names)) {
+			mentions = append(mentions, found)
+		}
+	}
+	for i := range g.decls {
+		refEdits, err := generalizeRefs(ctx, snapshot, pgf, g, i, tparam, mentions)
+		if err != nil {
+			return nil, err
+		}
+		for uri, e := range refEdits {
+			edits[uri] = append(edits[uri], e...)
+		}
+	}
+
+	// Choose the smallest constraint with which the package type checks.
+	type constraint struct{ check, text string }
+	candidates := []constraint{{"any", "any"}, {"comparable", "comparable"}}
+	var orderedEdits []diff.Edit
+	if versions.AtLeast(info.FileVersions[pgf.File], versions.Go1_21) {
+		prefix, importEdits := refactor.AddImport(info, pgf.File, "cmp", "cmp", "Ordered", primary.Pos())
+		for _, edit := range importEdits {
+			orderedEdits = append(orderedEdits, diff.Edit{Start: offset(edit.Pos), End: offset(edit.End), New: string(edit.NewText)})
+		}
+		candidates = append(candidates, constraint{orderedConstraint, prefix + "Ordered"})
+	}
+	var union []string
+	qual := typesinternal.FileQualifier(pgf.File, pkg.Types())
+	for _, t := range g.types {
+		if s := types.TypeString(t, qual); !slices.Contains(union, s) {
+			union = append(union, s)
+		}
+	}
+	candidates = append(candidates, constraint{strings.Join(union, " | "), strings.Join(union, " | ")})
+
+	logf := logger(ctx, "generalize", snapshot.Options().VerboseOutput)
+	var (
+		chosen *constraint
+		errs   []string
+	)
+	for _, c := range candidates {
+		tparams := diff.Edit{Start: offset(primary.Name.End()), End: offset(primary.Name.End()), New: "[" + tparam + " " + c.check + "]"}
+		files := make(map[protocol.DocumentURI]*ast.File)
+		for _, f := range pkg.CompiledGoFiles() {
+			fileEdits := edits[f.URI]
+			if f == pgf {
+				fileEdits = append(fileEdits[:len(fileEdits):len(fileEdits)], tparams)
+			}
+			if len(fileEdits) == 0 {
+				continue
+			}
+			src, err := diff.ApplyBytes(f.Src, dedupEdits(fileEdits))
+			if err != nil {
+				return nil, bug.Errorf("applying edits to %s: %v", f.URI, err)
+			}
+			file, err := parser.ParseFile(pkg.FileSet(), f.URI.Path(), src, parser.ParseComments|parser.SkipObjectResolution)
+			if err != nil {
+				return nil, bug.Errorf("parsing generalized %s: %v", f.URI, err)
+			}
+			files[f.URI] = file
+		}
+		if _, _, err := reTypeCheck(logf, pkg, files, false); err != nil {
+			errs = append(errs, fmt.Sprintf("%s: %v", c.text, err))
+			continue
+		}
+		chosen = &c
+		break
+	}
+	if chosen == nil {
+		return nil, fmt.Errorf("cannot generalize: no constraint is satisfied (%s)", strings.Join(errs, "; "))
+	}
+	edits[pgf.URI] = append(edits[pgf.URI], diff.Edit{Start: offset(primary.Name.End()), End: offset(primary.Name.End()), New: "[" + tparam + " " + chosen.text + "]"})
+	if chosen.check == orderedConstraint {
+		edits[pgf.URI] = append(edits[pgf.URI], orderedEdits...)
+	}
+
+	// Translate the edits into document changes.
+	var changes []protocol.DocumentChange
+	for uri, fileEdits := range edits {
+		fh, err := snapshot.ReadFile(ctx, uri)
+		if err != nil {
+			return nil, err
+		}
+		content, err := fh.Content()
+		if err != nil {
+			return nil, err
+		}
+		textedits, err := protocol.EditsFromDiffEdits(protocol.NewMapper(uri, content), dedupEdits(fileEdits))
+		if err != nil {
+			return nil, fmt.Errorf("computing edits for %s: %v", uri, err)
+		}
+		changes = append(changes, protocol.DocumentChangeEdit(fh, textedits))
+	}
+	return changes, nil
+}
+
+// generalizeRefs returns the edits to the references to the ith
+// function of g, which use the name of the primary function, with
+// explicit type arguments if they cannot be inferred from the
+// arguments of a call. mentions reports whether each parameter of the
+// generic function mentions its type parameter, tparam.
+func generalizeRefs(ctx context.Context, snapshot *cache.Snapshot, pgf *parsego.File, g *generalization, i int, tparam string, mentions []bool) (map[protocol.DocumentURI][]diff.Edit, error) {
+	decl := g.decls[i]
+	nameRng, err := pgf.NodeRange(decl.Name)
+	if err != nil {
+		return nil, err
+	}
+	fh, err := snapshot.ReadFile(ctx, pgf.URI)
+	if err != nil {
+		return nil, err
+	}
+	refs, err := References(ctx, snapshot, fh, nameRng, false)
+	if err != nil {
+		return nil, fmt.Errorf("finding references to %s: %v", decl.Name.Name, err)
+	}
+
+	edits := make(map[protocol.DocumentURI][]diff.Edit)
+	for _, ref := range refs {
+		refPkg, refPgf, err := NarrowestPackageForFile(ctx, snapshot, ref.URI)
+		if err != nil {
+			return nil, err
+		}
+		start, end, err := refPgf.RangePos(ref.Range)
+		if err != nil {
+			return nil, err
+		}
+		cur, ok := refPgf.Cursor().FindByPos(start, end)
+		if !ok {
+			return nil, bug.Errorf("reference to %s not found", decl.Name.Name)
+		}
+		id, ok := cur.Node().(*ast.Ident)
+		if !ok {
+			return nil, bug.Errorf("reference to %s is %T, not an identifier", decl.Name.Name, cur.Node())
+		}
+
+		startOff, endOff, err := safetoken.Offsets(refPgf.Tok, id.Pos(), id.End())
+		if err != nil {
+			return nil, err
+		}
+
+		// References within the primary function are recursive calls;
+		// those within the merged functions are deleted.
+		typeArg := types.TypeString(g.types[i], typesinternal.FileQualifier(refPgf.File, refPkg.Types()))
+		if ref.URI == pgf.URI {
+			within := func(d *ast.FuncDecl) bool {
+				start, end, err := safetoken.Offsets(pgf.Tok, d.Pos(), d.End())
+				return err == nil && start <= startOff && endOff <= end
+			}
+			if within(g.decls[0]) {
+				typeArg = tparam
+			} else if slices.ContainsFunc(g.decls[1:], within) {
+				continue
+			}
+		}
+
+		// Is the reference the operand of a call?
+		var e ast.Expr = id
+		if sel, ok := cur.Parent().Node().(*ast.SelectorExpr); ok && sel.Sel == id {
+			e, cur = sel, cur.Parent()
+		}
+		for is[*ast.ParenExpr](cur.Parent().Node()) {
+			cur = cur.Parent()
+		}
+		call, _ := cur.Parent().Node().(*ast.CallExpr)
+		inferable := call != nil && ast.Unparen(call.Fun) == e &&
+			inferableCall(refPkg.TypesInfo(), call, mentions)
+
+		text := ""
+		if i > 0 {
+			text = g.decls[0].Name.Name
+		}
+		if !inferable {
+			text += "[" + typeArg + "]"
+		}
+		if text == "" {
+			continue
+		}
+		if i == 0 {
+			startOff = endOff // insert type arguments
+		}
+		edits[ref.URI] = append(edits[ref.URI], diff.Edit{Start: startOff, End: endOff, New: text})
+	}
+	return edits, nil
+}
+
+// inferableCall reports whether the type argument of a call to a
+// generic function whose parameters do or do not mention the type
+// parameter (according to mentions) can be inferred from the types of
+// its arguments, because one of them is typed and corresponds to a
+// parameter that mentions the type parameter.
+func inferableCall(info *types.Info, call *ast.CallExpr, mentions []bool) bool {
+	for k, arg := range call.Args {
+		j := min(k, len(mentions)-1) // (extra arguments are variadic)
+		// (The recorded type of an untyped constant is its final type,
+		// so treat all constants as untyped.)
+		if tv, ok := info.Types[arg]; ok && j >= 0 && mentions[j] && tv.Value == nil && !tv.IsNil() {
+			return true
+		}
+	}
+	return false
+}
+
+// mentionsName reports whether any identifier within decl has the given
+// name.
+func mentionsName(decl *ast.FuncDecl, name string) bool {
+	found := false
+	ast.Inspect(decl, func(n ast.Node) bool {
+		if id, ok := n.(*ast.Ident); ok && id.Name == name {
+			found = true
+		}
+		return !found
+	})
+	return found
+}
+
+// dedupEdits returns the edits without duplicates, which arise when a
+// file belongs to several packages.
+func dedupEdits(edits []diff.Edit) []diff.Edit {
+	var res []diff.Edit
+	for _, edit := range edits {
+		if !slices.Contains(res, edit) {
+			res = append(res, edit)
+		}
+	}
+	return res
+}
@@ -0,0 +1,227 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package golang
+
+// This file defines the display of hot spots from pprof profiles.
+
+import (
+	"context"
+	"fmt"
+	"go/ast"
+	"go/token"
+	"maps"
+	"slices"
+	"strings"
+
+	"golang.org/x/tools/gopls/internal/cache"
+	"golang.org/x/tools/gopls/internal/cache/parsego"
+	"golang.org/x/tools/gopls/internal/file"
+	"golang.org/x/tools/gopls/internal/protocol"
+	"golang.org/x/tools/gopls/internal/protocol/command"
+	"golang.org/x/tools/gopls/internal/util/safetoken"
+	"golang.org/x/tools/internal/pprof"
+)
+
+// hotSpotThreshold is the minimum fraction of a profile's total that
+// a line or function must account for to be annotated.
+const hotSpotThreshold = 0.01
+
+// hotSpotStats records the flat and cumulative values of a line or
+// function, as fractions of the profile total.
+type hotSpotStats struct {
+	flat, cum float64
+}
+
+func (st hotSpotStats) String() string {
+	return fmt.Sprintf("flat %.1f%%, cum %.1f%%", 100*st.flat, 100*st.cum)
+}
+
+// fileHotSpots holds the hot spots of a single file.
+type fileHotSpots struct {
+	sampleType string               // e.g. "cpu"
+	lines      map[int]hotSpotStats // keyed by 1-based line
+	funcs      map[int]hotSpotStats // keyed by line of func declaration
+}
+
+// hotSpots returns the hot spots of the Go file uri according to the
+// snapshot's current pprof profile (see [cache.Snapshot.PprofProfile]),
+// or nil if there is no profile or it has no samples in the file.
+func hotSpots(ctx context.Context, snapshot *cache.Snapshot, uri protocol.DocumentURI) (*fileHotSpots, error) {
+	prof, err := snapshot.Pprof(ctx)
+	if err != nil || prof == nil || len(prof.SampleTypes) == 0 {
+		return nil, err
+	}
+	index := prof.DefaultValueIndex()
+
+	var total int64
+	for _, s := range prof.Samples {
+		if index < len(s.Values) {
+			total += s.Values[index]
+		}
+	}
+	if total == 0 {
+		return nil, nil
+	}
+
+	var pkgPath string
+	if mp, err := snapshot.NarrowestMetadataForFile(ctx, uri); err == nil {
+		pkgPath = string(mp.PkgPath)
+	}
+	inFile := func(f pprof.Frame) bool {
+		if f.File == uri.Path() {
+			return true
+		}
+		// The profile may have been recorded on another machine
+		// or with -trimpath, so also accept a frame whose function
+		// belongs to this package and whose file has the same base.
+		return pkgPath != "" &&
+			strings.HasSuffix(f.File, "/"+uri.Base()) &&
+			funcPackagePath(f.Function) == pkgPath
+	}
+
+	lines := make(map[int][2]int64) // flat, cum
+	funcs := make(map[int][2]int64)
+	for _, s := range prof.Samples {
+		if index >= len(s.Values) {
+			continue
+		}
+		v := s.Values[index]
+		// Each line or function is counted at most once
+		// per sample, even if it appears in the stack repeatedly
+		// (as with recursion).
+		var (
+			seenLines = make(map[int]bool)
+			seenFuncs = make(map[int]bool)
+		)
+		for i, f := range s.Stack {
+			if !inFile(f) {
+				continue
+			}
+			if f.Line > 0 {
+				st := lines[f.Line]
+				if i == 0 {
+					st[0] += v
+				}
+				if !seenLines[f.Line] {
+					seenLines[f.Line] = true
+					st[1] += v
+				}
+				lines[f.Line] = st
+			}
+			if f.StartLine > 0 {
+				st := funcs[f.StartLine]
+				if i == 0 {
+					st[0] += v
+				}
+				if !seenFuncs[f.StartLine] {
+					seenFuncs[f.StartLine] = true
+					st[1] += v
+				}
+				funcs[f.StartLine] = st
+			}
+		}
+	}
+	if len(lines) == 0 && len(funcs) == 0 {
+		return nil, nil
+	}
+
+	filter := func(m map[int][2]int64) map[int]hotSpotStats {
+		res := make(map[int]hotSpotStats)
+		for line, st := range m {
+			flat := float64(st[0]) / float64(total)
+			cum := float64(st[1]) / float64(total)
+			if cum >= hotSpotThreshold {
+				res[line] = hotSpotStats{flat, cum}
+			}
+		}
+		return res
+	}
+	return &fileHotSpots{
+		sampleType: prof.SampleTypes[index].Type,
+		lines:      filter(lines),
+		funcs:      filter(funcs),
+	}, nil
+}
+
+// funcPackagePath returns the package path of a qualified function
+// name such as "example.com/m/p.(*T).F", or "" if it has none.
+func funcPackagePath(name string) string {
+	slash := strings.LastIndexByte(name, '/')
+	if dot := strings.IndexByte(name[slash+1:], '.'); dot >= 0 {
+		return name[:slash+1+dot]
+	}
+	return ""
+}
+
+// hotSpotsCodeLens annotates each hot function declaration with its
+// share of the snapshot's pprof profile. The lens's command selects
+// the next profile of the pprofProfiles setting, or none.
+func hotSpotsCodeLens(ctx context.Context, snapshot *cache.Snapshot, fh file.Handle) ([]protocol.CodeLens, error) {
+	spots, err := hotSpots(ctx, snapshot, fh.URI())
+	if err != nil || spots == nil {
+		return nil, err
+	}
+	pgf, err := snapshot.ParseGo(ctx, fh, parsego.Full)
+	if err != nil {
+		return nil, err
+	}
+	var lenses []protocol.CodeLens
+	for _, decl := range pgf.File.Decls {
+		fn, ok := decl.(*ast.FuncDecl)
+		if !ok || fn.Body == nil {
+			continue
+		}
+		st, ok := spots.funcs[safetoken.Line(pgf.Tok, fn.Pos())]
+		if !ok {
+			continue
+		}
+		rng, err := pgf.PosRange(fn.Pos(), fn.Pos())
+		if err != nil {
+			return nil, err
+		}
+		title := fmt.Sprintf("%s: %v (%s)", spots.sampleType, st, snapshot.PprofProfile().Base())
+		cmd := command.NewToggleProfileCommand(title, command.ToggleProfileArgs{URI: fh.URI()})
+		lenses = append(lenses, protocol.CodeLens{Range: rng, Command: cmd})
+	}
+	return lenses, nil
+}
+
+// hotSpotHints appends an end-of-line inlay hint to each hot line of
+// the file between start and end.
+func hotSpotHints(ctx context.Context, snapshot *cache.Snapshot, pgf *parsego.File, start, end token.Pos, add func(protocol.InlayHint)) error {
+	spots, err := hotSpots(ctx, snapshot, pgf.URI)
+	if err != nil || spots == nil {
+		return err
+	}
+	tok := pgf.Tok
+	first, last := safetoken.Line(tok, start), safetoken.Line(tok, end)
+	for _, line := range slices.Sorted(maps.Keys(spots.lines)) {
+		if line < first || line > last || line > tok.LineCount() {
+			continue
+		}
+		// Position the hint at the end of the line.
+		lineEnd := tok.Size()
+		if line < tok.LineCount() {
+			next, err := safetoken.Offset(tok, tok.LineStart(line+1))
+			if err != nil {
+				return err
+			}
+			lineEnd = next - 1 // newline
+		}
+		if lineEnd > 0 && pgf.Src[lineEnd-1] == '\r' {
+			lineEnd--
+		}
+		st := spots.lines[line]
+		pos, err := pgf.Mapper.OffsetPosition(lineEnd)
+		if err != nil {
+			return err
+		}
+		add(protocol.InlayHint{
+			Position: pos,
+			Label:    []protocol.InlayHintLabelPart{{Value: fmt.Sprintf(" // %s: %v", spots.sampleType, st)}},
+		})
+	}
+	return nil
+}
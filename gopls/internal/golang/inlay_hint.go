@@ -48,7 +48,9 @@ func InlayHint(ctx context.Context, snapshot *cache.Snapshot, fh file.Handle, pR
 			enabledHints = append(enabledHints, fn)
 		}
 	}
-	if len(enabledHints) == 0 {
+	// Hot spots depend on the snapshot's profile, not just the package.
+	showHotSpots := inlayHintOptions.Hints[settings.HotSpots]
+	if len(enabledHints) == 0 && !showHotSpots {
 		return nil, nil
 	}
 
@@ -69,12 +71,17 @@ func InlayHint(ctx context.Context, snapshot *cache.Snapshot, fh file.Handle, pR
 	}
 
 	var hints []protocol.InlayHint
+	add := func(hint protocol.InlayHint) { hints = append(hints, hint) }
 	if curSubrange, ok := pgf.Cursor().FindByPos(start, end); ok {
-		add := func(hint protocol.InlayHint) { hints = append(hints, hint) }
 		for _, fn := range enabledHints {
 			fn(info, pgf, qual, curSubrange, add)
 		}
 	}
+	if showHotSpots {
+		if err := hotSpotHints(ctx, snapshot, pgf, start, end, add); err != nil {
+			return nil, err
+		}
+	}
 	return hints, nil
 }
 
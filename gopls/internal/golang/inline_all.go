@@ -33,10 +33,11 @@ import (
 // must type check repeatedly for each additional call.
 //
 // The provided post processing function is applied to the resulting source
-// after each transformation. This is necessary because we are using this
-// function to inline synthetic wrappers for the purpose of signature
-// rewriting. The delegated function has a fake name that doesn't exist in the
-// snapshot, and so we can't re-type check until we replace this fake name.
+// after each transformation, and may reject it by returning an error. This
+// is necessary because we are using this function to inline synthetic
+// wrappers for the purpose of signature rewriting. The delegated function
+// has a fake name that doesn't exist in the snapshot, and so we can't
+// re-type check until we replace this fake name.
 //
 // TODO(rfindley): this only works because removing a parameter is a very
 // narrow operation. A better solution would be to allow for ad-hoc snapshots
@@ -48,7 +49,7 @@ import (
 //
 // The code below notes where are assumptions are made that only hold true in
 // the case of parameter removal (annotated with 'Assumption:')
-func inlineAllCalls(ctx context.Context, snapshot *cache.Snapshot, pkg *cache.Package, pgf *parsego.File, origDecl *ast.FuncDecl, callee *inline.Callee, post func([]byte) []byte, opts *inline.Options) (_ map[protocol.DocumentURI][]byte, inlineErr error) {
+func inlineAllCalls(ctx context.Context, snapshot *cache.Snapshot, pkg *cache.Package, pgf *parsego.File, origDecl *ast.FuncDecl, callee *inline.Callee, post func(protocol.DocumentURI, []byte) ([]byte, error), opts *inline.Options) (_ map[protocol.DocumentURI][]byte, inlineErr error) {
 	// Collect references.
 	var refs []protocol.Location
 	{
@@ -267,7 +268,10 @@ func inlineAllCalls(ctx context.Context, snapshot *cache.Snapshot, pkg *cache.Pa
 			}
 
 			if post != nil {
-				content = post(content)
+				content, err = post(uri, content)
+				if err != nil {
+					return nil, err
+				}
 			}
 			if len(calls) <= 1 {
 				// No need to re-type check, as we've inlined all calls.
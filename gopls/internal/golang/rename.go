@@ -70,6 +70,7 @@ import (
 	"golang.org/x/tools/gopls/internal/cache/parsego"
 	"golang.org/x/tools/gopls/internal/file"
 	"golang.org/x/tools/gopls/internal/protocol"
+	"golang.org/x/tools/gopls/internal/protocol/command"
 	"golang.org/x/tools/gopls/internal/util/bug"
 	"golang.org/x/tools/gopls/internal/util/cursorutil"
 	"golang.org/x/tools/gopls/internal/util/pathutil"
@@ -328,26 +329,30 @@ func renameFuncSignature(ctx context.Context, pkg *cache.Package, pgf *parsego.F
 		}
 	}
 
-	var newParams []int
+	var newParams []command.ChangeSignatureParam
 	for name, field := range astutil.FlatFields(newType.Params) {
 		if name == nil {
 			return nil, fmt.Errorf("need named fields")
 		}
 		info, ok := oldParams[name.Name]
 		if !ok {
-			return nil, fmt.Errorf("couldn't find name %s: adding parameters not yet supported", name)
+			// A new parameter, whose value in existing calls is its zero value.
+			newParams = append(newParams, command.ChangeSignatureParam{
+				NewField: name.Name + " " + types.ExprString(field.Type),
+			})
+			continue
 		}
 		if newType := types.ExprString(field.Type); newType != info.typ {
 			return nil, fmt.Errorf("changing types (%s to %s) not yet supported", info.typ, newType)
 		}
-		newParams = append(newParams, info.idx)
+		newParams = append(newParams, command.ChangeSignatureParam{OldIndex: info.idx})
 	}
 
 	funcRng, err := pgf.PosRange(ftyp.Func, ftyp.Func)
 	if err != nil {
 		return nil, err
 	}
-	changes, err := ChangeSignature(ctx, snapshot, pkg, pgf, funcRng, newParams)
+	changes, err := ChangeSignature(ctx, snapshot, pkg, pgf, funcRng, SignatureChange{Params: newParams})
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,223 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package golang
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+	"slices"
+	"strings"
+
+	"golang.org/x/tools/gopls/internal/cache"
+	"golang.org/x/tools/gopls/internal/cache/metadata"
+	"golang.org/x/tools/gopls/internal/cache/parsego"
+	"golang.org/x/tools/gopls/internal/fuzzy"
+	"golang.org/x/tools/gopls/internal/protocol"
+	"golang.org/x/tools/gopls/internal/protocol/command"
+	"golang.org/x/tools/internal/astutil"
+	"golang.org/x/tools/internal/event"
+	"golang.org/x/tools/internal/modindex"
+)
+
+// QuerySymbolIndex returns the exported package-level symbols of the
+// workspace packages of the snapshot, and of the module cache index
+// of its view, whose names match the fuzzy query of args, best
+// matches first, up to args.Limit if positive. Symbols of the
+// workspace take precedence over those of the module cache with the
+// same import path and name.
+func QuerySymbolIndex(ctx context.Context, snapshot *cache.Snapshot, args command.QuerySymbolIndexArgs) ([]command.IndexSymbol, error) {
+	ctx, done := event.Start(ctx, "golang.QuerySymbolIndex")
+	defer done()
+
+	if args.Query == "" {
+		return nil, fmt.Errorf("empty query")
+	}
+	var kinds []modindex.LexType
+	for _, k := range args.Kinds {
+		kind, ok := lexTypes[k]
+		if !ok {
+			return nil, fmt.Errorf("invalid symbol kind %q (want func, type, var, or const)", k)
+		}
+		kinds = append(kinds, kind)
+	}
+	matcher := fuzzy.NewMatcher(args.Query)
+
+	type key struct{ importPath, name string }
+	seen := make(map[key]bool)
+	var result []command.IndexSymbol
+
+	// Search the workspace packages.
+	mps, err := snapshot.WorkspaceMetadata(ctx)
+	if err != nil {
+		return nil, err
+	}
+	mps = slices.DeleteFunc(mps, func(mp *metadata.Package) bool {
+		return mp.ForTest != "" || mp.Name == "main" || len(mp.CompiledGoFiles) == 0
+	})
+	ids := make([]metadata.PackageID, len(mps))
+	for i, mp := range mps {
+		ids[i] = mp.ID
+	}
+	syms, err := snapshot.Symbols(ctx, ids...)
+	if err != nil {
+		return nil, err
+	}
+	for i, pkg := range syms {
+		mp := mps[i]
+		for j, fileSyms := range pkg.Symbols {
+			for _, sym := range fileSyms {
+				// Fields and methods have dotted names.
+				if strings.Contains(sym.Name, ".") || !token.IsExported(sym.Name) {
+					continue
+				}
+				kind, ok := symbolLexType(sym.Kind)
+				if !ok || len(kinds) > 0 && !slices.Contains(kinds, kind) {
+					continue
+				}
+				score := matcher.Score(sym.Name)
+				if score <= 0 {
+					continue
+				}
+				k := key{string(mp.PkgPath), sym.Name}
+				if seen[k] {
+					continue
+				}
+				seen[k] = true
+				s := command.IndexSymbol{
+					Name:       sym.Name,
+					Kind:       kind.String(),
+					PkgName:    string(mp.Name),
+					ImportPath: string(mp.PkgPath),
+					Location:   &protocol.Location{URI: pkg.Files[j], Range: sym.Range},
+					Score:      float64(score),
+				}
+				if mp.Module != nil {
+					s.Version = mp.Module.Version
+				}
+				result = append(result, s)
+			}
+		}
+	}
+
+	// Search the module cache.
+	if ix, err := snapshot.View().ModcacheIndex(); err != nil {
+		event.Error(ctx, "reading module cache index", err)
+	} else if ix != nil {
+		matches := ix.Search(modindex.Query{
+			Match: func(name string) float64 { return float64(matcher.Score(name)) },
+			Kinds: kinds,
+			Limit: args.Limit,
+		})
+		for _, m := range matches {
+			k := key{m.ImportPath, m.Name}
+			if seen[k] {
+				continue
+			}
+			seen[k] = true
+			result = append(result, command.IndexSymbol{
+				Name:       m.Name,
+				Kind:       m.Type.String(),
+				PkgName:    m.PkgName,
+				ImportPath: m.ImportPath,
+				Signature:  m.Signature(),
+				Version:    m.Version,
+				Deprecated: m.Deprecated,
+				Score:      m.Score,
+			})
+		}
+	}
+
+	SortIndexSymbols(result)
+	if args.Limit > 0 && len(result) > args.Limit {
+		result = result[:args.Limit]
+	}
+
+	// Describe the functions of the workspace, which requires parsing.
+	for i := range result {
+		if s := &result[i]; s.Location != nil && s.Kind == "func" {
+			if err := describeFunc(ctx, snapshot, s); err != nil {
+				event.Error(ctx, "describing "+s.Name, err)
+			}
+		}
+	}
+	return result, nil
+}
+
+// SortIndexSymbols sorts symbols found by [QuerySymbolIndex] in
+// decreasing order of score, then by name and import path.
+func SortIndexSymbols(symbols []command.IndexSymbol) {
+	slices.SortStableFunc(symbols, func(x, y command.IndexSymbol) int {
+		if c := cmp.Compare(y.Score, x.Score); c != 0 {
+			return c
+		}
+		if c := cmp.Compare(x.Name, y.Name); c != 0 {
+			return c
+		}
+		return cmp.Compare(x.ImportPath, y.ImportPath)
+	})
+}
+
+var lexTypes = map[string]modindex.LexType{
+	"func":  modindex.Func,
+	"type":  modindex.Type,
+	"var":   modindex.Var,
+	"const": modindex.Const,
+}
+
+// symbolLexType returns the kind of package-level symbol
+// corresponding to the LSP symbol kind.
+func symbolLexType(kind protocol.SymbolKind) (modindex.LexType, bool) {
+	switch kind {
+	case protocol.Function:
+		return modindex.Func, true
+	case protocol.Class, protocol.Struct, protocol.Interface:
+		return modindex.Type, true
+	case protocol.Variable:
+		return modindex.Var, true
+	case protocol.Constant:
+		return modindex.Const, true
+	}
+	return 0, false
+}
+
+// describeFunc sets the signature and deprecation of the workspace
+// function s from its declaration. Named function types, which
+// symbols report as functions, are reclassified as types.
+func describeFunc(ctx context.Context, snapshot *cache.Snapshot, s *command.IndexSymbol) error {
+	fh, err := snapshot.ReadFile(ctx, s.Location.URI)
+	if err != nil {
+		return err
+	}
+	pgf, err := snapshot.ParseGo(ctx, fh, parsego.Full)
+	if err != nil {
+		return err
+	}
+	pos, _, err := pgf.RangePos(s.Location.Range)
+	if err != nil {
+		return err
+	}
+	for _, decl := range pgf.File.Decls {
+		switch decl := decl.(type) {
+		case *ast.FuncDecl:
+			if decl.Name.Pos() == pos {
+				s.Signature = types.ExprString(decl.Type)
+				s.Deprecated = astutil.Deprecation(decl.Doc) != ""
+				return nil
+			}
+		case *ast.GenDecl:
+			for _, spec := range decl.Specs {
+				if spec, ok := spec.(*ast.TypeSpec); ok && spec.Name.Pos() == pos {
+					s.Kind = modindex.Type.String()
+					return nil
+				}
+			}
+		}
+	}
+	return fmt.Errorf("no declaration of %s at %v", s.Name, s.Location)
+}
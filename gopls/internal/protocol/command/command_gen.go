@@ -36,6 +36,7 @@ const (
 	DiagnoseFiles           Command = "gopls.diagnose_files"
 	Doc                     Command = "gopls.doc"
 	EditGoDirective         Command = "gopls.edit_go_directive"
+	ExampleRewrite          Command = "gopls.example_rewrite"
 	ExtractToNewFile        Command = "gopls.extract_to_new_file"
 	FetchVulncheckResult    Command = "gopls.fetch_vulncheck_result"
 	FreeSymbols             Command = "gopls.free_symbols"
@@ -45,6 +46,7 @@ const (
 	ImplementInterface      Command = "gopls.implement_interface"
 	ListImports             Command = "gopls.list_imports"
 	ListKnownPackages       Command = "gopls.list_known_packages"
+	LoadCoverage            Command = "gopls.load_coverage"
 	LSP                     Command = "gopls.lsp"
 	MaybePromptForTelemetry Command = "gopls.maybe_prompt_for_telemetry"
 	MemStats                Command = "gopls.mem_stats"
@@ -54,6 +56,7 @@ const (
 	MoveType                Command = "gopls.move_type"
 	PackageSymbols          Command = "gopls.package_symbols"
 	Packages                Command = "gopls.packages"
+	QuerySymbolIndex        Command = "gopls.query_symbol_index"
 	RegenerateCgo           Command = "gopls.regenerate_cgo"
 	RemoveDependency        Command = "gopls.remove_dependency"
 	ResetGoModDiagnostics   Command = "gopls.reset_go_mod_diagnostics"
@@ -66,6 +69,7 @@ const (
 	StartProfile            Command = "gopls.start_profile"
 	StopProfile             Command = "gopls.stop_profile"
 	Tidy                    Command = "gopls.tidy"
+	ToggleProfile           Command = "gopls.toggle_profile"
 	UpdateGoSum             Command = "gopls.update_go_sum"
 	UpgradeDependency       Command = "gopls.upgrade_dependency"
 	Vendor                  Command = "gopls.vendor"
@@ -87,6 +91,7 @@ var Commands = []Command{
 	DiagnoseFiles,
 	Doc,
 	EditGoDirective,
+	ExampleRewrite,
 	ExtractToNewFile,
 	FetchVulncheckResult,
 	FreeSymbols,
@@ -96,6 +101,7 @@ var Commands = []Command{
 	ImplementInterface,
 	ListImports,
 	ListKnownPackages,
+	LoadCoverage,
 	LSP,
 	MaybePromptForTelemetry,
 	MemStats,
@@ -105,6 +111,7 @@ var Commands = []Command{
 	MoveType,
 	PackageSymbols,
 	Packages,
+	QuerySymbolIndex,
 	RegenerateCgo,
 	RemoveDependency,
 	ResetGoModDiagnostics,
@@ -117,6 +124,7 @@ var Commands = []Command{
 	StartProfile,
 	StopProfile,
 	Tidy,
+	ToggleProfile,
 	UpdateGoSum,
 	UpgradeDependency,
 	Vendor,
@@ -201,6 +209,12 @@ func Dispatch(ctx context.Context, params *protocol.ExecuteCommandParams, s Inte
 			return nil, err
 		}
 		return nil, s.EditGoDirective(ctx, a0)
+	case ExampleRewrite:
+		var a0 ExampleRewriteArgs
+		if err := UnmarshalArgs(params.Arguments, &a0); err != nil {
+			return nil, err
+		}
+		return s.ExampleRewrite(ctx, a0)
 	case ExtractToNewFile:
 		var a0 protocol.Location
 		if err := UnmarshalArgs(params.Arguments, &a0); err != nil {
@@ -256,6 +270,12 @@ func Dispatch(ctx context.Context, params *protocol.ExecuteCommandParams, s Inte
 			return nil, err
 		}
 		return s.ListKnownPackages(ctx, a0)
+	case LoadCoverage:
+		var a0 LoadCoverageArgs
+		if err := UnmarshalArgs(params.Arguments, &a0); err != nil {
+			return nil, err
+		}
+		return nil, s.LoadCoverage(ctx, a0)
 	case LSP:
 		var a0 LSPArgs
 		if err := UnmarshalArgs(params.Arguments, &a0); err != nil {
@@ -302,6 +322,12 @@ func Dispatch(ctx context.Context, params *protocol.ExecuteCommandParams, s Inte
 			return nil, err
 		}
 		return s.Packages(ctx, a0)
+	case QuerySymbolIndex:
+		var a0 QuerySymbolIndexArgs
+		if err := UnmarshalArgs(params.Arguments, &a0); err != nil {
+			return nil, err
+		}
+		return s.QuerySymbolIndex(ctx, a0)
 	case RegenerateCgo:
 		var a0 URIArg
 		if err := UnmarshalArgs(params.Arguments, &a0); err != nil {
@@ -371,6 +397,12 @@ func Dispatch(ctx context.Context, params *protocol.ExecuteCommandParams, s Inte
 			return nil, err
 		}
 		return nil, s.Tidy(ctx, a0)
+	case ToggleProfile:
+		var a0 ToggleProfileArgs
+		if err := UnmarshalArgs(params.Arguments, &a0); err != nil {
+			return nil, err
+		}
+		return nil, s.ToggleProfile(ctx, a0)
 	case UpdateGoSum:
 		var a0 URIArgs
 		if err := UnmarshalArgs(params.Arguments, &a0); err != nil {
@@ -499,6 +531,14 @@ func NewEditGoDirectiveCommand(title string, a0 EditGoDirectiveArgs) *protocol.C
 	}
 }
 
+func NewExampleRewriteCommand(title string, a0 ExampleRewriteArgs) *protocol.Command {
+	return &protocol.Command{
+		Title:     title,
+		Command:   ExampleRewrite.String(),
+		Arguments: MustMarshalArgs(a0),
+	}
+}
+
 func NewExtractToNewFileCommand(title string, a0 protocol.Location) *protocol.Command {
 	return &protocol.Command{
 		Title:     title,
@@ -571,6 +611,14 @@ func NewListKnownPackagesCommand(title string, a0 URIArg) *protocol.Command {
 	}
 }
 
+func NewLoadCoverageCommand(title string, a0 LoadCoverageArgs) *protocol.Command {
+	return &protocol.Command{
+		Title:     title,
+		Command:   LoadCoverage.String(),
+		Arguments: MustMarshalArgs(a0),
+	}
+}
+
 func NewLSPCommand(title string, a0 LSPArgs) *protocol.Command {
 	return &protocol.Command{
 		Title:     title,
@@ -643,6 +691,14 @@ func NewPackagesCommand(title string, a0 PackagesArgs) *protocol.Command {
 	}
 }
 
+func NewQuerySymbolIndexCommand(title string, a0 QuerySymbolIndexArgs) *protocol.Command {
+	return &protocol.Command{
+		Title:     title,
+		Command:   QuerySymbolIndex.String(),
+		Arguments: MustMarshalArgs(a0),
+	}
+}
+
 func NewRegenerateCgoCommand(title string, a0 URIArg) *protocol.Command {
 	return &protocol.Command{
 		Title:     title,
@@ -739,6 +795,14 @@ func NewTidyCommand(title string, a0 URIArgs) *protocol.Command {
 	}
 }
 
+func NewToggleProfileCommand(title string, a0 ToggleProfileArgs) *protocol.Command {
+	return &protocol.Command{
+		Title:     title,
+		Command:   ToggleProfile.String(),
+		Arguments: MustMarshalArgs(a0),
+	}
+}
+
 func NewUpdateGoSumCommand(title string, a0 URIArgs) *protocol.Command {
 	return &protocol.Command{
 		Title:     title,
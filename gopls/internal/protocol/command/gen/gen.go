@@ -129,6 +129,10 @@ func Generate() ([]byte, error) {
 					return false
 				case *types.Slice:
 					return fallible(t.Elem())
+				case *types.Pointer:
+					// A pointer to a basic type is an optional value.
+					_, basic := t.Elem().Underlying().(*types.Basic)
+					return !basic
 				case *types.Struct:
 					for field := range t.Fields() {
 						if fallible(field.Type()) {
@@ -143,6 +143,23 @@ type Interface interface {
 	// client-side logic in VS Code.)
 	GCDetails(context.Context, protocol.DocumentURI) error
 
+	// LoadCoverage: Load a coverage profile
+	//
+	// Loads a coverage profile, as produced by `go test
+	// -coverprofile`, and displays its data: uncovered code is
+	// reported as faded diagnostics, and the "coverage" code lens
+	// shows the coverage of each function. An empty Profile hides
+	// coverage. The profile is reloaded whenever it changes on disk.
+	LoadCoverage(context.Context, LoadCoverageArgs) error
+
+	// ToggleProfile: Toggle the displayed pprof profile
+	//
+	// Selects the pprof profile whose hot spots are displayed by
+	// the `hot_spots` code lens and the `hotSpots` inlay hint. If
+	// Profile is empty, it advances to the next profile named by
+	// the `pprofProfiles` setting, hiding hot spots after the last.
+	ToggleProfile(context.Context, ToggleProfileArgs) error
+
 	// LSP is a command that functions as a generic dispatcher, allowing clients
 	// to execute any LSP RPC through the "workspace/executeCommand" request.
 	//
@@ -268,10 +285,20 @@ type Interface interface {
 
 	// ChangeSignature: Perform a "change signature" refactoring
 	//
-	// This command is experimental, currently only supporting parameter removal.
+	// This command is experimental, currently supporting the removal,
+	// reordering and addition of parameters and results, and the
+	// conversion of a function to a method and back.
 	// Its signature will certainly change in the future (pun intended).
 	ChangeSignature(context.Context, ChangeSignatureArgs) (*protocol.WorkspaceEdit, error)
 
+	// ExampleRewrite: Apply an example-based rewrite to the workspace
+	//
+	// Applies the refactoring defined by the before and after functions
+	// of a template file, as described by package
+	// golang.org/x/tools/refactor/eg, to all packages in the workspace,
+	// and reports the location of each change.
+	ExampleRewrite(context.Context, ExampleRewriteArgs) (ExampleRewriteResult, error)
+
 	// DiagnoseFiles: Cause server to publish diagnostics for the specified files.
 	//
 	// This command is needed by the 'gopls {check,fix}' CLI subcommands.
@@ -328,6 +355,17 @@ type Interface interface {
 	// PackageSymbols: Return information about symbols in the given file's package.
 	PackageSymbols(context.Context, PackageSymbolsArgs) (PackageSymbolsResult, error)
 
+	// QuerySymbolIndex: Search workspace and module cache symbols by name.
+	//
+	// This command searches the exported package-level symbols of the
+	// workspace packages of each view, and those of the index of the
+	// module cache used for unimported completions, for names that
+	// match a fuzzy query. It requires no network access, so it can be
+	// used to discover offline which module provides a function.
+	//
+	// This command is needed by the 'gopls symbol_index' CLI subcommand.
+	QuerySymbolIndex(context.Context, QuerySymbolIndexArgs) (QuerySymbolIndexResult, error)
+
 	// ModifyTags: Add or remove struct tags on a given node.
 	ModifyTags(context.Context, ModifyTagsArgs, *protocol.InteractiveParams) error
 
@@ -350,6 +388,26 @@ type RunTestsArgs struct {
 
 	// Specific benchmarks to run, e.g. BenchmarkFoo.
 	Benchmarks []string
+
+	// Whether to record a coverage profile of the tests, and
+	// load it as if by the LoadCoverage command.
+	Coverage bool
+}
+
+type LoadCoverageArgs struct {
+	// A file in the view whose coverage should be displayed.
+	URI protocol.DocumentURI
+
+	// The coverage profile to load, or "" to hide coverage.
+	Profile protocol.DocumentURI
+}
+
+type ToggleProfileArgs struct {
+	// A file in the view whose profile should be changed.
+	URI protocol.DocumentURI
+
+	// The pprof profile to display, or "" for the next one.
+	Profile protocol.DocumentURI
 }
 
 type GenerateArgs struct {
@@ -624,7 +682,15 @@ type AddTelemetryCountersArgs struct {
 // either referencing a field in the old signature or by defining a new field:
 //   - If the element is an integer, it references a positional parameter in the
 //     old signature.
-//   - If the element is a string, it is parsed as a new field to add.
+//   - If the element is a string, it is parsed as a new field to add. It may
+//     specify a value after '=', such as "a int = 1", which is passed to the
+//     new parameter by each existing call, or returned as the new result by
+//     each existing return statement. The default is the zero value.
+//
+// Additionally, the NewRecv field may convert a function to a method whose
+// receiver is one of its parameters; conversely, for a method, the index -1
+// denotes its receiver, so that including -1 in NewParams converts the method
+// to a function.
 //
 // Suppose we have a function `F(a, b int) (string, error)`. Here are some
 // examples of refactoring this signature in practice, eliding the 'Location'
@@ -632,7 +698,8 @@ type AddTelemetryCountersArgs struct {
 //   - `{ "NewParams": [0], "NewResults": [0, 1] }` removes the second parameter
 //   - `{ "NewParams": [1, 0], "NewResults": [0, 1] }` flips the parameter order
 //   - `{ "NewParams": [0, 1, "a int"], "NewResults": [0, 1] }` adds a new field
-//   - `{ "NewParams": [1, 2], "NewResults": [1] }` drops the `error` result
+//   - `{ "NewParams": [0, 1], "NewResults": [0] }` drops the `error` result
+//   - `{ "NewParams": [1], "NewResults": [0, 1], "NewRecv": 0 }` makes F a method of a's type
 type ChangeSignatureArgs struct {
 	// Location is any range inside the function signature. By convention, this
 	// is the same location provided in the codeAction request.
@@ -646,12 +713,40 @@ type ChangeSignatureArgs struct {
 	// NewResults describes results of the new signature (see above).
 	// An int value references a result in the old signature by index.
 	// A string value describes a new result field (e.g. "err error").
+	// If NewResults is omitted, the results are unchanged.
 	NewResults []ChangeSignatureParam
 
+	// NewRecv, if set, is the index of the parameter of the old
+	// signature that becomes the receiver, converting a function to a
+	// method.
+	NewRecv *int `json:",omitempty"`
+
 	// Whether to resolve and return the edits.
 	ResolveEdits bool
 }
 
+// ExampleRewriteArgs specifies an example-based rewrite to perform.
+type ExampleRewriteArgs struct {
+	// Template is the location of a Go file declaring pairs of before
+	// and after functions. Only the pairs whose functions overlap the
+	// range are applied; use the range of the entire file to apply all
+	// of them.
+	Template protocol.Location
+
+	// Whether to resolve and return the edits, instead of applying them.
+	ResolveEdits bool
+}
+
+// ExampleRewriteResult is the result of an example-based rewrite.
+type ExampleRewriteResult struct {
+	// Matches holds the location of each rewritten region, before
+	// the rewrite.
+	Matches []protocol.Location
+
+	// Edit holds the resulting edits, if ResolveEdits was set.
+	Edit *protocol.WorkspaceEdit `json:",omitempty"`
+}
+
 // ChangeSignatureParam implements the API described in the doc string of
 // [ChangeSignatureArgs]: a union of JSON int | string.
 type ChangeSignatureParam struct {
@@ -873,6 +968,49 @@ type PackageSymbol struct {
 	File int `json:"file,omitempty"`
 }
 
+type QuerySymbolIndexArgs struct {
+	// Query is the fuzzy pattern matched against symbol names.
+	Query string
+
+	// Kinds, if non-empty, restricts the search to symbols of these
+	// kinds: "func", "type", "var", or "const".
+	Kinds []string
+
+	// Limit is the maximum number of results; if zero, 100.
+	Limit int
+}
+
+type QuerySymbolIndexResult struct {
+	// Symbols holds the matching symbols, best matches first.
+	Symbols []IndexSymbol
+}
+
+// IndexSymbol describes a symbol found by the QuerySymbolIndex command.
+type IndexSymbol struct {
+	Name       string // symbol name, e.g. "Marshal"
+	Kind       string // "func", "type", "var", or "const"
+	PkgName    string // package name, e.g. "json"
+	ImportPath string // package path, e.g. "encoding/json"
+
+	// Signature is the type of a function, e.g. "func(v any)".
+	// The results of functions in the module cache are omitted,
+	// as its index does not record them.
+	Signature string `json:",omitempty"`
+
+	// Version is the version of the module providing the symbol,
+	// or empty for main modules of the workspace.
+	Version string `json:",omitempty"`
+
+	Deprecated bool `json:",omitempty"`
+
+	// Location is the declaration of the symbol,
+	// for symbols of workspace packages only.
+	Location *protocol.Location `json:",omitempty"`
+
+	// Score is the quality of the match, between 0 and 1.
+	Score float64
+}
+
 type ImplementInterfaceArgs struct {
 	// Location is the location where the user invoked the code action.
 	// This location must be within a type declaration.
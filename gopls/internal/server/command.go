@@ -26,6 +26,7 @@ import (
 	"github.com/fatih/gomodifytags/modifytags"
 	"golang.org/x/mod/modfile"
 	"golang.org/x/telemetry/counter"
+	"golang.org/x/tools/cover"
 	"golang.org/x/tools/go/ast/astutil"
 	"golang.org/x/tools/gopls/internal/cache"
 	"golang.org/x/tools/gopls/internal/cache/metadata"
@@ -761,11 +762,27 @@ func (c *commandHandler) RunTests(ctx context.Context, args command.RunTestsArgs
 		forURI:      args.URI,
 	}, func(ctx context.Context, deps commandDeps) error {
 		jsonrpc2.Async(ctx) // don't block RPCs behind this command, since it can take a while
-		return c.runTests(ctx, deps.snapshot, deps.work, args.URI, args.Tests, args.Benchmarks)
+		return c.runTests(ctx, deps.snapshot, deps.work, args.URI, args.Tests, args.Benchmarks, args.Coverage)
 	})
 }
 
-func (c *commandHandler) runTests(ctx context.Context, snapshot *cache.Snapshot, work *progress.WorkDone, uri protocol.DocumentURI, tests, benchmarks []string) error {
+// getCoverageFile returns the name of the file to which the
+// source.test code action writes coverage, creating it if necessary.
+func (s *server) getCoverageFile() (string, error) {
+	s.coverageFileMu.Lock()
+	defer s.coverageFileMu.Unlock()
+	if s.coverageFile == "" {
+		f, err := os.CreateTemp("", "gopls-coverage-*.out")
+		if err != nil {
+			return "", err
+		}
+		f.Close()
+		s.coverageFile = f.Name()
+	}
+	return s.coverageFile, nil
+}
+
+func (c *commandHandler) runTests(ctx context.Context, snapshot *cache.Snapshot, work *progress.WorkDone, uri protocol.DocumentURI, tests, benchmarks []string, coverage bool) error {
 	// TODO: fix the error reporting when this runs async.
 	meta, err := snapshot.NarrowestMetadataForFile(ctx, uri)
 	if err != nil {
@@ -778,10 +795,39 @@ func (c *commandHandler) runTests(ctx context.Context, snapshot *cache.Snapshot,
 	ew := progress.NewEventWriter(ctx, "test")
 	out := io.MultiWriter(ew, progress.NewWorkDoneWriter(ctx, work), buf)
 
-	// Run `go test -run Func` on each test.
+	// Record coverage in a file of our own, never in a profile
+	// that belongs to the user, such as that of the coverageProfile
+	// setting: the tests run here may be only some of them.
+	var profile protocol.DocumentURI
+	if coverage && len(tests) > 0 {
+		name, err := c.s.getCoverageFile()
+		if err != nil {
+			return err
+		}
+		profile = protocol.URIFromPath(name)
+	}
+
+	// Run `go test -run Func` on each test, or, when recording
+	// coverage, on all of them at once so that a single profile
+	// covers them all.
+	var runs []string // -run flags
+	if profile != "" {
+		quoted := make([]string, len(tests))
+		for i, funcName := range tests {
+			quoted[i] = regexp.QuoteMeta(funcName)
+		}
+		runs = append(runs, fmt.Sprintf("-run=^(%s)$", strings.Join(quoted, "|")))
+	} else {
+		for _, funcName := range tests {
+			runs = append(runs, fmt.Sprintf("-run=^%s$", regexp.QuoteMeta(funcName)))
+		}
+	}
 	var failedTests int
-	for _, funcName := range tests {
-		args := []string{pkgPath, "-v", "-count=1", fmt.Sprintf("-run=^%s$", regexp.QuoteMeta(funcName))}
+	for _, run := range runs {
+		args := []string{pkgPath, "-v", "-count=1", run}
+		if profile != "" {
+			args = append(args, "-coverprofile="+profile.Path())
+		}
 		inv, cleanupInvocation, err := snapshot.GoCommandInvocation(cache.NoNetwork, uri.DirPath(), "test", args)
 		if err != nil {
 			return err
@@ -794,6 +840,11 @@ func (c *commandHandler) runTests(ctx context.Context, snapshot *cache.Snapshot,
 			failedTests++
 		}
 	}
+	if profile != "" {
+		if err := c.loadCoverage(ctx, snapshot, profile); err != nil {
+			return err
+		}
+	}
 
 	// Run `go test -run=^$ -bench Func` on each test.
 	var failedBenchmarks int
@@ -823,11 +874,15 @@ func (c *commandHandler) runTests(ctx context.Context, snapshot *cache.Snapshot,
 	} else {
 		return errors.New("No functions were provided")
 	}
+	testsFailed := fmt.Sprintf("%d / %d tests failed", failedTests, len(tests))
+	if profile != "" {
+		testsFailed = "tests failed" // a single run: we can't tell which ones
+	}
 	message := fmt.Sprintf("all %s passed", title)
 	if failedTests > 0 && failedBenchmarks > 0 {
-		message = fmt.Sprintf("%d / %d tests failed and %d / %d benchmarks failed", failedTests, len(tests), failedBenchmarks, len(benchmarks))
+		message = fmt.Sprintf("%s and %d / %d benchmarks failed", testsFailed, failedBenchmarks, len(benchmarks))
 	} else if failedTests > 0 {
-		message = fmt.Sprintf("%d / %d tests failed", failedTests, len(tests))
+		message = testsFailed
 	} else if failedBenchmarks > 0 {
 		message = fmt.Sprintf("%d / %d benchmarks failed", failedBenchmarks, len(benchmarks))
 	}
@@ -1069,6 +1124,76 @@ func (c *commandHandler) GCDetails(ctx context.Context, uri protocol.DocumentURI
 	})
 }
 
+func (c *commandHandler) LoadCoverage(ctx context.Context, args command.LoadCoverageArgs) error {
+	return c.run(ctx, commandConfig{
+		forURI: args.URI,
+	}, func(ctx context.Context, deps commandDeps) error {
+		return c.loadCoverage(ctx, deps.snapshot, args.Profile)
+	})
+}
+
+// loadCoverage displays the coverage profile in the view of the
+// snapshot, or hides coverage if profile is empty.
+func (c *commandHandler) loadCoverage(ctx context.Context, snapshot *cache.Snapshot, profile protocol.DocumentURI) error {
+	if profile != "" {
+		// Report a malformed profile now, not in the logs.
+		if _, err := cover.ParseProfiles(profile.Path()); err != nil {
+			return fmt.Errorf("loading coverage profile: %v", err)
+		}
+	}
+	if err := c.modifyState(ctx, FromLoadCoverage, func() (*cache.Snapshot, func(), error) {
+		return c.s.session.InvalidateView(ctx, snapshot.View(), cache.StateChange{
+			CoverageProfile: &profile,
+		})
+	}); err != nil {
+		return err
+	}
+	return c.s.updateWatchedDirectories(ctx) // watch the new profile
+}
+
+func (c *commandHandler) ToggleProfile(ctx context.Context, args command.ToggleProfileArgs) error {
+	return c.run(ctx, commandConfig{
+		forURI: args.URI,
+	}, func(ctx context.Context, deps commandDeps) error {
+		profile := args.Profile
+		if profile == "" {
+			// Advance to the next profile of the setting, or none.
+			profiles := deps.snapshot.PprofProfiles()
+			if len(profiles) == 0 {
+				return fmt.Errorf("no profiles: the pprofProfiles setting is empty")
+			}
+			if i := slices.Index(profiles, deps.snapshot.PprofProfile()); i < 0 {
+				profile = profiles[0]
+			} else if i+1 < len(profiles) {
+				profile = profiles[i+1]
+			}
+		}
+		if err := c.modifyState(ctx, FromToggleProfile, func() (*cache.Snapshot, func(), error) {
+			return c.s.session.InvalidateView(ctx, deps.snapshot.View(), cache.StateChange{
+				PprofProfile: &profile,
+			})
+		}); err != nil {
+			return err
+		}
+		if err := c.s.updateWatchedDirectories(ctx); err != nil { // watch the new profile
+			return err
+		}
+
+		// Hot spots are displayed only by code lenses and inlay hints.
+		if c.s.Options().CodeLensRefreshSupported {
+			if err := c.s.client.CodeLensRefresh(ctx); err != nil {
+				return err
+			}
+		}
+		if c.s.Options().InlayHintRefreshSupported {
+			if err := c.s.client.InlayHintRefresh(ctx); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
 func (c *commandHandler) ListKnownPackages(ctx context.Context, args command.URIArg) (command.ListKnownPackagesResult, error) {
 	var result command.ListKnownPackagesResult
 	err := c.run(ctx, commandConfig{
@@ -1648,16 +1773,12 @@ func (c *commandHandler) ChangeSignature(ctx context.Context, args command.Chang
 			return err
 		}
 
-		// For now, gopls only supports parameter permutation or removal.
-		var perm []int
-		for _, newParam := range args.NewParams {
-			if newParam.NewField != "" {
-				return fmt.Errorf("adding new parameters is currently unsupported")
-			}
-			perm = append(perm, newParam.OldIndex)
+		change := golang.SignatureChange{
+			Params:  args.NewParams,
+			Results: args.NewResults,
+			Recv:    args.NewRecv,
 		}
-
-		docedits, err := golang.ChangeSignature(ctx, deps.snapshot, pkg, pgf, args.Location.Range, perm)
+		docedits, err := golang.ChangeSignature(ctx, deps.snapshot, pkg, pgf, args.Location.Range, change)
 		if err != nil {
 			return err
 		}
@@ -1671,6 +1792,26 @@ func (c *commandHandler) ChangeSignature(ctx context.Context, args command.Chang
 	return result, err
 }
 
+func (c *commandHandler) ExampleRewrite(ctx context.Context, args command.ExampleRewriteArgs) (command.ExampleRewriteResult, error) {
+	var result command.ExampleRewriteResult
+	err := c.run(ctx, commandConfig{
+		progress: "Applying example-based rewrite",
+		forURI:   args.Template.URI,
+	}, func(ctx context.Context, deps commandDeps) error {
+		changes, matches, err := golang.ExampleRewrite(ctx, deps.snapshot, args.Template)
+		if err != nil {
+			return err
+		}
+		result.Matches = matches
+		if args.ResolveEdits {
+			result.Edit = protocol.NewWorkspaceEdit(changes...)
+			return nil
+		}
+		return applyChanges(ctx, c.s.client, changes)
+	})
+	return result, err
+}
+
 func (c *commandHandler) DiagnoseFiles(ctx context.Context, args command.DiagnoseFilesArgs) error {
 	return c.run(ctx, commandConfig{
 		progress: "Diagnose files",
@@ -1800,6 +1941,40 @@ func (c *commandHandler) PackageSymbols(ctx context.Context, args command.Packag
 	return result, err
 }
 
+func (c *commandHandler) QuerySymbolIndex(ctx context.Context, args command.QuerySymbolIndexArgs) (command.QuerySymbolIndexResult, error) {
+	// Merge the symbols of all views, which may share
+	// packages and module caches.
+	if args.Limit == 0 {
+		args.Limit = 100
+	}
+	var result command.QuerySymbolIndexResult
+	type key struct{ importPath, name, version string }
+	seen := make(map[key]bool)
+	for _, v := range c.s.session.Views() {
+		snapshot, release, err := v.Snapshot()
+		if err != nil {
+			return result, err
+		}
+		symbols, err := golang.QuerySymbolIndex(ctx, snapshot, args)
+		release()
+		if err != nil {
+			return result, err
+		}
+		for _, s := range symbols {
+			k := key{s.ImportPath, s.Name, s.Version}
+			if !seen[k] {
+				seen[k] = true
+				result.Symbols = append(result.Symbols, s)
+			}
+		}
+	}
+	golang.SortIndexSymbols(result.Symbols)
+	if len(result.Symbols) > args.Limit {
+		result.Symbols = result.Symbols[:args.Limit]
+	}
+	return result, nil
+}
+
 // optionsStringToMap transforms comma-separated options of the form
 // "foo=bar,baz=quux" to a go map. Returns nil if any options are malformed.
 func optionsStringToMap(options string) (map[string][]string, error) {
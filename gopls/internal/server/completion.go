@@ -10,6 +10,7 @@ import (
 	"strings"
 
 	"golang.org/x/tools/gopls/internal/file"
+	"golang.org/x/tools/gopls/internal/goasm"
 	"golang.org/x/tools/gopls/internal/golang"
 	"golang.org/x/tools/gopls/internal/golang/completion"
 	"golang.org/x/tools/gopls/internal/label"
@@ -61,6 +62,13 @@ func (s *server) Completion(ctx context.Context, params *protocol.CompletionPara
 			break // use common error handling, candidates==nil
 		}
 		return cl, nil
+	case file.Asm:
+		var cl *protocol.CompletionList
+		cl, err = goasm.Completion(ctx, snapshot, fh, pos)
+		if err != nil {
+			break // use common error handling, candidates==nil
+		}
+		return cl, nil
 	}
 	if err != nil {
 		event.Error(ctx, "no completions found", err, label.Position.Of(pos))
@@ -35,7 +35,7 @@ func (s *server) Definition(ctx context.Context, params *protocol.DefinitionPara
 	defer release()
 	switch kind := snapshot.FileKind(fh); kind {
 	case file.Tmpl:
-		return template.Definition(snapshot, fh, params.Range)
+		return template.Definition(ctx, snapshot, fh, params.Range)
 	case file.Go:
 		return golang.Definition(ctx, snapshot, fh, params.Range)
 	case file.Asm:
@@ -20,6 +20,7 @@ import (
 	"golang.org/x/tools/gopls/internal/cache"
 	"golang.org/x/tools/gopls/internal/cache/metadata"
 	"golang.org/x/tools/gopls/internal/file"
+	"golang.org/x/tools/gopls/internal/goasm"
 	"golang.org/x/tools/gopls/internal/golang"
 	"golang.org/x/tools/gopls/internal/label"
 	"golang.org/x/tools/gopls/internal/mod"
@@ -404,20 +405,26 @@ func (s *server) diagnose(ctx context.Context, snapshot *cache.Snapshot) (diagMa
 	}
 	s.updateCriticalErrorStatus(ctx, snapshot, statusErr)
 
+	var wg sync.WaitGroup // for potentially slow operations below
+
 	// Diagnose template (.tmpl) files.
-	tmplReports := template.Diagnostics(snapshot)
-	// NOTE(rfindley): typeCheckSource is not accurate here.
-	// (but this will be gone soon anyway).
-	store("diagnosing templates", tmplReports, nil)
+	//
+	// This type-checks the packages that execute templates, so we run
+	// it concurrently to other diagnostics.
+	wg.Go(func() {
+		tmplReports := template.Diagnostics(ctx, snapshot)
+		// NOTE(rfindley): typeCheckSource is not accurate here.
+		// (but this will be gone soon anyway).
+		store("diagnosing templates", tmplReports, nil)
+	})
 
 	// If there are no workspace packages, there is nothing to diagnose and
 	// there are no orphaned files.
 	if len(workspacePkgs) == 0 {
+		wg.Wait()
 		return diagnostics, nil
 	}
 
-	var wg sync.WaitGroup // for potentially slow operations below
-
 	// Maybe run go mod tidy (if it has been invalidated).
 	//
 	// Since go mod tidy can be slow, we run it concurrently to diagnostics.
@@ -479,6 +486,18 @@ func (s *server) diagnose(ctx context.Context, snapshot *cache.Snapshot) (diagMa
 		}
 	}
 
+	// Diagnose open assembly (.s) files.
+	wg.Go(func() {
+		asmDiags, err := goasm.Diagnostics(ctx, snapshot)
+		store("diagnosing assembly files", asmDiags, err)
+	})
+
+	// Display the coverage profile, if any.
+	wg.Go(func() {
+		coverageDiags, err := golang.CoverageDiagnostics(ctx, snapshot)
+		store("displaying coverage", coverageDiags, err)
+	})
+
 	wg.Go(func() {
 		compilerOptDetailsDiags, err := s.compilerOptDetailsDiagnostics(ctx, snapshot, toDiagnose)
 		store("collecting compiler optimization details", compilerOptDetailsDiags, err)
@@ -798,6 +798,14 @@ func (s *server) Shutdown(ctx context.Context) error {
 
 		// drop all the active views
 		s.session.Shutdown(ctx)
+
+		s.coverageFileMu.Lock()
+		if s.coverageFile != "" {
+			os.Remove(s.coverageFile) // ignore error
+			s.coverageFile = ""
+		}
+		s.coverageFileMu.Unlock()
+
 		s.state = serverShutDown
 	}
 	return nil
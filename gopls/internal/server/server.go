@@ -134,6 +134,11 @@ type server struct {
 	ongoingProfileMu sync.Mutex
 	ongoingProfile   *os.File // if non-nil, an ongoing profile is writing to this file
 
+	// The file to which the source.test code action writes coverage.
+	// Created on demand, and removed during LSP Shutdown.
+	coverageFileMu sync.Mutex
+	coverageFile   string
+
 	// Track most recently requested options.
 	optionsMu sync.Mutex
 	options   *settings.Options
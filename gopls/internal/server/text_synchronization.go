@@ -63,6 +63,14 @@ const (
 	// FromToggleCompilerOptDetails refers to state changes resulting from toggling
 	// a package's compiler optimization details flag.
 	FromToggleCompilerOptDetails
+
+	// FromLoadCoverage refers to state changes resulting from loading
+	// (or hiding) a coverage profile.
+	FromLoadCoverage
+
+	// FromToggleProfile refers to state changes resulting from
+	// selecting (or hiding) a pprof profile.
+	FromToggleProfile
 )
 
 func (m ModificationSource) String() string {
@@ -85,6 +93,10 @@ func (m ModificationSource) String() string {
 		return "from check upgrades"
 	case FromResetGoModDiagnostics:
 		return "from resetting go.mod diagnostics"
+	case FromLoadCoverage:
+		return "from loading coverage"
+	case FromToggleProfile:
+		return "from toggling profile"
 	default:
 		return "unknown file modification"
 	}
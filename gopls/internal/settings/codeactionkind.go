@@ -90,7 +90,9 @@ const (
 	// refactor.rewrite
 	RefactorRewriteChangeQuote        protocol.CodeActionKind = "refactor.rewrite.changeQuote"
 	RefactorRewriteFillStruct         protocol.CodeActionKind = "refactor.rewrite.fillStruct"
+	RefactorRewriteExample            protocol.CodeActionKind = "refactor.rewrite.example"
 	RefactorRewriteFillSwitch         protocol.CodeActionKind = "refactor.rewrite.fillSwitch"
+	RefactorRewriteGeneralize         protocol.CodeActionKind = "refactor.rewrite.generalize"
 	RefactorRewriteInvertIf           protocol.CodeActionKind = "refactor.rewrite.invertIf"
 	RefactorRewriteJoinLines          protocol.CodeActionKind = "refactor.rewrite.joinLines"
 	RefactorRewriteRemoveUnusedParam  protocol.CodeActionKind = "refactor.rewrite.removeUnusedParam"
@@ -107,13 +109,15 @@ const (
 	RefactorInlineVariable protocol.CodeActionKind = "refactor.inline.variable"
 
 	// refactor.extract
-	RefactorExtractConstant    protocol.CodeActionKind = "refactor.extract.constant"
-	RefactorExtractConstantAll protocol.CodeActionKind = "refactor.extract.constant-all"
-	RefactorExtractFunction    protocol.CodeActionKind = "refactor.extract.function"
-	RefactorExtractMethod      protocol.CodeActionKind = "refactor.extract.method"
-	RefactorExtractVariable    protocol.CodeActionKind = "refactor.extract.variable"
-	RefactorExtractVariableAll protocol.CodeActionKind = "refactor.extract.variable-all"
-	RefactorExtractToNewFile   protocol.CodeActionKind = "refactor.extract.toNewFile"
+	RefactorExtractConstant     protocol.CodeActionKind = "refactor.extract.constant"
+	RefactorExtractConstantAll  protocol.CodeActionKind = "refactor.extract.constant-all"
+	RefactorExtractFunction     protocol.CodeActionKind = "refactor.extract.function"
+	RefactorExtractInterface    protocol.CodeActionKind = "refactor.extract.interface"
+	RefactorExtractInterfaceAll protocol.CodeActionKind = "refactor.extract.interface-all"
+	RefactorExtractMethod       protocol.CodeActionKind = "refactor.extract.method"
+	RefactorExtractVariable     protocol.CodeActionKind = "refactor.extract.variable"
+	RefactorExtractVariableAll  protocol.CodeActionKind = "refactor.extract.variable-all"
+	RefactorExtractToNewFile    protocol.CodeActionKind = "refactor.extract.toNewFile"
 
 	// refactor.move
 	RefactorMoveType        protocol.CodeActionKind = "refactor.move.moveType"
@@ -56,8 +56,10 @@ func DefaultOptions(overrides ...func(*Options)) *Options {
 						GoSplitPackage:                    true,
 						GoplsDocFeatures:                  true,
 						RefactorRewriteChangeQuote:        true,
+						RefactorRewriteExample:            true,
 						RefactorRewriteFillStruct:         true,
 						RefactorRewriteFillSwitch:         true,
+						RefactorRewriteGeneralize:         true,
 						RefactorRewriteImplementInterface: true,
 						RefactorRewriteInvertIf:           true,
 						RefactorRewriteJoinLines:          true,
@@ -68,6 +70,8 @@ func DefaultOptions(overrides ...func(*Options)) *Options {
 						RefactorExtractConstant:           true,
 						RefactorExtractConstantAll:        true,
 						RefactorExtractFunction:           true,
+						RefactorExtractInterface:          true,
+						RefactorExtractInterfaceAll:       true,
 						RefactorExtractMethod:             true,
 						RefactorExtractVariable:           true,
 						RefactorExtractVariableAll:        true,
@@ -134,6 +138,8 @@ func DefaultOptions(overrides ...func(*Options)) *Options {
 						CodeLensUpgradeDependency: true,
 						CodeLensVendor:            true,
 						CodeLensRunGovulncheck:    true,
+						CodeLensCoverage:          true,
+						CodeLensHotSpots:          true,
 					},
 					NewGoFileHeader:        true,
 					RenameMovesSubpackages: false,
@@ -6,11 +6,15 @@ package settings
 
 import (
 	"fmt"
+	"go/token"
 	"maps"
 	"math"
 	"path/filepath"
 	"reflect"
+	"slices"
 	"strings"
+	"text/template"
+	"text/template/parse"
 	"time"
 
 	"golang.org/x/tools/gopls/internal/file"
@@ -18,6 +22,7 @@ import (
 	"golang.org/x/tools/gopls/internal/protocol/semtok"
 	"golang.org/x/tools/gopls/internal/telemetry"
 	"golang.org/x/tools/gopls/internal/util/frob"
+	"golang.org/x/tools/internal/imports"
 )
 
 // An Annotation is a category of Go compiler optimization diagnostic.
@@ -107,6 +112,8 @@ type ClientOptions struct {
 	SupportedResourceOperations                []protocol.ResourceOperationKind
 	CodeActionResolveOptions                   []string
 	ShowDocumentSupported                      bool
+	CodeLensRefreshSupported                   bool
+	InlayHintRefreshSupported                  bool
 	// SupportedWorkDoneProgressFormats specifies the formats supported by the
 	// client for handling workdone progress metadata.
 	SupportedWorkDoneProgressFormats map[WorkDoneProgressStyle]bool
@@ -278,6 +285,34 @@ type UIOptions struct {
 	// MoveDeclaration enables producing Move Declaration codeactions. The implementation
 	// is unfinished so we use this setting to gate its use.
 	MoveDeclaration bool `status:"experimental"`
+
+	// CoverageProfile is the name of a coverage profile, as produced
+	// by `go test -coverprofile`, whose data gopls should display.
+	// A relative name is resolved against the workspace folder.
+	//
+	// Uncovered code is reported as faded hint diagnostics and
+	// covered code as plain hint diagnostics, and the `coverage`
+	// code lens reports the coverage of each function.
+	// The display is refreshed whenever the profile changes. The
+	// "Run tests and benchmarks" code action (`source.test`) records
+	// the coverage of the tests it runs in a file of its own, which
+	// it then displays, and never writes to this profile. The
+	// `gopls.load_coverage` command overrides this setting.
+	CoverageProfile string `status:"experimental"`
+
+	// PprofProfiles is a list of names of CPU or other profiles in
+	// the pprof format, such as the `default.pgo` file used for
+	// [profile-guided optimization](https://go.dev/doc/pgo), whose
+	// hot spots gopls should display. Relative names are resolved
+	// against the workspace folder.
+	//
+	// The first profile is displayed initially, and the
+	// `gopls.toggle_profile` command cycles through the others.
+	// The percentage of samples attributed to hot functions is
+	// shown by the `hot_spots` code lens, and to hot lines by the
+	// `hotSpots` inlay hint. The display is refreshed whenever the
+	// profile changes.
+	PprofProfiles []string `status:"experimental"`
 }
 
 // A CodeLensSource identifies an (algorithmic) source of code lenses.
@@ -382,6 +417,29 @@ const (
 	// module root so that it contains an up-to-date copy of all
 	// necessary package dependencies.
 	CodeLensVendor CodeLensSource = "vendor"
+
+	// Show test coverage of functions
+	//
+	// This codelens source annotates each function declaration with
+	// the percentage of its statements covered by the current
+	// coverage profile, if any (see the `coverageProfile` setting and
+	// the `gopls.load_coverage` command). The lens's command reloads
+	// the profile.
+	//
+	//gopls:status experimental
+	CodeLensCoverage CodeLensSource = "coverage"
+
+	// Show profile hot spots
+	//
+	// This codelens source annotates each function that accounts for
+	// at least 1% of the samples of the current pprof profile, if any
+	// (see the `pprofProfiles` setting), with the percentage of
+	// samples in the function itself ("flat") and in it and its
+	// callees ("cum"). The lens's command switches to the next
+	// profile.
+	//
+	//gopls:status experimental
+	CodeLensHotSpots CodeLensSource = "hot_spots"
 )
 
 // Note: CompletionOptions must be comparable with reflect.DeepEqual.
@@ -405,6 +463,35 @@ type CompletionOptions struct {
 	// such as "someSlice.sort!".
 	ExperimentalPostfixCompletions bool `status:"experimental"`
 
+	// PostfixSnippets defines additional postfix completion snippets,
+	// offered when experimentalPostfixCompletions is enabled. Each
+	// element is an object with these fields:
+	//
+	//   - "label", the name of the snippet, as in "x.label!";
+	//   - "details", a description shown alongside the candidate;
+	//   - "body", a text/template producing the snippet, with access
+	//     to the same facilities as the built-in snippets, such as
+	//     `{{.X}}` for the receiver expression and
+	//     `{{.Import "pkg"}}` for the name of an imported package; and
+	//   - "types", an optional list of the kinds of receiver to which
+	//     the snippet applies: "array", "basic", "chan", "error",
+	//     "interface", "map", "pointer", "signature", "slice", "struct",
+	//     or "tuple".
+	//
+	// For example:
+	//
+	// ```json5
+	// "postfixSnippets": [{
+	//   "label": "wrap",
+	//   "details": "fmt.Errorf(\"...: %w\", err)",
+	//   "body": "{{.Import \"fmt\"}}.Errorf(\"{{.Cursor}}: %w\", {{.X}})",
+	//   "types": ["error"],
+	// }]
+	// ```
+	//
+	// A snippet whose label matches a built-in snippet replaces it.
+	PostfixSnippets []PostfixSnippet `status:"experimental"`
+
 	// CompleteFunctionCalls enables function call completion.
 	//
 	// When completing a statement, or when a function return type matches the
@@ -413,6 +500,64 @@ type CompletionOptions struct {
 	CompleteFunctionCalls bool
 }
 
+// A PostfixSnippet is a user-defined postfix completion snippet.
+// See [CompletionOptions.PostfixSnippets].
+type PostfixSnippet struct {
+	Label   string
+	Details string
+	Body    string
+	Types   []string // receiver kinds; empty => any
+
+	trees map[string]*parse.Tree // parsed Body, set when the setting is applied
+}
+
+// PostfixFuncs are the functions available to the templates of
+// postfix snippets, in addition to the predefined functions of
+// text/template.
+var PostfixFuncs = template.FuncMap{
+	"inc": func(i int) int { return i + 1 },
+}
+
+// Template returns the parsed body of the snippet.
+func (s PostfixSnippet) Template() (*template.Template, error) {
+	if s.trees == nil {
+		return template.New(s.Label).Funcs(PostfixFuncs).Parse(s.Body)
+	}
+	tmpl := template.New(s.Label).Funcs(PostfixFuncs)
+	for name, tree := range s.trees {
+		if _, err := tmpl.AddParseTree(name, tree); err != nil {
+			return nil, err
+		}
+	}
+	return tmpl, nil
+}
+
+// PostfixSnippetTypes are the permitted elements of [PostfixSnippet.Types]:
+// the kinds of receiver type reported by the Kind method of the
+// template arguments, and "error" for any type that implements error.
+var PostfixSnippetTypes = []string{
+	"array", "basic", "chan", "error", "interface", "map",
+	"pointer", "signature", "slice", "struct", "tuple",
+}
+
+// parsePostfixTemplate parses the body of a postfix snippet,
+// including the functions that it calls, and returns its trees.
+// Parsed trees hold no functions, so options that contain them
+// remain comparable with reflect.DeepEqual.
+func parsePostfixTemplate(label, body string) (map[string]*parse.Tree, error) {
+	tmpl, err := template.New(label).Funcs(PostfixFuncs).Parse(body)
+	if err != nil {
+		return nil, err
+	}
+	trees := make(map[string]*parse.Tree)
+	for _, t := range tmpl.Templates() {
+		if t.Tree != nil {
+			trees[t.Name()] = t.Tree
+		}
+	}
+	return trees, nil
+}
+
 // Note: DocumentationOptions must be comparable with reflect.DeepEqual.
 type DocumentationOptions struct {
 	// HoverKind controls the information that appears in the hover text.
@@ -484,6 +629,36 @@ type FormattingOptions struct {
 	// existing imports.
 	Local string
 
+	// ImportGroups is an ordered list of import groups that replaces
+	// the default grouping of imports into standard library, third-party,
+	// and `local` packages, as by the `goimports -groups` flag.
+	// Each element is one of:
+	//
+	//   - "std", for standard library packages;
+	//   - "module", for packages of the module containing the file;
+	//   - "default", for packages that belong to no other group;
+	//   - "prefix:P,...", for packages whose path begins with a prefix P;
+	//   - "regexp:RE", for packages whose path matches the regular expression RE.
+	//
+	// An import that matches several groups belongs to the last of them.
+	// For example, `["std", "default", "prefix:example.com/", "module"]`
+	// places the packages of the current module after all other
+	// example.com packages.
+	//
+	// When set, an LSP Organize Imports request moves each import into
+	// its group, and separates the groups by blank lines.
+	ImportGroups []string
+
+	// PinnedImports is a list of import paths to prefer when adding a
+	// missing import that several packages of the same name could
+	// satisfy, as by the `goimports -pin` flag. For example,
+	// `["github.com/sirupsen/logrus"]` resolves references to `logrus`
+	// to that package rather than any other of that name.
+	//
+	// In the absence of a pinned import, gopls prefers the package
+	// already imported by the most packages of the current module.
+	PinnedImports []string
+
 	// Gofumpt indicates if we should run gofumpt formatting.
 	Gofumpt bool
 }
@@ -653,6 +828,16 @@ const (
 	// A handful of common functions such as `fmt.Println` are
 	// excluded from the check.
 	IgnoredError InlayHint = "ignoredError"
+
+	// HotSpots inlay hints for lines that account for at least 1%
+	// of the samples of the current pprof profile (see the
+	// `pprofProfiles` setting):
+	// ```go
+	// 	sum += f(x)« // cpu: flat 3.2%, cum 41.0%»
+	// ```
+	// "flat" is the percentage of samples in the line itself, and
+	// "cum" the percentage in the line and the functions it calls.
+	HotSpots InlayHint = "hotSpots"
 )
 
 type NavigationOptions struct {
@@ -1086,6 +1271,12 @@ func (o *Options) ForClientCapabilities(clientInfo *protocol.ClientInfo, caps pr
 	if caps.Window.ShowDocument != nil {
 		o.ShowDocumentSupported = caps.Window.ShowDocument.Support
 	}
+	if caps.Workspace.CodeLens != nil {
+		o.CodeLensRefreshSupported = caps.Workspace.CodeLens.RefreshSupport
+	}
+	if caps.Workspace.InlayHint != nil {
+		o.InlayHintRefreshSupported = caps.Workspace.InlayHint.RefreshSupport
+	}
 	// Check if the client supports configuration messages.
 	o.ConfigurationSupported = caps.Workspace.Configuration
 	o.DynamicConfigurationSupported = caps.Workspace.DidChangeConfiguration.DynamicRegistration
@@ -1152,7 +1343,13 @@ func (o *Options) ForClientCapabilities(clientInfo *protocol.ClientInfo, caps pr
 var codec = frob.CodecFor[*Options]()
 
 func (o *Options) Clone() *Options {
-	return codec.Decode(codec.Encode(o))
+	clone := codec.Decode(codec.Encode(o))
+	// The codec drops the parsed templates of postfix snippets,
+	// which are immutable and may be shared.
+	for i := range clone.PostfixSnippets {
+		clone.PostfixSnippets[i].trees = o.PostfixSnippets[i].trees
+	}
+	return clone
 }
 
 // validateDirectoryFilter validates if the filter string
@@ -1364,6 +1561,20 @@ func (o *Options) setOne(name string, value any) (applied []CounterPath, _ error
 	case "local":
 		return nil, setString(&o.Local, value)
 
+	case "importGroups":
+		groups, err := asStringSlice(value)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := imports.ParseGroups(groups); err != nil {
+			return nil, err
+		}
+		o.ImportGroups = groups
+		return nil, nil
+
+	case "pinnedImports":
+		return nil, setStringSlice(&o.PinnedImports, value)
+
 	case "maxFileCacheBytes":
 		return setInt64(&o.MaxFileCacheBytes, value)
 
@@ -1412,6 +1623,12 @@ func (o *Options) setOne(name string, value any) (applied []CounterPath, _ error
 	case "newGoFileHeader":
 		return setBool(&o.NewGoFileHeader, value)
 
+	case "coverageProfile":
+		return nil, setString(&o.CoverageProfile, value)
+
+	case "pprofProfiles":
+		return nil, setStringSlice(&o.PprofProfiles, value)
+
 	case "expandWorkspaceToModule":
 		// See golang/go#63536: we can consider deprecating
 		// expandWorkspaceToModule, but probably need to change the default
@@ -1421,6 +1638,9 @@ func (o *Options) setOne(name string, value any) (applied []CounterPath, _ error
 	case "experimentalPostfixCompletions":
 		return setBool(&o.ExperimentalPostfixCompletions, value)
 
+	case "postfixSnippets":
+		return nil, setPostfixSnippets(&o.PostfixSnippets, value)
+
 	case "templateExtensions":
 		switch value := value.(type) {
 		case []any:
@@ -1753,6 +1973,63 @@ func asString(value any) (string, error) {
 	return str, nil
 }
 
+func setPostfixSnippets(dest *[]PostfixSnippet, value any) error {
+	array, ok := value.([]any)
+	if !ok {
+		return fmt.Errorf("invalid type %T (want JSON array of object)", value)
+	}
+	var (
+		snippets []PostfixSnippet
+		labels   = make(map[string]bool)
+	)
+	for _, elem := range array {
+		obj, ok := elem.(map[string]any)
+		if !ok {
+			return fmt.Errorf("invalid array element type %T (want JSON object)", elem)
+		}
+		var snip PostfixSnippet
+		for k, v := range obj {
+			var err error
+			switch k {
+			case "label":
+				err = setString(&snip.Label, v)
+			case "details":
+				err = setString(&snip.Details, v)
+			case "body":
+				err = setString(&snip.Body, v)
+			case "types":
+				err = setStringSlice(&snip.Types, v)
+			default:
+				err = fmt.Errorf("unexpected field")
+			}
+			if err != nil {
+				return fmt.Errorf("postfix snippet field %q: %v", k, err)
+			}
+		}
+		if !token.IsIdentifier(snip.Label) {
+			return fmt.Errorf("invalid postfix snippet label %q (want identifier)", snip.Label)
+		}
+		if labels[snip.Label] {
+			return fmt.Errorf("duplicate postfix snippet %q", snip.Label)
+		}
+		labels[snip.Label] = true
+		for _, t := range snip.Types {
+			if !slices.Contains(PostfixSnippetTypes, t) {
+				return fmt.Errorf("postfix snippet %q: invalid type %q (want one of %s)",
+					snip.Label, t, strings.Join(PostfixSnippetTypes, ", "))
+			}
+		}
+		trees, err := parsePostfixTemplate(snip.Label, snip.Body)
+		if err != nil {
+			return fmt.Errorf("postfix snippet %q: %v", snip.Label, err)
+		}
+		snip.trees = trees
+		snippets = append(snippets, snip)
+	}
+	*dest = snippets
+	return nil
+}
+
 func setStringSlice(dest *[]string, value any) error {
 	slice, err := asStringSlice(value)
 	if err != nil {
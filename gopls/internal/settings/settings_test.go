@@ -6,10 +6,12 @@ package settings_test
 
 import (
 	"reflect"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
 	"golang.org/x/tools/gopls/internal/clonetest"
 	. "golang.org/x/tools/gopls/internal/settings"
 )
@@ -180,6 +182,72 @@ func TestOptions_Set(t *testing.T) {
 				return len(o.DirectoryFilters) == 0
 			},
 		},
+		{
+			name:  "importGroups",
+			value: []any{"std", "default", "prefix:example.com/", "module"},
+			check: func(o Options) bool {
+				return len(o.ImportGroups) == 4 && o.ImportGroups[2] == "prefix:example.com/"
+			},
+		},
+		{
+			name:      "importGroups",
+			value:     []any{"std", "regexp:("},
+			wantError: true,
+			check: func(o Options) bool {
+				return o.ImportGroups == nil
+			},
+		},
+		{
+			name:  "pinnedImports",
+			value: []any{"github.com/sirupsen/logrus"},
+			check: func(o Options) bool {
+				return len(o.PinnedImports) == 1 && o.PinnedImports[0] == "github.com/sirupsen/logrus"
+			},
+		},
+		{
+			name: "postfixSnippets",
+			value: []any{map[string]any{
+				"label": "wrap",
+				"body":  `fmt.Errorf("%w", {{.X}})`,
+				"types": []any{"error"},
+			}},
+			check: func(o Options) bool {
+				return len(o.PostfixSnippets) == 1 && o.PostfixSnippets[0].Types[0] == "error"
+			},
+		},
+		{
+			name: "postfixSnippets",
+			value: []any{map[string]any{
+				"label": "wrap",
+				"body":  "{{.X",
+			}},
+			wantError: true,
+			check: func(o Options) bool {
+				return len(o.PostfixSnippets) == 0
+			},
+		},
+		{
+			name: "postfixSnippets",
+			value: []any{map[string]any{
+				"label": "wrap",
+				"body":  "{{bogus .X}}",
+			}},
+			wantError: true,
+			check: func(o Options) bool {
+				return len(o.PostfixSnippets) == 0
+			},
+		},
+		{
+			name: "postfixSnippets",
+			value: []any{map[string]any{
+				"label": "wrap",
+				"types": []any{"number"},
+			}},
+			wantError: true,
+			check: func(o Options) bool {
+				return len(o.PostfixSnippets) == 0
+			},
+		},
 		{
 			name: "annotations",
 			value: map[string]any{
@@ -253,13 +321,45 @@ func TestOptions_Clone(t *testing.T) {
 	opts2 := opts.Clone()
 
 	// The clone should be equivalent to the original.
-	if diff := cmp.Diff(golden, opts2); diff != "" {
+	ignore := cmpopts.IgnoreUnexported(PostfixSnippet{})
+	if diff := cmp.Diff(golden, opts2, ignore); diff != "" {
 		t.Errorf("Clone() does not match original (-want +got):\n%s", diff)
 	}
 
 	// Mutating the clone should not mutate the original.
 	clonetest.ZeroOut(opts2)
-	if diff := cmp.Diff(golden, opts); diff != "" {
+	if diff := cmp.Diff(golden, opts, ignore); diff != "" {
 		t.Errorf("Mutating clone mutated the original (-want +got):\n%s", diff)
 	}
 }
+
+func TestPostfixSnippetTemplates(t *testing.T) {
+	set := func() *Options {
+		opts := new(Options)
+		if _, err := opts.Set(map[string]any{"postfixSnippets": []any{map[string]any{
+			"label": "last",
+			"body":  "{{inc (len .X)}}",
+		}}}); err != nil {
+			t.Fatal(err)
+		}
+		return opts
+	}
+	a, b := set(), set()
+	if !reflect.DeepEqual(a, b) {
+		t.Error("options with the same postfix snippets are not DeepEqual")
+	}
+	if clone := a.Clone(); !reflect.DeepEqual(a, clone) {
+		t.Error("Clone did not preserve the parsed postfix snippets")
+	}
+	tmpl, err := a.PostfixSnippets[0].Template()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf strings.Builder
+	if err := tmpl.Execute(&buf, map[string]any{"X": "ab"}); err != nil {
+		t.Fatal(err)
+	}
+	if got := buf.String(); got != "3" {
+		t.Errorf("executing template: got %q, want %q", got, "3")
+	}
+}
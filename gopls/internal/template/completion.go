@@ -10,6 +10,7 @@ import (
 	"fmt"
 	"go/scanner"
 	gotoken "go/token"
+	"go/types"
 	"strings"
 
 	"golang.org/x/tools/gopls/internal/cache"
@@ -24,6 +25,7 @@ type completer struct {
 	offset int // offset of the start of the Token
 	ctx    protocol.CompletionContext
 	syms   map[string]symbol
+	types  *typeInfo // type information for p, or nil
 }
 
 func Completion(ctx context.Context, snapshot *cache.Snapshot, fh file.Handle, pos protocol.Position, context protocol.CompletionContext) (*protocol.CompletionList, error) {
@@ -54,6 +56,7 @@ func Completion(ctx context.Context, snapshot *cache.Snapshot, fh file.Handle, p
 		offset: start + len(lbraces),
 		ctx:    context,
 		syms:   syms,
+		types:  typesFor(ctx, snapshot, fh, p),
 	}
 	return c.complete()
 }
@@ -142,6 +145,12 @@ func (c *completer) complete() (*protocol.CompletionList, error) {
 		return nil, nil // if this happens, why were we called?
 	}
 	pattern := words[len(words)-1]
+	if c.types != nil {
+		if items, ok := c.fieldCompletions(sofar, start); ok {
+			ans.Items = items
+			return ans, nil
+		}
+	}
 	if pattern[0] == '$' {
 		// should we also return a raw "$"?
 		for _, s := range c.syms {
@@ -203,6 +212,63 @@ func (c *completer) complete() (*protocol.CompletionList, error) {
 	return ans, nil
 }
 
+// fieldCompletions returns completions for the field chain at the end
+// of sofar, such as ".Foo.Ba" or "$x.Ba", using the type of dot at
+// offset or the type of the variable. It reports false if the type of
+// the chain is unknown.
+func (c *completer) fieldCompletions(sofar []byte, offset int) ([]protocol.CompletionItem, bool) {
+	i := len(sofar)
+	for i > 0 && (isIdentByte(sofar[i-1]) || sofar[i-1] == '.' || sofar[i-1] == '$') {
+		i--
+	}
+	chain := string(sofar[i:])
+	dot := strings.LastIndexByte(chain, '.')
+	if dot < 0 {
+		return nil, false
+	}
+	parts, prefix := strings.Split(chain[:dot], "."), chain[dot+1:]
+	var typ types.Type
+	switch {
+	case parts[0] == "":
+		typ = c.types.dot(offset)
+	case parts[0][0] == '$':
+		typ = c.types.vars[parts[0]]
+	default:
+		return nil, false
+	}
+	for _, name := range parts[1:] {
+		if typ == nil {
+			break
+		}
+		_, typ, _ = c.types.lookup(typ, name)
+	}
+	objs := members(typ)
+	if len(objs) == 0 {
+		return nil, false
+	}
+	qual := c.types.qualifier()
+	items := []protocol.CompletionItem{}
+	for _, obj := range objs {
+		if weakMatch("."+obj.Name(), "."+prefix) == 0 {
+			continue
+		}
+		kind := protocol.FieldCompletion
+		if _, ok := obj.(*types.Func); ok {
+			kind = protocol.MethodCompletion
+		}
+		items = append(items, protocol.CompletionItem{
+			Label:  obj.Name(),
+			Kind:   kind,
+			Detail: types.TypeString(obj.Type(), qual),
+		})
+	}
+	return items, true
+}
+
+func isIdentByte(b byte) bool {
+	return b == '_' || '0' <= b && b <= '9' || 'a' <= b && b <= 'z' || 'A' <= b && b <= 'Z'
+}
+
 // version of c.analyze that uses go/scanner.
 func scan(buf []byte) []string {
 	fset := gotoken.NewFileSet()
@@ -13,8 +13,10 @@ import (
 
 	"golang.org/x/tools/gopls/internal/cache"
 	"golang.org/x/tools/gopls/internal/file"
+	"golang.org/x/tools/gopls/internal/golang"
 	"golang.org/x/tools/gopls/internal/protocol"
 	"golang.org/x/tools/gopls/internal/protocol/semtok"
+	"golang.org/x/tools/internal/event"
 )
 
 // line number (1-based) and message
@@ -23,10 +25,49 @@ var errRe = regexp.MustCompile(`template.*:(\d+): (.*)`)
 // Diagnostics returns parse errors. There is only one per file.
 // The errors are not always helpful. For instance { {end}}
 // will likely point to the end of the file.
-func Diagnostics(snapshot *cache.Snapshot) map[protocol.DocumentURI][]*cache.Diagnostic {
+//
+// For templates whose data type is known (see [bindings]), it also
+// reports references to unknown fields and undefined functions.
+func Diagnostics(ctx context.Context, snapshot *cache.Snapshot) map[protocol.DocumentURI][]*cache.Diagnostic {
+	bs, err := bindings(ctx, snapshot)
+	if err != nil {
+		event.Error(ctx, "finding template bindings", err)
+	}
 	diags := make(map[protocol.DocumentURI][]*cache.Diagnostic)
 	for uri, fh := range snapshot.Templates() {
 		diags[uri] = diagnoseOne(fh)
+		if len(diags[uri]) == 0 && bs[uri] != nil {
+			diags[uri] = diagnoseTypes(fh, bs[uri])
+		}
+	}
+	return diags
+}
+
+// diagnoseTypes reports unresolved references in a template that
+// parses without error.
+func diagnoseTypes(fh file.Handle, b *binding) []*cache.Diagnostic {
+	buf, err := fh.Content()
+	if err != nil {
+		return nil
+	}
+	p := parseBuffer(fh.URI(), buf)
+	ti := typeCheck(p, b)
+	if ti == nil {
+		return nil
+	}
+	var diags []*cache.Diagnostic
+	for _, e := range ti.errs {
+		rng, err := p.mapper.OffsetRange(e.start, e.end)
+		if err != nil {
+			continue
+		}
+		diags = append(diags, &cache.Diagnostic{
+			URI:      fh.URI(),
+			Range:    rng,
+			Severity: protocol.SeverityWarning,
+			Source:   cache.TemplateError,
+			Message:  e.msg,
+		})
 	}
 	return diags
 }
@@ -96,12 +137,22 @@ func diagnoseOne(fh file.Handle) []*cache.Diagnostic {
 // Definition finds the definitions of the symbol at loc. It
 // does not understand scoping (if any) in templates. This code is
 // for definitions, type definitions, and implementations.
-// Results only for variables and templates.
-func Definition(snapshot *cache.Snapshot, fh file.Handle, rng protocol.Range) ([]protocol.Location, error) {
-	x, _, err := symAtRange(fh, rng)
+// Results are for variables and templates, and for fields, methods
+// and functions whose Go declarations are known.
+func Definition(ctx context.Context, snapshot *cache.Snapshot, fh file.Handle, rng protocol.Range) ([]protocol.Location, error) {
+	x, p, err := symAtRange(fh, rng)
 	if err != nil {
 		return nil, err
 	}
+	if ti := typesFor(ctx, snapshot, fh, p); ti != nil {
+		if r := ti.refAt(x.start); r != nil && r.obj != nil {
+			loc, err := golang.ObjectLocation(ctx, ti.b.pkg.FileSet(), snapshot, r.obj)
+			if err != nil {
+				return nil, err
+			}
+			return []protocol.Location{loc}, nil
+		}
+	}
 	sym := x.name
 	ans := []protocol.Location{}
 	// PJW: this is probably a pattern to abstract
@@ -128,27 +179,11 @@ func Hover(ctx context.Context, snapshot *cache.Snapshot, fh file.Handle, rng pr
 	}
 
 	var value string
-	switch sym.kind {
-	case protocol.Function:
-		value = fmt.Sprintf("function: %s", sym.name)
-	case protocol.Variable:
-		value = fmt.Sprintf("variable: %s", sym.name)
-	case protocol.Constant:
-		value = fmt.Sprintf("constant %s", sym.name)
-	case protocol.Method: // field or method
-		value = fmt.Sprintf("%s: field or method", sym.name)
-	case protocol.Package: // template use, template def (PJW: do we want two?)
-		value = fmt.Sprintf("template %s\n(add definition)", sym.name)
-	case protocol.Namespace:
-		value = fmt.Sprintf("template %s defined", sym.name)
-	case protocol.Number:
-		value = "number"
-	case protocol.String:
-		value = "string"
-	case protocol.Boolean:
-		value = "boolean"
-	default:
-		value = fmt.Sprintf("oops, sym=%#v", sym)
+	if ti := typesFor(ctx, snapshot, fh, p); ti != nil {
+		value = ti.hover(sym)
+	}
+	if value == "" {
+		value = hoverSymbol(sym)
 	}
 
 	symRng, err := p.mapper.OffsetRange(sym.offsets())
@@ -165,6 +200,32 @@ func Hover(ctx context.Context, snapshot *cache.Snapshot, fh file.Handle, rng pr
 	}, nil
 }
 
+// hoverSymbol returns the hover text for a symbol whose type is unknown.
+func hoverSymbol(sym *symbol) string {
+	switch sym.kind {
+	case protocol.Function:
+		return fmt.Sprintf("function: %s", sym.name)
+	case protocol.Variable:
+		return fmt.Sprintf("variable: %s", sym.name)
+	case protocol.Constant:
+		return fmt.Sprintf("constant %s", sym.name)
+	case protocol.Method: // field or method
+		return fmt.Sprintf("%s: field or method", sym.name)
+	case protocol.Package: // template use, template def (PJW: do we want two?)
+		return fmt.Sprintf("template %s\n(add definition)", sym.name)
+	case protocol.Namespace:
+		return fmt.Sprintf("template %s defined", sym.name)
+	case protocol.Number:
+		return "number"
+	case protocol.String:
+		return "string"
+	case protocol.Boolean:
+		return "boolean"
+	default:
+		return fmt.Sprintf("oops, sym=%#v", sym)
+	}
+}
+
 func References(ctx context.Context, snapshot *cache.Snapshot, fh file.Handle, params *protocol.ReferenceParams) ([]protocol.Location, error) {
 	sym, _, err := symAtRange(fh, params.Range)
 	if err != nil {
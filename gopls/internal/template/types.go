@@ -0,0 +1,835 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package template
+
+// This file infers the Go type of the data passed to a template by
+// looking in workspace packages for calls such as
+//
+//	template.Must(template.ParseFiles("page.tmpl")).Execute(w, data)
+//
+// and uses it to resolve the field and method references of the
+// template, which in turn drive completion, hover, definition and
+// diagnostics.
+
+import (
+	"context"
+	"fmt"
+	"go/ast"
+	"go/constant"
+	"go/types"
+	"path/filepath"
+	"slices"
+	"sort"
+	"text/template/parse"
+
+	"golang.org/x/tools/go/types/typeutil"
+	"golang.org/x/tools/gopls/internal/cache"
+	"golang.org/x/tools/gopls/internal/cache/metadata"
+	"golang.org/x/tools/gopls/internal/file"
+	"golang.org/x/tools/gopls/internal/protocol"
+	"golang.org/x/tools/internal/event"
+	"golang.org/x/tools/internal/typesinternal"
+)
+
+// A binding records what is known about how a template file is
+// executed by Go code in the workspace.
+type binding struct {
+	pkg   *cache.Package      // package containing the Execute call
+	entry string              // name of the template that is executed ("" for the file's root)
+	data  types.Type          // type of the data argument, or nil if unknown
+	funcs map[string]tmplFunc // functions added by Funcs; nil if unknown
+}
+
+// A tmplFunc is a function added to a template by a FuncMap.
+type tmplFunc struct {
+	obj types.Object     // declaration of the function value, if any
+	sig *types.Signature // type of the function value, if known
+}
+
+// A ref is a field, method or function reference in a template
+// that has been resolved to a Go declaration.
+type ref struct {
+	start, end int          // byte offsets of the name in the template
+	obj        types.Object // field, method or function; nil for map keys
+	typ        types.Type   // type of the field, or result type of the method
+}
+
+// A typeError is a reference in a template that cannot be resolved.
+type typeError struct {
+	start, end int
+	msg        string
+}
+
+// A dotAt records the type of dot at the start of a node.
+type dotAt struct {
+	pos int
+	typ types.Type
+}
+
+// typeInfo holds the result of type-annotating one template file.
+type typeInfo struct {
+	b     *binding
+	refs  []ref
+	errs  []typeError
+	dots  []dotAt               // sorted by pos
+	vars  map[string]types.Type // variables, ignoring scope
+	funcs map[string]tmplFunc
+}
+
+// bindingsKey is the key under which the result of [bindings] is
+// memoized in each snapshot.
+type bindingsKey struct{}
+
+// bindings returns the binding of each template file of the snapshot
+// that is named by a call to ParseFiles or ParseGlob whose result is
+// executed in a workspace package.
+//
+// Finding the bindings requires type-checking every workspace package
+// that imports a template package, so the result is memoized for the
+// lifetime of the snapshot.
+func bindings(ctx context.Context, snapshot *cache.Snapshot) (map[protocol.DocumentURI]*binding, error) {
+	type result struct {
+		bs  map[protocol.DocumentURI]*binding
+		err error
+	}
+	v, err := snapshot.Memoize(ctx, bindingsKey{}, func(ctx context.Context, snapshot *cache.Snapshot) any {
+		bs, err := findBindings(ctx, snapshot)
+		return result{bs, err}
+	})
+	if err != nil {
+		return nil, err
+	}
+	res := v.(result)
+	return res.bs, res.err
+}
+
+// findBindings computes the result of [bindings].
+func findBindings(ctx context.Context, snapshot *cache.Snapshot) (map[protocol.DocumentURI]*binding, error) {
+	tmpls := snapshot.Templates()
+	if len(tmpls) == 0 {
+		return nil, nil
+	}
+	mps, err := snapshot.WorkspaceMetadata(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var ids []metadata.PackageID
+	for _, mp := range mps {
+		if mp.IsIntermediateTestVariant() {
+			continue
+		}
+		if mp.DepsByPkgPath["text/template"] != "" || mp.DepsByPkgPath["html/template"] != "" {
+			ids = append(ids, mp.ID)
+		}
+	}
+	if len(ids) == 0 {
+		return nil, nil
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	pkgs, err := snapshot.TypeCheck(ctx, ids...)
+	if err != nil {
+		return nil, err
+	}
+	res := make(map[protocol.DocumentURI]*binding)
+	for _, pkg := range pkgs {
+		f := &finder{pkg: pkg, tmpls: tmpls, res: res}
+		for _, pgf := range pkg.CompiledGoFiles() {
+			f.dir = pgf.URI.DirPath()
+			ast.Inspect(pgf.File, func(n ast.Node) bool {
+				if call, ok := n.(*ast.CallExpr); ok {
+					f.execute(call)
+				}
+				return true
+			})
+		}
+	}
+	return res, nil
+}
+
+// A finder looks for calls to Template.Execute in a single package.
+type finder struct {
+	pkg   *cache.Package
+	tmpls map[protocol.DocumentURI]file.Handle
+	res   map[protocol.DocumentURI]*binding
+	dir   string                  // directory of the current file
+	inits map[*types.Var]ast.Expr // lazily computed initializers of variables
+}
+
+// execute records bindings for call if it is a call to Execute or
+// ExecuteTemplate on a template whose source files can be determined.
+func (f *finder) execute(call *ast.CallExpr) {
+	info := f.pkg.TypesInfo()
+	fn, _ := typeutil.Callee(info, call).(*types.Func)
+	if fn == nil || len(call.Args) < 2 {
+		return
+	}
+	var entry string
+	switch {
+	case isTemplateMethod(fn, "Execute"):
+	case isTemplateMethod(fn, "ExecuteTemplate"):
+		tv, ok := info.Types[call.Args[1]]
+		if !ok || tv.Value == nil || tv.Value.Kind() != constant.String {
+			return
+		}
+		entry = constant.StringVal(tv.Value)
+	default:
+		return
+	}
+	sel, ok := ast.Unparen(call.Fun).(*ast.SelectorExpr)
+	if !ok {
+		return
+	}
+	var (
+		files []protocol.DocumentURI
+		funcs = make(map[string]tmplFunc)
+	)
+	if !f.source(sel.X, &files, &funcs, 0) || len(files) == 0 {
+		return
+	}
+	data := info.TypeOf(call.Args[len(call.Args)-1])
+	if basic, ok := data.(*types.Basic); ok && basic.Kind() == types.UntypedNil {
+		data = nil
+	}
+	for i, uri := range files {
+		if _, ok := f.res[uri]; ok {
+			continue // first binding wins
+		}
+		b := &binding{pkg: f.pkg, funcs: funcs, entry: entry}
+		// Execute runs the template named after the first file;
+		// ExecuteTemplate runs the named template, which may be
+		// a file or a {{define}} within any of the files.
+		switch {
+		case entry == "" && i == 0, entry != "" && entry == uri.Base():
+			b.entry, b.data = "", data
+		case entry != "":
+			b.data = data
+		}
+		f.res[uri] = b
+	}
+}
+
+// source reports the template files and functions of the template
+// denoted by e, following chains of method calls such as
+//
+//	template.Must(template.New("x").Funcs(m).ParseFiles("a.tmpl"))
+//
+// and simple variables initialized by such calls. It reports false if
+// the template cannot be determined. A nil *funcs means that the set of
+// functions could not be determined.
+func (f *finder) source(e ast.Expr, files *[]protocol.DocumentURI, funcs *map[string]tmplFunc, depth int) bool {
+	if depth > 10 {
+		return false // cycle or absurdly long chain
+	}
+	info := f.pkg.TypesInfo()
+	switch e := ast.Unparen(e).(type) {
+	case *ast.Ident:
+		v, ok := info.Uses[e].(*types.Var)
+		if !ok {
+			return false
+		}
+		init := f.initializer(v)
+		return init != nil && f.source(init, files, funcs, depth+1)
+
+	case *ast.CallExpr:
+		fn, _ := typeutil.Callee(info, e).(*types.Func)
+		if fn == nil {
+			return false
+		}
+		switch {
+		case isTemplateFunc(fn, "Must"):
+			return len(e.Args) == 1 && f.source(e.Args[0], files, funcs, depth+1)
+		case isTemplateFunc(fn, "New"):
+			return true
+		case isTemplateFunc(fn, "ParseFiles"), isTemplateFunc(fn, "ParseGlob"):
+			f.files(fn.Name(), e.Args, files)
+			return true
+		}
+		sel, ok := ast.Unparen(e.Fun).(*ast.SelectorExpr)
+		if !ok || !isTemplateMethod(fn, fn.Name()) {
+			return false
+		}
+		switch fn.Name() {
+		case "ParseFiles", "ParseGlob":
+			f.files(fn.Name(), e.Args, files)
+		case "Funcs":
+			if len(e.Args) == 1 {
+				f.funcMap(e.Args[0], funcs)
+			}
+		case "Option", "Delims", "Lookup", "New", "Clone":
+			// These preserve (or share) the template's associated
+			// files and functions, so far as we are concerned.
+		default:
+			return false
+		}
+		return f.source(sel.X, files, funcs, depth+1)
+	}
+	return false
+}
+
+// files appends the template files named by the arguments of a call
+// to ParseFiles or ParseGlob. Relative names are resolved relative to
+// the directory of the calling file, on the assumption that the
+// program is run from there.
+func (f *finder) files(name string, args []ast.Expr, files *[]protocol.DocumentURI) {
+	info := f.pkg.TypesInfo()
+	for _, arg := range args {
+		tv, ok := info.Types[arg]
+		if !ok || tv.Value == nil || tv.Value.Kind() != constant.String {
+			continue
+		}
+		path := constant.StringVal(tv.Value)
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(f.dir, path)
+		}
+		if name == "ParseFiles" {
+			uri := protocol.URIFromPath(path)
+			if _, ok := f.tmpls[uri]; ok {
+				*files = append(*files, uri)
+			}
+			continue
+		}
+		var matches []protocol.DocumentURI
+		for uri := range f.tmpls {
+			if ok, _ := filepath.Match(path, uri.Path()); ok {
+				matches = append(matches, uri)
+			}
+		}
+		slices.Sort(matches) // ParseGlob orders files lexically
+		*files = append(*files, matches...)
+	}
+}
+
+// funcMap adds the functions of the FuncMap denoted by e to *funcs,
+// or sets it to nil if they cannot be determined.
+func (f *finder) funcMap(e ast.Expr, funcs *map[string]tmplFunc) {
+	if *funcs == nil {
+		return
+	}
+	info := f.pkg.TypesInfo()
+	if id, ok := ast.Unparen(e).(*ast.Ident); ok {
+		if v, ok := info.Uses[id].(*types.Var); ok {
+			if init := f.initializer(v); init != nil {
+				e = init
+			}
+		}
+	}
+	lit, ok := ast.Unparen(e).(*ast.CompositeLit)
+	if !ok {
+		*funcs = nil
+		return
+	}
+	for _, elt := range lit.Elts {
+		kv, ok := elt.(*ast.KeyValueExpr)
+		if !ok {
+			continue
+		}
+		tv, ok := info.Types[kv.Key]
+		if !ok || tv.Value == nil || tv.Value.Kind() != constant.String {
+			*funcs = nil // computed key
+			return
+		}
+		var fn tmplFunc
+		fn.sig, _ = info.TypeOf(kv.Value).Underlying().(*types.Signature)
+		switch v := ast.Unparen(kv.Value).(type) {
+		case *ast.Ident:
+			fn.obj = info.Uses[v]
+		case *ast.SelectorExpr:
+			fn.obj = info.Uses[v.Sel]
+		}
+		(*funcs)[constant.StringVal(tv.Value)] = fn
+	}
+}
+
+// initializer returns the expression first assigned to v in the
+// package, or nil if there is none.
+func (f *finder) initializer(v *types.Var) ast.Expr {
+	if f.inits == nil {
+		f.inits = make(map[*types.Var]ast.Expr)
+		info := f.pkg.TypesInfo()
+		record := func(lhs ast.Expr, rhs ast.Expr) {
+			id, ok := lhs.(*ast.Ident)
+			if !ok {
+				return
+			}
+			obj, _ := info.ObjectOf(id).(*types.Var)
+			if _, ok := f.inits[obj]; obj != nil && !ok {
+				f.inits[obj] = rhs
+			}
+		}
+		for _, pgf := range f.pkg.CompiledGoFiles() {
+			ast.Inspect(pgf.File, func(n ast.Node) bool {
+				switch n := n.(type) {
+				case *ast.ValueSpec:
+					if len(n.Values) == len(n.Names) {
+						for i, name := range n.Names {
+							record(name, n.Values[i])
+						}
+					} else if len(n.Values) == 1 && len(n.Names) > 0 {
+						record(n.Names[0], n.Values[0]) // t, err = template.ParseFiles(...)
+					}
+				case *ast.AssignStmt:
+					if len(n.Lhs) == len(n.Rhs) {
+						for i, lhs := range n.Lhs {
+							record(lhs, n.Rhs[i])
+						}
+					} else if len(n.Rhs) == 1 {
+						record(n.Lhs[0], n.Rhs[0])
+					}
+				}
+				return true
+			})
+		}
+	}
+	return f.inits[v]
+}
+
+func isTemplateFunc(fn *types.Func, name string) bool {
+	return typesinternal.IsFunctionNamed(fn, "text/template", name) ||
+		typesinternal.IsFunctionNamed(fn, "html/template", name)
+}
+
+func isTemplateMethod(fn *types.Func, name string) bool {
+	return typesinternal.IsMethodNamed(fn, "text/template", "Template", name) ||
+		typesinternal.IsMethodNamed(fn, "html/template", "Template", name)
+}
+
+// typesFor returns the type information for the parsed template file
+// p, or nil if no Execute call for the file was found.
+func typesFor(ctx context.Context, snapshot *cache.Snapshot, fh file.Handle, p *parsed) *typeInfo {
+	bs, err := bindings(ctx, snapshot)
+	if err != nil {
+		event.Error(ctx, "finding template bindings", err)
+		return nil
+	}
+	return typeCheck(p, bs[fh.URI()])
+}
+
+// typeCheck computes type information for the parsed template file p
+// from its binding, which may be nil. It returns nil if nothing is
+// known about the file.
+func typeCheck(p *parsed, b *binding) *typeInfo {
+	if b == nil || p.parseErr != nil {
+		return nil
+	}
+	ti := &typeInfo{
+		b:     b,
+		vars:  make(map[string]types.Type),
+		funcs: b.funcs,
+	}
+	t := &typer{p: p, ti: ti, seen: make(map[string]bool)}
+	for _, tmpl := range p.named {
+		if tmpl.Name() == b.entry {
+			t.seen[b.entry] = true
+			ti.vars["$"] = b.data
+			t.list(tmpl.Root, b.data)
+		}
+	}
+	// Named templates that are not reached from the entry point are
+	// still checked for calls to undefined functions.
+	for _, tmpl := range p.named {
+		if !t.seen[tmpl.Name()] {
+			t.seen[tmpl.Name()] = true
+			t.list(tmpl.Root, nil)
+		}
+	}
+	sort.SliceStable(ti.dots, func(i, j int) bool { return ti.dots[i].pos < ti.dots[j].pos })
+	return ti
+}
+
+// dot returns the type of dot at the given offset, or nil if unknown.
+func (ti *typeInfo) dot(offset int) types.Type {
+	i := sort.Search(len(ti.dots), func(i int) bool { return ti.dots[i].pos > offset })
+	if i == 0 {
+		return nil
+	}
+	return ti.dots[i-1].typ
+}
+
+// refAt returns the resolved reference enclosing offset, if any.
+func (ti *typeInfo) refAt(offset int) *ref {
+	for i, r := range ti.refs {
+		if r.start <= offset && offset <= r.end {
+			return &ti.refs[i]
+		}
+	}
+	return nil
+}
+
+// hover returns the hover text for a symbol whose Go type is known,
+// or "" if it is not.
+func (ti *typeInfo) hover(sym *symbol) string {
+	qual := ti.qualifier()
+	var text string
+	if r := ti.refAt(sym.start); r != nil {
+		switch {
+		case r.obj != nil:
+			text = types.ObjectString(r.obj, qual)
+		case r.typ != nil:
+			text = fmt.Sprintf("map value %s %s", sym.name, types.TypeString(r.typ, qual))
+		}
+	} else if sym.kind == protocol.Variable {
+		name, typ := sym.name, ti.vars[sym.name]
+		if name == "dot" {
+			name, typ = ".", ti.dot(sym.start)
+		}
+		if typ != nil {
+			text = fmt.Sprintf("%s %s", name, types.TypeString(typ, qual))
+		}
+	}
+	if text == "" {
+		return ""
+	}
+	return "```go\n" + text + "\n```"
+}
+
+// qualifier returns the qualifier for type strings in messages.
+func (ti *typeInfo) qualifier() types.Qualifier {
+	return types.RelativeTo(ti.b.pkg.Types())
+}
+
+// A typer walks the parse tree of a template, tracking the type of dot.
+type typer struct {
+	p    *parsed
+	ti   *typeInfo
+	seen map[string]bool // named templates already visited
+}
+
+func (t *typer) list(l *parse.ListNode, dot types.Type) {
+	if l == nil {
+		return
+	}
+	for _, n := range l.Nodes {
+		t.ti.dots = append(t.ti.dots, dotAt{int(n.Position()), dot})
+		t.node(n, dot)
+	}
+}
+
+func (t *typer) node(n parse.Node, dot types.Type) {
+	switch n := n.(type) {
+	case *parse.ActionNode:
+		t.pipe(n.Pipe, dot)
+	case *parse.IfNode:
+		t.pipe(n.Pipe, dot)
+		t.list(n.List, dot)
+		t.list(n.ElseList, dot)
+	case *parse.WithNode:
+		inner := t.pipe(n.Pipe, dot)
+		t.list(n.List, inner)
+		t.list(n.ElseList, dot)
+	case *parse.RangeNode:
+		key, elem := rangeTypes(t.cmds(n.Pipe, dot))
+		if n.Pipe != nil {
+			switch len(n.Pipe.Decl) {
+			case 1:
+				t.declare(n.Pipe.Decl[0], elem)
+			case 2:
+				t.declare(n.Pipe.Decl[0], key)
+				t.declare(n.Pipe.Decl[1], elem)
+			}
+		}
+		t.list(n.List, elem)
+		t.list(n.ElseList, dot)
+	case *parse.TemplateNode:
+		arg := t.pipe(n.Pipe, dot)
+		if t.seen[n.Name] {
+			break
+		}
+		for _, tmpl := range t.p.named {
+			if tmpl.Name() == n.Name {
+				t.seen[n.Name] = true
+				if n.Pipe == nil {
+					arg = nil
+				}
+				t.list(tmpl.Root, arg)
+			}
+		}
+	case *parse.ListNode:
+		t.list(n, dot)
+	}
+}
+
+// pipe returns the type of the pipeline, declaring its variables.
+func (t *typer) pipe(p *parse.PipeNode, dot types.Type) types.Type {
+	typ := t.cmds(p, dot)
+	if p != nil {
+		for _, v := range p.Decl {
+			t.declare(v, typ)
+		}
+	}
+	return typ
+}
+
+// cmds returns the type of the last command of a pipeline.
+func (t *typer) cmds(p *parse.PipeNode, dot types.Type) types.Type {
+	if p == nil {
+		return nil
+	}
+	var typ types.Type
+	for _, c := range p.Cmds {
+		typ = t.command(c, dot)
+	}
+	return typ
+}
+
+func (t *typer) declare(v *parse.VariableNode, typ types.Type) {
+	if len(v.Ident) == 1 {
+		t.ti.vars[v.Ident[0]] = typ
+	}
+}
+
+// command returns the result type of a command.
+func (t *typer) command(c *parse.CommandNode, dot types.Type) types.Type {
+	if len(c.Args) == 0 {
+		return nil
+	}
+	var args []types.Type
+	for _, a := range c.Args[1:] {
+		args = append(args, t.arg(a, dot))
+	}
+	if id, ok := c.Args[0].(*parse.IdentifierNode); ok {
+		return t.call(id, args)
+	}
+	return t.arg(c.Args[0], dot)
+}
+
+// arg returns the type of an operand.
+func (t *typer) arg(n parse.Node, dot types.Type) types.Type {
+	switch n := n.(type) {
+	case *parse.DotNode:
+		return dot
+	case *parse.FieldNode:
+		return t.fields(dot, n.Ident, t.p.fields(n.Ident, n))
+	case *parse.VariableNode:
+		syms := t.p.fields(n.Ident, n)
+		if len(syms) > 0 {
+			syms = syms[1:]
+		}
+		return t.fields(t.ti.vars[n.Ident[0]], n.Ident[1:], syms)
+	case *parse.ChainNode:
+		return t.fields(t.arg(n.Node, dot), n.Field, t.p.fields(n.Field, n))
+	case *parse.PipeNode:
+		return t.pipe(n, dot)
+	case *parse.IdentifierNode:
+		return t.call(n, nil)
+	case *parse.StringNode:
+		return types.Typ[types.String]
+	case *parse.BoolNode:
+		return types.Typ[types.Bool]
+	}
+	return nil
+}
+
+// fields returns the type of the field chain names applied to recv,
+// recording references and errors. The symbols give the locations
+// of the names, if known.
+func (t *typer) fields(recv types.Type, names []string, syms []symbol) types.Type {
+	for i, name := range names {
+		if recv == nil {
+			return nil
+		}
+		start, end := -1, -1
+		if i < len(syms) && syms[i].name == name {
+			start, end = syms[i].offsets()
+		}
+		obj, typ, err := t.ti.lookup(recv, name)
+		if err != "" {
+			if start >= 0 {
+				t.ti.errs = append(t.ti.errs, typeError{start, end, err})
+			}
+			return nil
+		}
+		if start >= 0 && (obj != nil || typ != nil) {
+			t.ti.refs = append(t.ti.refs, ref{start, end, obj, typ})
+		}
+		recv = typ
+	}
+	return recv
+}
+
+// lookup resolves a field or method name in recv in the manner of
+// text/template, returning the declaration, its type (or method
+// result type) and an error message if the name cannot be resolved.
+// Both results are nil if the type is not statically known.
+func (ti *typeInfo) lookup(recv types.Type, name string) (types.Object, types.Type, string) {
+	for {
+		ptr, ok := recv.Underlying().(*types.Pointer)
+		if !ok {
+			break
+		}
+		// Look for methods on the pointer type before indirecting.
+		if obj, _, _ := types.LookupFieldOrMethod(recv, false, nil, name); obj != nil {
+			break
+		}
+		recv = ptr.Elem()
+	}
+	switch u := recv.Underlying().(type) {
+	case *types.Interface:
+		if obj, _, _ := types.LookupFieldOrMethod(recv, false, nil, name); obj != nil {
+			return obj, resultType(obj), ""
+		}
+		return nil, nil, "" // depends on the dynamic type
+	case *types.Map:
+		if obj, _, _ := types.LookupFieldOrMethod(recv, true, nil, name); obj != nil {
+			return obj, resultType(obj), ""
+		}
+		if basic, ok := u.Key().Underlying().(*types.Basic); ok && basic.Info()&types.IsString != 0 {
+			return nil, u.Elem(), ""
+		}
+	case *types.TypeParam:
+		return nil, nil, ""
+	}
+	obj, _, _ := types.LookupFieldOrMethod(recv, true, nil, name)
+	if obj == nil || !obj.Exported() {
+		return nil, nil, fmt.Sprintf("can't evaluate field %s in type %s",
+			name, types.TypeString(recv, ti.qualifier()))
+	}
+	return obj, resultType(obj), ""
+}
+
+// resultType returns the type of a field, or the first result type of
+// a method.
+func resultType(obj types.Object) types.Type {
+	if fn, ok := obj.(*types.Func); ok {
+		sig := fn.Signature()
+		if sig.Results().Len() == 0 {
+			return nil
+		}
+		return sig.Results().At(0).Type()
+	}
+	return obj.Type()
+}
+
+// call returns the result type of a call to the named function,
+// recording a reference or an error if it is not defined.
+func (t *typer) call(id *parse.IdentifierNode, args []types.Type) types.Type {
+	start, end := int(id.Pos), int(id.Pos)+len(id.Ident)
+	if fn, ok := t.ti.funcs[id.Ident]; ok {
+		if fn.obj != nil {
+			t.ti.refs = append(t.ti.refs, ref{start, end, fn.obj, fn.sig})
+		}
+		if fn.sig == nil || fn.sig.Results().Len() == 0 {
+			return nil
+		}
+		return fn.sig.Results().At(0).Type()
+	}
+	if !slices.Contains(globals, id.Ident) {
+		if t.ti.funcs != nil {
+			t.ti.errs = append(t.ti.errs, typeError{start, end,
+				fmt.Sprintf("function %q not defined", id.Ident)})
+		}
+		return nil
+	}
+	switch id.Ident {
+	case "not", "eq", "ne", "lt", "le", "gt", "ge":
+		return types.Typ[types.Bool]
+	case "len":
+		return types.Typ[types.Int]
+	case "html", "js", "urlquery", "print", "printf", "println":
+		return types.Typ[types.String]
+	case "slice":
+		if len(args) > 0 {
+			return args[0]
+		}
+	case "index":
+		if len(args) == 0 {
+			return nil
+		}
+		typ := args[0]
+		for range args[1:] {
+			if typ == nil {
+				return nil
+			}
+			_, typ = rangeTypes(typ)
+		}
+		return typ
+	case "call":
+		if len(args) > 0 && args[0] != nil {
+			if sig, ok := args[0].Underlying().(*types.Signature); ok && sig.Results().Len() > 0 {
+				return sig.Results().At(0).Type()
+			}
+		}
+	}
+	return nil
+}
+
+// rangeTypes returns the key and element types of a range over a value
+// of type typ, following the rules of text/template.
+func rangeTypes(typ types.Type) (key, elem types.Type) {
+	if typ == nil {
+		return nil, nil
+	}
+	if ptr, ok := typ.Underlying().(*types.Pointer); ok {
+		typ = ptr.Elem()
+	}
+	switch u := typ.Underlying().(type) {
+	case *types.Slice:
+		return types.Typ[types.Int], u.Elem()
+	case *types.Array:
+		return types.Typ[types.Int], u.Elem()
+	case *types.Map:
+		return u.Key(), u.Elem()
+	case *types.Chan:
+		return u.Elem(), u.Elem()
+	case *types.Basic:
+		if u.Info()&types.IsInteger != 0 {
+			return typ, typ
+		}
+	case *types.Signature:
+		// iter.Seq or iter.Seq2
+		if u.Params().Len() == 1 {
+			if yield, ok := u.Params().At(0).Type().Underlying().(*types.Signature); ok {
+				switch yield.Params().Len() {
+				case 1:
+					return yield.Params().At(0).Type(), yield.Params().At(0).Type()
+				case 2:
+					return yield.Params().At(0).Type(), yield.Params().At(1).Type()
+				}
+			}
+		}
+	}
+	return nil, nil
+}
+
+// members returns the exported fields and methods that may follow a
+// value of type typ in a field chain, in a deterministic order.
+func members(typ types.Type) []types.Object {
+	if typ == nil {
+		return nil
+	}
+	var res []types.Object
+	seen := make(map[string]bool)
+	add := func(obj types.Object) {
+		if obj.Exported() && !seen[obj.Name()] {
+			seen[obj.Name()] = true
+			res = append(res, obj)
+		}
+	}
+	for _, sel := range typeutil.IntuitiveMethodSet(typ, nil) {
+		add(sel.Obj())
+	}
+	// Fields, including promoted fields of embedded structs.
+	var visit func(t types.Type, depth int)
+	visit = func(t types.Type, depth int) {
+		if depth > 5 {
+			return
+		}
+		if ptr, ok := t.Underlying().(*types.Pointer); ok {
+			t = ptr.Elem()
+		}
+		st, ok := t.Underlying().(*types.Struct)
+		if !ok {
+			return
+		}
+		for f := range st.Fields() {
+			add(f)
+			if f.Embedded() {
+				visit(f.Type(), depth+1)
+			}
+		}
+	}
+	visit(typ, 0)
+	sort.Slice(res, func(i, j int) bool { return res[i].Name() < res[j].Name() })
+	return res
+}
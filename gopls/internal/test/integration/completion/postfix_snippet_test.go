@@ -5,6 +5,7 @@
 package completion
 
 import (
+	"slices"
 	"strings"
 	"testing"
 
@@ -760,3 +761,98 @@ func _() {
 		}
 	})
 }
+
+func TestUserPostfixSnippets(t *testing.T) {
+	const src = `
+-- go.mod --
+module mod.com
+
+go 1.18
+
+-- a.go --
+package foo
+
+func _(err error) {
+	err.
+}
+
+func _(n int) {
+	n.
+}
+`
+	WithOptions(
+		Settings{
+			"experimentalPostfixCompletions": true,
+			"postfixSnippets": []any{
+				map[string]any{
+					"label":   "wrap",
+					"details": "wrap error",
+					"body":    `{{.Import "fmt"}}.Errorf("{{.Cursor}}: %w", {{.X}})`,
+					"types":   []any{"error"},
+				},
+				map[string]any{
+					"label":   "print",
+					"details": "print to stderr",
+					"body":    `{{if .StmtOK}}println({{.X}}){{end}}`,
+				},
+			},
+		},
+	).Run(t, src, func(t *testing.T, env *Env) {
+		env.OpenFile("a.go")
+		labels := func(re string) []string {
+			var labels []string
+			for _, item := range env.Completion(env.RegexpSearch("a.go", re)).Items {
+				if strings.HasSuffix(item.Label, "!") {
+					labels = append(labels, item.Label)
+				}
+			}
+			return labels
+		}
+
+		// The user-defined "wrap" applies only to errors,
+		// and "print" replaces the built-in snippet.
+		errLabels := labels(`err\.()`)
+		if !slices.Contains(errLabels, "wrap!") {
+			t.Errorf("postfix completions of error = %q, want wrap!", errLabels)
+		}
+		intLabels := labels(`n\.()`)
+		if slices.Contains(intLabels, "wrap!") {
+			t.Errorf("postfix completions of int = %q, want no wrap!", intLabels)
+		}
+
+		loc := env.RegexpSearch("a.go", `n\.()`)
+		for _, item := range env.Completion(loc).Items {
+			if item.Label == "print!" {
+				env.AcceptCompletion(loc, item)
+			}
+		}
+		if got := env.BufferText("a.go"); !strings.Contains(got, "\tprintln(n)\n") {
+			t.Errorf("after print! completion:\n%s\nwant println(n)", got)
+		}
+	})
+}
+
+func TestInvalidPostfixSnippets(t *testing.T) {
+	for _, body := range []string{
+		"{{.X",         // syntax error
+		"{{bogus .X}}", // undefined function
+	} {
+		t.Run(body, func(t *testing.T) {
+			WithOptions(
+				Settings{
+					"postfixSnippets": []any{
+						map[string]any{
+							"label": "bad",
+							"body":  body,
+						},
+					},
+				},
+			).Run(t, "", func(t *testing.T, env *Env) {
+				env.OnceMet(
+					InitialWorkspaceLoad,
+					ShownMessage(`postfix snippet "bad"`),
+				)
+			})
+		})
+	}
+}
@@ -0,0 +1,233 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package misc
+
+// This file defines tests of the ChangeSignature command, for changes
+// that have no corresponding code action.
+
+import (
+	"strings"
+	"testing"
+
+	"golang.org/x/tools/gopls/internal/protocol"
+	"golang.org/x/tools/gopls/internal/protocol/command"
+	. "golang.org/x/tools/gopls/internal/test/integration"
+)
+
+const changeSignatureSrc = `
+-- go.mod --
+module example.com
+
+go 1.21
+
+-- a/a.go --
+package a
+
+type T struct{ n int }
+
+func Add(x, y int) int {
+	return x + y
+}
+
+func Div(x, y int) (int, error) {
+	if y == 0 {
+		return 0, nil
+	}
+	return x / y, nil
+}
+
+func Split(s string) (head, tail string) {
+	head, tail = s[:1], s[1:]
+	return
+}
+
+func Check(x int) bool {
+	return x > 0
+}
+
+func Scale(k int, t *T) {
+	t.n *= k
+}
+
+func (t T) Get(delta int) int {
+	return t.n + delta
+}
+
+-- b/b.go --
+package b
+
+import "example.com/a"
+
+func _() {
+	var t a.T
+	_ = a.Add(1, 2)
+	s := a.Add(3, 4)
+	q, _ := a.Div(s, 2)
+	h, _ := a.Split("ab")
+	a.Scale(2, &t)
+	_ = a.Check(q)
+	_ = t.Get(q)
+	_ = h
+}
+`
+
+// changeSignature executes the ChangeSignature command for the function
+// declaration at the first match of re in the named file.
+func changeSignature(env *Env, name, re string, args command.ChangeSignatureArgs) error {
+	args.Location = env.RegexpSearch(name, re)
+	cmd := command.NewChangeSignatureCommand("", args)
+	return env.Editor.ExecuteCommand(env.Ctx, &protocol.ExecuteCommandParams{
+		Command:   cmd.Command,
+		Arguments: cmd.Arguments,
+	}, nil)
+}
+
+// params returns a list of fields of a new signature. Each element is an
+// old index (int) or a new field (string).
+func params(elems ...any) []command.ChangeSignatureParam {
+	res := []command.ChangeSignatureParam{} // (nil results mean "unchanged")
+	for _, elem := range elems {
+		switch elem := elem.(type) {
+		case int:
+			res = append(res, command.ChangeSignatureParam{OldIndex: elem})
+		case string:
+			res = append(res, command.ChangeSignatureParam{NewField: elem})
+		}
+	}
+	return res
+}
+
+func TestChangeSignature(t *testing.T) {
+	tests := []struct {
+		name   string
+		re     string // location of declaration in a/a.go
+		args   command.ChangeSignatureArgs
+		wantA  []string // substrings of the new a/a.go
+		wantB  []string // substrings of the new b/b.go
+		errStr string   // substring of expected error
+	}{
+		{
+			name:  "add parameter",
+			re:    "func (Add)",
+			args:  command.ChangeSignatureArgs{NewParams: params(0, 1, "z int = 10")},
+			wantA: []string{"func Add(x, y, z int) int {"},
+			wantB: []string{"_ = a.Add(1, 2, 10)", "s := a.Add(3, 4, 10)"},
+		},
+		{
+			name:  "add parameter with zero value",
+			re:    "func (Add)",
+			args:  command.ChangeSignatureArgs{NewParams: params("name string", 0, 1)},
+			wantA: []string{"func Add(name string, x, y int) int {"},
+			wantB: []string{`_ = a.Add("", 1, 2)`},
+		},
+		{
+			name: "add result",
+			re:   "func (Add)",
+			args: command.ChangeSignatureArgs{NewParams: params(0, 1), NewResults: params(0, "bool = true")},
+			wantA: []string{
+				"func Add(x, y int) (int, bool) {",
+				"return x + y, true",
+			},
+			wantB: []string{"_, _ = a.Add(1, 2)", "s, _ := a.Add(3, 4)"},
+		},
+		{
+			name: "remove result",
+			re:   "func (Div)",
+			args: command.ChangeSignatureArgs{NewParams: params(0, 1), NewResults: params(0)},
+			wantA: []string{
+				"func Div(x, y int) int {",
+				"return 0\n",
+				"return x / y\n",
+			},
+			wantB: []string{"q := a.Div(s, 2)"},
+		},
+		{
+			name: "add named result with bare returns",
+			re:   "func (Split)",
+			args: command.ChangeSignatureArgs{NewParams: params(0), NewResults: params(0, 1, "n int = 1")},
+			wantA: []string{
+				"func Split(s string) (head, tail string, n int) {\n\tn = 1\n",
+				"\treturn\n",
+			},
+			wantB: []string{`h, _, _ := a.Split("ab")`},
+		},
+		{
+			name: "remove used named result",
+			re:   "func (Split)",
+			args: command.ChangeSignatureArgs{NewParams: params(0), NewResults: params(0)},
+			wantA: []string{
+				"func Split(s string) (head string) {\n\thead, _ = s[:1], s[1:]\n",
+			},
+			wantB: []string{`h := a.Split("ab")`},
+		},
+		{
+			name:  "remove all results",
+			re:    "func (Check)",
+			args:  command.ChangeSignatureArgs{NewParams: params(0), NewResults: params()},
+			wantA: []string{"func Check(x int) {\n}"},
+			wantB: []string{"\ta.Check(q)\n"},
+		},
+		{
+			name:  "function to method",
+			re:    "func (Scale)",
+			args:  command.ChangeSignatureArgs{NewParams: params(0), NewRecv: new(1)},
+			wantA: []string{"func (t *T) Scale(k int) {"},
+			wantB: []string{"t.Scale(2)"},
+		},
+		{
+			name:  "method to function",
+			re:    "func \\(t T\\) (Get)",
+			args:  command.ChangeSignatureArgs{NewParams: params(-1, 0)},
+			wantA: []string{"func Get(t T, delta int) int {"},
+			wantB: []string{"_ = a.Get(t, q)"},
+		},
+		{
+			name:   "remove assigned result",
+			re:     "func (Add)",
+			args:   command.ChangeSignatureArgs{NewParams: params(0, 1), NewResults: params()},
+			errStr: "cannot remove result assigned to s",
+		},
+		{
+			name:   "function to method of unnamed type",
+			re:     "func (Add)",
+			args:   command.ChangeSignatureArgs{NewParams: params(1), NewRecv: new(0)},
+			errStr: "not a named type",
+		},
+		{
+			name:   "shadowing value",
+			re:     "func (Add)",
+			args:   command.ChangeSignatureArgs{NewParams: params(0, 1, "z int = x")},
+			errStr: "undefined: x",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			Run(t, changeSignatureSrc, func(t *testing.T, env *Env) {
+				env.OpenFile("a/a.go")
+				env.OpenFile("b/b.go")
+				err := changeSignature(env, "a/a.go", test.re, test.args)
+				if test.errStr != "" {
+					if err == nil || !strings.Contains(err.Error(), test.errStr) {
+						t.Fatalf("ChangeSignature: got error %v, want %q", err, test.errStr)
+					}
+					return
+				}
+				if err != nil {
+					t.Fatal(err)
+				}
+				for file, wants := range map[string][]string{"a/a.go": test.wantA, "b/b.go": test.wantB} {
+					got := env.BufferText(file)
+					for _, want := range wants {
+						if !strings.Contains(got, want) {
+							t.Errorf("%s does not contain %q:\n%s", file, want, got)
+						}
+					}
+				}
+				env.AfterChange(NoDiagnostics())
+			})
+		})
+	}
+}
@@ -0,0 +1,181 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package misc
+
+// This file defines tests of the display of coverage profiles.
+
+import (
+	"slices"
+	"testing"
+
+	"golang.org/x/tools/gopls/internal/protocol"
+	"golang.org/x/tools/gopls/internal/protocol/command"
+	"golang.org/x/tools/gopls/internal/server"
+	"golang.org/x/tools/gopls/internal/settings"
+	. "golang.org/x/tools/gopls/internal/test/integration"
+)
+
+const coverageSrc = `
+-- go.mod --
+module example.com
+
+go 1.19
+
+-- a/a.go --
+package a
+
+func Abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
+
+func Neg(x int) int {
+	return -x
+}
+
+-- a/a_test.go --
+package a
+
+import "testing"
+
+func TestAbs(t *testing.T) {
+	if Abs(1) != 1 {
+		t.Fatal("Abs")
+	}
+}
+`
+
+// coverageLenses returns the titles of the coverage code lenses of
+// the named file.
+func coverageLenses(env *Env, name string) []string {
+	var titles []string
+	for _, lens := range env.CodeLens(name) {
+		if lens.Command.Command == command.LoadCoverage.String() {
+			titles = append(titles, lens.Command.Title)
+		}
+	}
+	return titles
+}
+
+func TestCoverageProfile(t *testing.T) {
+	const profile = `mode: set
+example.com/a/a.go:4.2,4.11 1 1
+example.com/a/a.go:5.3,6.1 1 0
+example.com/a/a.go:7.2,7.10 1 1
+example.com/a/a.go:11.2,12.1 1 0
+`
+	WithOptions(
+		Settings{"coverageProfile": "cover.out"},
+	).Run(t, coverageSrc, func(t *testing.T, env *Env) {
+		env.WriteWorkspaceFile("cover.out", profile)
+		env.OpenFile("a/a.go")
+		env.AfterChange(
+			Diagnostics(
+				ForFile("a/a.go"),
+				AtPosition("a/a.go", 4, 2), // return -x (LSP coordinates)
+				WithMessage("not covered by tests"),
+				WithSeverityTags("coverage", protocol.SeverityHint, []protocol.DiagnosticTag{protocol.Unnecessary}),
+			),
+			Diagnostics(ForFile("a/a.go"), AtPosition("a/a.go", 10, 1)),
+			Diagnostics(
+				ForFile("a/a.go"),
+				AtPosition("a/a.go", 3, 1), // if x < 0
+				WithMessage("covered by tests"),
+				WithSeverityTags("coverage", protocol.SeverityHint, nil),
+			),
+		)
+		want := []string{
+			"coverage: 66.7% (2/3 statements)",
+			"coverage: 0.0% (0/1 statements)",
+		}
+		if got := coverageLenses(env, "a/a.go"); !slices.Equal(got, want) {
+			t.Errorf("coverage lenses = %q, want %q", got, want)
+		}
+
+		// A change to the profile on disk updates the display.
+		env.WriteWorkspaceFile("cover.out", `mode: set
+example.com/a/a.go:4.2,4.11 1 1
+example.com/a/a.go:5.3,6.1 1 1
+example.com/a/a.go:7.2,7.10 1 1
+example.com/a/a.go:11.2,12.1 1 1
+`)
+		env.AfterChange(
+			NoDiagnostics(ForFile("a/a.go"), WithMessage("not covered")),
+			Diagnostics(ForFile("a/a.go"), AtPosition("a/a.go", 10, 1), WithMessage("covered by tests")),
+		)
+		want = []string{
+			"coverage: 100.0% (3/3 statements)",
+			"coverage: 100.0% (1/1 statements)",
+		}
+		if got := coverageLenses(env, "a/a.go"); !slices.Equal(got, want) {
+			t.Errorf("coverage lenses = %q, want %q", got, want)
+		}
+
+		// Loading an empty profile hides coverage.
+		env.WriteWorkspaceFile("cover.out", profile)
+		env.AfterChange(Diagnostics(ForFile("a/a.go"), WithMessage("not covered")))
+		cmd := command.NewLoadCoverageCommand("", command.LoadCoverageArgs{URI: env.Editor.DocumentURI("a/a.go")})
+		env.ExecuteCommand(&protocol.ExecuteCommandParams{
+			Command:   cmd.Command,
+			Arguments: cmd.Arguments,
+		}, nil)
+		env.OnceMet(
+			CompletedWork(server.DiagnosticWorkTitle(server.FromLoadCoverage), 1, true),
+			NoDiagnostics(ForFile("a/a.go")),
+		)
+		if got := coverageLenses(env, "a/a.go"); len(got) > 0 {
+			t.Errorf("coverage lenses after hiding coverage = %q, want none", got)
+		}
+	})
+}
+
+// TestRunTestsWithCoverage checks that the source.test code action
+// displays the coverage of the tests it runs, without overwriting the
+// configured coverage profile.
+func TestRunTestsWithCoverage(t *testing.T) {
+	const profile = `mode: set
+example.com/a/a.go:4.2,4.11 1 1
+example.com/a/a.go:5.3,6.1 1 1
+example.com/a/a.go:7.2,7.10 1 1
+example.com/a/a.go:11.2,12.1 1 1
+`
+	WithOptions(
+		Settings{"coverageProfile": "cover.out"},
+	).Run(t, coverageSrc, func(t *testing.T, env *Env) {
+		env.WriteWorkspaceFile("cover.out", profile)
+		env.OpenFile("a/a.go")
+		env.OpenFile("a/a_test.go")
+		env.AfterChange(NoDiagnostics(ForFile("a/a.go"), WithMessage("not covered")))
+
+		loc := env.RegexpSearch("a/a_test.go", "Abs")
+		actions, err := env.Editor.Server.CodeAction(env.Ctx, &protocol.CodeActionParams{
+			TextDocument: protocol.TextDocumentIdentifier{URI: loc.URI},
+			Range:        loc.Range,
+			Context: protocol.CodeActionContext{
+				Only: []protocol.CodeActionKind{settings.GoTest},
+			},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(actions) != 1 || actions[0].Command == nil {
+			t.Fatalf("CodeAction returned %#v, want one source.test action", actions)
+		}
+		env.ExecuteCommand(&protocol.ExecuteCommandParams{
+			Command:   actions[0].Command.Command,
+			Arguments: actions[0].Command.Arguments,
+		}, nil)
+		// The test run may also produce a file-watching notification
+		// for the profile, so just wait for the coverage to appear.
+		env.Await(
+			Diagnostics(ForFile("a/a.go"), AtPosition("a/a.go", 4, 2), WithMessage("not covered by tests")),
+		)
+		if got := env.ReadWorkspaceFile("cover.out"); got != profile {
+			t.Errorf("running tests changed the configured profile to:\n%s", got)
+		}
+	})
+}
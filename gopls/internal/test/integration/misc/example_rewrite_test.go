@@ -0,0 +1,110 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package misc
+
+import (
+	"strings"
+	"testing"
+
+	"golang.org/x/tools/gopls/internal/protocol"
+	"golang.org/x/tools/gopls/internal/protocol/command"
+	. "golang.org/x/tools/gopls/internal/test/integration"
+)
+
+func TestExampleRewrite(t *testing.T) {
+	const src = `
+-- go.mod --
+module example.com
+
+go 1.21
+
+-- template/template.go --
+package template
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+)
+
+func before(s string) error { return fmt.Errorf("%s", s) }
+func after(s string) error  { return errors.New(s) }
+
+func beforeLock(mu *sync.Mutex, body func()) {
+	mu.Lock()
+	body()
+	mu.Unlock()
+}
+
+func afterLock(mu *sync.Mutex, body func()) {
+	mu.Lock()
+	defer mu.Unlock()
+	body()
+}
+
+-- a/a.go --
+package a
+
+import (
+	"fmt"
+	"sync"
+)
+
+var mu sync.Mutex
+
+func F(n int) error {
+	mu.Lock()
+	n++
+	mu.Unlock()
+	return fmt.Errorf("%s", "n")
+}
+`
+	Run(t, src, func(t *testing.T, env *Env) {
+		env.OpenFile("template/template.go")
+		env.OpenFile("a/a.go")
+		orig := env.BufferText("a/a.go")
+
+		// Apply all rules of the template.
+		loc := env.RegexpSearch("template/template.go", `(?s)package.*`)
+		exampleRewrite := func(args command.ExampleRewriteArgs) command.ExampleRewriteResult {
+			t.Helper()
+			cmd := command.NewExampleRewriteCommand("", args)
+			var result command.ExampleRewriteResult
+			env.ExecuteCommand(&protocol.ExecuteCommandParams{
+				Command:   cmd.Command,
+				Arguments: cmd.Arguments,
+			}, &result)
+			return result
+		}
+
+		// With ResolveEdits, the edits are returned, not applied.
+		result := exampleRewrite(command.ExampleRewriteArgs{Template: loc, ResolveEdits: true})
+		if got := len(result.Matches); got != 3 {
+			t.Errorf("got %d matches, want 3 (import, lock, errorf): %v", got, result.Matches)
+		}
+		if result.Edit == nil || len(result.Edit.DocumentChanges) != 1 {
+			t.Errorf("got edit %v, want changes to a/a.go only", result.Edit)
+		}
+		if got := env.BufferText("a/a.go"); got != orig {
+			t.Errorf("a/a.go was modified:\n%s", got)
+		}
+
+		result = exampleRewrite(command.ExampleRewriteArgs{Template: loc})
+		got := env.BufferText("a/a.go")
+		for _, want := range []string{
+			"\t\"errors\"\n",
+			"\tmu.Lock()\n\tdefer mu.Unlock()\n\tn++\n",
+			"return errors.New(\"n\")",
+		} {
+			if !strings.Contains(got, want) {
+				t.Errorf("a/a.go does not contain %q:\n%s", want, got)
+			}
+		}
+		if result.Edit != nil {
+			t.Errorf("got edit %v, want none", result.Edit)
+		}
+		env.AfterChange(NoDiagnostics(ForFile("a/a.go")))
+	})
+}
@@ -0,0 +1,182 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package misc
+
+// This file defines tests of the display of pprof profile hot spots.
+
+import (
+	"encoding/binary"
+	"slices"
+	"testing"
+
+	"golang.org/x/tools/gopls/internal/protocol"
+	"golang.org/x/tools/gopls/internal/protocol/command"
+	"golang.org/x/tools/gopls/internal/server"
+	. "golang.org/x/tools/gopls/internal/test/integration"
+)
+
+const hotSpotsSrc = `
+-- go.mod --
+module example.com
+
+go 1.19
+
+-- a/a.go --
+package a
+
+func Outer() int {
+	x := Inner()
+	return x + 1
+}
+
+func Inner() int {
+	return 42
+}
+`
+
+// A hotSpotFrame is a frame of a sample of a synthetic profile.
+type hotSpotFrame struct {
+	function, file  string
+	line, startLine int
+}
+
+// encodePprof returns a CPU profile in the (uncompressed) pprof format
+// that has one sample of the given value for each stack.
+func encodePprof(stacks [][]hotSpotFrame, values []int64) []byte {
+	var (
+		strtab  = []string{""}
+		strings = map[string]int{"": 0}
+	)
+	str := func(s string) uint64 {
+		i, ok := strings[s]
+		if !ok {
+			i = len(strtab)
+			strings[s] = i
+			strtab = append(strtab, s)
+		}
+		return uint64(i)
+	}
+	field := func(buf []byte, fld int, v uint64) []byte {
+		buf = binary.AppendUvarint(buf, uint64(fld)<<3|0) // varint
+		return binary.AppendUvarint(buf, v)
+	}
+	message := func(buf []byte, fld int, msg []byte) []byte {
+		buf = binary.AppendUvarint(buf, uint64(fld)<<3|2) // bytes
+		buf = binary.AppendUvarint(buf, uint64(len(msg)))
+		return append(buf, msg...)
+	}
+
+	var (
+		buf []byte
+		id  uint64
+	)
+	buf = message(buf, 1, field(field(nil, 1, str("cpu")), 2, str("nanoseconds"))) // sample_type
+	for i, stack := range stacks {
+		var sample []byte
+		for _, f := range stack {
+			id++ // one function and location per frame
+			var fn []byte
+			fn = field(fn, 1, id)
+			fn = field(fn, 2, str(f.function))
+			fn = field(fn, 4, str(f.file))
+			fn = field(fn, 5, uint64(f.startLine))
+			buf = message(buf, 5, fn) // function
+
+			line := field(field(nil, 1, id), 2, uint64(f.line))
+			buf = message(buf, 4, message(field(nil, 1, id), 4, line)) // location
+
+			sample = field(sample, 1, id) // location_id
+		}
+		sample = field(sample, 2, uint64(values[i])) // value
+		buf = message(buf, 2, sample)
+	}
+	for _, s := range strtab {
+		buf = message(buf, 6, []byte(s)) // string_table
+	}
+	return buf
+}
+
+// hotSpotLenses returns the titles of the hot spot code lenses of
+// the named file.
+func hotSpotLenses(env *Env, name string) []string {
+	var titles []string
+	for _, lens := range env.CodeLens(name) {
+		if lens.Command.Command == command.ToggleProfile.String() {
+			titles = append(titles, lens.Command.Title)
+		}
+	}
+	return titles
+}
+
+func TestHotSpots(t *testing.T) {
+	// The file names of the profile need not match the workspace,
+	// as when a profile is recorded on another machine.
+	var (
+		outer4 = hotSpotFrame{"example.com/a.Outer", "/build/a/a.go", 4, 3}
+		outer5 = hotSpotFrame{"example.com/a.Outer", "/build/a/a.go", 5, 3}
+		inner  = hotSpotFrame{"example.com/a.Inner", "/build/a/a.go", 9, 8}
+		other  = hotSpotFrame{"runtime.main", "/goroot/src/runtime/proc.go", 1, 1}
+	)
+	profile := encodePprof([][]hotSpotFrame{
+		{inner, outer4},
+		{outer5},
+		{other},
+	}, []int64{80, 20, 100})
+
+	WithOptions(
+		Settings{
+			"pprofProfiles": []string{"a.pprof", "b.pprof"},
+			"hints":         map[string]any{"hotSpots": true},
+		},
+	).Run(t, hotSpotsSrc, func(t *testing.T, env *Env) {
+		env.WriteWorkspaceFile("a.pprof", string(profile))
+		env.WriteWorkspaceFile("b.pprof", string(encodePprof([][]hotSpotFrame{{outer5}}, []int64{1})))
+		env.OpenFile("a/a.go")
+		env.AfterChange()
+
+		want := []string{
+			"cpu: flat 10.0%, cum 50.0% (a.pprof)",
+			"cpu: flat 40.0%, cum 40.0% (a.pprof)",
+		}
+		if got := hotSpotLenses(env, "a/a.go"); !slices.Equal(got, want) {
+			t.Errorf("hot spot lenses = %q, want %q", got, want)
+		}
+
+		var hints []string
+		for _, hint := range env.InlayHints("a/a.go") {
+			hints = append(hints, hint.Label[0].Value)
+		}
+		wantHints := []string{
+			" // cpu: flat 0.0%, cum 40.0%",
+			" // cpu: flat 10.0%, cum 10.0%",
+			" // cpu: flat 40.0%, cum 40.0%",
+		}
+		if !slices.Equal(hints, wantHints) {
+			t.Errorf("hot spot inlay hints = %q, want %q", hints, wantHints)
+		}
+
+		// The command selects the next profile, then none.
+		toggle := func(n int) {
+			cmd := command.NewToggleProfileCommand("", command.ToggleProfileArgs{URI: env.Editor.DocumentURI("a/a.go")})
+			env.ExecuteCommand(&protocol.ExecuteCommandParams{
+				Command:   cmd.Command,
+				Arguments: cmd.Arguments,
+			}, nil)
+			env.OnceMet(CompletedWork(server.DiagnosticWorkTitle(server.FromToggleProfile), uint64(n), true))
+		}
+		toggle(1)
+		want = []string{"cpu: flat 100.0%, cum 100.0% (b.pprof)"}
+		if got := hotSpotLenses(env, "a/a.go"); !slices.Equal(got, want) {
+			t.Errorf("hot spot lenses after toggle = %q, want %q", got, want)
+		}
+		toggle(2)
+		if got := hotSpotLenses(env, "a/a.go"); len(got) > 0 {
+			t.Errorf("hot spot lenses after hiding profile = %q, want none", got)
+		}
+		if got := env.InlayHints("a/a.go"); len(got) > 0 {
+			t.Errorf("inlay hints after hiding profile = %v, want none", got)
+		}
+	})
+}
@@ -6,6 +6,7 @@ package template
 
 import (
 	"os"
+	"slices"
 	"strings"
 	"testing"
 
@@ -285,3 +286,77 @@ Hello {{
 }
 
 // Hover needs tests
+
+func TestTypedTemplates(t *testing.T) {
+	const files = `
+-- go.mod --
+module mod.com
+
+go 1.22
+-- main.go --
+package main
+
+import (
+	"os"
+	"slices"
+	"strings"
+	"text/template"
+)
+
+type Page struct {
+	Title   string
+	Planets []Planet
+}
+
+type Planet struct {
+	Name  string
+	Moons int
+}
+
+func (p Planet) Mass() float64 { return 0 }
+
+var funcs = template.FuncMap{"upper": strings.ToUpper}
+
+func main() {
+	t := template.Must(template.New("page.tmpl").Funcs(funcs).ParseFiles("page.tmpl"))
+	t.Execute(os.Stdout, Page{})
+}
+-- page.tmpl --
+{{upper .Title}}
+{{range .Planets}}{{.Name}} {{.Mass}} {{.Radius}}{{end}}
+{{lower .Title}}
+`
+	WithOptions(
+		Settings{"templateExtensions": []string{"tmpl"}},
+	).Run(t, files, func(t *testing.T, env *Env) {
+		env.OpenFile("page.tmpl")
+		env.AfterChange(
+			Diagnostics(env.AtRegexp("page.tmpl", "Radius"), WithMessage("can't evaluate field Radius in type Planet")),
+			Diagnostics(env.AtRegexp("page.tmpl", "lower"), WithMessage(`function "lower" not defined`)),
+		)
+
+		content, _ := env.Hover(env.RegexpSearch("page.tmpl", "Na()me"))
+		if want := "field Name string"; !strings.Contains(content.Value, want) {
+			t.Errorf("hover: got %q, want %q", content.Value, want)
+		}
+
+		loc := env.FirstDefinition(env.RegexpSearch("page.tmpl", "Ma()ss"))
+		if got := env.Sandbox.Workdir.URIToPath(loc.URI); got != "main.go" {
+			t.Errorf("definition of Mass: got %s, want main.go", got)
+		}
+		loc = env.FirstDefinition(env.RegexpSearch("page.tmpl", "up()per"))
+		if got := env.Sandbox.Workdir.URIToPath(loc.URI); got == "page.tmpl" {
+			t.Errorf("definition of upper: got %s, want strings.ToUpper", got)
+		}
+
+		env.RegexpReplace("page.tmpl", "{{lower .Title}}", "{{.Pl")
+		completions := env.Completion(env.RegexpSearch("page.tmpl", `\{\{\.Pl()`))
+		var labels []string
+		for _, item := range completions.Items {
+			labels = append(labels, item.Label)
+		}
+		if !slices.Equal(labels, []string{"Planets"}) {
+			t.Errorf("completion of .Pl: got %v, want [Planets]", labels)
+		}
+	})
+}
@@ -326,13 +326,28 @@ func IExportData(out io.Writer, fset *token.FileSet, pkg *types.Package) error {
 	return iexportCommon(out, fset, bundle, shallow, iexportVersion, []*types.Package{pkg}, nil)
 }
 
+// IExportAllData is like [IExportData], but it also writes the
+// unexported package-level declarations of pkg, so that the imported
+// package is as complete as the original.
+func IExportAllData(out io.Writer, fset *token.FileSet, pkg *types.Package) error {
+	const bundle, shallow, unexported = false, false, true
+	return iexportPackages(out, fset, bundle, shallow, unexported, iexportVersion, []*types.Package{pkg}, nil)
+}
+
 // IExportBundle writes an indexed export bundle for pkgs to out.
 func IExportBundle(out io.Writer, fset *token.FileSet, pkgs []*types.Package) error {
 	const bundle, shallow = true, false
 	return iexportCommon(out, fset, bundle, shallow, iexportVersion, pkgs, nil)
 }
 
-func iexportCommon(out io.Writer, fset *token.FileSet, bundle, shallow bool, version int, pkgs []*types.Package, reportf ReportFunc) (err error) {
+func iexportCommon(out io.Writer, fset *token.FileSet, bundle, shallow bool, version int, pkgs []*types.Package, reportf ReportFunc) error {
+	const unexported = false
+	return iexportPackages(out, fset, bundle, shallow, unexported, version, pkgs, reportf)
+}
+
+// iexportPackages is like iexportCommon, but if unexported is set, it
+// also exports the unexported package-level declarations of pkgs.
+func iexportPackages(out io.Writer, fset *token.FileSet, bundle, shallow, unexported bool, version int, pkgs []*types.Package, reportf ReportFunc) (err error) {
 	if !debug {
 		defer func() {
 			if e := recover(); e != nil {
@@ -374,11 +389,11 @@ func iexportCommon(out io.Writer, fset *token.FileSet, bundle, shallow bool, ver
 		panic(internalErrorf("too many predeclared types: %d > %d", len(p.typIndex), predeclReserved))
 	}
 
-	// Initialize work queue with exported declarations.
+	// Initialize work queue with exported (or all) declarations.
 	for _, pkg := range pkgs {
 		scope := pkg.Scope()
 		for _, name := range scope.Names() {
-			if token.IsExported(name) {
+			if unexported || token.IsExported(name) {
 				p.pushDecl(scope.Lookup(name))
 			}
 		}
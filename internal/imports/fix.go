@@ -6,6 +6,7 @@ package imports
 
 import (
 	"bytes"
+	"cmp"
 	"context"
 	"encoding/json"
 	"fmt"
@@ -21,6 +22,7 @@ import (
 	"path"
 	"path/filepath"
 	"reflect"
+	"slices"
 	"sort"
 	"strconv"
 	"strings"
@@ -960,6 +962,12 @@ type ProcessEnv struct {
 	// multiple ProcessEnvs.
 	ModCache *DirInfoCache
 
+	// PinnedImports lists import paths that are preferred over all
+	// other candidates with the same package name when adding a
+	// missing import. Otherwise, candidates that are imported more
+	// often by files of the main module are preferred.
+	PinnedImports []string
+
 	initialized bool // see TODO above
 
 	// resolver and resolverErr are lazily evaluated (see GetResolver).
@@ -1238,6 +1246,20 @@ func addExternalCandidates(ctx context.Context, pass *pass, refs References, fil
 		}
 	}
 
+	// Prefer candidates that are pinned or already used by the module,
+	// including those from the index, to other candidates of the same name.
+	// Computing the preferences may walk the whole main module, so do it
+	// only when there is a choice to make.
+	if src, ok := pass.source.(*ProcessEnvSource); ok && ix != nil {
+		results = slices.DeleteFunc(results, func(r *Result) bool { return r == nil })
+		if resolver, err := src.env.GetResolver(); err == nil && hasSharedName(results) {
+			prefs := sync.OnceValue(func() *Preferences { return src.preferences(resolver) })
+			slices.SortStableFunc(results, func(x, y *Result) int {
+				return cmp.Compare(prefs().Score(y.Import.ImportPath), prefs().Score(x.Import.ImportPath))
+			})
+		}
+	}
+
 	for _, result := range results {
 		if result == nil {
 			continue
@@ -1258,6 +1280,19 @@ func addExternalCandidates(ctx context.Context, pass *pass, refs References, fil
 	return nil
 }
 
+// hasSharedName reports whether two or more results have the same
+// package name.
+func hasSharedName(results []*Result) bool {
+	seen := make(map[string]bool)
+	for _, r := range results {
+		if seen[r.Package.Name] {
+			return true
+		}
+		seen[r.Package.Name] = true
+	}
+	return false
+}
+
 // notIdentifier reports whether ch is an invalid identifier character.
 func notIdentifier(ch rune) bool {
 	return !('a' <= ch && ch <= 'z' || 'A' <= ch && ch <= 'Z' ||
@@ -1655,6 +1690,7 @@ type symbolSearcher struct {
 	srcDir      string // directory containing the file
 	xtest       bool   // if set, the file containing is an x_test file
 	loadExports func(ctx context.Context, pkg *pkg, includeTest bool) (string, []stdlib.Symbol, error)
+	preferences func() *Preferences // if non-nil, ranks candidates before other heuristics
 }
 
 // search searches the provided candidates for a package containing all
@@ -1667,6 +1703,13 @@ func (s *symbolSearcher) search(ctx context.Context, candidates []pkgDistance, p
 	// ones.  Note that this sorts by the de-vendored name, so
 	// there's no "penalty" for vendoring.
 	sort.Sort(byDistanceOrImportPathShortLength(candidates))
+	if len(candidates) > 1 && s.preferences != nil {
+		// Prefer candidates that are pinned or already used by the module.
+		prefs := s.preferences()
+		sort.SliceStable(candidates, func(i, j int) bool {
+			return prefs.Score(candidates[i].pkg.importPathShort) > prefs.Score(candidates[j].pkg.importPathShort)
+		})
+	}
 	if s.logf != nil {
 		for i, c := range candidates {
 			s.logf("%s candidate %d/%d: %v in %v", pkgName, i+1, len(candidates), c.pkg.importPathShort, c.pkg.dir)
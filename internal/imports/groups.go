@@ -0,0 +1,231 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package imports
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// A GroupMatcher describes one group of an import grouping policy.
+// See [ParseGroups] for the syntax.
+type GroupMatcher struct {
+	spec     string
+	std      bool           // standard library
+	module   bool           // current module
+	fallback bool           // imports in no other group
+	prefixes []string       // import path prefixes
+	re       *regexp.Regexp // import path pattern
+}
+
+// String returns the specification from which m was parsed.
+func (m GroupMatcher) String() string { return m.spec }
+
+// ParseGroups parses an ordered list of import group specifications.
+// Each specification is one of:
+//
+//	std            standard library packages
+//	module         packages of the current module (see [Options.ModulePath])
+//	default        packages that belong to no other group
+//	prefix:P,...   packages whose path begins with one of the prefixes P
+//	regexp:RE      packages whose path matches the regular expression RE
+//
+// When an import matches several groups, the last of them wins, so
+// that more specific groups may follow general ones: for example,
+// "std default prefix:example.com/ regexp:\.pb$" places generated
+// protocol buffer packages last even within example.com. Imports
+// that match no group, when there is no default group, form a final
+// group of their own.
+func ParseGroups(specs []string) ([]GroupMatcher, error) {
+	groups := make([]GroupMatcher, 0, len(specs))
+	hasDefault := false
+	for _, spec := range specs {
+		m := GroupMatcher{spec: spec}
+		switch kind, arg, _ := strings.Cut(spec, ":"); kind {
+		case "std":
+			m.std = true
+		case "module":
+			m.module = true
+		case "default":
+			if hasDefault {
+				return nil, fmt.Errorf("duplicate default import group")
+			}
+			hasDefault = true
+			m.fallback = true
+		case "prefix":
+			for p := range strings.SplitSeq(arg, ",") {
+				if p != "" {
+					m.prefixes = append(m.prefixes, p)
+				}
+			}
+			if m.prefixes == nil {
+				return nil, fmt.Errorf("import group %q: no prefixes", spec)
+			}
+		case "regexp":
+			re, err := regexp.Compile(arg)
+			if err != nil {
+				return nil, fmt.Errorf("import group %q: %v", spec, err)
+			}
+			m.re = re
+		default:
+			return nil, fmt.Errorf("invalid import group %q (want std, module, default, prefix:P, or regexp:RE)", spec)
+		}
+		groups = append(groups, m)
+	}
+	return groups, nil
+}
+
+// match reports whether importPath belongs to group m.
+// A default group matches nothing.
+func (m *GroupMatcher) match(modulePath, importPath string) bool {
+	switch {
+	case m.std:
+		return !strings.Contains(strings.Split(importPath, "/")[0], ".")
+	case m.module:
+		return modulePath != "" &&
+			(importPath == modulePath || strings.HasPrefix(importPath, modulePath+"/"))
+	case m.prefixes != nil:
+		for _, p := range m.prefixes {
+			if strings.HasPrefix(importPath, p) || strings.TrimSuffix(p, "/") == importPath {
+				return true
+			}
+		}
+	case m.re != nil:
+		return m.re.MatchString(importPath)
+	}
+	return false
+}
+
+// groupIndex returns the index in groups of the group of importPath.
+func groupIndex(groups []GroupMatcher, modulePath, importPath string) int {
+	fallback := len(groups)
+	for i := len(groups) - 1; i >= 0; i-- {
+		if groups[i].match(modulePath, importPath) {
+			return i
+		}
+		if groups[i].fallback {
+			fallback = i
+		}
+	}
+	return fallback
+}
+
+// importGroup returns the group number of importPath under the
+// grouping policy of opt.
+func (opt *Options) importGroup(importPath string) int {
+	if opt.Groups != nil {
+		return groupIndex(opt.Groups, opt.ModulePath, importPath)
+	}
+	return importGroup(opt.LocalPrefix, importPath)
+}
+
+// groupName returns a description of group number n for use in messages.
+func (opt *Options) groupName(n int) string {
+	if opt.Groups != nil {
+		if n < len(opt.Groups) {
+			return strconv.Quote(opt.Groups[n].spec)
+		}
+		return "of ungrouped imports"
+	}
+	switch n {
+	case 0:
+		return "of standard packages"
+	case 2:
+		return "of appengine packages"
+	case 3:
+		return "of local packages"
+	}
+	return "of third-party packages"
+}
+
+// A GroupError describes an import that violates the import grouping
+// policy of the [Options].
+type GroupError struct {
+	Pos  token.Position
+	Path string // import path
+	Msg  string
+}
+
+func (e *GroupError) Error() string { return fmt.Sprintf("%v: %s", e.Pos, e.Msg) }
+
+// CheckGroups reports the imports of the file whose grouping does not
+// follow the policy of opt (see [Options.Groups]): imports that appear
+// after a group that should follow theirs, imports separated by a blank
+// line from the preceding import of the same group, and imports of a
+// new group not separated from the preceding import by a blank line.
+// Only parenthesized import declarations are checked.
+//
+// CheckGroups does not report unsorted imports within a group,
+// nor does it add or remove imports.
+func CheckGroups(filename string, src []byte, opt *Options) ([]*GroupError, error) {
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, filename, src, parser.ImportsOnly|parser.ParseComments)
+	if err != nil {
+		return nil, err
+	}
+	tokFile := fset.File(f.FileStart)
+
+	// blankBetween reports whether there is a blank line
+	// strictly between lines x and y.
+	blankBetween := func(x, y int) bool {
+		for line := x + 1; line < y; line++ {
+			start := tokFile.Offset(tokFile.LineStart(line))
+			end := len(src)
+			if line < tokFile.LineCount() {
+				end = tokFile.Offset(tokFile.LineStart(line + 1))
+			}
+			if len(bytes.TrimSpace(src[start:end])) == 0 {
+				return true
+			}
+		}
+		return false
+	}
+
+	var errs []*GroupError
+	for _, decl := range f.Decls {
+		decl, ok := decl.(*ast.GenDecl)
+		if !ok || decl.Tok != token.IMPORT || !decl.Lparen.IsValid() {
+			continue
+		}
+		maxGroup := -1 // the greatest group number so far
+		var prev *ast.ImportSpec
+		for _, spec := range decl.Specs {
+			spec := spec.(*ast.ImportSpec)
+			path := importPath(spec)
+			group := opt.importGroup(path)
+			report := func(format string, args ...any) {
+				errs = append(errs, &GroupError{
+					Pos:  fset.Position(spec.Pos()),
+					Path: path,
+					Msg:  fmt.Sprintf(format, args...),
+				})
+			}
+			if prev != nil {
+				prevGroup := opt.importGroup(importPath(prev))
+				blank := blankBetween(tokFile.Line(prev.End()), tokFile.Line(spec.Pos()))
+				switch {
+				case group < maxGroup:
+					report("import %q, in group %s, should precede group %s",
+						path, opt.groupName(group), opt.groupName(maxGroup))
+				case group == prevGroup && blank:
+					report("blank line within group %s before import %q",
+						opt.groupName(group), path)
+				case group != prevGroup && !blank:
+					report("import %q, in group %s, should be separated from group %s by a blank line",
+						path, opt.groupName(group), opt.groupName(prevGroup))
+				}
+			}
+			maxGroup = max(maxGroup, group)
+			prev = spec
+		}
+	}
+	return errs, nil
+}
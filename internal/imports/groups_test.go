@@ -0,0 +1,190 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package imports
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+const groupsSrc = `package p
+
+import (
+	"example.com/corp/gen/apipb"
+	"fmt"
+
+	"github.com/pkg/errors"
+	"example.com/corp/log"
+	"example.com/me/util"
+
+	// a comment
+	"os"
+	"example.com/me/other"
+)
+`
+
+// groupsOptions returns options for formatting only, with the
+// grouping policy of our style guide.
+func groupsOptions(t *testing.T) *Options {
+	groups, err := ParseGroups(strings.Fields(`std default prefix:example.com/corp/ module regexp:pb$`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &Options{
+		Groups:     groups,
+		ModulePath: "example.com/me",
+		FormatOnly: true,
+		Comments:   true,
+		TabIndent:  true,
+		TabWidth:   8,
+	}
+}
+
+func TestGroups(t *testing.T) {
+	got, err := Process("p.go", []byte(groupsSrc), groupsOptions(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	const want = `package p
+
+import (
+	"fmt"
+	// a comment
+	"os"
+
+	"github.com/pkg/errors"
+
+	"example.com/corp/log"
+
+	"example.com/me/other"
+	"example.com/me/util"
+
+	"example.com/corp/gen/apipb"
+)
+`
+	if string(got) != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+
+	// The result follows the policy.
+	errs, err := CheckGroups("p.go", got, groupsOptions(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, err := range errs {
+		t.Errorf("unexpected violation in formatted file: %v", err)
+	}
+}
+
+// TestGroupsComments checks that regrouping keeps each comment with
+// its import spec.
+func TestGroupsComments(t *testing.T) {
+	const src = `package p
+
+import (
+	"example.com/me/util" // util
+	"fmt"
+
+	// Corporate logging.
+	// See go/log.
+	"example.com/corp/log"
+	"github.com/pkg/errors"
+	/* ctx */ "context"
+)
+`
+	got, err := Process("p.go", []byte(src), groupsOptions(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	const want = `package p
+
+import (
+	/* ctx */ "context"
+	"fmt"
+
+	"github.com/pkg/errors"
+
+	// Corporate logging.
+	// See go/log.
+	"example.com/corp/log"
+
+	"example.com/me/util" // util
+)
+`
+	if string(got) != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestCheckGroups(t *testing.T) {
+	errs, err := CheckGroups("p.go", []byte(groupsSrc), groupsOptions(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, err := range errs {
+		got = append(got, err.Error())
+	}
+	want := []string{
+		`p.go:5:2: import "fmt", in group "std", should precede group "regexp:pb$"`,
+		`p.go:7:2: import "github.com/pkg/errors", in group "default", should precede group "regexp:pb$"`,
+		`p.go:8:2: import "example.com/corp/log", in group "prefix:example.com/corp/", should precede group "regexp:pb$"`,
+		`p.go:9:2: import "example.com/me/util", in group "module", should precede group "regexp:pb$"`,
+		`p.go:12:2: import "os", in group "std", should precede group "regexp:pb$"`,
+		`p.go:13:2: import "example.com/me/other", in group "module", should precede group "regexp:pb$"`,
+	}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("got:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+
+	// Blank lines within and between groups.
+	const src = `package p
+
+import (
+	"fmt"
+
+	"os"
+	"github.com/pkg/errors"
+)
+`
+	errs, err = CheckGroups("p.go", []byte(src), groupsOptions(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	got = nil
+	for _, err := range errs {
+		got = append(got, err.Error())
+	}
+	want = []string{
+		`p.go:6:2: blank line within group "std" before import "os"`,
+		`p.go:7:2: import "github.com/pkg/errors", in group "default", should be separated from group "std" by a blank line`,
+	}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("got:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestParseGroups(t *testing.T) {
+	for _, test := range []struct {
+		specs   string
+		wantErr string
+	}{
+		{"std default module", ""},
+		{"std bogus", `invalid import group "bogus"`},
+		{"default default", "duplicate default import group"},
+		{"prefix:", "no prefixes"},
+		{"regexp:(", "missing closing )"},
+	} {
+		_, err := ParseGroups(strings.Fields(test.specs))
+		if test.wantErr == "" {
+			if err != nil {
+				t.Errorf("ParseGroups(%q) failed: %v", test.specs, err)
+			}
+		} else if err == nil || !strings.Contains(err.Error(), test.wantErr) {
+			t.Errorf("ParseGroups(%q) = %v, want error containing %q", test.specs, err, test.wantErr)
+		}
+	}
+}
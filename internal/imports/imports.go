@@ -34,6 +34,18 @@ type Options struct {
 	// into another group after 3rd-party packages.
 	LocalPrefix string
 
+	// Groups, if non-nil, is the ordered list of import groups, which
+	// replaces the default grouping of standard library, third-party
+	// and LocalPrefix packages (see [ParseGroups]). Each import block
+	// is then sorted as a whole: imports are moved into their groups,
+	// and groups are separated by single blank lines. As within a run
+	// of imports, a comment between imports moves with the preceding one.
+	Groups []GroupMatcher
+
+	// ModulePath is the path of the module containing the file,
+	// for the "module" import group.
+	ModulePath string
+
 	Fragment  bool // Accept fragment of a source file (no package statement)
 	AllErrors bool // Report all errors (not just the first 10 on different lines)
 
@@ -127,9 +139,26 @@ func ApplyFixes(fixes []*ImportFix, filename string, src []byte, opt *Options, e
 // formatted file, and returns the postpocessed result.
 func formatFile(fset *token.FileSet, file *ast.File, src []byte, adjust func(orig []byte, src []byte) []byte, opt *Options) ([]byte, error) {
 	mergeImports(file)
-	sortImports(opt.LocalPrefix, fset.File(file.FileStart), file)
+	sortImports(opt, fset.File(file.FileStart), file)
 	var spacesBefore []string // import paths we need spaces before
-	for _, impSection := range astutil.Imports(fset, file) {
+	sections := astutil.Imports(fset, file)
+	if opt.Groups != nil {
+		// Each import declaration was sorted as a whole, and
+		// the comments of its specs may separate their lines.
+		sections = nil
+		for _, decl := range file.Decls {
+			decl, ok := decl.(*ast.GenDecl)
+			if !ok || decl.Tok != token.IMPORT {
+				break
+			}
+			var section []*ast.ImportSpec
+			for _, spec := range decl.Specs {
+				section = append(section, spec.(*ast.ImportSpec))
+			}
+			sections = append(sections, section)
+		}
+	}
+	for _, impSection := range sections {
 		// Within each block of contiguous imports, see if any
 		// import lines are in different group numbers. If so,
 		// we'll need to put a space between them so it's
@@ -137,7 +166,7 @@ func formatFile(fset *token.FileSet, file *ast.File, src []byte, adjust func(ori
 		lastGroup := -1
 		for _, importSpec := range impSection {
 			importPath, _ := strconv.Unquote(importSpec.Path.Value)
-			groupNum := importGroup(opt.LocalPrefix, importPath)
+			groupNum := opt.importGroup(importPath)
 			if groupNum != lastGroup && lastGroup != -1 {
 				spacesBefore = append(spacesBefore, importPath)
 			}
@@ -325,11 +354,14 @@ func matchSpace(orig []byte, src []byte) []byte {
 
 var impLine = regexp.MustCompile(`^\s+(?:[\w\.]+\s+)?"(.+?)"`)
 
+var commentLine = regexp.MustCompile(`^\s+//`)
+
 func addImportSpaces(r io.Reader, breaks []string) ([]byte, error) {
 	var out bytes.Buffer
 	in := bufio.NewReader(r)
 	inImports := false
 	done := false
+	var comments []string // comment lines preceding the current line
 	for {
 		s, err := in.ReadString('\n')
 		if err == io.EOF {
@@ -349,6 +381,12 @@ func addImportSpaces(r io.Reader, breaks []string) ([]byte, error) {
 			inImports = false
 		}
 		if inImports && len(breaks) > 0 {
+			// Hold back comment lines, so that a break
+			// precedes the comments of the import below.
+			if commentLine.MatchString(s) {
+				comments = append(comments, s)
+				continue
+			}
 			if m := impLine.FindStringSubmatch(s); m != nil {
 				if m[1] == breaks[0] {
 					out.WriteByte('\n')
@@ -357,7 +395,14 @@ func addImportSpaces(r io.Reader, breaks []string) ([]byte, error) {
 			}
 		}
 
+		for _, c := range comments {
+			fmt.Fprint(&out, c)
+		}
+		comments = nil
 		fmt.Fprint(&out, s)
 	}
+	for _, c := range comments {
+		fmt.Fprint(&out, c)
+	}
 	return out.Bytes(), nil
 }
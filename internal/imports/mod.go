@@ -9,6 +9,9 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"go/parser"
+	"go/token"
+	"io/fs"
 	"os"
 	"path"
 	"path/filepath"
@@ -17,6 +20,7 @@ import (
 	"sort"
 	"strconv"
 	"strings"
+	"sync"
 
 	"golang.org/x/mod/module"
 	"golang.org/x/tools/internal/event"
@@ -85,6 +89,11 @@ type ModuleResolver struct {
 	// may change.
 	moduleCacheCache *DirInfoCache
 	otherCache       *DirInfoCache
+
+	// usesMu guards uses, the counts of imports of each main module,
+	// keyed by module directory; see importUses.
+	usesMu sync.Mutex
+	uses   map[string]map[string]int
 }
 
 // newModuleResolver returns a new module-aware goimports resolver.
@@ -816,3 +825,70 @@ func modulePath(mod []byte) string {
 	}
 	return "" // missing module path
 }
+
+// importUses returns, for each import path, the number of files of the
+// main module containing dir that import it, or nil if dir is not in a
+// main module. The counts are computed once per resolver: like scans,
+// they are refreshed by ClearForNewScan.
+func (r *ModuleResolver) importUses(dir string) map[string]int {
+	var main *gocommand.ModuleJSON
+	for _, m := range r.mains {
+		if m.Dir != "" && (main == nil || len(m.Dir) > len(main.Dir)) && pathIsInDir(dir, m.Dir) {
+			main = m
+		}
+	}
+	if main == nil {
+		return nil
+	}
+
+	r.usesMu.Lock()
+	defer r.usesMu.Unlock()
+	if uses, ok := r.uses[main.Dir]; ok {
+		return uses
+	}
+	uses := make(map[string]int)
+	filepath.WalkDir(main.Dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil // skip unreadable directories
+		}
+		name := d.Name()
+		if d.IsDir() {
+			if path == main.Dir {
+				return nil
+			}
+			if name == "vendor" || name == "testdata" ||
+				strings.HasPrefix(name, ".") || strings.HasPrefix(name, "_") ||
+				r.env.SkipPathInScan != nil && r.env.SkipPathInScan(path) {
+				return filepath.SkipDir
+			}
+			if _, err := os.Stat(filepath.Join(path, "go.mod")); err == nil {
+				return filepath.SkipDir // nested module
+			}
+			return nil
+		}
+		if !strings.HasSuffix(name, ".go") {
+			return nil
+		}
+		f, err := parser.ParseFile(token.NewFileSet(), path, nil, parser.ImportsOnly)
+		if err != nil {
+			return nil
+		}
+		for _, imp := range f.Imports {
+			if path, err := strconv.Unquote(imp.Path.Value); err == nil {
+				uses[path]++
+			}
+		}
+		return nil
+	})
+	if r.uses == nil {
+		r.uses = make(map[string]map[string]int)
+	}
+	r.uses[main.Dir] = uses
+	return uses
+}
+
+// pathIsInDir reports whether path is dir or is within it.
+func pathIsInDir(path, dir string) bool {
+	rel, err := filepath.Rel(dir, path)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
@@ -1281,6 +1281,63 @@ import (
 	}
 }
 
+// Tests that goimports prefers the candidate that the module already
+// imports, or that is pinned, over a closer one with a shorter path.
+func TestModPreferUsedImports(t *testing.T) {
+	mt := setup(t, nil, `
+-- go.mod --
+module x
+
+-- a/log/log.go --
+package log
+func Info() {}
+
+-- internal/third_party/log/log.go --
+package log
+func Info() {}
+
+-- b/b.go --
+package b
+import "x/internal/third_party/log"
+var _ = log.Info
+
+-- c/c.go --
+package c
+import "x/internal/third_party/log"
+var _ = log.Info
+
+-- d/d.go --
+package d
+import "x/a/log"
+var _ = log.Info
+`, "")
+	defer mt.cleanup()
+
+	const input = `package main
+
+func main() { log.Info() }
+`
+	process := func() string {
+		filename := filepath.Join(mt.env.WorkingDir, "main.go")
+		got, err := Process(filename, []byte(input), &Options{Env: mt.env, Comments: true, TabIndent: true, TabWidth: 8})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(got)
+	}
+
+	// x/internal/third_party/log is imported by more files of the module.
+	if got, want := process(), `import "x/internal/third_party/log"`; !strings.Contains(got, want) {
+		t.Errorf("got:\n%s\nwant it to contain %s", got, want)
+	}
+
+	// Pinned imports take precedence.
+	mt.env.PinnedImports = []string{"x/a/log"}
+	if got, want := process(), `import "x/a/log"`; !strings.Contains(got, want) {
+		t.Errorf("with pinned import, got:\n%s\nwant it to contain %s", got, want)
+	}
+}
+
 func BenchmarkModuleResolver_RescanModCache(b *testing.B) {
 	env := &ProcessEnv{
 		GocmdRunner: &gocommand.Runner{},
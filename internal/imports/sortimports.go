@@ -18,10 +18,12 @@ import (
 )
 
 // sortImports sorts runs of consecutive import lines in import blocks in f.
+// If opt.Groups is set, each block is sorted as a single run, in which
+// comments on lines of their own move with the import spec below them.
 // It also removes duplicate imports when it is possible to do so without data loss.
 //
 // It may mutate the token.File and the ast.File.
-func sortImports(localPrefix string, tokFile *token.File, f *ast.File) {
+func sortImports(opt *Options, tokFile *token.File, f *ast.File) {
 	for i, d := range f.Decls {
 		d, ok := d.(*ast.GenDecl)
 		if !ok || d.Tok != token.IMPORT {
@@ -44,13 +46,13 @@ func sortImports(localPrefix string, tokFile *token.File, f *ast.File) {
 		i := 0
 		specs := d.Specs[:0]
 		for j, s := range d.Specs {
-			if j > i && tokFile.Line(s.Pos()) > 1+tokFile.Line(d.Specs[j-1].End()) {
+			if opt.Groups == nil && j > i && tokFile.Line(s.Pos()) > 1+tokFile.Line(d.Specs[j-1].End()) {
 				// j begins a new run.  End this one.
-				specs = append(specs, sortSpecs(localPrefix, tokFile, f, d.Specs[i:j])...)
+				specs = append(specs, sortSpecs(opt, tokFile, f, d.Specs[i:j])...)
 				i = j
 			}
 		}
-		specs = append(specs, sortSpecs(localPrefix, tokFile, f, d.Specs[i:])...)
+		specs = append(specs, sortSpecs(opt, tokFile, f, d.Specs[i:])...)
 		d.Specs = specs
 
 		// Deduping can leave a blank line before the rparen; clean that up.
@@ -152,7 +154,7 @@ type posSpan struct {
 
 // sortSpecs sorts the import specs within each import decl.
 // It may mutate the token.File.
-func sortSpecs(localPrefix string, tokFile *token.File, f *ast.File, specs []ast.Spec) []ast.Spec {
+func sortSpecs(opt *Options, tokFile *token.File, f *ast.File, specs []ast.Spec) []ast.Spec {
 	// Can't short-circuit here even if specs are already sorted,
 	// since they might yet need deduplication.
 	// A lone import, however, may be safely ignored.
@@ -186,22 +188,36 @@ func sortSpecs(localPrefix string, tokFile *token.File, f *ast.File, specs []ast
 	comments := f.Comments[cstart:cend]
 
 	// Assign each comment to the import spec preceding it.
+	// When regrouping, a comment on lines of its own, such as a group
+	// header, is instead assigned to the import spec following it.
 	importComment := map[*ast.ImportSpec][]*ast.CommentGroup{}
+	importDoc := map[*ast.ImportSpec][]*ast.CommentGroup{}
+	inline := map[*ast.CommentGroup]bool{} // doc comments on the line of their spec
 	specIndex := 0
 	for _, g := range comments {
 		for specIndex+1 < len(specs) && pos[specIndex+1].Start <= g.Pos() {
 			specIndex++
 		}
+		if opt.Groups != nil && specIndex+1 < len(specs) && tokFile.Line(g.Pos()) > tokFile.Line(pos[specIndex].End) {
+			s := specs[specIndex+1].(*ast.ImportSpec)
+			importDoc[s] = append(importDoc[s], g)
+			inline[g] = tokFile.Line(g.End()) == tokFile.Line(s.Pos())
+			continue
+		}
 		s := specs[specIndex].(*ast.ImportSpec)
 		importComment[s] = append(importComment[s], g)
 	}
+	end := pos[len(pos)-1].End
+	if len(comments) > 0 {
+		end = max(end, comments[len(comments)-1].End())
+	}
 
 	// Sort the import specs by import path.
 	// Remove duplicates, when possible without data loss.
 	// Reassign the import paths to have the same position sequence.
 	// Reassign each comment to abut the end of its spec.
 	// Sort the comments by new position.
-	sort.Sort(byImportSpec{localPrefix, specs})
+	sort.Sort(byImportSpec{opt, specs})
 
 	// Dedup. Thanks to our sorting, we can just consider
 	// adjacent pairs of imports.
@@ -216,6 +232,12 @@ func sortSpecs(localPrefix string, tokFile *token.File, f *ast.File, specs []ast
 	}
 	specs = deduped
 
+	if opt.Groups != nil {
+		layoutSpecs(tokFile, specs, pos[0].Start, end, importDoc, inline, importComment)
+		sort.Sort(byCommentPos(comments))
+		return specs
+	}
+
 	// Fix up comment positions
 	for i, s := range specs {
 		s := s.(*ast.ImportSpec)
@@ -263,9 +285,67 @@ func sortSpecs(localPrefix string, tokFile *token.File, f *ast.File, specs []ast
 	return specs
 }
 
+// layoutSpecs assigns positions to the sorted import specs and their
+// comments, which occupy the source from start to end, so that each
+// spec and each comment on lines of its own starts a new line, and
+// replaces the lines of the token.File in that range to match.
+func layoutSpecs(tokFile *token.File, specs []ast.Spec, start, end token.Pos, docs map[*ast.ImportSpec][]*ast.CommentGroup, inline map[*ast.CommentGroup]bool, comments map[*ast.ImportSpec][]*ast.CommentGroup) {
+	var starts []int // line offsets within [start, end]
+	off := tokFile.Offset(start)
+	place := func(c *ast.Comment) {
+		c.Slash = tokFile.Pos(off)
+		for i, b := range []byte(c.Text) {
+			if b == '\n' {
+				starts = append(starts, off+i+1)
+			}
+		}
+		off += len(c.Text)
+	}
+	for i, s := range specs {
+		s := s.(*ast.ImportSpec)
+		if i > 0 {
+			off++ // newline
+			starts = append(starts, off)
+		}
+		for _, g := range docs[s] {
+			for _, c := range g.List {
+				place(c)
+				off++ // newline or space
+				if !inline[g] {
+					starts = append(starts, off)
+				}
+			}
+		}
+		delta := tokFile.Pos(off) - s.Pos()
+		length := int(s.End() - s.Pos())
+		if s.Name != nil {
+			s.Name.NamePos += delta
+		}
+		updateBasicLitPos(s.Path, s.Path.Pos()+delta)
+		off += length
+		s.EndPos = tokFile.Pos(off)
+		for _, g := range comments[s] {
+			for _, c := range g.List {
+				off++ // space
+				place(c)
+			}
+		}
+	}
+
+	// Replace the lines from the first spec to end.
+	lines := tokFile.Lines()
+	first, last := tokFile.Line(start), tokFile.Line(end)
+	newLines := slices.Clone(lines[:first])
+	newLines = append(newLines, starts...)
+	newLines = append(newLines, lines[last:]...)
+	if !tokFile.SetLines(newLines) {
+		log.Printf("imports: invalid line table after sorting %d import specs", len(specs))
+	}
+}
+
 type byImportSpec struct {
-	localPrefix string
-	specs       []ast.Spec // slice of *ast.ImportSpec
+	opt   *Options
+	specs []ast.Spec // slice of *ast.ImportSpec
 }
 
 func (x byImportSpec) Len() int      { return len(x.specs) }
@@ -274,8 +354,8 @@ func (x byImportSpec) Less(i, j int) bool {
 	ipath := importPath(x.specs[i])
 	jpath := importPath(x.specs[j])
 
-	igroup := importGroup(x.localPrefix, ipath)
-	jgroup := importGroup(x.localPrefix, jpath)
+	igroup := x.opt.importGroup(ipath)
+	jgroup := x.opt.importGroup(jpath)
 	if igroup != jgroup {
 		return igroup < jgroup
 	}
@@ -4,7 +4,11 @@
 
 package imports
 
-import "context"
+import (
+	"context"
+	"math"
+	"slices"
+)
 
 // These types document the APIs below.
 //
@@ -61,3 +65,32 @@ type Source interface {
 	// missing map.
 	ResolveReferences(ctx context.Context, filename string, missing References) ([]*Result, error)
 }
+
+// Preferences ranks the candidate packages, having the same name, that
+// could satisfy a missing import, so that the choice is consistent with
+// the rest of the module. Candidates with higher [Preferences.Score]
+// are preferred; ties are broken by each [Source]'s own heuristics.
+//
+// A nil *Preferences expresses no preference.
+type Preferences struct {
+	// Pinned lists import paths that are preferred over all other
+	// candidates, typically from user configuration.
+	Pinned []ImportPath
+
+	// Uses records the number of uses of each import path in the
+	// module of the file being fixed, such as the number of files or
+	// packages that import it.
+	Uses map[ImportPath]int
+}
+
+// Score returns the preference for the candidate importPath:
+// math.MaxInt if it is pinned, and otherwise its number of uses.
+func (p *Preferences) Score(importPath ImportPath) int {
+	if p == nil {
+		return 0
+	}
+	if slices.Contains(p.Pinned, importPath) {
+		return math.MaxInt
+	}
+	return p.Uses[importPath]
+}
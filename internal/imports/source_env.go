@@ -91,6 +91,7 @@ func (s *ProcessEnvSource) ResolveReferences(ctx context.Context, filename strin
 		srcDir:      s.srcDir,
 		xtest:       strings.HasSuffix(s.pkgName, "_test"),
 		loadExports: resolver.loadExports,
+		preferences: sync.OnceValue(func() *Preferences { return s.preferences(resolver) }),
 	}
 
 	var resultMu sync.Mutex
@@ -127,3 +128,14 @@ func (s *ProcessEnvSource) ResolveReferences(ctx context.Context, filename strin
 	}
 	return ans, nil
 }
+
+// preferences returns the ranking of candidate imports for the file,
+// based on the pinned imports of the environment and on the imports of
+// the main module containing the file, if any.
+func (s *ProcessEnvSource) preferences(resolver Resolver) *Preferences {
+	prefs := &Preferences{Pinned: s.env.PinnedImports}
+	if r, ok := resolver.(*ModuleResolver); ok {
+		prefs.Uses = r.importUses(s.srcDir)
+	}
+	return prefs
+}
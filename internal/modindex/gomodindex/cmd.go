@@ -25,7 +25,10 @@ import (
 	"golang.org/x/tools/internal/modindex"
 )
 
-var verbose = flag.Int("v", 0, "how much information to print")
+var (
+	verbose = flag.Int("v", 0, "how much information to print")
+	limit   = flag.Int("n", 100, "maximum number of query results, or 0 for all")
+)
 
 type cmd struct {
 	name string
@@ -36,7 +39,7 @@ type cmd struct {
 var cmds = []cmd{
 	{"update", update, "if there is an existing index of GOMODCACHE, update it. Otherwise create one."},
 	{"clean", clean, "removed unreferenced indexes more than an hour old"},
-	{"query", query, "not yet implemented"},
+	{"query", query, "print the symbols whose name contains the (case-insensitive) pattern argument"},
 }
 
 func goEnv(s string) string {
@@ -87,7 +90,37 @@ func update(dir string) {
 }
 
 func query(dir string) {
-	panic("implement")
+	if flag.NArg() != 2 {
+		log.Fatal("query expects a pattern argument")
+	}
+	ix, err := modindex.Read(dir)
+	if err != nil {
+		log.Fatal(err)
+	}
+	pattern := strings.ToLower(flag.Arg(1))
+	matches := ix.Search(modindex.Query{
+		// Prefer exact matches, then prefixes, then substrings.
+		Match: func(name string) float64 {
+			name = strings.ToLower(name)
+			switch {
+			case name == pattern:
+				return 1
+			case strings.HasPrefix(name, pattern):
+				return 0.5
+			case strings.Contains(name, pattern):
+				return 0.25
+			}
+			return 0
+		},
+		Limit: *limit,
+	})
+	for _, m := range matches {
+		desc := m.Type.String()
+		if sig := m.Signature(); sig != "" {
+			desc = sig
+		}
+		fmt.Printf("%s.%s\t%s\t%s\n", m.ImportPath, m.Name, desc, m.Version)
+	}
 }
 
 func clean(_ string) {
@@ -5,6 +5,7 @@
 package modindex
 
 import (
+	"fmt"
 	"slices"
 	"strconv"
 	"strings"
@@ -17,6 +18,7 @@ type Candidate struct {
 	Name       string
 	Dir        string
 	ImportPath string
+	Version    string // module version, e.g. "v1.2.3"
 	Type       LexType
 	Deprecated bool
 	// information for Funcs
@@ -28,6 +30,30 @@ type Field struct {
 	Arg, Type string
 }
 
+// Signature returns the parameters of a function candidate in the form
+// of a function type, such as "func(x int, y string)", or "" for other
+// kinds of symbol. The results are omitted: the index records only
+// their number.
+func (c Candidate) Signature() string {
+	if c.Type != Func {
+		return ""
+	}
+	named := slices.ContainsFunc(c.Sig, func(f Field) bool { return f.Arg != "_" })
+	var b strings.Builder
+	b.WriteString("func(")
+	for i, f := range c.Sig {
+		if i > 0 {
+			b.WriteString(", ")
+		}
+		if named {
+			b.WriteString(f.Arg + " ")
+		}
+		b.WriteString(f.Type)
+	}
+	b.WriteString(")")
+	return b.String()
+}
+
 type LexType int8
 
 const (
@@ -103,43 +129,54 @@ func (ix *Index) Lookup(pkgName, name string, prefix bool) []Candidate {
 				// past range of matching Names
 				break
 			}
-			if len(flds) < 2 {
-				continue // should never happen
-			}
-			impPath, err := module.UnescapePath(e.ImportPath)
-			if err != nil {
+			px, ok := e.candidate(flds)
+			if !ok {
 				continue
 			}
-			px := Candidate{
-				PkgName:    pkgName,
-				Name:       flds[0],
-				Dir:        string(e.Dir),
-				ImportPath: impPath,
-				Type:       asLexType(flds[1][0]),
-				Deprecated: len(flds[1]) > 1 && flds[1][1] == 'D',
-			}
-			if px.Type == Func {
-				n, err := strconv.Atoi(flds[2])
-				if err != nil {
-					continue // should never happen
-				}
-				px.Results = int16(n)
-				if len(flds) >= 4 {
-					sig := strings.Split(flds[3], " ")
-					for i := range sig {
-						// $ cannot otherwise occur. removing the spaces
-						// almost works, but for chan struct{}, e.g.
-						sig[i] = strings.Replace(sig[i], "$", " ", -1)
-					}
-					px.Sig = toFields(sig)
-				}
-			}
 			ans = append(ans, px)
 		}
 	}
 	return ans
 }
 
+// candidate returns the Candidate for the symbol of e whose
+// information is split into fields flds by fastSplit.
+func (e *Entry) candidate(flds []string) (Candidate, bool) {
+	if len(flds) < 2 {
+		return Candidate{}, false // should never happen
+	}
+	impPath, err := module.UnescapePath(e.ImportPath)
+	if err != nil {
+		return Candidate{}, false
+	}
+	px := Candidate{
+		PkgName:    e.PkgName,
+		Name:       flds[0],
+		Dir:        string(e.Dir),
+		ImportPath: impPath,
+		Version:    e.Version,
+		Type:       asLexType(flds[1][0]),
+		Deprecated: len(flds[1]) > 1 && flds[1][1] == 'D',
+	}
+	if px.Type == Func {
+		n, err := strconv.Atoi(flds[2])
+		if err != nil {
+			return Candidate{}, false // should never happen
+		}
+		px.Results = int16(n)
+		if len(flds) >= 4 {
+			sig := strings.Split(flds[3], " ")
+			for i := range sig {
+				// $ cannot otherwise occur. removing the spaces
+				// almost works, but for chan struct{}, e.g.
+				sig[i] = strings.Replace(sig[i], "$", " ", -1)
+			}
+			px.Sig = toFields(sig)
+		}
+	}
+	return px, true
+}
+
 func toFields(sig []string) []Field {
 	ans := make([]Field, len(sig)/2)
 	for i := range ans {
@@ -182,3 +219,19 @@ func asLexType(c byte) LexType {
 	}
 	return -1
 }
+
+// String returns the name of the kind of symbol, as in Go
+// declarations: "const", "var", "type", or "func".
+func (t LexType) String() string {
+	switch t {
+	case Const:
+		return "const"
+	case Var:
+		return "var"
+	case Type:
+		return "type"
+	case Func:
+		return "func"
+	}
+	return fmt.Sprintf("LexType(%d)", t)
+}
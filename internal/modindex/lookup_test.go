@@ -121,6 +121,52 @@ func TestLookup(t *testing.T) {
 	}
 }
 
+func TestSearch(t *testing.T) {
+	dir := testModCache(t)
+	wrtData(t, dir, thedata)
+	if _, err := Create(dir); err != nil {
+		t.Fatal(err)
+	}
+	ix, err := Read(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// names containing "oo", with exact matches first
+	contains := func(name string) float64 {
+		switch {
+		case name == "FooF":
+			return 1
+		case strings.Contains(name, "oo"):
+			return 0.5
+		}
+		return 0
+	}
+	got := ix.Search(Query{Match: contains})
+	var names []string
+	for _, m := range got {
+		names = append(names, m.Name)
+	}
+	if want := "FooF Foo FooC FooT FooV Goo GooVV Ⱋoox"; strings.Join(names, " ") != want {
+		t.Errorf("got %v, want %s", names, want)
+	}
+	if len(got) > 0 {
+		m := got[0]
+		if m.Score != 1 || m.Version != "v0.4.1" || m.PkgName != "foo" ||
+			m.ImportPath != "cloud.google.com/go/Longrunning" || m.Signature() != "func(int, float)" {
+			t.Errorf("got %+v (signature %q)", m, m.Signature())
+		}
+	}
+
+	got = ix.Search(Query{Match: contains, Kinds: []LexType{Var, Const}, Limit: 2})
+	names = nil
+	for _, m := range got {
+		names = append(names, m.Name)
+	}
+	if want := "FooC FooV"; strings.Join(names, " ") != want {
+		t.Errorf("with kinds and limit, got %v, want %s", names, want)
+	}
+}
+
 func wrtData(t *testing.T, dir string, data tdata) {
 	t.Helper()
 	locname := filepath.FromSlash(data.fname)
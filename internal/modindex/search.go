@@ -0,0 +1,74 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package modindex
+
+import (
+	"cmp"
+	"slices"
+)
+
+// A Query specifies a search of the index by [Index.Search].
+type Query struct {
+	// Match returns the score of a symbol name: a positive value,
+	// typically in (0, 1], for names that match the query, or 0
+	// for names that do not.
+	Match func(name string) float64
+
+	// Kinds, if non-empty, restricts the search to symbols
+	// of these kinds.
+	Kinds []LexType
+
+	// Limit, if positive, is the maximum number of results.
+	Limit int
+}
+
+// A Match is a symbol found by [Index.Search], with its score.
+type Match struct {
+	Candidate
+	Score float64
+}
+
+// Search returns the symbols of the index that match the query, in
+// decreasing order of score, then by name and import path. Unlike
+// [Index.Lookup], which requires the package name, Search examines
+// every symbol of the index, so it is suited to interactive
+// queries rather than to import resolution.
+func (ix *Index) Search(q Query) []Match {
+	var ans []Match
+	for i := range ix.Entries {
+		e := &ix.Entries[i]
+		for _, nstr := range e.Names {
+			flds := fastSplit(nstr)
+			if len(flds) < 2 {
+				continue // should never happen
+			}
+			if len(q.Kinds) > 0 && !slices.Contains(q.Kinds, asLexType(flds[1][0])) {
+				continue
+			}
+			score := q.Match(flds[0])
+			if score <= 0 {
+				continue
+			}
+			c, ok := e.candidate(flds)
+			if !ok {
+				continue
+			}
+			ans = append(ans, Match{c, score})
+		}
+	}
+	slices.SortFunc(ans, func(x, y Match) int {
+		if c := cmp.Compare(y.Score, x.Score); c != 0 {
+			return c
+		}
+		if c := cmp.Compare(x.Name, y.Name); c != 0 {
+			return c
+		}
+		return cmp.Compare(x.ImportPath, y.ImportPath)
+	})
+	if q.Limit > 0 && len(ans) > q.Limit {
+		ans = ans[:q.Limit]
+	}
+	return ans
+}
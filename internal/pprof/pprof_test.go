@@ -44,3 +44,42 @@ func TestTotalTime(t *testing.T) {
 		t.Fatalf("TotalTime(%q): got %v (%d), want %v (%d)", filename, got, got, want, want)
 	}
 }
+
+func TestParse(t *testing.T) {
+	// $ go tool pprof -top testdata/sample.pprof
+	//       flat  flat%   sum%        cum   cum%
+	//     9090ms 32.95% 32.95%     9090ms 32.95%  runtime.madvise
+	const filename = "testdata/sample.pprof"
+
+	profGz, err := os.ReadFile(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p, err := pprof.Parse(profGz)
+	if err != nil {
+		t.Fatal(err)
+	}
+	i := p.DefaultValueIndex()
+	if got, want := p.SampleTypes[i], (pprof.ValueType{Type: "cpu", Unit: "nanoseconds"}); got != want {
+		t.Errorf("default sample type = %v, want %v", got, want)
+	}
+
+	var total time.Duration
+	flat := make(map[string]int64)
+	for _, s := range p.Samples {
+		total += time.Duration(s.Values[i])
+		flat[s.Stack[0].Function] += s.Values[i]
+	}
+	if want := 27590 * time.Millisecond; total != want {
+		t.Errorf("total = %v, want %v", total, want)
+	}
+	var hottest string
+	for fn, v := range flat {
+		if hottest == "" || v > flat[hottest] {
+			hottest = fn
+		}
+	}
+	if got, want := time.Duration(flat[hottest]).Round(10*time.Millisecond), 9090*time.Millisecond; hottest != "runtime.madvise" || got != want {
+		t.Errorf("hottest function = %s (%v), want runtime.madvise (%v)", hottest, got, want)
+	}
+}
@@ -0,0 +1,274 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package pprof
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+)
+
+// A Profile is the subset of a pprof profile that describes the
+// source locations of its samples.
+type Profile struct {
+	SampleTypes       []ValueType // type of each element of Sample.Values
+	DefaultSampleType string      // preferred sample type, if specified
+	Samples           []Sample
+}
+
+// A ValueType describes the type and unit of a sample value,
+// such as "cpu" and "nanoseconds".
+type ValueType struct {
+	Type, Unit string
+}
+
+// A Sample is a call stack and its associated values.
+type Sample struct {
+	Stack  []Frame // innermost frame first, with inlined calls expanded
+	Values []int64 // one per Profile.SampleTypes
+}
+
+// A Frame is a source location within a call stack.
+type Frame struct {
+	Function  string // qualified name, e.g. "example.com/p.(*T).F"
+	File      string // absolute file name, if known
+	Line      int    // 1-based line number, or zero
+	StartLine int    // line of function declaration, or zero
+}
+
+// DefaultValueIndex returns the index within Sample.Values of the
+// default sample type: the one named by DefaultSampleType, if any,
+// or else the last one, following the pprof convention.
+func (p *Profile) DefaultValueIndex() int {
+	for i, t := range p.SampleTypes {
+		if t.Type == p.DefaultSampleType {
+			return i
+		}
+	}
+	return len(p.SampleTypes) - 1
+}
+
+// Parse parses a profile in the pprof format, which may be gzipped.
+func Parse(data []byte) (_ *Profile, err error) {
+	if len(data) >= 2 && data[0] == 0x1f && data[1] == 0x8b {
+		rd, err := gzip.NewReader(bytes.NewReader(data))
+		if err != nil {
+			return nil, err
+		}
+		if data, err = io.ReadAll(rd); err != nil {
+			return nil, err
+		}
+	}
+
+	defer func() {
+		if x := recover(); x != nil {
+			err = fmt.Errorf("error parsing pprof profile: %v", x)
+		}
+	}()
+	return parseProfile(data), nil
+}
+
+// more pprof field numbers, from https://github.com/google/pprof/blob/master/proto/profile.proto
+const (
+	fldProfileSampleType        = 1  // repeated ValueType
+	fldProfileLocation          = 4  // repeated Location
+	fldProfileFunction          = 5  // repeated Function
+	fldProfileStringTable       = 6  // repeated string
+	fldProfileDefaultSampleType = 14 // int64 (string index)
+
+	fldValueTypeType = 1 // int64 (string index)
+	fldValueTypeUnit = 2 // int64 (string index)
+
+	fldSampleLocationID = 1 // repeated uint64
+
+	fldLocationID   = 1 // uint64
+	fldLocationLine = 4 // repeated Line
+
+	fldLineFunctionID = 1 // uint64
+	fldLineLine       = 2 // int64
+
+	fldFunctionID        = 1 // uint64
+	fldFunctionName      = 2 // int64 (string index)
+	fldFunctionFilename  = 4 // int64 (string index)
+	fldFunctionStartLine = 5 // int64
+)
+
+// more protobuf wire types
+const (
+	wireFixed64 = 1
+	wireFixed32 = 5
+)
+
+// parseProfile decodes a Profile message.
+// Since fields may appear in any order, and samples refer to
+// locations, functions and strings by index, the references are
+// resolved after the entire message has been read.
+func parseProfile(data []byte) *Profile {
+	type line struct {
+		function uint64
+		line     int
+	}
+	type function struct {
+		name, filename int
+		startLine      int
+	}
+	var (
+		strtab      []string
+		sampleTypes [][2]int // string indices of type and unit
+		defaultType int
+		samples     [][]byte // undecoded Sample messages
+		locations   = make(map[uint64][]line)
+		functions   = make(map[uint64]function)
+	)
+	fields(data, func(fld, ival uint64, sval []byte) {
+		switch fld {
+		case fldProfileSampleType:
+			var t [2]int
+			fields(sval, func(fld, ival uint64, _ []byte) {
+				switch fld {
+				case fldValueTypeType:
+					t[0] = int(ival)
+				case fldValueTypeUnit:
+					t[1] = int(ival)
+				}
+			})
+			sampleTypes = append(sampleTypes, t)
+
+		case fldProfileSample:
+			samples = append(samples, sval)
+
+		case fldProfileLocation:
+			var (
+				id    uint64
+				lines []line
+			)
+			fields(sval, func(fld, ival uint64, sval []byte) {
+				switch fld {
+				case fldLocationID:
+					id = ival
+				case fldLocationLine:
+					var l line
+					fields(sval, func(fld, ival uint64, _ []byte) {
+						switch fld {
+						case fldLineFunctionID:
+							l.function = ival
+						case fldLineLine:
+							l.line = int(ival)
+						}
+					})
+					lines = append(lines, l)
+				}
+			})
+			locations[id] = lines
+
+		case fldProfileFunction:
+			var (
+				id uint64
+				f  function
+			)
+			fields(sval, func(fld, ival uint64, _ []byte) {
+				switch fld {
+				case fldFunctionID:
+					id = ival
+				case fldFunctionName:
+					f.name = int(ival)
+				case fldFunctionFilename:
+					f.filename = int(ival)
+				case fldFunctionStartLine:
+					f.startLine = int(ival)
+				}
+			})
+			functions[id] = f
+
+		case fldProfileStringTable:
+			strtab = append(strtab, string(sval))
+
+		case fldProfileDefaultSampleType:
+			defaultType = int(ival)
+		}
+	})
+
+	str := func(i int) string {
+		if 0 <= i && i < len(strtab) {
+			return strtab[i]
+		}
+		return ""
+	}
+	p := &Profile{DefaultSampleType: str(defaultType)}
+	for _, t := range sampleTypes {
+		p.SampleTypes = append(p.SampleTypes, ValueType{str(t[0]), str(t[1])})
+	}
+	for _, data := range samples {
+		var s Sample
+		fields(data, func(fld, ival uint64, sval []byte) {
+			switch fld {
+			case fldSampleLocationID:
+				for _, id := range packed(ival, sval) {
+					// Lines within a location are listed innermost
+					// first: all but the last were inlined into it.
+					for _, l := range locations[id] {
+						f := functions[l.function]
+						s.Stack = append(s.Stack, Frame{
+							Function:  str(f.name),
+							File:      str(f.filename),
+							Line:      l.line,
+							StartLine: f.startLine,
+						})
+					}
+				}
+			case fldSampleValue:
+				for _, v := range packed(ival, sval) {
+					s.Values = append(s.Values, int64(v))
+				}
+			}
+		})
+		p.Samples = append(p.Samples, s)
+	}
+	return p
+}
+
+// fields calls f for each field of the protobuf message data, passing
+// its field number and its value, either an integer or (for wire type
+// "bytes") a byte slice. Fixed-size fields are ignored.
+func fields(data []byte, f func(fld, ival uint64, sval []byte)) {
+	for len(data) > 0 {
+		tag := varint(&data)
+		var (
+			ival uint64
+			sval []byte
+		)
+		switch wire := tag & 7; wire {
+		case wireVarint:
+			ival = varint(&data)
+		case wireBytes:
+			n := varint(&data)
+			sval, data = data[:n], data[n:]
+		case wireFixed64:
+			data = data[8:]
+			continue
+		case wireFixed32:
+			data = data[4:]
+			continue
+		default:
+			panic(fmt.Sprintf("unexpected wire type: %d", wire))
+		}
+		f(tag>>3, ival, sval)
+	}
+}
+
+// packed returns the elements of a repeated integer field, which may
+// be encoded either as a single varint (ival) or as a packed sequence
+// of varints (sval).
+func packed(ival uint64, sval []byte) []uint64 {
+	if sval == nil {
+		return []uint64{ival}
+	}
+	var res []uint64
+	for len(sval) > 0 {
+		res = append(res, varint(&sval))
+	}
+	return res
+}
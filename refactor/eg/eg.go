@@ -17,15 +17,18 @@ import (
 	"go/types"
 	"maps"
 	"os"
+	"strings"
 )
 
 const Help = `
-This tool implements example-based refactoring of expressions.
+This tool implements example-based refactoring of expressions and
+statements.
 
 The transformation is specified as a Go file defining two functions,
-'before' and 'after', of identical types.  Each function body consists
-of a single statement: either a return statement with a single
-(possibly multi-valued) expression, or an expression statement.  The
+'before' and 'after', of identical types.  In the simplest case, each
+function body consists of a single statement: either a return
+statement with a single (possibly multi-valued) expression, or an
+expression statement.  The
 'before' expression specifies a pattern and the 'after' expression its
 replacement.
 
@@ -40,6 +43,12 @@ result, for example:
  	func before(msg string) { log.Fatalf("%s", msg) }
  	func after(msg string)  { log.Fatal(msg) }
 
+If the body of the 'after' function of an expression rule has
+statements before its return statement, they are inserted before the
+statement that contains each match. Such a rule does not apply to
+expressions outside any statement, such as the initializer of a
+package-level variable; other expression rules do.
+
 The parameters of both functions are wildcards that may match any
 expression assignable to that type.  If the pattern contains multiple
 occurrences of the same parameter, each must match the same expression
@@ -65,6 +74,59 @@ Matching of type syntax is semantic, not syntactic: type syntax in the
 pattern matches type syntax in the input if the types are identical.
 Thus, func(x int) matches func(y int).
 
+A template may contain several rules, each a pair of functions whose
+names are "before" and "after" followed by the same suffix, such as
+beforeRead and afterRead.  Each pair must have identical types.  The
+rules are tried in the order they appear in the file, and the first
+whose pattern matches is applied.  Other functions whose names begin
+with "before", such as a beforeHook helper with no afterHook, are not
+rules.
+
+STATEMENTS
+
+If the body of a 'before' function is anything other than a single
+return or expression statement, its statements form a pattern that
+matches any sequence of consecutive statements in a block, and the
+body of the 'after' function is the replacement:
+
+	func before(mu *sync.Mutex, body func()) {
+		mu.Lock()
+		body()
+		mu.Unlock()
+	}
+	func after(mu *sync.Mutex, body func()) {
+		mu.Lock()
+		defer mu.Unlock()
+		body()
+	}
+
+A parameter of type func() that is called as a statement of the
+pattern, such as body above, is a list wildcard: it matches any
+sequence of statements, possibly empty.  It binds the shortest such
+sequence for which the rest of the pattern matches, except when it is
+the last statement of the pattern, in which case it binds all the
+remaining statements of the block.  A list wildcard may be used only
+in a call statement, in both functions.
+
+Variables declared by a statement pattern are also wildcards, which
+match any identifier:
+
+	func beforeWrite(name string, data []byte) error {
+		f, err := os.Create(name)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		_, err = f.Write(data)
+		return err
+	}
+	func afterWrite(name string, data []byte) error {
+		return os.WriteFile(name, data, 0666)
+	}
+
+Statements are matched syntactically, except for the expressions within
+them.  Declarations, switch, select and labeled statements never match.
+
 This tool was inspired by other example-based refactoring tools,
 'gofmt -r' for Go and Refaster for Java.
 
@@ -75,9 +137,8 @@ LIMITATIONS
 EXPRESSIVENESS
 
 Only refactorings that replace one expression with another, regardless
-of the expression's context, may be expressed.  Refactoring arbitrary
-statements (or sequences of statements) is a less well-defined problem
-and is less amenable to this approach.
+of the expression's context, or one sequence of statements with
+another, may be expressed.
 
 A pattern that contains a function literal (and hence statements)
 never matches.
@@ -143,42 +204,44 @@ type Transformer struct {
 	verbose        bool
 	info           *types.Info // combined type info for template/input/output ASTs
 	seenInfos      map[*types.Info]bool
-	wildcards      map[*types.Var]bool                // set of parameters in func before()
+	wildcards      map[*types.Var]bool                // set of parameters and locals in before funcs
+	locals         map[*types.Var]bool                // subset of wildcards that are locals of statement patterns
+	lists          map[*types.Var]bool                // subset of wildcards that bind statement lists
 	env            map[string]ast.Expr                // maps parameter name to wildcard binding
-	importedObjs   map[types.Object]*ast.SelectorExpr // objects imported by after().
-	before, after  ast.Expr
-	afterStmts     []ast.Stmt
+	stmtEnv        map[string][]ast.Stmt              // maps list wildcard name to binding
+	importedObjs   map[types.Object]*ast.SelectorExpr // objects imported by after funcs
+	rules          []*rule
 	allowWildcards bool
 
 	// Working state of Transform():
-	nsubsts    int            // number of substitutions made
-	currentPkg *types.Package // package of current call
+	nsubsts    int                       // number of substitutions made
+	stmtDepth  int                       // number of enclosing statements
+	currentPkg *types.Package            // package of current call
+	imports    map[string]*types.Package // packages referenced by substitutions
+}
+
+// A rule is a single before/after pair of functions in a template.
+//
+// In an expression rule, before and after are the pattern and
+// replacement expressions, and afterStmts are the statements that
+// precede the replacement. In a statement rule, beforeStmts and
+// afterStmts are the pattern and replacement statement lists.
+type rule struct {
+	name          string // the before function's name
+	before, after ast.Expr
+	beforeStmts   []ast.Stmt
+	afterStmts    []ast.Stmt
 }
 
 // NewTransformer returns a transformer based on the specified template,
-// a single-file package containing "before" and "after" functions as
-// described in the package documentation.
+// a single-file package containing one or more pairs of "before" and
+// "after" functions as described in the package documentation.
 // tmplInfo is the type information for tmplFile.
 func NewTransformer(fset *token.FileSet, tmplPkg *types.Package, tmplFile *ast.File, tmplInfo *types.Info, verbose bool) (*Transformer, error) {
 	// These maps are required by types.Info.TypeOf.
 	if tmplInfo.Types == nil || tmplInfo.Defs == nil || tmplInfo.Uses == nil {
 		return nil, errors.New("eg.NewTransformer: types.Info argument missing one of Types, Defs or Uses")
 	}
-	// Check the template.
-	beforeSig := funcSig(tmplPkg, "before")
-	if beforeSig == nil {
-		return nil, fmt.Errorf("no 'before' func found in template")
-	}
-	afterSig := funcSig(tmplPkg, "after")
-	if afterSig == nil {
-		return nil, fmt.Errorf("no 'after' func found in template")
-	}
-
-	// TODO(adonovan): should we also check the names of the params match?
-	if !types.Identical(afterSig, beforeSig) {
-		return nil, fmt.Errorf("before %s and after %s functions have different signatures",
-			beforeSig, afterSig)
-	}
 
 	for _, imp := range tmplFile.Imports {
 		if imp.Name != nil && imp.Name.Name == "." {
@@ -188,67 +251,16 @@ func NewTransformer(fset *token.FileSet, tmplPkg *types.Package, tmplFile *ast.F
 			return nil, fmt.Errorf("dot-import (of %s) in template", imp.Path.Value)
 		}
 	}
-	var beforeDecl, afterDecl *ast.FuncDecl
-	for _, decl := range tmplFile.Decls {
-		if decl, ok := decl.(*ast.FuncDecl); ok {
-			switch decl.Name.Name {
-			case "before":
-				beforeDecl = decl
-			case "after":
-				afterDecl = decl
-			}
-		}
-	}
-
-	before, err := soleExpr(beforeDecl)
-	if err != nil {
-		return nil, fmt.Errorf("before: %s", err)
-	}
-	afterStmts, after, err := stmtAndExpr(afterDecl)
-	if err != nil {
-		return nil, fmt.Errorf("after: %s", err)
-	}
-
-	wildcards := make(map[*types.Var]bool)
-	for v := range beforeSig.Params().Variables() {
-		wildcards[v] = true
-	}
-
-	// checkExprTypes returns an error if Tb (type of before()) is not
-	// safe to replace with Ta (type of after()).
-	//
-	// Only superficial checks are performed, and they may result in both
-	// false positives and negatives.
-	//
-	// Ideally, we would only require that the replacement be assignable
-	// to the context of a specific pattern occurrence, but the type
-	// checker doesn't record that information and it's complex to deduce.
-	// A Go type cannot capture all the constraints of a given expression
-	// context, which may include the size, constness, signedness,
-	// namedness or constructor of its type, and even the specific value
-	// of the replacement.  (Consider the rule that array literal keys
-	// must be unique.)  So we cannot hope to prove the safety of a
-	// transformation in general.
-	Tb := tmplInfo.TypeOf(before)
-	Ta := tmplInfo.TypeOf(after)
-	if types.AssignableTo(Tb, Ta) {
-		// safe: replacement is assignable to pattern.
-	} else if tuple, ok := Tb.(*types.Tuple); ok && tuple.Len() == 0 {
-		// safe: pattern has void type (must appear in an ExprStmt).
-	} else {
-		return nil, fmt.Errorf("%s is not a safe replacement for %s", Ta, Tb)
-	}
 
 	tr := &Transformer{
 		fset:           fset,
 		verbose:        verbose,
-		wildcards:      wildcards,
+		wildcards:      make(map[*types.Var]bool),
+		locals:         make(map[*types.Var]bool),
+		lists:          make(map[*types.Var]bool),
 		allowWildcards: true,
 		seenInfos:      make(map[*types.Info]bool),
 		importedObjs:   make(map[types.Object]*ast.SelectorExpr),
-		before:         before,
-		after:          after,
-		afterStmts:     afterStmts,
 	}
 
 	// Combine type info from the template and input packages, and
@@ -263,21 +275,186 @@ func NewTransformer(fset *token.FileSet, tmplPkg *types.Package, tmplFile *ast.F
 	}
 	mergeTypeInfo(tr.info, tmplInfo)
 
+	// Pair each beforeX function with its afterX function.
+	decls := make(map[string]*ast.FuncDecl)
+	var befores []*ast.FuncDecl
+	for _, decl := range tmplFile.Decls {
+		if decl, ok := decl.(*ast.FuncDecl); ok && decl.Recv == nil {
+			decls[decl.Name.Name] = decl
+			if strings.HasPrefix(decl.Name.Name, "before") {
+				befores = append(befores, decl)
+			}
+		}
+	}
+	if len(befores) == 0 {
+		return nil, fmt.Errorf("no 'before' func found in template")
+	}
+	for _, beforeDecl := range befores {
+		afterName := "after" + strings.TrimPrefix(beforeDecl.Name.Name, "before")
+		afterDecl := decls[afterName]
+		if afterDecl == nil {
+			if beforeDecl.Name.Name == "before" {
+				return nil, fmt.Errorf("no '%s' func found in template", afterName)
+			}
+			continue // not a rule, but a helper such as beforeHook
+		}
+		r, err := tr.addRule(tmplPkg, tmplInfo, beforeDecl, afterDecl)
+		if err != nil {
+			if len(befores) > 1 {
+				err = fmt.Errorf("%s: %v", beforeDecl.Name.Name, err)
+			}
+			return nil, err
+		}
+		tr.rules = append(tr.rules, r)
+	}
+	if len(tr.rules) == 0 {
+		return nil, fmt.Errorf("no 'before' func found in template")
+	}
+
+	return tr, nil
+}
+
+// addRule checks a pair of before and after functions of the template
+// and returns the rule they define.
+func (tr *Transformer) addRule(tmplPkg *types.Package, tmplInfo *types.Info, beforeDecl, afterDecl *ast.FuncDecl) (*rule, error) {
+	beforeSig := funcSig(tmplPkg, beforeDecl.Name.Name)
+	afterSig := funcSig(tmplPkg, afterDecl.Name.Name)
+
+	// TODO(adonovan): should we also check the names of the params match?
+	if !types.Identical(afterSig, beforeSig) {
+		return nil, fmt.Errorf("%s %s and %s %s functions have different signatures",
+			beforeDecl.Name.Name, beforeSig, afterDecl.Name.Name, afterSig)
+	}
+	if beforeDecl.Body == nil {
+		return nil, fmt.Errorf("%s: no body", beforeDecl.Name.Name)
+	}
+
+	r := &rule{name: beforeDecl.Name.Name}
+	var wildcards []*types.Var
+	for v := range beforeSig.Params().Variables() {
+		wildcards = append(wildcards, v)
+	}
+
+	if isStmtPattern(beforeDecl) {
+		// Statement rule: the before and after bodies are
+		// statement lists, and locals declared by the pattern
+		// are wildcards too.
+		if afterDecl.Body == nil {
+			return nil, fmt.Errorf("%s: no body", afterDecl.Name.Name)
+		}
+		r.beforeStmts = beforeDecl.Body.List
+		r.afterStmts = afterDecl.Body.List
+		for id, obj := range tmplInfo.Defs {
+			if v, ok := obj.(*types.Var); ok && !v.IsField() &&
+				beforeDecl.Body.Pos() <= id.Pos() && id.Pos() < beforeDecl.Body.End() {
+				wildcards = append(wildcards, v)
+				tr.locals[v] = true
+			}
+		}
+		if err := tr.checkLists(beforeDecl, afterDecl, beforeSig, afterSig); err != nil {
+			return nil, err
+		}
+
+	} else {
+		// Expression rule.
+		before, err := soleExpr(beforeDecl)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %s", beforeDecl.Name.Name, err)
+		}
+		afterStmts, after, err := stmtAndExpr(afterDecl)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %s", afterDecl.Name.Name, err)
+		}
+		r.before, r.after, r.afterStmts = before, after, afterStmts
+
+		// checkExprTypes returns an error if Tb (type of before()) is not
+		// safe to replace with Ta (type of after()).
+		//
+		// Only superficial checks are performed, and they may result in both
+		// false positives and negatives.
+		//
+		// Ideally, we would only require that the replacement be assignable
+		// to the context of a specific pattern occurrence, but the type
+		// checker doesn't record that information and it's complex to deduce.
+		// A Go type cannot capture all the constraints of a given expression
+		// context, which may include the size, constness, signedness,
+		// namedness or constructor of its type, and even the specific value
+		// of the replacement.  (Consider the rule that array literal keys
+		// must be unique.)  So we cannot hope to prove the safety of a
+		// transformation in general.
+		Tb := tmplInfo.TypeOf(before)
+		Ta := tmplInfo.TypeOf(after)
+		if types.AssignableTo(Tb, Ta) {
+			// safe: replacement is assignable to pattern.
+		} else if tuple, ok := Tb.(*types.Tuple); ok && tuple.Len() == 0 {
+			// safe: pattern has void type (must appear in an ExprStmt).
+		} else {
+			return nil, fmt.Errorf("%s is not a safe replacement for %s", Ta, Tb)
+		}
+	}
+
+	for _, v := range wildcards {
+		tr.wildcards[v] = true
+	}
+
 	// Compute set of imported objects required by after().
 	// TODO(adonovan): reject dot-imports in pattern
-	ast.Inspect(after, func(n ast.Node) bool {
+	ast.Inspect(afterDecl.Body, func(n ast.Node) bool {
 		if n, ok := n.(*ast.SelectorExpr); ok {
 			if _, ok := tr.info.Selections[n]; !ok {
 				// qualified ident
-				obj := tr.info.Uses[n.Sel]
-				tr.importedObjs[obj] = n
+				if obj := tr.info.Uses[n.Sel]; obj != nil {
+					tr.importedObjs[obj] = n
+				}
 				return false // prune
 			}
 		}
 		return true // recur
 	})
 
-	return tr, nil
+	return r, nil
+}
+
+// checkLists records the list wildcards of a statement rule, that is,
+// its parameters of type func() that are called as a statement of the
+// pattern, and checks that they are used only as such statements in
+// both before and after.
+func (tr *Transformer) checkLists(beforeDecl, afterDecl *ast.FuncDecl, beforeSig, afterSig *types.Signature) error {
+	beforeCalls := listCalls(beforeDecl.Body)
+	afterCalls := listCalls(afterDecl.Body)
+
+	lists := make(map[types.Object]bool) // list wildcards of before and after
+	for i := range beforeSig.Params().Len() {
+		v := beforeSig.Params().At(i)
+		if !isNullaryFunc(v.Type()) {
+			continue
+		}
+		for id := range beforeCalls {
+			if tr.info.Uses[id] == v {
+				tr.lists[v] = true
+				lists[v] = true
+				lists[afterSig.Params().At(i)] = true
+				break
+			}
+		}
+	}
+
+	for _, check := range []struct {
+		decl  *ast.FuncDecl
+		calls map[*ast.Ident]bool
+	}{
+		{beforeDecl, beforeCalls},
+		{afterDecl, afterCalls},
+	} {
+		for id, obj := range tr.info.Uses {
+			if lists[obj] && !check.calls[id] &&
+				check.decl.Body.Pos() <= id.Pos() && id.Pos() < check.decl.Body.End() {
+				return fmt.Errorf("%s: list wildcard %s may be used only in a call statement %s()",
+					check.decl.Name.Name, id.Name, id.Name)
+			}
+		}
+	}
+	return nil
 }
 
 // WriteAST is a convenience function that writes AST f to the specified file.
@@ -305,6 +482,55 @@ func funcSig(pkg *types.Package, name string) *types.Signature {
 	return nil
 }
 
+// isStmtPattern reports whether the before function fn defines a
+// statement rule, that is, whether its body is anything other than a
+// single return statement with one operand or expression statement.
+func isStmtPattern(fn *ast.FuncDecl) bool {
+	if fn.Body == nil || len(fn.Body.List) != 1 {
+		return true
+	}
+	switch stmt := fn.Body.List[0].(type) {
+	case *ast.ReturnStmt:
+		return len(stmt.Results) != 1
+	case *ast.ExprStmt:
+		return false
+	}
+	return true
+}
+
+// listCall returns the function identifier of n if it is a call
+// statement f() of a named function with no arguments, or nil
+// otherwise.
+func listCall(n ast.Node) *ast.Ident {
+	if stmt, ok := n.(*ast.ExprStmt); ok {
+		if call, ok := stmt.X.(*ast.CallExpr); ok && len(call.Args) == 0 {
+			if id, ok := call.Fun.(*ast.Ident); ok {
+				return id
+			}
+		}
+	}
+	return nil
+}
+
+// listCalls returns the set of function identifiers of call statements
+// within n (see listCall).
+func listCalls(n ast.Node) map[*ast.Ident]bool {
+	calls := make(map[*ast.Ident]bool)
+	ast.Inspect(n, func(n ast.Node) bool {
+		if id := listCall(n); id != nil {
+			calls[id] = true
+		}
+		return true
+	})
+	return calls
+}
+
+// isNullaryFunc reports whether t is the type func().
+func isNullaryFunc(t types.Type) bool {
+	sig, ok := t.Underlying().(*types.Signature)
+	return ok && sig.Params().Len() == 0 && sig.Results().Len() == 0
+}
+
 // soleExpr returns the sole expression in the before/after template function.
 func soleExpr(fn *ast.FuncDecl) (ast.Expr, error) {
 	if fn.Body == nil {
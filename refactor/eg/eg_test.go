@@ -63,11 +63,15 @@ func Test(t *testing.T) {
 		"testdata/h.txtar",
 		"testdata/i.txtar",
 		"testdata/j.txtar",
+		"testdata/k.txtar",
+		"testdata/l.txtar",
 		"testdata/bad_type.txtar",
 		"testdata/no_before.txtar",
+		"testdata/no_after.txtar",
 		"testdata/no_after_return.txtar",
 		"testdata/type_mismatch.txtar",
 		"testdata/expr_type_mismatch.txtar",
+		"testdata/bad_list.txtar",
 	} {
 		t.Run(filename, func(t *testing.T) {
 			// Extract and load packages from test archive.
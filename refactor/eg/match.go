@@ -10,7 +10,7 @@ import (
 	"go/constant"
 	"go/token"
 	"go/types"
-	"log"
+	"maps"
 	"os"
 	"reflect"
 )
@@ -60,7 +60,10 @@ func (tr *Transformer) matchExpr(x, y ast.Expr) bool {
 	}
 	switch x := x.(type) {
 	case *ast.Ident:
-		log.Fatalf("unexpected Ident: %s", astString(tr.fset, x))
+		if x.Name == "_" {
+			return y.(*ast.Ident).Name == "_"
+		}
+		panic(fmt.Sprintf("unexpected Ident: %s", astString(tr.fset, x)))
 
 	case *ast.BasicLit:
 		y := y.(*ast.BasicLit)
@@ -159,12 +162,23 @@ func (tr *Transformer) wildcardObj(x ast.Expr) (*types.Var, bool) {
 		if xobj, ok := tr.info.Uses[x].(*types.Var); ok && tr.wildcards[xobj] {
 			return xobj, true
 		}
+		// The declaring identifier of a local of a statement pattern.
+		if xobj, ok := tr.info.Defs[x].(*types.Var); ok && tr.locals[xobj] {
+			return xobj, true
+		}
 	}
 	return nil, false
 }
 
 func (tr *Transformer) matchSelectorExpr(x, y *ast.SelectorExpr) bool {
 	if xobj, ok := tr.wildcardObj(x.X); ok {
+		if old, ok := tr.env[xobj.Name()]; ok {
+			// found existing binding
+			tr.allowWildcards = false
+			r := tr.matchExpr(old, y.X)
+			tr.allowWildcards = true
+			return r
+		}
 		field := x.Sel.Name
 		yt := tr.info.TypeOf(y.X)
 		o, _, _ := types.LookupFieldOrMethod(yt, true, tr.currentPkg, field)
@@ -184,6 +198,11 @@ func (tr *Transformer) matchWildcard(xobj *types.Var, y ast.Expr) bool {
 			tr.fset.Position(y.Pos()), name, astString(tr.fset, y))
 	}
 
+	// A local of a statement pattern matches only an identifier.
+	if _, ok := y.(*ast.Ident); !ok && tr.locals[xobj] {
+		return false
+	}
+
 	// Check that y is assignable to the declared type of the param.
 	yt := tr.info.TypeOf(y)
 	if yt == nil {
@@ -225,14 +244,174 @@ func (tr *Transformer) matchWildcard(xobj *types.Var, y ast.Expr) bool {
 	return true
 }
 
+// matchStmts reports whether the statement pattern xs matches a prefix
+// of ys, and returns the length of that prefix. If all is set, the
+// pattern must match all of ys.
+//
+// A list wildcard (a call statement w() of a parameter w of type
+// func()) matches any sequence of statements; it binds the shortest
+// sequence for which the rest of the pattern matches, unless it is the
+// last statement of the pattern, in which case it binds all the
+// remaining statements. As with expression wildcards, a list wildcard
+// appearing more than once must match the same statements each time.
+func (tr *Transformer) matchStmts(xs, ys []ast.Stmt, all bool) (int, bool) {
+	if len(xs) == 0 {
+		return 0, !all || len(ys) == 0
+	}
+
+	env, stmtEnv := maps.Clone(tr.env), maps.Clone(tr.stmtEnv)
+	restore := func() { tr.env, tr.stmtEnv = maps.Clone(env), maps.Clone(stmtEnv) }
+
+	if xobj, ok := tr.listWildcardObj(xs[0]); ok {
+		name := xobj.Name()
+		if old, ok := tr.stmtEnv[name]; ok {
+			// found existing binding
+			if len(old) > len(ys) {
+				return 0, false
+			}
+			tr.allowWildcards = false
+			_, same := tr.matchStmts(old, ys[:len(old)], true)
+			tr.allowWildcards = true
+			if same {
+				if n, ok := tr.matchStmts(xs[1:], ys[len(old):], all); ok {
+					return len(old) + n, true
+				}
+			}
+			restore()
+			return 0, false
+		}
+
+		min := 0
+		if len(xs) == 1 {
+			min = len(ys) // trailing wildcard binds the rest
+		}
+		for k := min; k <= len(ys); k++ {
+			tr.stmtEnv[name] = ys[:k] // record binding
+			if n, ok := tr.matchStmts(xs[1:], ys[k:], all); ok {
+				return k + n, true
+			}
+			restore()
+		}
+		return 0, false
+	}
+
+	if len(ys) > 0 && tr.matchStmt(xs[0], ys[0]) {
+		if n, ok := tr.matchStmts(xs[1:], ys[1:], all); ok {
+			return 1 + n, true
+		}
+	}
+	restore()
+	return 0, false
+}
+
+// matchStmt reports whether statement pattern x matches y.
+// Statements are matched syntactically, except that the expressions
+// within them are matched by matchExpr.
+func (tr *Transformer) matchStmt(x, y ast.Stmt) bool {
+	if x == nil || y == nil {
+		return x == nil && y == nil
+	}
+	if reflect.TypeOf(x) != reflect.TypeOf(y) {
+		return false
+	}
+	switch x := x.(type) {
+	case *ast.EmptyStmt:
+		return true
+
+	case *ast.ExprStmt:
+		y := y.(*ast.ExprStmt)
+		return tr.matchExpr(x.X, y.X)
+
+	case *ast.AssignStmt:
+		y := y.(*ast.AssignStmt)
+		return x.Tok == y.Tok &&
+			tr.matchExprs(x.Lhs, y.Lhs) &&
+			tr.matchExprs(x.Rhs, y.Rhs)
+
+	case *ast.IncDecStmt:
+		y := y.(*ast.IncDecStmt)
+		return x.Tok == y.Tok &&
+			tr.matchExpr(x.X, y.X)
+
+	case *ast.SendStmt:
+		y := y.(*ast.SendStmt)
+		return tr.matchExpr(x.Chan, y.Chan) &&
+			tr.matchExpr(x.Value, y.Value)
+
+	case *ast.ReturnStmt:
+		y := y.(*ast.ReturnStmt)
+		return tr.matchExprs(x.Results, y.Results)
+
+	case *ast.DeferStmt:
+		y := y.(*ast.DeferStmt)
+		return tr.matchExpr(x.Call, y.Call)
+
+	case *ast.GoStmt:
+		y := y.(*ast.GoStmt)
+		return tr.matchExpr(x.Call, y.Call)
+
+	case *ast.BranchStmt:
+		y := y.(*ast.BranchStmt)
+		return x.Tok == y.Tok &&
+			(x.Label == nil) == (y.Label == nil) &&
+			(x.Label == nil || x.Label.Name == y.Label.Name)
+
+	case *ast.BlockStmt:
+		y := y.(*ast.BlockStmt)
+		_, ok := tr.matchStmts(x.List, y.List, true)
+		return ok
+
+	case *ast.IfStmt:
+		y := y.(*ast.IfStmt)
+		return tr.matchStmt(x.Init, y.Init) &&
+			tr.matchExpr(x.Cond, y.Cond) &&
+			tr.matchStmt(x.Body, y.Body) &&
+			tr.matchStmt(x.Else, y.Else)
+
+	case *ast.ForStmt:
+		y := y.(*ast.ForStmt)
+		return tr.matchStmt(x.Init, y.Init) &&
+			tr.matchExpr(x.Cond, y.Cond) &&
+			tr.matchStmt(x.Post, y.Post) &&
+			tr.matchStmt(x.Body, y.Body)
+
+	case *ast.RangeStmt:
+		y := y.(*ast.RangeStmt)
+		return x.Tok == y.Tok &&
+			tr.matchExpr(x.Key, y.Key) &&
+			tr.matchExpr(x.Value, y.Value) &&
+			tr.matchExpr(x.X, y.X) &&
+			tr.matchStmt(x.Body, y.Body)
+	}
+
+	// TODO(adonovan): match declarations, switches, selects,
+	// and labeled statements.
+	return false
+}
+
+// listWildcardObj returns the parameter of the list wildcard call
+// statement x, if any.
+func (tr *Transformer) listWildcardObj(x ast.Stmt) (*types.Var, bool) {
+	if id := listCall(x); id != nil && tr.allowWildcards {
+		if xobj, ok := tr.info.Uses[id].(*types.Var); ok && tr.lists[xobj] {
+			return xobj, true
+		}
+	}
+	return nil, false
+}
+
 // -- utilities --------------------------------------------------------
 
 // isRef returns the object referred to by this (possibly qualified)
 // identifier, or nil if the node is not a referring identifier.
+// A declaring identifier refers to the object it declares.
 func isRef(n ast.Node, info *types.Info) types.Object {
 	switch n := n.(type) {
 	case *ast.Ident:
-		return info.Uses[n]
+		if obj := info.Uses[n]; obj != nil {
+			return obj
+		}
+		return info.Defs[n]
 
 	case *ast.SelectorExpr:
 		if _, ok := info.Selections[n]; !ok {
@@ -22,50 +22,111 @@ import (
 	"golang.org/x/tools/go/ast/astutil"
 )
 
+// A binding records a match of an expression rule, so that the
+// statements that precede its replacement can be inserted before the
+// enclosing statement.
+type binding struct {
+	rule *rule
+	env  map[string]ast.Expr
+}
+
 // transformItem takes a reflect.Value representing a variable of type ast.Node
 // transforms its child elements recursively with apply, and then transforms the
 // actual element if it contains an expression.
-func (tr *Transformer) transformItem(rv reflect.Value) (reflect.Value, bool, map[string]ast.Expr) {
+func (tr *Transformer) transformItem(rv reflect.Value) (reflect.Value, bool, *binding) {
 	// don't bother if val is invalid to start with
 	if !rv.IsValid() {
 		return reflect.Value{}, false, nil
 	}
 
-	rv, changed, newEnv := tr.apply(tr.transformItem, rv)
+	rv, changed, newBinding := tr.apply(tr.transformItem, rv)
 
 	e := rvToExpr(rv)
 	if e == nil {
-		return rv, changed, newEnv
+		return rv, changed, newBinding
 	}
 
 	savedEnv := tr.env
-	tr.env = make(map[string]ast.Expr) // inefficient!  Use a slice of k/v pairs
-
-	if tr.matchExpr(tr.before, e) {
-		if tr.verbose {
-			fmt.Fprintf(os.Stderr, "%s matches %s",
-				astString(tr.fset, tr.before), astString(tr.fset, e))
-			if len(tr.env) > 0 {
-				fmt.Fprintf(os.Stderr, " with:")
-				for name, ast := range tr.env {
-					fmt.Fprintf(os.Stderr, " %s->%s",
-						name, astString(tr.fset, ast))
-				}
+	for _, r := range tr.rules {
+		if r.before == nil {
+			continue // statement rule
+		}
+		if len(r.afterStmts) > 0 && tr.stmtDepth == 0 {
+			continue // no statement before which to insert afterStmts
+		}
+		tr.env = make(map[string]ast.Expr) // inefficient!  Use a slice of k/v pairs
+
+		if tr.matchExpr(r.before, e) {
+			if tr.verbose {
+				fmt.Fprintf(os.Stderr, "%s matches %s",
+					astString(tr.fset, r.before), astString(tr.fset, e))
+				tr.printEnv()
+				fmt.Fprintf(os.Stderr, "\n")
 			}
-			fmt.Fprintf(os.Stderr, "\n")
+			tr.nsubsts++
+
+			// Clone the replacement tree, performing parameter substitution.
+			// We update all positions to n.Pos() to aid comment placement.
+			rv = tr.subst(tr.env, reflect.ValueOf(r.after),
+				reflect.ValueOf(e.Pos()))
+			changed = true
+			newBinding = &binding{r, tr.env}
+			break
 		}
-		tr.nsubsts++
-
-		// Clone the replacement tree, performing parameter substitution.
-		// We update all positions to n.Pos() to aid comment placement.
-		rv = tr.subst(tr.env, reflect.ValueOf(tr.after),
-			reflect.ValueOf(e.Pos()))
-		changed = true
-		newEnv = tr.env
 	}
 	tr.env = savedEnv
 
-	return rv, changed, newEnv
+	return rv, changed, newBinding
+}
+
+// transformStmts replaces each sequence of statements in list that
+// matches the pattern of a statement rule by its replacement.
+func (tr *Transformer) transformStmts(list []ast.Stmt) []ast.Stmt {
+	var out []ast.Stmt
+next:
+	for i := 0; i < len(list); {
+		for _, r := range tr.rules {
+			if r.beforeStmts == nil {
+				continue // expression rule
+			}
+			tr.env = make(map[string]ast.Expr)
+			tr.stmtEnv = make(map[string][]ast.Stmt)
+			if n, ok := tr.matchStmts(r.beforeStmts, list[i:], false); ok && n > 0 {
+				if tr.verbose {
+					fmt.Fprintf(os.Stderr, "%s: %s matches %d statements",
+						tr.fset.Position(list[i].Pos()), r.name, n)
+					tr.printEnv()
+					fmt.Fprintf(os.Stderr, "\n")
+				}
+				tr.nsubsts++
+
+				stmts := tr.subst(tr.env, reflect.ValueOf(r.afterStmts),
+					reflect.ValueOf(list[i].Pos()))
+				out = append(out, stmts.Interface().([]ast.Stmt)...)
+				tr.env, tr.stmtEnv = nil, nil
+				i += n
+				continue next
+			}
+		}
+		out = append(out, list[i])
+		i++
+	}
+	tr.env, tr.stmtEnv = nil, nil
+	return out
+}
+
+// printEnv prints the current wildcard bindings, for verbose output.
+func (tr *Transformer) printEnv() {
+	if len(tr.env)+len(tr.stmtEnv) > 0 {
+		fmt.Fprintf(os.Stderr, " with:")
+		for name, ast := range tr.env {
+			fmt.Fprintf(os.Stderr, " %s->%s",
+				name, astString(tr.fset, ast))
+		}
+		for name, list := range tr.stmtEnv {
+			fmt.Fprintf(os.Stderr, " %s->(%d statements)", name, len(list))
+		}
+	}
 }
 
 // Transform applies the transformation to the specified parsed file,
@@ -84,17 +145,24 @@ func (tr *Transformer) Transform(info *types.Info, pkg *types.Package, file *ast
 	}
 	tr.currentPkg = pkg
 	tr.nsubsts = 0
+	tr.imports = make(map[string]*types.Package)
 
 	if tr.verbose {
-		fmt.Fprintf(os.Stderr, "before: %s\n", astString(tr.fset, tr.before))
-		fmt.Fprintf(os.Stderr, "after: %s\n", astString(tr.fset, tr.after))
-		fmt.Fprintf(os.Stderr, "afterStmts: %s\n", tr.afterStmts)
+		for _, r := range tr.rules {
+			if r.before != nil {
+				fmt.Fprintf(os.Stderr, "%s: %s\n", r.name, astString(tr.fset, r.before))
+				fmt.Fprintf(os.Stderr, "after: %s\n", astString(tr.fset, r.after))
+			} else {
+				fmt.Fprintf(os.Stderr, "%s: %d statements\n", r.name, len(r.beforeStmts))
+			}
+			fmt.Fprintf(os.Stderr, "afterStmts: %s\n", r.afterStmts)
+		}
 	}
 
-	o, changed, _ := tr.apply(tr.transformItem, reflect.ValueOf(file))
-	if changed {
-		panic("BUG")
-	}
+	// (Matches outside any statement, such as in package-level
+	// declarations, may report a change here; they need no
+	// preceding statements.)
+	o, _, _ := tr.apply(tr.transformItem, reflect.ValueOf(file))
 	file2 := o.Interface().(*ast.File)
 
 	// By construction, the root node is unchanged.
@@ -105,11 +173,7 @@ func (tr *Transformer) Transform(info *types.Info, pkg *types.Package, file *ast
 	// Add any necessary imports.
 	// TODO(adonovan): remove no-longer needed imports too.
 	if tr.nsubsts > 0 {
-		pkgs := make(map[string]*types.Package)
-		for obj := range tr.importedObjs {
-			pkgs[obj.Pkg().Path()] = obj.Pkg()
-		}
-
+		pkgs := tr.imports
 		for _, imp := range file.Imports {
 			path, _ := strconv.Unquote(imp.Path.Value)
 			delete(pkgs, path)
@@ -129,6 +193,7 @@ func (tr *Transformer) Transform(info *types.Info, pkg *types.Package, file *ast
 	}
 
 	tr.currentPkg = nil
+	tr.imports = nil
 
 	return tr.nsubsts
 }
@@ -162,6 +227,7 @@ var (
 	selectorExprType = reflect.TypeFor[*ast.SelectorExpr]()
 	objectPtrType    = reflect.TypeFor[*ast.Object]()
 	statementType    = reflect.TypeFor[ast.Stmt]()
+	stmtSliceType    = reflect.TypeFor[[]ast.Stmt]()
 	positionType     = reflect.TypeFor[token.Pos]()
 	scopePtrType     = reflect.TypeFor[*ast.Scope]()
 )
@@ -170,9 +236,10 @@ var (
 // To avoid extra conversions, f operates on the reflect.Value form.
 // f takes a reflect.Value representing the variable to modify of type ast.Node.
 // It returns a reflect.Value containing the transformed value of type ast.Node,
-// whether any change was made, and a map of identifiers to ast.Expr (so we can
+// whether any change was made, and the binding of the match (so we can
 // do contextually correct substitutions in the parent statements).
-func (tr *Transformer) apply(f func(reflect.Value) (reflect.Value, bool, map[string]ast.Expr), val reflect.Value) (reflect.Value, bool, map[string]ast.Expr) {
+// Statement lists are also transformed by the statement rules.
+func (tr *Transformer) apply(f func(reflect.Value) (reflect.Value, bool, *binding), val reflect.Value) (reflect.Value, bool, *binding) {
 	if !val.IsValid() {
 		return reflect.Value{}, false, nil
 	}
@@ -194,56 +261,58 @@ func (tr *Transformer) apply(f func(reflect.Value) (reflect.Value, bool, map[str
 		// no possible rewriting of statements.
 		if v.Type().Elem() != statementType {
 			changed := false
-			var envp map[string]ast.Expr
+			var bindp *binding
 			for i := 0; i < v.Len(); i++ {
 				e := v.Index(i)
-				o, localchanged, env := f(e)
+				o, localchanged, b := f(e)
 				if localchanged {
 					changed = true
-					// we clobber envp here,
+					// we clobber bindp here,
 					// which means if we have two successive
 					// replacements inside the same statement
 					// we will only generate the setup for one of them.
-					envp = env
+					bindp = b
 				}
 				setValue(e, o)
 			}
-			return val, changed, envp
+			return val, changed, bindp
 		}
 
 		// statements are rewritten.
 		var out []ast.Stmt
 		for i := 0; i < v.Len(); i++ {
 			e := v.Index(i)
-			o, changed, env := f(e)
+			tr.stmtDepth++
+			o, changed, b := f(e)
+			tr.stmtDepth--
 			if changed {
-				for _, s := range tr.afterStmts {
-					t := tr.subst(env, reflect.ValueOf(s), reflect.Value{}).Interface()
+				for _, s := range b.rule.afterStmts {
+					t := tr.subst(b.env, reflect.ValueOf(s), reflect.Value{}).Interface()
 					out = append(out, t.(ast.Stmt))
 				}
 			}
 			setValue(e, o)
 			out = append(out, e.Interface().(ast.Stmt))
 		}
-		return reflect.ValueOf(out), false, nil
+		return reflect.ValueOf(tr.transformStmts(out)), false, nil
 	case reflect.Struct:
 		changed := false
-		var envp map[string]ast.Expr
+		var bindp *binding
 		for i := 0; i < v.NumField(); i++ {
 			e := v.Field(i)
-			o, localchanged, env := f(e)
+			o, localchanged, b := f(e)
 			if localchanged {
 				changed = true
-				envp = env
+				bindp = b
 			}
 			setValue(e, o)
 		}
-		return val, changed, envp
+		return val, changed, bindp
 	case reflect.Interface:
 		e := v.Elem()
-		o, changed, env := f(e)
+		o, changed, b := f(e)
 		setValue(v, o)
-		return val, changed, env
+		return val, changed, b
 	}
 	return val, false, nil
 }
@@ -302,6 +371,9 @@ func (tr *Transformer) subst(env map[string]ast.Expr, pattern, pos reflect.Value
 					id = sel.Sel // unqualified
 				} else {
 					id = sel // pkg-qualified
+					if tr.imports != nil {
+						tr.imports[obj.Pkg().Path()] = obj.Pkg()
+					}
 				}
 
 				// Return a clone of id.
@@ -322,6 +394,24 @@ func (tr *Transformer) subst(env map[string]ast.Expr, pattern, pos reflect.Value
 		return pos
 	}
 
+	// Statement lists are copied, replacing list wildcards by
+	// their bindings.
+	if env != nil && pattern.Type() == stmtSliceType {
+		var stmts []ast.Stmt
+		for _, stmt := range pattern.Interface().([]ast.Stmt) {
+			if id := listCall(stmt); id != nil {
+				if list, ok := tr.stmtEnv[id.Name]; ok {
+					for _, s := range list {
+						stmts = append(stmts, tr.subst(nil, reflect.ValueOf(s), reflect.Value{}).Interface().(ast.Stmt))
+					}
+					continue
+				}
+			}
+			stmts = append(stmts, tr.subst(env, reflect.ValueOf(stmt), pos).Interface().(ast.Stmt))
+		}
+		return reflect.ValueOf(stmts)
+	}
+
 	// Otherwise copy.
 	switch p := pattern; p.Kind() {
 	case reflect.Slice:
@@ -0,0 +1,487 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package rename
+
+// This file defines MoveInWorkspace, the module-aware counterpart of Move.
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"golang.org/x/mod/modfile"
+	"golang.org/x/tools/go/packages"
+	"golang.org/x/tools/internal/edit"
+)
+
+// MoveInWorkspace moves the package from, and all its subpackages,
+// to the import path to, updating all imports of them. It is like
+// [Move] but operates in module mode: the packages that may be
+// affected are those of the main modules of the workspace enclosing
+// the directory dir, which is defined by a go.work file, or else by
+// a single go.mod file.
+//
+// The destination may lie in a different module of the workspace
+// than the source. The package from may not be the root of a module.
+// If moveTmpl is not empty, it is a template for a version control
+// command to move the directory, as for [Move].
+//
+// Before making any change, MoveInWorkspace checks for conflicts
+// that would prevent the move, such as an existing package or
+// directory at the destination, an import that the visibility rules
+// for internal packages would forbid after the move, or, for a move
+// to another module, an import of a module that the destination
+// module does not require. It reports all of them in its error.
+//
+// If, after a move to another module, a module of the workspace
+// imports a package of another workspace module that it does not
+// require, such as an importer in the source module of a package moved
+// to the destination module, MoveInWorkspace adds a requirement of
+// that module to its go.mod file, along with a replace directive that
+// points to the module's directory, since workspace modules need not
+// have a published version.
+//
+// Files excluded by build constraints are updated too.
+// If the name of the package from is the final element of its path,
+// its package clause is changed to the final element of the new path,
+// and each import of it is given an explicit name, so that
+// references to it need not change.
+func MoveInWorkspace(dir, from, to, moveTmpl string) error {
+	mods, err := workspaceModules(dir)
+	if err != nil {
+		return err
+	}
+	fromMod := enclosingModule(mods, from)
+	if fromMod == nil {
+		return fmt.Errorf("package %s is not in any module of the workspace", from)
+	}
+	if from == fromMod.Path {
+		return fmt.Errorf("cannot move %s: it is the root of its module", from)
+	}
+	toMod := enclosingModule(mods, to)
+	if toMod == nil {
+		return fmt.Errorf("invalid move destination: %s is not in any module of the workspace", to)
+	}
+	fromDir := filepath.Join(fromMod.Dir, filepath.FromSlash(strings.TrimPrefix(from, fromMod.Path)))
+	toDir := filepath.Join(toMod.Dir, filepath.FromSlash(strings.TrimPrefix(to, toMod.Path)))
+	if fi, err := os.Stat(fromDir); err != nil || !fi.IsDir() {
+		return fmt.Errorf("no directory for package %s", from)
+	}
+
+	// Load all packages of the workspace, including tests.
+	cfg := &packages.Config{
+		Dir:   dir,
+		Mode:  packages.NeedName | packages.NeedFiles | packages.NeedModule,
+		Tests: true,
+	}
+	var patterns []string
+	for _, mod := range mods {
+		patterns = append(patterns, mod.Path+"/...")
+	}
+	pkgs, err := packages.Load(cfg, patterns...)
+	if err != nil {
+		return err
+	}
+
+	m := &modMover{
+		from:         from,
+		to:           to,
+		fromMod:      fromMod,
+		toMod:        toMod,
+		mods:         mods,
+		destinations: make(map[string]string),
+		requires:     make(map[*packages.Module]map[*packages.Module]bool),
+		oldName:      path.Base(from),
+		fset:         token.NewFileSet(),
+		edits:        make(map[string]*edit.Buffer),
+	}
+
+	// Determine the files of the workspace, and the packages to move.
+	type fileInfo struct {
+		name    string
+		pkgPath string // path of the package in the file's directory
+	}
+	var files []fileInfo
+	seen := make(map[string]bool)
+	for _, pkg := range pkgs {
+		if pkg.Module == nil || !pkg.Module.Main || strings.HasSuffix(pkg.PkgPath, ".test") {
+			continue // not in the workspace, or a generated test main package
+		}
+		pkgPath := pkg.PkgPath
+		if strings.HasSuffix(pkg.Name, "_test") {
+			pkgPath = strings.TrimSuffix(pkgPath, "_test") // external test package
+		} else if pkgPath == from {
+			m.oldName = pkg.Name
+		}
+		if pkg.Module.Path == fromMod.Path && isSubpackage(pkgPath, from) {
+			m.destinations[pkgPath] = to + strings.TrimPrefix(pkgPath, from)
+		}
+		for _, name := range slices.Concat(pkg.GoFiles, pkg.IgnoredFiles) {
+			if strings.HasSuffix(name, ".go") && !seen[name] {
+				seen[name] = true
+				files = append(files, fileInfo{name, pkgPath})
+			}
+		}
+	}
+	slices.SortFunc(files, func(x, y fileInfo) int { return strings.Compare(x.name, y.name) })
+
+	// Only a package whose name matches its path is renamed.
+	m.newName = m.oldName
+	if m.oldName == path.Base(from) {
+		m.newName = path.Base(to)
+	}
+
+	// Check the destination.
+	if m.newName != m.oldName && !isValidIdentifier(m.newName) {
+		m.conflictf("the base name of %s is not a valid package name", to)
+	}
+	if _, err := os.Stat(toDir); err == nil {
+		m.conflictf("%s conflicts with existing file or directory %s", to, toDir)
+	}
+	for _, pkg := range pkgs {
+		for _, dest := range m.destinations {
+			if pkg.PkgPath == dest {
+				m.conflictf("package %s already exists", dest)
+			}
+		}
+	}
+	filepath.WalkDir(fromDir, func(name string, d fs.DirEntry, err error) error {
+		if err == nil && !d.IsDir() && d.Name() == "go.mod" {
+			m.conflictf("%s contains a nested module, %s", fromDir, filepath.Dir(name))
+		}
+		return nil
+	})
+	if fromMod.Path != toMod.Path {
+		data, err := os.ReadFile(toMod.GoMod)
+		if err != nil {
+			return err
+		}
+		if m.toModFile, err = modfile.ParseLax(toMod.GoMod, data, nil); err != nil {
+			return err
+		}
+	}
+
+	// Compute the changes to each file, checking its imports.
+	for _, file := range files {
+		if err := m.updateFile(file.name, file.pkgPath); err != nil {
+			return err
+		}
+	}
+
+	if len(m.conflicts) > 0 {
+		return fmt.Errorf("cannot move %s to %s:\n\t%s", from, to, strings.Join(m.conflicts, "\n\t"))
+	}
+
+	// Prepare the move command, if one was supplied.
+	var cmd string
+	if moveTmpl != "" {
+		if cmd, err = moveCmd(moveTmpl, fromDir, toDir); err != nil {
+			return err
+		}
+	}
+
+	// Prepare the new requirements of each go.mod file.
+	modEdits := make(map[string][]byte)
+	for _, mod := range mods {
+		if len(m.requires[mod]) > 0 {
+			data, err := addRequirements(mod, m.requires[mod])
+			if err != nil {
+				return err
+			}
+			if data != nil {
+				modEdits[mod.GoMod] = data
+			}
+		}
+	}
+
+	// Write the files, then move the directory.
+	for _, file := range files {
+		if buf, ok := m.edits[file.name]; ok {
+			if err := writeFile(file.name, buf.Bytes()); err != nil {
+				return err
+			}
+		}
+	}
+	for _, mod := range mods {
+		if data, ok := modEdits[mod.GoMod]; ok {
+			if err := writeFile(mod.GoMod, data); err != nil {
+				return err
+			}
+		}
+	}
+	if err := os.MkdirAll(filepath.Dir(toDir), 0777); err != nil {
+		return err
+	}
+	if cmd != "" {
+		return runMoveCmd(cmd)
+	}
+	return moveDirectory(fromDir, toDir)
+}
+
+// A modMover holds the state of a module-aware move.
+type modMover struct {
+	from, to       string
+	fromMod, toMod *packages.Module
+	mods           []*packages.Module      // main modules of the workspace
+	toModFile      *modfile.File           // go.mod file of toMod, if it differs from fromMod
+	destinations   map[string]string       // maps path of each package to move to its new path
+	oldName        string                  // name of package from
+	newName        string                  // new name of package from
+	fset           *token.FileSet          // for parsing files
+	edits          map[string]*edit.Buffer // changes to each file
+	conflicts      []string
+
+	// requires maps each workspace module to the workspace
+	// modules that it imports after the move.
+	requires map[*packages.Module]map[*packages.Module]bool
+}
+
+func (m *modMover) conflictf(format string, args ...any) {
+	msg := fmt.Sprintf(format, args...)
+	if !slices.Contains(m.conflicts, msg) {
+		m.conflicts = append(m.conflicts, msg)
+	}
+}
+
+// updateFile computes the changes to the named file, which belongs
+// to the package (or test of the package) with path pkgPath, and
+// checks that its imports remain valid after the move.
+func (m *modMover) updateFile(filename, pkgPath string) error {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return err
+	}
+	f, err := parser.ParseFile(m.fset, filename, data, parser.ImportsOnly|parser.ParseComments)
+	if err != nil {
+		return err
+	}
+	buf := edit.NewBuffer(data)
+	changed := false
+	at := func(pos token.Pos) int { return m.fset.File(pos).Offset(pos) }
+
+	newPkgPath, moved := m.destinations[pkgPath]
+	if !moved {
+		newPkgPath = pkgPath
+	}
+
+	// Update the package clause and import comment of package from.
+	if pkgPath == m.from && m.newName != m.oldName {
+		if name := f.Name.Name; name == m.oldName || name == m.oldName+"_test" {
+			buf.Replace(at(f.Name.Pos()), at(f.Name.End()), m.newName+strings.TrimPrefix(name, m.oldName))
+			changed = true
+		}
+	}
+	if moved {
+		for _, cg := range f.Comments {
+			c := cg.List[0]
+			if c.Slash >= f.Name.End() && sameLine(m.fset, c.Slash, f.Name.End()) &&
+				(f.Decls == nil || c.Slash < f.Decls[0].Pos()) {
+				for _, form := range []string{`// import "%s"`, `/* import "%s" */`} {
+					if c.Text == fmt.Sprintf(form, pkgPath) {
+						buf.Replace(at(c.Pos()), at(c.End()), fmt.Sprintf(form, newPkgPath))
+						changed = true
+					}
+				}
+			}
+		}
+	}
+
+	for _, imp := range f.Imports {
+		importPath, err := strconv.Unquote(imp.Path.Value)
+		if err != nil {
+			continue
+		}
+		newPath, ok := m.destinations[importPath]
+		if !ok {
+			newPath = importPath
+		}
+
+		// Check the visibility of internal packages.
+		if canImport(pkgPath, importPath) && !canImport(newPkgPath, newPath) {
+			m.conflictf("%s: package %s would not be allowed to import internal package %s",
+				m.position(imp), newPkgPath, newPath)
+		}
+
+		// Check that a package moved to another module may
+		// still import its dependencies.
+		if moved && !ok && m.toModFile != nil && !m.provides(importPath) {
+			m.conflictf("%s: module %s does not require a module providing %s",
+				m.position(imp), m.toMod.Path, importPath)
+		}
+
+		// Record the workspace modules imported by the
+		// module of the file after the move.
+		if moved || ok {
+			importer, imported := enclosingModule(m.mods, newPkgPath), enclosingModule(m.mods, newPath)
+			if importer != nil && imported != nil && importer != imported {
+				if m.requires[importer] == nil {
+					m.requires[importer] = make(map[*packages.Module]bool)
+				}
+				m.requires[importer][imported] = true
+			}
+		}
+
+		if ok {
+			buf.Replace(at(imp.Path.Pos()), at(imp.Path.End()), strconv.Quote(newPath))
+			if importPath == m.from && m.newName != m.oldName {
+				if imp.Name == nil {
+					buf.Insert(at(imp.Path.Pos()), m.oldName+" ")
+				} else if imp.Name.Name == m.newName {
+					buf.Delete(at(imp.Name.Pos()), at(imp.Path.Pos()))
+				}
+			}
+			changed = true
+		}
+	}
+
+	if changed {
+		m.edits[filename] = buf
+	}
+	return nil
+}
+
+// provides reports whether the import path belongs to the standard
+// library, to a module of the workspace, or to a module required by
+// the destination module.
+func (m *modMover) provides(importPath string) bool {
+	if elem, _, _ := strings.Cut(importPath, "/"); !strings.Contains(elem, ".") {
+		return true // standard library
+	}
+	if enclosingModule(m.mods, importPath) != nil {
+		return true
+	}
+	for _, req := range m.toModFile.Require {
+		if isSubpackage(importPath, req.Mod.Path) {
+			return true
+		}
+	}
+	return false
+}
+
+// addRequirements returns the content of the go.mod file of mod
+// after adding a requirement of each of the workspace modules
+// required that it does not already require, or nil if there are none.
+// Each added requirement is accompanied by a replace directive that
+// points to the directory of the required module, unless mod already
+// replaces it.
+func addRequirements(mod *packages.Module, required map[*packages.Module]bool) ([]byte, error) {
+	data, err := os.ReadFile(mod.GoMod)
+	if err != nil {
+		return nil, err
+	}
+	f, err := modfile.Parse(mod.GoMod, data, nil)
+	if err != nil {
+		return nil, err
+	}
+	var reqs []*packages.Module
+	for req := range required {
+		if !slices.ContainsFunc(f.Require, func(r *modfile.Require) bool { return r.Mod.Path == req.Path }) {
+			reqs = append(reqs, req)
+		}
+	}
+	if len(reqs) == 0 {
+		return nil, nil
+	}
+	slices.SortFunc(reqs, func(x, y *packages.Module) int { return strings.Compare(x.Path, y.Path) })
+	for _, req := range reqs {
+		// This is the version that the go command records for
+		// a module that is only provided by a replacement.
+		if err := f.AddRequire(req.Path, "v0.0.0-00010101000000-000000000000"); err != nil {
+			return nil, err
+		}
+		if slices.ContainsFunc(f.Replace, func(r *modfile.Replace) bool { return r.Old.Path == req.Path }) {
+			continue
+		}
+		rel, err := filepath.Rel(filepath.Dir(mod.GoMod), req.Dir)
+		if err != nil {
+			return nil, err
+		}
+		rel = filepath.ToSlash(rel)
+		if !strings.HasPrefix(rel, "../") {
+			rel = "./" + rel
+		}
+		if err := f.AddReplace(req.Path, "", rel, ""); err != nil {
+			return nil, err
+		}
+	}
+	f.SortBlocks()
+	f.Cleanup()
+	return modfile.Format(f.Syntax), nil
+}
+
+func (m *modMover) position(n ast.Node) string {
+	posn := m.fset.Position(n.Pos())
+	return fmt.Sprintf("%s:%d:%d", posn.Filename, posn.Line, posn.Column)
+}
+
+// workspaceModules returns the main modules of the workspace
+// enclosing dir.
+func workspaceModules(dir string) ([]*packages.Module, error) {
+	cmd := exec.Command("go", "list", "-m", "-json")
+	cmd.Dir = dir
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("go list -m: %v: %s", err, stderr.Bytes())
+	}
+	var mods []*packages.Module
+	for dec := json.NewDecoder(&stdout); dec.More(); {
+		mod := new(packages.Module)
+		if err := dec.Decode(mod); err != nil {
+			return nil, fmt.Errorf("go list -m: %v", err)
+		}
+		if mod.Dir == "" {
+			return nil, fmt.Errorf("not in a module or workspace")
+		}
+		mods = append(mods, mod)
+	}
+	return mods, nil
+}
+
+// enclosingModule returns the module among mods with the longest
+// path that is a prefix of the package path pkgPath, or nil.
+func enclosingModule(mods []*packages.Module, pkgPath string) *packages.Module {
+	var best *packages.Module
+	for _, mod := range mods {
+		if isSubpackage(pkgPath, mod.Path) && (best == nil || len(mod.Path) > len(best.Path)) {
+			best = mod
+		}
+	}
+	return best
+}
+
+// isSubpackage reports whether path equals root or lies beneath it.
+func isSubpackage(path, root string) bool {
+	return path == root || strings.HasPrefix(path, root+"/")
+}
+
+// canImport reports whether the package importer may import the
+// package imported, according to the rules for internal packages.
+func canImport(importer, imported string) bool {
+	// As in the go command, use the final internal element,
+	// which imposes the most restrictive requirement.
+	var parent string
+	switch {
+	case strings.HasSuffix(imported, "/internal"):
+		parent = strings.TrimSuffix(imported, "/internal")
+	case strings.Contains(imported, "/internal/"):
+		parent = imported[:strings.LastIndex(imported, "/internal/")]
+	default:
+		return true // not internal, or internal to the standard library
+	}
+	return isSubpackage(importer, parent)
+}
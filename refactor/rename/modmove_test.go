@@ -0,0 +1,294 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package rename
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"testing"
+
+	"golang.org/x/tools/internal/testenv"
+	"golang.org/x/tools/internal/testfiles"
+	"golang.org/x/tools/txtar"
+)
+
+const moduleSrc = `
+-- go.mod --
+module example.com/m
+
+go 1.21
+-- foo/foo.go --
+package foo // import "example.com/m/foo"
+
+import "example.com/m/foo/sub"
+
+func F() int { return sub.G() }
+-- foo/foo_test.go --
+package foo_test
+
+import (
+	"testing"
+
+	"example.com/m/foo"
+)
+
+func TestF(t *testing.T) { foo.F() }
+-- foo/sub/sub.go --
+package sub
+
+func G() int { return 1 }
+-- main.go --
+package main
+
+import (
+	"fmt"
+
+	"example.com/m/foo"
+	"example.com/m/foo/sub"
+)
+
+func main() { fmt.Println(foo.F(), sub.G()) }
+-- windows.go --
+//go:build windows
+
+package main
+
+import baz "example.com/m/foo"
+
+var _ = baz.F
+-- other/other.go --
+package other
+
+func H() {}
+`
+
+const workspaceSrc = `
+-- go.work --
+go 1.21
+
+use (
+	./a
+	./b
+)
+
+replace example.com/dep v1.0.0 => ./dep
+-- dep/go.mod --
+module example.com/dep
+
+go 1.21
+-- dep/dep.go --
+package dep
+
+const D = 1
+-- a/go.mod --
+module example.com/a
+
+go 1.21
+
+require example.com/dep v1.0.0
+-- a/internal/x/x.go --
+package x
+
+const X = 1
+-- a/util/util.go --
+package util
+
+import (
+	"example.com/a/internal/x"
+	"example.com/dep"
+)
+
+const U = x.X + dep.D
+-- a/strs/strs.go --
+package strs
+
+import "strings"
+
+func Up(s string) string { return strings.ToUpper(s) }
+-- a/main.go --
+package main
+
+import (
+	"example.com/a/strs"
+	"example.com/a/util"
+)
+
+func main() { println(strs.Up("x"), util.U) }
+-- b/go.mod --
+module example.com/b
+
+go 1.21
+-- b/b.go --
+package b
+`
+
+func TestMoveInWorkspace(t *testing.T) {
+	testenv.NeedsGoPackages(t)
+
+	// Flags such as -mod=mod are not allowed in workspace mode.
+	t.Setenv("GOFLAGS", "")
+
+	tests := []struct {
+		name     string
+		src      string
+		from, to string
+		want     map[string]string // expected content of changed or new files
+		gone     []string          // files that should no longer exist
+		wantErr  string            // regexp to match error
+		build    bool              // whether the result should build
+	}{
+		{
+			name: "within module",
+			src:  moduleSrc,
+			from: "example.com/m/foo", to: "example.com/m/bar/baz",
+			want: map[string]string{
+				"bar/baz/foo.go": `package baz // import "example.com/m/bar/baz"
+
+import "example.com/m/bar/baz/sub"
+
+func F() int { return sub.G() }
+`,
+				"bar/baz/foo_test.go": `package baz_test
+
+import (
+	"testing"
+
+	foo "example.com/m/bar/baz"
+)
+
+func TestF(t *testing.T) { foo.F() }
+`,
+				"bar/baz/sub/sub.go": `package sub
+
+func G() int { return 1 }
+`,
+				"main.go": `package main
+
+import (
+	"fmt"
+
+	foo "example.com/m/bar/baz"
+	"example.com/m/bar/baz/sub"
+)
+
+func main() { fmt.Println(foo.F(), sub.G()) }
+`,
+				"windows.go": `//go:build windows
+
+package main
+
+import "example.com/m/bar/baz"
+
+var _ = baz.F
+`,
+			},
+			gone: []string{"foo/foo.go", "foo/sub/sub.go"},
+		},
+		{
+			name: "existing destination",
+			src:  moduleSrc,
+			from: "example.com/m/foo", to: "example.com/m/other",
+			wantErr: `example.com/m/other conflicts with existing file or directory .*other
+	package example.com/m/other already exists`,
+		},
+		{
+			name: "module root",
+			src:  moduleSrc,
+			from: "example.com/m", to: "example.com/m/x",
+			wantErr: "it is the root of its module",
+		},
+		{
+			name: "across modules",
+			src:  workspaceSrc,
+			from: "example.com/a/strs", to: "example.com/b/text/strs",
+			want: map[string]string{
+				"b/text/strs/strs.go": `package strs
+
+import "strings"
+
+func Up(s string) string { return strings.ToUpper(s) }
+`,
+				"a/main.go": `package main
+
+import (
+	"example.com/b/text/strs"
+	"example.com/a/util"
+)
+
+func main() { println(strs.Up("x"), util.U) }
+`,
+				"a/go.mod": `module example.com/a
+
+go 1.21
+
+require (
+	example.com/b v0.0.0-00010101000000-000000000000
+	example.com/dep v1.0.0
+)
+
+replace example.com/b => ../b
+`,
+			},
+			gone:  []string{"a/strs/strs.go"},
+			build: true,
+		},
+		{
+			name: "across modules with conflicts",
+			src:  workspaceSrc,
+			from: "example.com/a/util", to: "example.com/b/util",
+			wantErr: `cannot move example.com/a/util to example.com/b/util:
+	.*util.go:4:2: package example.com/b/util would not be allowed to import internal package example.com/a/internal/x
+	.*util.go:5:2: module example.com/b does not require a module providing example.com/dep`,
+		},
+	}
+
+	// Other tests replace these functions.
+	writeFile, moveDirectory = reallyWriteFile, os.Rename
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			fs, err := txtar.FS(txtar.Parse([]byte(test.src)))
+			if err != nil {
+				t.Fatal(err)
+			}
+			dir := testfiles.CopyToTmp(t, fs)
+
+			err = MoveInWorkspace(dir, test.from, test.to, "")
+			if test.wantErr != "" {
+				if err == nil || !regexp.MustCompile(test.wantErr).MatchString(err.Error()) {
+					t.Fatalf("MoveInWorkspace: got error %v, want match for %q", err, test.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			for name, want := range test.want {
+				got, err := os.ReadFile(filepath.Join(dir, filepath.FromSlash(name)))
+				if err != nil {
+					t.Error(err)
+					continue
+				}
+				if string(got) != want {
+					t.Errorf("%s: got <<%s>>, want <<%s>>", name, got, want)
+				}
+			}
+			for _, name := range test.gone {
+				if _, err := os.Stat(filepath.Join(dir, filepath.FromSlash(name))); err == nil {
+					t.Errorf("%s still exists", name)
+				}
+			}
+			if test.build {
+				cmd := exec.Command("go", "build", "./...")
+				cmd.Dir = filepath.Join(dir, "a")
+				if out, err := cmd.CombinedOutput(); err != nil {
+					t.Errorf("go build: %v\n%s", err, out)
+				}
+			}
+		})
+	}
+}
@@ -333,28 +333,32 @@ func (m *mover) move() error {
 	//      For now, it's required that it does exist.
 
 	if m.cmd != "" {
-		// TODO(matloob): Verify that the windows and plan9 cases are correct.
-		var cmd *exec.Cmd
-		switch runtime.GOOS {
-		case "windows":
-			cmd = exec.Command("cmd", "/c", m.cmd)
-		case "plan9":
-			cmd = exec.Command("rc", "-c", m.cmd)
-		default:
-			cmd = exec.Command("sh", "-c", m.cmd)
-		}
-		cmd.Stderr = os.Stderr
-		cmd.Stdout = os.Stdout
-		if err := cmd.Run(); err != nil {
-			return fmt.Errorf("version control system's move command failed: %v", err)
-		}
-
-		return nil
+		return runMoveCmd(m.cmd)
 	}
 
 	return moveDirectory(m.fromDir, m.toDir)
 }
 
+// runMoveCmd runs the shell command produced by moveCmd.
+func runMoveCmd(command string) error {
+	// TODO(matloob): Verify that the windows and plan9 cases are correct.
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "windows":
+		cmd = exec.Command("cmd", "/c", command)
+	case "plan9":
+		cmd = exec.Command("rc", "-c", command)
+	default:
+		cmd = exec.Command("sh", "-c", command)
+	}
+	cmd.Stderr = os.Stderr
+	cmd.Stdout = os.Stdout
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("version control system's move command failed: %v", err)
+	}
+	return nil
+}
+
 // sameLine reports whether two positions in the same file are on the same line.
 func sameLine(fset *token.FileSet, x, y token.Pos) bool {
 	return fset.Position(x).Line == fset.Position(y).Line